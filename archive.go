@@ -0,0 +1,129 @@
+package mimetype
+
+import (
+	stdtar "archive/tar"
+	stdzip "archive/zip"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrNotArchive is returned by DetectArchive when the input is not an
+// archive whose entries can be listed.
+var ErrNotArchive = errors.New("mimetype: input is not a zip or tar archive")
+
+// ArchiveEntry is a file stored in an archive, as listed by DetectArchive.
+// Err is set, and MIME is nil, when the content of the entry could not be
+// read, like for the zip entries compressed with an unsupported method.
+type ArchiveEntry struct {
+	Name string
+	Size int64
+	MIME *MIME
+	Err  error
+}
+
+// DetectArchive detects the MIME type of the size bytes of r and, when it
+// is a zip or a tar archive, the type of each file stored in it, so the
+// content of uploaded archives can be checked without extracting them.
+// Tar archives compressed with a registered decompressor, like .tar.gz
+// files, are listed too. Subtypes of zip, like docx documents or jar files,
+// are archives as well.
+//
+// Only the head of each entry is decompressed: at most ReadLimit bytes, or
+// the limit set with WithLimit. Each entry is detected with its name as
+// hint. Directories, links and the other special entries are left out.
+// Other archive formats, like 7z, are not supported and ErrNotArchive is
+// returned for them, along with the type of the input.
+func DetectArchive(r io.ReaderAt, size int64, opts ...Option) (*MIME, []ArchiveEntry, error) {
+	c := newConfig(opts)
+	n, m, err := c.detectReaderAt(r, size)
+	if err != nil {
+		return m, nil, err
+	}
+
+	// Archives are told apart by the ancestors of the detected node, since
+	// types like Kustomize have zip, tar and gzip variants sharing their
+	// MIME type. Compressed tar archives come first.
+	if unwrap, ok := unwrapperOf(n); ok {
+		u, err := unwrap(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return m, nil, err
+		}
+		head, err := c.readHead(u)
+		if err != nil {
+			return m, nil, err
+		}
+		if inner, _ := c.detectNode(head); descends(inner, tar) {
+			entries, err := c.tarEntries(io.MultiReader(bytes.NewReader(head), u))
+			return m, entries, err
+		}
+		return m, nil, ErrNotArchive
+	}
+	switch {
+	case descends(n, zip):
+		entries, err := c.zipEntries(r, size)
+		return m, entries, err
+	case descends(n, tar):
+		entries, err := c.tarEntries(io.NewSectionReader(r, 0, size))
+		return m, entries, err
+	}
+
+	return m, nil, ErrNotArchive
+}
+
+func (c *config) zipEntries(r io.ReaderAt, size int64) ([]ArchiveEntry, error) {
+	zr, err := stdzip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ArchiveEntry
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		e := ArchiveEntry{Name: f.Name, Size: int64(f.UncompressedSize64)}
+		rc, err := f.Open()
+		if err == nil {
+			e.MIME, e.Err = c.detectEntry(rc, f.Name)
+			rc.Close()
+		} else {
+			e.Err = err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (c *config) tarEntries(r io.Reader) ([]ArchiveEntry, error) {
+	tr := stdtar.NewReader(r)
+	var entries []ArchiveEntry
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		if h.Typeflag != stdtar.TypeReg && h.Typeflag != stdtar.TypeRegA {
+			continue
+		}
+		e := ArchiveEntry{Name: h.Name, Size: h.Size}
+		e.MIME, e.Err = c.detectEntry(tr, h.Name)
+		entries = append(entries, e)
+	}
+}
+
+// detectEntry detects the head of the archive entry r, using its name as
+// hint instead of the hint and the subtree given for the archive.
+func (c *config) detectEntry(r io.Reader, name string) (*MIME, error) {
+	ec := *c
+	ec.hint, ec.subtree = name, ""
+	head, err := ec.readHead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ec.detect(head), nil
+}
@@ -0,0 +1,166 @@
+package mimetype
+
+import (
+	stdtar "archive/tar"
+	stdzip "archive/zip"
+	"bytes"
+	stdgzip "compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveFiles are the files stored in the archives built by the tests.
+var archiveFiles = []struct {
+	name string
+	mime string
+}{
+	{"png.png", "image/png"},
+	{"pdf.pdf", "application/pdf"},
+	{"dir/html.html", "text/html; charset=utf-8"},
+}
+
+func readArchiveFile(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, filepath.Base(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func checkEntries(t *testing.T, name string, entries []ArchiveEntry) {
+	if len(entries) != len(archiveFiles) {
+		t.Fatalf("%s: expected %d entries, got %d", name, len(archiveFiles), len(entries))
+	}
+	for i, f := range archiveFiles {
+		e := entries[i]
+		if e.Err != nil || e.Name != f.name || e.MIME.String() != f.mime {
+			t.Errorf("%s: expected %s of type %s, got %s of type %s, %v", name, f.name, f.mime, e.Name, e.MIME, e.Err)
+		}
+		if size := int64(len(readArchiveFile(t, f.name))); e.Size != size {
+			t.Errorf("%s: %s: expected size %d, got %d", name, e.Name, size, e.Size)
+		}
+	}
+}
+
+func TestDetectArchiveZip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := stdzip.NewWriter(buf)
+	if _, err := zw.Create("dir/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range archiveFiles {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(readArchiveFile(t, f.name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, entries, err := DetectArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != Zip {
+		t.Errorf("expected %s, got %s", Zip, m)
+	}
+	checkEntries(t, "zip", entries)
+}
+
+func TestDetectArchiveTar(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := stdtar.NewWriter(buf)
+	tw.WriteHeader(&stdtar.Header{Name: "dir/", Typeflag: stdtar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&stdtar.Header{Name: "link", Linkname: "png.png", Typeflag: stdtar.TypeSymlink, Mode: 0644})
+	for _, f := range archiveFiles {
+		data := readArchiveFile(t, f.name)
+		if err := tw.WriteHeader(&stdtar.Header{Name: f.name, Size: int64(len(data)), Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tarData := buf.Bytes()
+
+	m, entries, err := DetectArchive(bytes.NewReader(tarData), int64(len(tarData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != Tar {
+		t.Errorf("expected %s, got %s", Tar, m)
+	}
+	checkEntries(t, "tar", entries)
+
+	buf = &bytes.Buffer{}
+	gw := stdgzip.NewWriter(buf)
+	gw.Write(tarData)
+	gw.Close()
+	m, entries, err = DetectArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != Gzip {
+		t.Errorf("expected %s, got %s", Gzip, m)
+	}
+	checkEntries(t, "tar.gz", entries)
+}
+
+// TestDetectArchiveVariants checks the subtypes having zip, tar and gzip
+// variants with the same MIME type are listed like the format they use.
+func TestDetectArchiveVariants(t *testing.T) {
+	tcs := []struct {
+		file  string
+		first string
+		count int
+	}{
+		{"helm.tgz", "nginx/Chart.yaml", 3},
+		{"compose.tgz", "compose.yaml", 2},
+		{"compose.tar", "compose.yaml", 2},
+		{"compose.zip", "compose.yaml", 2},
+		{"kustomize.tgz", "base/kustomization.yaml", 2},
+		{"kustomize.tar", "base/kustomization.yaml", 2},
+		{"kustomize.zip", "base/kustomization.yaml", 2},
+		{"etckeeper.tgz", "etc/.etckeeper", 3},
+		{"etckeeper.tar", "etc/.etckeeper", 3},
+		{"vagrant.gz.box", "metadata.json", 3},
+		{"vagrant.box", "metadata.json", 3},
+	}
+	for _, tc := range tcs {
+		data := readArchiveFile(t, tc.file)
+		m, entries, err := DetectArchive(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Errorf("%s: %v", tc.file, err)
+			continue
+		}
+		if want := files[tc.file].mime; m.String() != want {
+			t.Errorf("%s: expected %s, got %s", tc.file, want, m)
+		}
+		if len(entries) != tc.count || entries[0].Name != tc.first {
+			t.Errorf("%s: expected %d entries starting with %s, got %d", tc.file, tc.count, tc.first, len(entries))
+		}
+	}
+}
+
+func TestDetectArchiveUnsupported(t *testing.T) {
+	for _, name := range []string{"7z.7z", "png.png", "gz.gz"} {
+		f, err := os.Open(filepath.Join(testDataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, _ := f.Stat()
+		m, entries, err := DetectArchive(f, info.Size())
+		f.Close()
+		if err != ErrNotArchive || entries != nil {
+			t.Errorf("%s: expected ErrNotArchive, got %d entries, %v", name, len(entries), err)
+		}
+		if want := files[name].mime; m.String() != want {
+			t.Errorf("%s: expected %s, got %s", name, want, m)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package mimetype
+
+import (
+	"bytes"
+	"sort"
+)
+
+// AuditReport describes how well the matchers tree is covered
+// by a corpus of test fixtures.
+type AuditReport struct {
+	// Fixtures maps the MIME types of the tree detected for at least one
+	// fixture to the paths of those fixtures.
+	Fixtures map[string][]string
+	// Uncovered lists the MIME types of the tree detected for no fixture.
+	Uncovered []string
+	// AliasOnly lists the MIME types only known from the mappings loaded
+	// with LoadMimeTypes, for which there is no matcher in the tree.
+	AliasOnly []string
+	// Overlaps lists the sibling matchers whose signature prefixes overlap.
+	Overlaps []Overlap
+	// Errors lists the fixtures which could not be read.
+	Errors []error
+}
+
+// Overlap describes two sibling matchers whose fixed signature prefixes
+// overlap, so that inputs starting with Prefix are tried by both of them.
+// First is tried before Second.
+type Overlap struct {
+	Parent        string
+	First, Second string
+	Prefix        string
+}
+
+// Audit detects the files of the fixtures directory tree and reports which
+// MIME types of the matchers tree they cover, along with the MIME types
+// having no matcher and the matchers sharing signature prefixes. The options
+// are used for the detection of the fixtures.
+func Audit(fixtures string, opts ...Option) *AuditReport {
+	r := &AuditReport{Fixtures: map[string][]string{}}
+	for res := range DetectDir(fixtures, opts...) {
+		if res.Err != nil {
+			r.Errors = append(r.Errors, res.Err)
+			continue
+		}
+		mime := mediaType(res.MIME.String())
+		r.Fixtures[mime] = append(r.Fixtures[mime], res.Path)
+	}
+
+	inTree := map[string]bool{}
+	for _, n := range root.flatten() {
+		mime := mediaType(n.mime)
+		if !inTree[mime] && len(r.Fixtures[mime]) == 0 {
+			r.Uncovered = append(r.Uncovered, mime)
+		}
+		inTree[mime] = true
+		r.Overlaps = append(r.Overlaps, n.overlaps()...)
+	}
+
+	extTables.RLock()
+	for mime := range extTables.importMimes {
+		if !inTree[mime] {
+			r.AliasOnly = append(r.AliasOnly, mime)
+		}
+	}
+	extTables.RUnlock()
+	sort.Strings(r.Uncovered)
+	sort.Strings(r.AliasOnly)
+
+	return r
+}
+
+// overlaps returns the pairs of children of n having signature prefixes
+// such that one of them starts with the other.
+func (n *node) overlaps() []Overlap {
+	var out []Overlap
+	for i, a := range n.children {
+		for _, b := range n.children[i+1:] {
+			if a.mime == b.mime {
+				continue
+			}
+			if p, ok := sharedPrefix(a.prefixes, b.prefixes); ok {
+				out = append(out, Overlap{Parent: n.mime, First: a.mime, Second: b.mime, Prefix: string(p)})
+			}
+		}
+	}
+
+	return out
+}
+
+// sharedPrefix returns the shorter of the first two prefixes of a and b
+// such that one of them starts with the other.
+func sharedPrefix(a, b [][]byte) ([]byte, bool) {
+	for _, pa := range a {
+		for _, pb := range b {
+			switch {
+			case bytes.HasPrefix(pa, pb):
+				return pb, true
+			case bytes.HasPrefix(pb, pa):
+				return pa, true
+			}
+		}
+	}
+
+	return nil, false
+}
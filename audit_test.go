@@ -0,0 +1,49 @@
+package mimetype
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	defer func() {
+		extTables.importExt = map[string]string{}
+		extTables.importMimes = map[string][]string{}
+	}()
+	types := "image/png png\napplication/x-custom cst\n"
+	if err := LoadMimeTypes(strings.NewReader(types)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := Audit(testDataDir)
+	if len(r.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	if len(r.Fixtures["image/png"]) == 0 {
+		t.Errorf("expected fixtures for image/png")
+	}
+	for _, mime := range r.Uncovered {
+		if len(r.Fixtures[mime]) > 0 {
+			t.Errorf("%s is both covered and uncovered", mime)
+		}
+		if mime == "image/png" {
+			t.Errorf("image/png reported as uncovered")
+		}
+	}
+	if len(r.AliasOnly) != 1 || r.AliasOnly[0] != "application/x-custom" {
+		t.Errorf("expected only application/x-custom without matcher, got %v", r.AliasOnly)
+	}
+
+	found := false
+	for _, o := range r.Overlaps {
+		if o.First == WebP && o.Second == RMID && o.Prefix == "RIFF" && o.Parent == root.mime {
+			found = true
+		}
+		if o.First == o.Second {
+			t.Errorf("overlap of %s with itself", o.First)
+		}
+	}
+	if !found {
+		t.Errorf("expected WebP and RMID to overlap on RIFF, got %v", r.Overlaps)
+	}
+}
@@ -0,0 +1,115 @@
+package mimetype
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FilesError is returned by DetectFiles when some of the files could not be
+// read. It maps the path of every failing file to its error.
+type FilesError map[string]error
+
+func (e FilesError) Error() string {
+	paths := make([]string, 0, len(e))
+	for p := range e {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	msgs := make([]string, len(paths))
+	for i, p := range paths {
+		msgs[i] = e[p].Error()
+	}
+
+	return fmt.Sprintf("mimetype: failed to detect %d files: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// DetectFiles detects the MIME type of the provided files using at most
+// workers goroutines. When workers is not positive, the number of CPUs is used.
+//
+// The returned map holds the result of every file read successfully. If some
+// files could not be read, the error is a FilesError holding their errors.
+// Files larger than the size set by WithMaxSize are left out of both.
+func DetectFiles(paths []string, workers int, opts ...Option) (map[string]*MIME, error) {
+	c := newConfig(opts)
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]*MIME, len(paths))
+		errs    = FilesError{}
+		jobs    = make(chan string)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			in := make([]byte, c.readLimit())
+			for p := range jobs {
+				m, err := detectFileMIME(p, in, c)
+				mu.Lock()
+				if err != nil {
+					errs[p] = err
+				} else if m != nil {
+					results[p] = m
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range paths {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return results, errs
+	}
+
+	return results, nil
+}
+
+// detectFileMIME detects the MIME type of file using buf as read buffer.
+// It returns a nil result if the file is larger than the configured maximum size.
+func detectFileMIME(file string, buf []byte, c *config) (*MIME, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if c.maxSize > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > c.maxSize {
+			return nil, nil
+		}
+	}
+	in, err := readInto(f, buf)
+	if err != nil {
+		return nil, err
+	}
+	if need := c.deepLimit(in); need > 0 {
+		grown := make([]byte, need)
+		copy(grown, in)
+		more, err := readInto(f, grown[len(in):])
+		if err != nil {
+			return nil, err
+		}
+		in = grown[:len(in)+len(more)]
+	}
+
+	return c.detect(in), nil
+}
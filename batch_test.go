@@ -0,0 +1,36 @@
+package mimetype
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFiles(t *testing.T) {
+	var paths []string
+	for fName := range files {
+		paths = append(paths, filepath.Join(testDataDir, fName))
+	}
+	inexistent := filepath.Join(testDataDir, "inexistent.file")
+	paths = append(paths, inexistent)
+
+	results, err := DetectFiles(paths, 4)
+	fErr, ok := err.(FilesError)
+	if !ok {
+		t.Fatalf("expected FilesError, got %v", err)
+	}
+	if len(fErr) != 1 || fErr[inexistent] == nil {
+		t.Errorf("expected only %s to fail, got %v", inexistent, fErr)
+	}
+	if len(results) != len(files) {
+		t.Errorf("expected %d results, got %d", len(files), len(results))
+	}
+	for fName, n := range files {
+		if m := results[filepath.Join(testDataDir, fName)]; m == nil || m.String() != n.mime {
+			t.Errorf("%s: expected %s, got %v", fName, n.mime, m)
+		}
+	}
+
+	if _, err := DetectFiles(paths[:1], 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package mimetype
+
+import (
+	"bytes"
+	"container/list"
+	"hash/crc32"
+	"sync"
+)
+
+// Cache is a bounded cache of detection results, evicting the least
+// recently used ones. It is keyed by the examined bytes, so inputs having
+// the same head are matched only once. A Cache is safe for
+// concurrent use and can be shared by detections using different options.
+//
+// The results cached are not invalidated when the matchers tree changes,
+// so Extend, Reorder and LoadMagicFile must be called before the cache is
+// used, or followed by a call to Purge.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[cacheKey]*list.Element
+}
+
+// cacheKey identifies an input by its length and by two checksums of it,
+// computed with hardware accelerated polynomials. The checksums are not
+// cryptographic and colliding inputs are easy to craft, so the examined
+// bytes are kept in the entry and compared on every lookup. The node the
+// detection starts from is part of the key, for the detections using
+// WithSubtree.
+type cacheKey struct {
+	start      *node
+	length     int
+	crcC, crcI uint32
+}
+
+type cacheEntry struct {
+	key cacheKey
+	in  []byte
+	n   *node
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// NewCache returns a cache holding at most size detection results. Each
+// result keeps a copy of the bytes examined, so the cache uses up to size
+// times the read limit of memory.
+func NewCache(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Cache{
+		size:    size,
+		lru:     list.New(),
+		entries: map[cacheKey]*list.Element{},
+	}
+}
+
+// WithCache makes the detection look up its result in cache before running
+// the matchers, and store it there afterwards.
+func WithCache(cache *Cache) Option {
+	return func(c *config) {
+		c.cache = cache
+	}
+}
+
+// Len returns the number of results in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Purge removes all the results from the cache.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = map[cacheKey]*list.Element{}
+}
+
+func newCacheKey(start *node, in []byte) cacheKey {
+	return cacheKey{
+		start:  start,
+		length: len(in),
+		crcC:   crc32.Checksum(in, castagnoli),
+		crcI:   crc32.ChecksumIEEE(in),
+	}
+}
+
+// get returns the node cached for key, or nil if there is none or if the
+// cached input differs from in.
+func (c *Cache) get(key cacheKey, in []byte) *node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*cacheEntry)
+	if !bytes.Equal(entry.in, in) {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+
+	return entry.n
+}
+
+// add caches n for the input in identified by key, evicting the least
+// recently used result if the cache is full. A colliding input replaces
+// the result cached for key.
+func (c *Cache) add(key cacheKey, in []byte, n *node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		if !bytes.Equal(entry.in, in) {
+			entry.in = append([]byte(nil), in...)
+			entry.n = n
+		}
+		c.lru.MoveToFront(e)
+		return
+	}
+	entry := &cacheEntry{key: key, in: append([]byte(nil), in...), n: n}
+	c.entries[key] = c.lru.PushFront(entry)
+	if c.lru.Len() > c.size {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).key)
+	}
+}
+
+// detect returns the node matching in, starting from start,
+// from the cache if possible. Cache hits are reported to the installed
+// hooks like any other detection.
+func (c *Cache) detect(start *node, in []byte, parallel bool) *node {
+	return observed(start, in, func() *node {
+		key := newCacheKey(start, in)
+		if n := c.get(key, in); n != nil {
+			return n
+		}
+		n := matchFrom(start, in, parallel)
+		c.add(key, in, n)
+
+		return n
+	})
+}
@@ -0,0 +1,138 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache(len(files))
+	for i := 0; i < 2; i++ {
+		for f, n := range files {
+			data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m, _ := Detect(data, WithCache(cache)); m != n.mime {
+				t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+			}
+		}
+	}
+
+	zip := []byte("PK\x03\x04")
+	if m, _ := Detect(zip, WithCache(cache)); m != "application/zip" {
+		t.Errorf("expected application/zip, got %s", m)
+	}
+	// The same input detected under another parent is not a cache hit.
+	if m, _ := DetectUnder("application/x-tar", zip, WithCache(cache)); !m.Is("application/x-tar") {
+		t.Errorf("expected application/x-tar, got %s", m)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected an empty cache after Purge, got %d results", cache.Len())
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache := NewCache(2)
+	inputs := [][]byte{[]byte("\x89PNG\r\n\x1A\n"), []byte("%PDF-1.7"), []byte("GIF89a"), []byte("%PDF-1.7")}
+	for _, in := range inputs {
+		Detect(in, WithCache(cache))
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 cached results, got %d", cache.Len())
+	}
+	if n := cache.get(newCacheKey(root, inputs[0]), inputs[0]); n != nil {
+		t.Errorf("expected the least recently used result to be evicted, got %s", n.mime)
+	}
+	if n := cache.get(newCacheKey(root, inputs[1]), inputs[1]); n != pdf {
+		t.Errorf("expected %s to be cached", pdf.mime)
+	}
+}
+
+func TestCacheCollision(t *testing.T) {
+	cache := NewCache(4)
+	in := []byte("<html><body></body></html>")
+	// An entry stored under the key of html, as a crafted input having
+	// the same checksums would, must not be returned for in.
+	cache.add(newCacheKey(root, in), []byte("\x89PNG\r\n\x1A\n"), png)
+	if m, _ := Detect(in, WithCache(cache)); m != "text/html; charset=utf-8" {
+		t.Errorf("expected text/html; charset=utf-8, got %s", m)
+	}
+	if n := cache.get(newCacheKey(root, in), in); n != html {
+		t.Errorf("expected the colliding result to be replaced")
+	}
+}
+
+func TestCacheHooks(t *testing.T) {
+	defer SetHooks(Hooks{})
+
+	var mimes []string
+	m := NewMetrics()
+	l := &recordingLogger{}
+	SetHooks(Hooks{
+		OnDetect: func(mime string, took time.Duration, bytesRead int) {
+			mimes = append(mimes, mime)
+		},
+		Metrics: m,
+		Logger:  l,
+	})
+
+	cache := NewCache(4)
+	in := []byte("\x89PNG\r\n\x1A\n")
+	for i := 0; i < 3; i++ {
+		Detect(in, WithCache(cache))
+	}
+	// The cache hits are reported like the first detection.
+	if len(mimes) != 3 || mimes[2] != "image/png" {
+		t.Errorf("expected 3 image/png OnDetect calls, got %v", mimes)
+	}
+	if len(l.msgs) != 3 {
+		t.Errorf("expected 3 log records, got %d", len(l.msgs))
+	}
+	if d := m.snapshot().Detections; d != 3 {
+		t.Errorf("expected 3 detections in the metrics, got %d", d)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := NewCache(4)
+	inputs := [][]byte{[]byte("\x89PNG\r\n\x1A\n"), []byte("%PDF-1.7"), []byte("GIF89a"), []byte("plain text"), []byte("{}")}
+	expected := []string{"image/png", "application/pdf", "image/gif", "text/plain", "application/json"}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				k := (g + i) % len(inputs)
+				if m, _ := Detect(inputs[k], WithCache(cache)); m != expected[k] {
+					t.Errorf("expected %s, got %s", expected[k], m)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkCache(b *testing.B) {
+	data := bytes.Repeat([]byte("plain text "), matchers.ReadLimit/11)
+	b.Run("uncached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data)
+		}
+	})
+	cache := NewCache(16)
+	b.Run("cached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data, WithCache(cache))
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package mimetype
+
+import (
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// charset guesses the character set of a text input.
+func charset(in []byte) string {
+	if cs := matchers.WideCharset(in); cs != "" {
+		return cs
+	}
+	// The input may be truncated in the middle of a character.
+	for i := 0; i < utf8.UTFMax && i < len(in); i++ {
+		if utf8.Valid(in[:len(in)-i]) {
+			return "utf-8"
+		}
+	}
+	if cs := cjkCharset(in); cs != "" {
+		return cs
+	}
+
+	return singleByteCharset(in)
+}
+
+// dbcs describes a double-byte charset, where the characters are either
+// single bytes or a lead byte followed by a trail byte.
+type dbcs struct {
+	lead, trail, single func(b byte) bool
+}
+
+var (
+	shiftJIS = dbcs{
+		lead:   func(b byte) bool { return 0x81 <= b && b <= 0x9F || 0xE0 <= b && b <= 0xFC },
+		trail:  func(b byte) bool { return 0x40 <= b && b <= 0xFC && b != 0x7F },
+		single: func(b byte) bool { return b < 0x80 || 0xA1 <= b && b <= 0xDF }, // half-width katakana
+	}
+	gbk = dbcs{
+		lead:   func(b byte) bool { return 0x81 <= b && b <= 0xFE },
+		trail:  func(b byte) bool { return 0x40 <= b && b <= 0xFE && b != 0x7F },
+		single: func(b byte) bool { return b <= 0x80 }, // 0x80 is the euro sign
+	}
+	eucKR = dbcs{
+		lead:   func(b byte) bool { return 0xA1 <= b && b <= 0xFE },
+		trail:  func(b byte) bool { return 0xA1 <= b && b <= 0xFE },
+		single: func(b byte) bool { return b < 0x80 },
+	}
+)
+
+// dbcsStats counts the double-byte characters of an input.
+type dbcsStats struct {
+	chars int
+	// high counts the characters whose trail byte is not ASCII too.
+	high  int
+	leads [256]int
+}
+
+// scan returns the statistics of in, and false if in is not valid in the
+// charset. A lead byte ending the input is ignored, since the input may be
+// truncated in the middle of a character.
+func (cs dbcs) scan(in []byte) (dbcsStats, bool) {
+	var s dbcsStats
+	for i := 0; i < len(in); i++ {
+		b := in[i]
+		switch {
+		case cs.single(b):
+		case !cs.lead(b):
+			return s, false
+		case i+1 == len(in):
+		case !cs.trail(in[i+1]):
+			return s, false
+		default:
+			s.chars++
+			s.leads[b]++
+			if in[i+1] >= 0x80 {
+				s.high++
+			}
+			i++
+		}
+	}
+
+	return s, true
+}
+
+// cjkCharset returns the charset of Chinese, Japanese or Korean text in
+// one of their legacy double-byte charsets, or an empty string.
+//
+// Text in these charsets is mostly made of double-byte characters with
+// both bytes outside of ASCII, unlike Latin text whose non-ASCII letters
+// are isolated, which would often be valid double-byte text too. Among
+// the valid charsets, the most frequent lead bytes tell the language: the
+// kana of Shift_JIS start with 0x82 or 0x83, and the Hangul syllables of
+// EUC-KR are encoded below the lead byte 0xC9, while the Chinese
+// characters of GBK spread beyond it.
+func cjkCharset(in []byte) string {
+	const minChars = 2
+	sjis, sjisOK := shiftJIS.scan(in)
+	gb, gbOK := gbk.scan(in)
+	kr, krOK := eucKR.scan(in)
+
+	// Japanese text is rarely written without kana.
+	if kana := sjis.leads[0x82] + sjis.leads[0x83]; sjisOK && kana >= minChars && kana*4 >= sjis.chars {
+		return "shift_jis"
+	}
+	if !gbOK || gb.chars < minChars || gb.high*2 < gb.chars {
+		return ""
+	}
+	if krOK {
+		beyond := 0
+		for b := 0xC9; b <= 0xFE; b++ {
+			beyond += kr.leads[b]
+		}
+		if beyond*20 < kr.chars {
+			return "euc-kr"
+		}
+	}
+
+	return "gbk"
+}
+
+// singleByteCharset returns the most likely single-byte charset of text
+// which is not valid UTF-8: windows-1251 or iso-8859-5 for Cyrillic text,
+// iso-8859-2 for Central European text, windows-1252 when the printable
+// characters it defines in the 0x80-0x9F range are used, and iso-8859-1
+// otherwise.
+func singleByteCharset(in []byte) string {
+	var (
+		letters   int // ASCII letters and bytes above 0xBF
+		cyrillic  int // bytes above 0xBF following another one
+		iso5      int // bytes from 0xD0 to 0xDF: lowercase in iso-8859-5 only
+		cp1251    int // bytes above 0xEF: lowercase in windows-1251 only
+		central   int // letters of iso-8859-2 in the 0xA1-0xBF range
+		c1        int // printable windows-1252 characters from 0x80 to 0x9F
+		c1Invalid bool
+	)
+	for i, b := range in {
+		switch {
+		case 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z':
+			letters++
+		case b >= 0xC0:
+			letters++
+			if i > 0 && in[i-1] >= 0xC0 {
+				cyrillic++
+			}
+			if 0xD0 <= b && b <= 0xDF {
+				iso5++
+			} else if b >= 0xF0 {
+				cp1251++
+			}
+		case 0xA1 <= b && b <= 0xBF:
+			if isCentralLetter(b) && (isASCIILetter(in, i-1) || isASCIILetter(in, i+1)) {
+				central++
+			}
+		case 0x80 <= b && b <= 0x9F:
+			switch b {
+			case 0x81, 0x8D, 0x8F, 0x90, 0x9D:
+				c1Invalid = true
+			default:
+				c1++
+			}
+		}
+	}
+
+	switch {
+	// Cyrillic words are made of consecutive non-ASCII letters only.
+	case cyrillic*3 >= letters && cyrillic > 0:
+		// Most letters are lowercase, and the two charsets place them in
+		// different ranges.
+		if iso5 > cp1251 {
+			return "iso-8859-5"
+		}
+		return "windows-1251"
+	case central >= 2:
+		return "iso-8859-2"
+	case c1 > 0 && !c1Invalid:
+		return "windows-1252"
+	}
+
+	return "iso-8859-1"
+}
+
+// isCentralLetter reports whether b, in the 0xA1-0xBF range, is a letter
+// in iso-8859-2, like ł or ś, and a symbol rarely found inside words in
+// iso-8859-1, like ³ or ¶.
+func isCentralLetter(b byte) bool {
+	switch b {
+	case 0xA2, 0xA4, 0xA7, 0xA8, 0xAA, 0xAD, 0xB0, 0xB2, 0xB4, 0xB5, 0xB7, 0xB8, 0xBA, 0xBD:
+		return false
+	}
+
+	return true
+}
+
+func isASCIILetter(in []byte, i int) bool {
+	if i < 0 || i >= len(in) {
+		return false
+	}
+	b := in[i]
+
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
@@ -0,0 +1,34 @@
+package mimetype
+
+import "testing"
+
+func TestCharset(t *testing.T) {
+	tcs := []struct {
+		name, in, charset string
+	}{
+		{"ascii", "plain text", "utf-8"},
+		{"utf-8", "caf\xc3\xa9", "utf-8"},
+		{"utf-16", "\xff\xfeh\x00i\x00", "utf-16le"},
+		{"japanese", "\x82\xb1\x82\xea\x82\xcd\x93\xfa\x96{\x8c\xea\x82\xcc\x83e\x83L\x83X\x83g\x82\xc5\x82\xb7\x81B\x83t\x83@\x83C\x83\x8b\x82\xcc\x95\xb6\x8e\x9a\x83R\x81[\x83h\x82\xf0\x94\xbb\x92\xe8\x82\xb5\x82\xdc\x82\xb7\x81B", "shift_jis"},
+		{"chinese", "\xd5\xe2\xca\xc7\xd2\xbb\xb8\xf6\xd6\xd0\xce\xc4\xce\xc4\xb1\xbe\xce\xc4\xbc\xfe\xa3\xac\xce\xd2\xc3\xc7\xd0\xe8\xd2\xaa\xbc\xec\xb2\xe2\xcb\xfc\xb5\xc4\xd7\xd6\xb7\xfb\xb1\xe0\xc2\xeb\xa1\xa3", "gbk"},
+		{"korean", "\xc0\xcc\xb0\xcd\xc0\xba \xc7\xd1\xb1\xb9\xbe\xee \xc5\xd8\xbd\xba\xc6\xae \xc6\xc4\xc0\xcf\xc0\xd4\xb4\xcf\xb4\xd9. \xb9\xae\xc0\xda \xc0\xce\xc4\xda\xb5\xf9\xc0\xbb \xb0\xa8\xc1\xf6\xc7\xd5\xb4\xcf\xb4\xd9.", "euc-kr"},
+		{"russian windows-1251", "\xdd\xf2\xee \xf2\xe5\xea\xf1\xf2\xee\xe2\xfb\xe9 \xf4\xe0\xe9\xeb \xed\xe0 \xf0\xf3\xf1\xf1\xea\xee\xec \xff\xe7\xfb\xea\xe5, \xea\xee\xe4\xe8\xf0\xee\xe2\xea\xe0 \xee\xef\xf0\xe5\xe4\xe5\xeb\xff\xe5\xf2\xf1\xff.", "windows-1251"},
+		{"russian iso-8859-5", "\xcd\xe2\xde \xe2\xd5\xda\xe1\xe2\xde\xd2\xeb\xd9 \xe4\xd0\xd9\xdb \xdd\xd0 \xe0\xe3\xe1\xe1\xda\xde\xdc \xef\xd7\xeb\xda\xd5, \xda\xde\xd4\xd8\xe0\xde\xd2\xda\xd0 \xde\xdf\xe0\xd5\xd4\xd5\xdb\xef\xd5\xe2\xe1\xef.", "iso-8859-5"},
+		{"polish", "Za\xbf\xf3\xb3\xe6 g\xea\xb6l\xb1 ja\xbc\xf1, \xb3\xf3d\xbc p\xb3ynie szybko po jeziorze.", "iso-8859-2"},
+		{"french windows-1252", "\x93Le caf\xe9 est pr\xeat\x94 \x96 dit-il\x85 \xe0 l\x92h\xf4tel.", "windows-1252"},
+		{"french", "Le caf\xe9 est pr\xeat, dit-il \xe0 l'h\xf4tel pr\xe8s de la for\xeat.", "iso-8859-1"},
+		{"german", "Gr\xf6\xdfere \xdcbungen f\xfcr \xc4pfel und B\xe4ren.", "iso-8859-1"},
+		{"short russian", "\xdd\xf2\xee \xf2\xe5\xea\xf1", "windows-1251"},
+		{"latin prices", "Prix: 12\xb0C, 3\xbd kg, \xa35", "iso-8859-1"},
+	}
+	for _, tc := range tcs {
+		if cs := charset([]byte(tc.in)); cs != tc.charset {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.charset, cs)
+		}
+	}
+
+	m := DetectMIME([]byte(tcs[3].in), WithCharset())
+	if m.String() != "text/plain; charset=shift_jis" {
+		t.Errorf("expected text/plain; charset=shift_jis, got %s", m)
+	}
+}
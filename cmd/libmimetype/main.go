@@ -0,0 +1,106 @@
+// Command libmimetype exports the detection as a C shared library, so
+// programs written in Python, Ruby, C++ and other languages with a C foreign
+// function interface can embed it instead of running the mimetype command.
+// Build it with:
+//
+//	go build -buildmode=c-shared -o libmimetype.so ./cmd/libmimetype
+//
+// which writes libmimetype.h along with the library. The functions write the
+// MIME type and the extension of the input, NUL terminated, to buffers
+// provided by the caller, so no memory is shared between the library and
+// its callers:
+//
+//	int mimetype_abi_version(void);
+//	int mimetype_detect_bytes(char *data, size_t n,
+//		char *mime, size_t mimeSize, char *ext, size_t extSize);
+//	int mimetype_detect_file(char *path,
+//		char *mime, size_t mimeSize, char *ext, size_t extSize);
+//
+// They return MIMETYPE_OK, MIMETYPE_ERR_SHORT_BUFFER when a result did not
+// fit its buffer, in which case it is truncated, or MIMETYPE_ERR_FILE when
+// the file could not be read. MIMETYPE_MAX_MIME is enough for any result.
+// ext may be NULL when the extension is not needed. The data passed to
+// mimetype_detect_bytes is only read during the call, and only its head is
+// examined. From Python:
+//
+//	lib = ctypes.CDLL("./libmimetype.so")
+//	mime = ctypes.create_string_buffer(256)
+//	lib.mimetype_detect_bytes(data, len(data), mime, len(mime), None, 0)
+//
+// The functions, their signatures and the meaning of the return values only
+// change along with MIMETYPE_ABI_VERSION, which callers can compare with
+// the value returned by mimetype_abi_version to check the library they load.
+package main
+
+/*
+#include <stddef.h>
+
+#define MIMETYPE_ABI_VERSION 1
+
+#define MIMETYPE_OK 0
+#define MIMETYPE_ERR_SHORT_BUFFER -1
+#define MIMETYPE_ERR_FILE -2
+
+#define MIMETYPE_MAX_MIME 256
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// main is required by -buildmode=c-shared, but never called.
+func main() {}
+
+//export mimetype_abi_version
+func mimetype_abi_version() C.int {
+	return C.MIMETYPE_ABI_VERSION
+}
+
+//export mimetype_detect_bytes
+func mimetype_detect_bytes(data *C.char, n C.size_t, mime *C.char, mimeSize C.size_t, ext *C.char, extSize C.size_t) C.int {
+	// Only the head of the input is examined: copy just that.
+	if n > matchers.ReadLimit {
+		n = matchers.ReadLimit
+	}
+	m, e := mimetype.Detect(C.GoBytes(unsafe.Pointer(data), C.int(n)))
+	return write(m, e, mime, mimeSize, ext, extSize)
+}
+
+//export mimetype_detect_file
+func mimetype_detect_file(path *C.char, mime *C.char, mimeSize C.size_t, ext *C.char, extSize C.size_t) C.int {
+	m, e, err := mimetype.DetectFile(C.GoString(path))
+	if err != nil {
+		return C.MIMETYPE_ERR_FILE
+	}
+	return write(m, e, mime, mimeSize, ext, extSize)
+}
+
+// write copies the results to the buffers of the caller.
+func write(m, e string, mime *C.char, mimeSize C.size_t, ext *C.char, extSize C.size_t) C.int {
+	ok := copyString(mime, mimeSize, m)
+	ok = copyString(ext, extSize, e) && ok
+	if !ok {
+		return C.MIMETYPE_ERR_SHORT_BUFFER
+	}
+	return C.MIMETYPE_OK
+}
+
+// copyString copies s, NUL terminated, to the size bytes of dst, truncating
+// it when needed. It reports whether s fit. A NULL dst means the result is
+// not wanted.
+func copyString(dst *C.char, size C.size_t, s string) bool {
+	if dst == nil {
+		return true
+	}
+	if size == 0 {
+		return false
+	}
+	b := (*[1 << 30]byte)(unsafe.Pointer(dst))[:size:size]
+	n := copy(b[:size-1], s)
+	b[n] = 0
+	return n == len(s)
+}
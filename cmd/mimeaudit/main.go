@@ -0,0 +1,84 @@
+// Command mimeaudit reports how well the matchers tree is covered by a
+// corpus of test fixtures: the MIME types detected for no fixture, the MIME
+// types loaded from mime.types files which have no matcher, and the sibling
+// matchers sharing signature prefixes.
+//
+// Usage:
+//
+//	mimeaudit [-mimetypes file] [-limit n] [-v] dir
+//
+// The exit status is 1 when some MIME types are not covered by fixtures.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments args and returns its exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mimeaudit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mimeTypes := fs.String("mimetypes", "", "mime.types file whose MIME types are checked for matchers")
+	limit := fs.Int("limit", 0, "number of bytes examined during detection; 0 means the default")
+	verbose := fs.Bool("v", false, "also list the fixtures of each covered MIME type")
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: mimeaudit [-mimetypes file] [-limit n] [-v] dir")
+		return 2
+	}
+	if *mimeTypes != "" {
+		if err := mimetype.LoadMimeTypesFile(*mimeTypes); err != nil {
+			fmt.Fprintln(stderr, "mimeaudit:", err)
+			return 2
+		}
+	}
+
+	r := mimetype.Audit(fs.Arg(0), mimetype.WithLimit(*limit))
+	for _, err := range r.Errors {
+		fmt.Fprintln(stderr, "mimeaudit:", err)
+	}
+
+	if *verbose {
+		var covered []string
+		for mime := range r.Fixtures {
+			covered = append(covered, mime)
+		}
+		sort.Strings(covered)
+		fmt.Fprintf(stdout, "covered (%d):\n", len(covered))
+		for _, mime := range covered {
+			fmt.Fprintf(stdout, "\t%s\t%v\n", mime, r.Fixtures[mime])
+		}
+	}
+	fmt.Fprintf(stdout, "without fixtures (%d):\n", len(r.Uncovered))
+	for _, mime := range r.Uncovered {
+		fmt.Fprintf(stdout, "\t%s\n", mime)
+	}
+	fmt.Fprintf(stdout, "without matchers (%d):\n", len(r.AliasOnly))
+	for _, mime := range r.AliasOnly {
+		fmt.Fprintf(stdout, "\t%s\n", mime)
+	}
+	fmt.Fprintf(stdout, "overlapping signatures (%d):\n", len(r.Overlaps))
+	for _, o := range r.Overlaps {
+		fmt.Fprintf(stdout, "\t%s: %s before %s on %q\n", o.Parent, o.First, o.Second, o.Prefix)
+	}
+
+	if len(r.Uncovered) > 0 {
+		return 1
+	}
+
+	return 0
+}
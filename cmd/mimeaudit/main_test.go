@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDataDir = "../../testdata"
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimeaudit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"png.png", "pdf.pdf"} {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	types := filepath.Join(dir, "mime.types")
+	if err := ioutil.WriteFile(types, []byte("application/x-audit-only aud\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	png := filepath.Join(dir, "png.png")
+
+	tcs := []struct {
+		name   string
+		args   []string
+		status int
+		out    []string // substrings of the standard output
+		absent []string // substrings the standard output must not have
+	}{
+		{"no directory", nil, 2, nil, nil},
+		{"two directories", []string{dir, dir}, 2, nil, nil},
+		{"unknown flag", []string{"-unknown", dir}, 2, nil, nil},
+		{"missing mime.types", []string{"-mimetypes", filepath.Join(dir, "missing"), dir}, 2, nil, nil},
+		{"uncovered", []string{dir}, 1, []string{
+			"without fixtures (",
+			"\tapplication/zip\n",
+			"without matchers (0):\n",
+			"overlapping signatures (",
+		}, []string{"covered (", "\timage/png\n", "\tapplication/pdf\n"}},
+		{"verbose", []string{"-v", dir}, 1, []string{
+			"covered (3):\n",
+			"\tapplication/pdf\t[" + filepath.Join(dir, "pdf.pdf") + "]\n",
+			"\timage/png\t[" + png + "]\n",
+		}, nil},
+		{"mime.types", []string{"-mimetypes", types, dir}, 1, []string{"without matchers (1):\n\tapplication/x-audit-only\n"}, nil},
+	}
+	for _, tc := range tcs {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := run(tc.args, stdout, stderr)
+		if status != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.name, tc.status, status, stderr)
+		}
+		if status == 2 && stderr.Len() == 0 {
+			t.Errorf("%s: expected an error message", tc.name)
+		}
+		for _, s := range tc.out {
+			if !strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: expected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+		for _, s := range tc.absent {
+			if strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: unexpected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+	}
+}
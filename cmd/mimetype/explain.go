@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// explainer prints the explanation of the detection of files, with -explain.
+type explainer struct {
+	w     io.Writer
+	opts  []mimetype.Option
+	limit int
+}
+
+// explain reads the head of the file at path from r, and prints the
+// explanation of its detection.
+func (e *explainer) explain(path string, r io.Reader) error {
+	limit := e.limit
+	if limit <= 0 {
+		limit = matchers.ReadLimit
+	}
+	in, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)))
+	if err != nil {
+		return err
+	}
+	x := mimetype.Explain(in, e.opts...)
+
+	ew := &errWriter{w: e.w}
+	ew.printf("%s: %s\n", path, x.MIME)
+	for _, s := range x.Steps {
+		skipped := 0
+		for _, r := range s.Rejected {
+			switch r.Reason {
+			case mimetype.ReasonPrefix, mimetype.ReasonTooShort:
+				skipped++
+			default:
+				ew.printf("  %s: %s %s\n", s.Parent, r.MIME, r.Reason)
+			}
+		}
+		switch {
+		case skipped == 1:
+			ew.printf("  %s: 1 matcher rejected by its signature prefix or length\n", s.Parent)
+		case skipped > 1:
+			ew.printf("  %s: %d matchers rejected by their signature prefix or length\n", s.Parent, skipped)
+		}
+		if s.Matched == "" {
+			ew.printf("  %s: no matcher passed\n", s.Parent)
+			continue
+		}
+		ew.printf("  %s: %s matched", s.Parent, s.Matched)
+		for i, r := range s.Ranges {
+			sep := ","
+			if i == 0 {
+				sep = " on"
+			}
+			ew.printf("%s %s %q", sep, offsets(r), r.Bytes)
+		}
+		ew.printf("\n")
+	}
+	if x.Hinted != "" {
+		ew.printf("  hint: %s\n", x.Hinted)
+	}
+
+	return ew.err
+}
+
+// offsets returns the offsets of the bytes of r, like "bytes 0-3".
+func offsets(r mimetype.ByteRange) string {
+	if len(r.Bytes) == 1 {
+		return fmt.Sprintf("byte %d", r.Offset)
+	}
+
+	return fmt.Sprintf("bytes %d-%d", r.Offset, r.Offset+len(r.Bytes)-1)
+}
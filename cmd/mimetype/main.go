@@ -0,0 +1,294 @@
+// Command mimetype detects the MIME type of files, or of its standard input,
+// and prints one result per line.
+//
+// Usage:
+//
+//	mimetype [-r] [-json | -csv] [-extension-mismatch | -report | -explain] [-limit n] [file...]
+//
+// Without files, or with "-", the standard input is examined. Directories
+// are walked when -r is given. The results are printed as "path: type"
+// lines, as JSON objects, one per line, with -json, or as CSV records with
+// -csv. With -extension-mismatch, only the files whose extension does not
+// fit their content are printed, like a PNG image named photo.jpg. Files
+// with an unknown extension, or without one, are not reported.
+//
+// With -report, a single report is printed once all the files are detected,
+// for audits: the number of files of each MIME type, the files whose
+// extension does not fit their content, the files of unknown type and the
+// errors. It is printed as text, as a JSON object with -json, or as CSV
+// records with -csv, whose first field names the section of the record.
+//
+// With -explain, the way the detection of each file went through the
+// matchers tree is printed, to understand unexpected results: for each level
+// of the tree, the matcher which passed, the bytes it depends on, and the
+// matchers tried before it, with the reason they were rejected. The matchers
+// rejected because the file does not start with their signature, or is too
+// short for them, are only counted. Only the head of the files is examined.
+//
+// The exit status is 0 on success, 1 when -extension-mismatch or -report
+// found mismatched files and 2 when the usage is wrong or some files could
+// not be read.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const usage = "usage: mimetype [-r] [-json | -csv] [-extension-mismatch | -report | -explain] [-limit n] [file...]"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments args and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mimetype", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	recursive := fs.Bool("r", false, "walk the directories given as arguments")
+	asJSON := fs.Bool("json", false, "print the results as JSON objects, one per line")
+	asCSV := fs.Bool("csv", false, "print the results as CSV records")
+	mismatch := fs.Bool("extension-mismatch", false, "only print the files whose extension does not fit their content")
+	report := fs.Bool("report", false, "print a report of all the files instead of one result per file")
+	explain := fs.Bool("explain", false, "print how the detection of each file went through the matchers tree")
+	limit := fs.Int("limit", 0, "number of bytes examined during detection; 0 means the default")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if *asJSON && *asCSV || *mismatch && *report || *explain && (*asJSON || *asCSV || *mismatch || *report) {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+
+	var p printer = plainPrinter{stdout}
+	switch {
+	case *report:
+		// The results are printed in the report.
+	case *asJSON:
+		p = jsonPrinter{json.NewEncoder(stdout)}
+	case *asCSV:
+		w := csv.NewWriter(stdout)
+		w.Write([]string{"path", "mime", "extension", "kind"})
+		w.Flush()
+		p = csvPrinter{w}
+	}
+	d := &detector{
+		printer:  p,
+		stdin:    stdin,
+		stderr:   stderr,
+		mismatch: *mismatch,
+		opts:     []mimetype.Option{mimetype.WithLimit(*limit)},
+	}
+	if *report {
+		d.summary = newReport()
+	}
+	if *explain {
+		d.explainer = &explainer{w: stdout, opts: d.opts, limit: *limit}
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	for _, path := range paths {
+		d.detect(path, *recursive)
+		if d.printErr != nil {
+			fmt.Fprintln(stderr, "mimetype:", d.printErr)
+			return 2
+		}
+	}
+	if d.summary != nil {
+		var err error
+		switch {
+		case *asJSON:
+			err = d.summary.writeJSON(stdout)
+		case *asCSV:
+			err = d.summary.writeCSV(stdout)
+		default:
+			err = d.summary.writeText(stdout)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "mimetype:", err)
+			return 2
+		}
+	}
+
+	switch {
+	case d.failed:
+		return 2
+	case d.mismatch && d.printed, d.summary != nil && len(d.summary.Mismatches) > 0:
+		return 1
+	}
+
+	return 0
+}
+
+type detector struct {
+	printer
+	stdin    io.Reader
+	stderr   io.Writer
+	mismatch bool
+	opts     []mimetype.Option
+	failed   bool  // some files could not be read
+	printed  bool  // some results were printed
+	printErr error // the results could not be printed
+	// summary collects the results instead of printing them, with -report.
+	summary *report
+	// explainer prints the explanation of the detections, with -explain.
+	explainer *explainer
+}
+
+// detect detects the file at path, or the standard input for "-", and walks
+// the directory at path when recursive is set.
+func (d *detector) detect(path string, recursive bool) {
+	if path == "-" && d.explainer != nil {
+		d.explain(path, d.stdin)
+		return
+	}
+	if path == "-" {
+		m, _, err := mimetype.DetectAndReplay(d.stdin, d.opts...)
+		if err != nil {
+			d.fail(err)
+			return
+		}
+		// The standard input has no name, so no extension to check.
+		if d.summary != nil {
+			d.summary.add(path, m, false)
+			return
+		}
+		if !d.mismatch {
+			d.report(path, m)
+		}
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		d.fail(err)
+		return
+	}
+	if !info.IsDir() {
+		m, err := mimetype.DetectFileMmap(path, d.opts...)
+		if err != nil {
+			d.fail(err)
+			return
+		}
+		d.result(path, m)
+		return
+	}
+	if !recursive {
+		d.fail(fmt.Errorf("%s is a directory, use -r to walk it", path))
+		return
+	}
+	for r := range mimetype.DetectDir(path, d.opts...) {
+		if r.Err != nil {
+			d.fail(r.Err)
+			continue
+		}
+		d.result(r.Path, r.MIME)
+	}
+}
+
+// result reports the detection result m of the file at path, unless only
+// mismatches are reported and its extension fits.
+func (d *detector) result(path string, m *mimetype.MIME) {
+	if d.printErr != nil {
+		return
+	}
+	if d.explainer != nil {
+		f, err := os.Open(path)
+		if err != nil {
+			d.fail(err)
+			return
+		}
+		defer f.Close()
+		d.explain(path, f)
+		return
+	}
+	if d.summary != nil {
+		d.summary.add(path, m, !m.FitsExtension(path))
+		return
+	}
+	if d.mismatch && m.FitsExtension(path) {
+		return
+	}
+	d.report(path, m)
+}
+
+func (d *detector) report(path string, m *mimetype.MIME) {
+	if err := d.print(path, m); err != nil {
+		d.printErr = err
+		return
+	}
+	d.printed = true
+}
+
+func (d *detector) explain(path string, r io.Reader) {
+	if err := d.explainer.explain(path, r); err != nil {
+		d.fail(err)
+		return
+	}
+	d.printed = true
+}
+
+func (d *detector) fail(err error) {
+	fmt.Fprintln(d.stderr, "mimetype:", err)
+	d.failed = true
+	if d.summary != nil {
+		d.summary.Errors = append(d.summary.Errors, err.Error())
+	}
+}
+
+// printer writes the detection result m of the file at path.
+type printer interface {
+	print(path string, m *mimetype.MIME) error
+}
+
+type plainPrinter struct{ w io.Writer }
+
+func (p plainPrinter) print(path string, m *mimetype.MIME) error {
+	_, err := fmt.Fprintf(p.w, "%s: %s\n", path, m)
+	return err
+}
+
+type jsonPrinter struct{ enc *json.Encoder }
+
+// jsonResult is the JSON object printed for each file.
+type jsonResult struct {
+	Path      string            `json:"path"`
+	MIME      string            `json:"mime"`
+	Extension string            `json:"extension,omitempty"`
+	Kind      string            `json:"kind"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+func (p jsonPrinter) print(path string, m *mimetype.MIME) error {
+	return p.enc.Encode(jsonResult{
+		Path:      path,
+		MIME:      m.String(),
+		Extension: m.Extension(),
+		Kind:      m.Kind().String(),
+		Metadata:  m.Metadata(),
+	})
+}
+
+type csvPrinter struct{ w *csv.Writer }
+
+func (p csvPrinter) print(path string, m *mimetype.MIME) error {
+	p.w.Write([]string{path, m.String(), m.Extension(), m.Kind().String()})
+	// Flush every record, so results are seen as they come in pipelines.
+	p.w.Flush()
+	return p.w.Error()
+}
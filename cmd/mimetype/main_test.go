@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDataDir = "../../testdata"
+
+// files creates, in a temporary directory, a PNG image named a.png, the same
+// image named b.jpg, whose extension does not fit, and a file of unknown
+// type named blob. It returns the directory.
+func files(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "mimetype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	png, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"a.png": png,
+		"b.jpg": png,
+		"blob":  {0x8f, 0x03, 0xa1, 0x5e, 0x00, 0xc7},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := files(t)
+	defer os.RemoveAll(dir)
+	a, b, blob := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.jpg"), filepath.Join(dir, "blob")
+
+	tcs := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+		out    []string // substrings of the standard output
+		absent []string // substrings the standard output must not have
+	}{
+		{"plain", []string{a, blob}, "", 0, []string{a + ": image/png\n", blob + ": application/octet-stream\n"}, nil},
+		{"stdin", nil, "%PDF-1.7\n", 0, []string{"-: application/pdf\n"}, nil},
+		{"json", []string{"-json", a}, "", 0, []string{`"mime":"image/png"`, `"extension":"png"`, `"kind":"image"`}, nil},
+		{"csv", []string{"-csv", a}, "", 0, []string{"path,mime,extension,kind\n", a + ",image/png,png,image\n"}, nil},
+		{"recursive", []string{"-r", dir}, "", 0, []string{a + ": image/png\n", b + ": image/png\n"}, nil},
+		{"directory without -r", []string{dir}, "", 2, nil, nil},
+		{"missing file", []string{filepath.Join(dir, "missing")}, "", 2, nil, nil},
+		{"json and csv", []string{"-json", "-csv", a}, "", 2, nil, nil},
+		{"mismatch and report", []string{"-extension-mismatch", "-report", a}, "", 2, nil, nil},
+		{"explain and json", []string{"-explain", "-json", a}, "", 2, nil, nil},
+		{"unknown flag", []string{"-unknown", a}, "", 2, nil, nil},
+		{"help", []string{"-h"}, "", 0, nil, nil},
+
+		{"mismatch found", []string{"-extension-mismatch", a, b, blob}, "", 1, []string{b + ": image/png\n"}, []string{a, blob}},
+		{"mismatch not found", []string{"-extension-mismatch", a, blob}, "", 0, nil, []string{a, blob}},
+		{"mismatch of stdin", []string{"-extension-mismatch"}, "%PDF-1.7\n", 0, nil, []string{"-:"}},
+		{"mismatch and missing file", []string{"-extension-mismatch", b, filepath.Join(dir, "missing")}, "", 2, []string{b}, nil},
+
+		{"report", []string{"-report", a, b, blob}, "", 1, []string{
+			"files: 3\n",
+			"       2  image/png\n",
+			"extension mismatches (1):\n  " + b + ": image/png\n",
+			"unknown (1):\n  " + blob + "\n",
+			"errors (0):\n",
+		}, nil},
+		{"report without mismatch", []string{"-report", a}, "", 0, []string{"files: 1\n", "extension mismatches (0):\n"}, nil},
+		{"report csv", []string{"-report", "-csv", a, b}, "", 1, []string{
+			"section,path,mime,value\n",
+			"files,,,2\n",
+			"count,,image/png,2\n",
+			"mismatch," + b + ",image/png,.jpg\n",
+		}, nil},
+		{"report with missing file", []string{"-report", a, filepath.Join(dir, "missing")}, "", 2, []string{"errors (1):\n"}, nil},
+
+		{"explain", []string{"-explain", a}, "", 0, []string{
+			a + ": image/png\n",
+			`application/octet-stream: image/png matched on bytes 0-7 "\x89PNG\r\n\x1a\n"`,
+		}, nil},
+		{"explain stdin", []string{"-explain", "-"}, "%PDF-1.7\n", 0, []string{"-: application/pdf\n", `application/pdf matched on bytes 0-3 "%PDF"`}, nil},
+		{"explain unknown", []string{"-explain", blob}, "", 0, []string{blob + ": application/octet-stream\n", "application/octet-stream: no matcher passed\n"}, nil},
+	}
+	for _, tc := range tcs {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := run(tc.args, strings.NewReader(tc.stdin), stdout, stderr)
+		if status != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.name, tc.status, status, stderr)
+		}
+		if status == 2 && tc.name != "help" && stderr.Len() == 0 {
+			t.Errorf("%s: expected an error message", tc.name)
+		}
+		for _, s := range tc.out {
+			if !strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: expected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+		for _, s := range tc.absent {
+			if strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: unexpected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+	}
+}
+
+func TestRunReportJSON(t *testing.T) {
+	dir := files(t)
+	defer os.RemoveAll(dir)
+	a, b, blob := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.jpg"), filepath.Join(dir, "blob")
+	missing := filepath.Join(dir, "missing")
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if status := run([]string{"-report", "-json", a, b, blob, missing}, strings.NewReader(""), stdout, stderr); status != 2 {
+		t.Errorf("expected status 2 for the missing file, got %d", status)
+	}
+	var r report
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		t.Fatalf("%v: %s", err, stdout)
+	}
+	if r.Files != 3 || r.Counts["image/png"] != 2 || r.Counts["application/octet-stream"] != 1 {
+		t.Errorf("unexpected counts: %d files, %v", r.Files, r.Counts)
+	}
+	if len(r.Mismatches) != 1 || r.Mismatches[0] != (reportFile{Path: b, MIME: "image/png", Extension: ".jpg"}) {
+		t.Errorf("unexpected mismatches: %v", r.Mismatches)
+	}
+	if len(r.Unknown) != 1 || r.Unknown[0] != blob {
+		t.Errorf("unexpected unknown files: %v", r.Unknown)
+	}
+	if len(r.Errors) != 1 || !strings.Contains(r.Errors[0], missing) {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+// TestRunWriteError checks the command stops when the results cannot be
+// printed.
+func TestRunWriteError(t *testing.T) {
+	dir := files(t)
+	defer os.RemoveAll(dir)
+
+	for _, args := range [][]string{{"-r", dir}, {"-json", "-r", dir}, {"-report", filepath.Join(dir, "a.png")}} {
+		stderr := &bytes.Buffer{}
+		if status := run(args, strings.NewReader(""), failingWriter{}, stderr); status != 2 || !strings.Contains(stderr.String(), "broken pipe") {
+			t.Errorf("%v: expected status 2 and the write error, got %d: %s", args, status, stderr)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// report is the summary of the detection of all the files, printed with
+// -report.
+type report struct {
+	Files      int            `json:"files"`
+	Counts     map[string]int `json:"counts"`
+	Mismatches []reportFile   `json:"mismatches"`
+	Unknown    []string       `json:"unknown"`
+	Errors     []string       `json:"errors"`
+}
+
+// reportFile is a file whose extension does not fit its content.
+type reportFile struct {
+	Path      string `json:"path"`
+	MIME      string `json:"mime"`
+	Extension string `json:"extension"` // the extension of the file name
+}
+
+func newReport() *report {
+	// Empty lists, not null, in the JSON output.
+	return &report{Counts: map[string]int{}, Mismatches: []reportFile{}, Unknown: []string{}, Errors: []string{}}
+}
+
+// add records the detection result m of the file at path.
+func (r *report) add(path string, m *mimetype.MIME, mismatch bool) {
+	r.Files++
+	r.Counts[m.String()]++
+	if mismatch {
+		r.Mismatches = append(r.Mismatches, reportFile{Path: path, MIME: m.String(), Extension: filepath.Ext(path)})
+	}
+	if m.Is("application/octet-stream") {
+		r.Unknown = append(r.Unknown, path)
+	}
+}
+
+// mimes returns the detected MIME types, the most frequent first.
+func (r *report) mimes() []string {
+	mimes := make([]string, 0, len(r.Counts))
+	for m := range r.Counts {
+		mimes = append(mimes, m)
+	}
+	sort.Slice(mimes, func(i, j int) bool {
+		if r.Counts[mimes[i]] != r.Counts[mimes[j]] {
+			return r.Counts[mimes[i]] > r.Counts[mimes[j]]
+		}
+		return mimes[i] < mimes[j]
+	})
+
+	return mimes
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeCSV writes the report as CSV records of four fields: the section,
+// then the path, the MIME type and a value, which are empty when they do not
+// apply to the section. The value is the number of files for the counts,
+// the extension of the file for the mismatches and the message for the
+// errors.
+func (r *report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "path", "mime", "value"})
+	cw.Write([]string{"files", "", "", strconv.Itoa(r.Files)})
+	for _, m := range r.mimes() {
+		cw.Write([]string{"count", "", m, strconv.Itoa(r.Counts[m])})
+	}
+	for _, f := range r.Mismatches {
+		cw.Write([]string{"mismatch", f.Path, f.MIME, f.Extension})
+	}
+	for _, path := range r.Unknown {
+		cw.Write([]string{"unknown", path, "application/octet-stream", ""})
+	}
+	for _, err := range r.Errors {
+		cw.Write([]string{"error", "", "", err})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func (r *report) writeText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("files: %d\n", r.Files)
+	ew.printf("\ncounts:\n")
+	for _, m := range r.mimes() {
+		ew.printf("  %6d  %s\n", r.Counts[m], m)
+	}
+	ew.printf("\nextension mismatches (%d):\n", len(r.Mismatches))
+	for _, f := range r.Mismatches {
+		ew.printf("  %s: %s\n", f.Path, f.MIME)
+	}
+	ew.printf("\nunknown (%d):\n", len(r.Unknown))
+	for _, path := range r.Unknown {
+		ew.printf("  %s\n", path)
+	}
+	ew.printf("\nerrors (%d):\n", len(r.Errors))
+	for _, err := range r.Errors {
+		ew.printf("  %s\n", err)
+	}
+
+	return ew.err
+}
+
+// errWriter keeps the first error of its writes, and skips the following
+// writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
@@ -0,0 +1,311 @@
+// Command mimetyped serves the detection over HTTP, so programs written in
+// other languages can use it without Go bindings.
+//
+// Usage:
+//
+//	mimetyped [-addr host:port] [-root dir] [-concurrency n] [-limit n]
+//
+// The endpoints answer with JSON objects holding the mime, extension, kind
+// and metadata of the detected type:
+//
+//	POST /detect             detects the request body
+//	GET  /detect?path=p      detects the file at path p, relative to -root
+//	POST /detect/batch       detects a list of items
+//
+// The name query parameter of POST /detect is used as detection hint. The
+// body of POST /detect/batch is an object whose items are either files,
+// {"path": "a/b.pdf"}, or data encoded in base64, {"data": "...", "name":
+// "b.csv"}; the results are returned in the same order, each with an error
+// field when its item could not be detected.
+//
+// Files are only served when -root is given, and paths cannot leave it,
+// not even through symbolic links.
+// At most -concurrency detections run at once; the others wait for their
+// turn until their request is canceled. On SIGINT or SIGTERM the server
+// stops accepting connections and waits for the running requests to end.
+//
+// Only HTTP with JSON is served: gRPC would need dependencies outside the
+// standard library, which this module does not take.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const usage = "usage: mimetyped [-addr host:port] [-root dir] [-concurrency n] [-limit n]"
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	root := flag.String("root", "", "directory the file paths are relative to; files are not served without it")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "maximum number of detections running at once")
+	limit := flag.Int("limit", 0, "number of bytes examined during detection; 0 means the default")
+	maxBody := flag.Int64("max-batch-body", 32<<20, "maximum size in bytes of a batch request body")
+	timeout := flag.Duration("shutdown-timeout", 10*time.Second, "time given to the running requests on shutdown")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 || *concurrency < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s := &server{
+		root:    *root,
+		sem:     make(chan struct{}, *concurrency),
+		maxBody: *maxBody,
+		opts:    []mimetype.Option{mimetype.WithLimit(*limit)},
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalln("mimetyped:", err)
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		close(stop)
+	}()
+
+	log.Println("mimetyped: listening on", l.Addr())
+	if err := serve(&http.Server{Handler: s.handler()}, l, stop, *timeout); err != nil {
+		log.Fatalln("mimetyped:", err)
+	}
+}
+
+// serve serves srv on l until stop is closed. It then stops accepting
+// connections and waits up to timeout for the running requests to end.
+func serve(srv *http.Server, l net.Listener, stop <-chan struct{}, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(l)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown: %v", err)
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+type server struct {
+	root    string
+	sem     chan struct{} // holds a token for each running detection
+	maxBody int64
+	opts    []mimetype.Option
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/detect", s.detect)
+	mux.HandleFunc("/detect/batch", s.batch)
+	return mux
+}
+
+// result is the JSON object returned for each detection.
+type result struct {
+	MIME      string            `json:"mime,omitempty"`
+	Extension string            `json:"extension,omitempty"`
+	Kind      string            `json:"kind,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func newResult(m *mimetype.MIME, err error) result {
+	if err != nil {
+		return result{Error: err.Error()}
+	}
+	return result{
+		MIME:      m.String(),
+		Extension: m.Extension(),
+		Kind:      m.Kind().String(),
+		Metadata:  m.Metadata(),
+	}
+}
+
+// errNoRoot is returned for file paths when the server has no -root.
+var errNoRoot = errors.New("files are not served")
+
+// errOutsideRoot is returned for file paths leading out of -root through
+// symbolic links.
+var errOutsideRoot = errors.New("path leads outside of the served files")
+
+func (s *server) detect(w http.ResponseWriter, r *http.Request) {
+	var (
+		m   *mimetype.MIME
+		err error
+	)
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("path") != "":
+		if !s.acquire(w, r) {
+			return
+		}
+		m, err = s.detectFile(r.URL.Query().Get("path"))
+		s.release()
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case err == errNoRoot || err == errOutsideRoot:
+				status = http.StatusForbidden
+			case os.IsNotExist(err):
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, newResult(nil, err))
+			return
+		}
+	case r.Method == http.MethodPost:
+		if !s.acquire(w, r) {
+			return
+		}
+		opts := s.opts
+		if name := r.URL.Query().Get("name"); name != "" {
+			opts = append(opts[:len(opts):len(opts)], mimetype.WithHint(name))
+		}
+		// Only the head of the body is read.
+		m, err = mimetype.DetectReaderMIME(r.Body, opts...)
+		s.release()
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, newResult(nil, err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, result{Error: "use POST with a body, or GET with a path"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newResult(m, nil))
+}
+
+// batchItem is one of the inputs of a batch request: a file, when Path is
+// set, or Data, detected with Name as hint.
+type batchItem struct {
+	Path string `json:"path,omitempty"`
+	Data []byte `json:"data,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+func (s *server) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeJSON(w, http.StatusMethodNotAllowed, result{Error: "use POST"})
+		return
+	}
+	var req struct {
+		Items []batchItem `json:"items"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBody)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, result{Error: err.Error()})
+		return
+	}
+
+	results := make([]result, len(req.Items))
+	var wg sync.WaitGroup
+	for i, item := range req.Items {
+		if !s.acquire(w, r) {
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(i int, item batchItem) {
+			defer wg.Done()
+			defer s.release()
+			results[i] = newResult(s.detectItem(item))
+		}(i, item)
+	}
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, struct {
+		Results []result `json:"results"`
+	}{results})
+}
+
+func (s *server) detectItem(item batchItem) (*mimetype.MIME, error) {
+	if item.Path != "" {
+		return s.detectFile(item.Path)
+	}
+	opts := s.opts
+	if item.Name != "" {
+		opts = append(opts[:len(opts):len(opts)], mimetype.WithHint(item.Name))
+	}
+	return mimetype.DetectReaderMIME(bytes.NewReader(item.Data), opts...)
+}
+
+// detectFile detects the file at p, relative to the root directory. The
+// path is cleaned as an absolute one first, so its dot-dot elements cannot
+// leave the root, and the symbolic links it goes through must lead to
+// files in the root too.
+func (s *server) detectFile(p string) (*mimetype.MIME, error) {
+	if s.root == "" {
+		return nil, errNoRoot
+	}
+	root, err := filepath.EvalSymlinks(s.root)
+	if err != nil {
+		return nil, err
+	}
+	name, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(path.Clean("/"+p))))
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errOutsideRoot
+	}
+	return mimetype.DetectFileMmap(name, s.opts...)
+}
+
+// acquire waits for a detection slot. It answers the request and returns
+// false if the request is canceled first.
+func (s *server) acquire(w http.ResponseWriter, r *http.Request) bool {
+	select {
+	case s.sem <- struct{}{}:
+		return true
+	case <-r.Context().Done():
+		writeJSON(w, http.StatusServiceUnavailable, result{Error: "too many detections running"})
+		return false
+	}
+}
+
+func (s *server) release() {
+	<-s.sem
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("mimetyped:", err)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDataDir = "../../testdata"
+
+func newServer(root string, concurrency int) *server {
+	return &server{
+		root:    root,
+		sem:     make(chan struct{}, concurrency),
+		maxBody: 1 << 20,
+	}
+}
+
+// do sends the request to the handler of s and decodes the JSON answer into v.
+func do(t *testing.T, s *server, r *http.Request, v interface{}) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, r)
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s %s: %v: %q", r.Method, r.URL, err, w.Body.String())
+	}
+	return w.Code
+}
+
+func TestDetect(t *testing.T) {
+	s := newServer(testDataDir, 2)
+	png, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name   string
+		r      *http.Request
+		status int
+		mime   string
+	}{
+		{"body", httptest.NewRequest("POST", "/detect", bytes.NewReader(png)), 200, "image/png"},
+		{"hint", httptest.NewRequest("POST", "/detect?name=a.csv", strings.NewReader("a,b\n1,2\n")), 200, "text/csv"},
+		{"path", httptest.NewRequest("GET", "/detect?path=png.png", nil), 200, "image/png"},
+		{"missing", httptest.NewRequest("GET", "/detect?path=missing.png", nil), 404, ""},
+		{"method", httptest.NewRequest("PUT", "/detect", nil), 405, ""},
+	}
+	for _, tc := range tcs {
+		var res result
+		status := do(t, s, tc.r, &res)
+		if status != tc.status || !strings.HasPrefix(res.MIME, tc.mime) {
+			t.Errorf("%s: expected %d %s, got %d %s", tc.name, tc.status, tc.mime, status, res.MIME)
+		}
+		if tc.status != 200 && res.Error == "" {
+			t.Errorf("%s: expected an error message", tc.name)
+		}
+	}
+
+	var res result
+	if status := do(t, newServer("", 1), httptest.NewRequest("GET", "/detect?path=png.png", nil), &res); status != 403 {
+		t.Errorf("expected 403 without root, got %d", status)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	s := newServer(testDataDir, 2)
+	body := `{"items": [
+		{"path": "pdf.pdf"},
+		{"data": "iVBORw0KGgo="},
+		{"data": "YSxiCjEsMgo=", "name": "a.csv"},
+		{"path": "missing.pdf"}
+	]}`
+	var res struct {
+		Results []result `json:"results"`
+	}
+	if status := do(t, s, httptest.NewRequest("POST", "/detect/batch", strings.NewReader(body)), &res); status != 200 {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	expected := []string{"application/pdf", "image/png", "text/csv", ""}
+	if len(res.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(res.Results))
+	}
+	for i, mime := range expected {
+		if !strings.HasPrefix(res.Results[i].MIME, mime) {
+			t.Errorf("item %d: expected %s, got %s", i, mime, res.Results[i].MIME)
+		}
+	}
+	if res.Results[3].Error == "" {
+		t.Errorf("expected an error for the missing file")
+	}
+
+	var bad result
+	if status := do(t, s, httptest.NewRequest("POST", "/detect/batch", strings.NewReader("{")), &bad); status != 400 {
+		t.Errorf("expected 400 for a malformed body, got %d", status)
+	}
+	if status := do(t, s, httptest.NewRequest("GET", "/detect/batch", nil), &bad); status != 405 {
+		t.Errorf("expected 405 for GET, got %d", status)
+	}
+}
+
+func TestTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimetyped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "secret.pdf"), "%PDF-1.7\n")
+	write(filepath.Join(root, "sub", "in.pdf"), "%PDF-1.7\n")
+	links := map[string]string{
+		filepath.Join(dir, "secret.pdf"): filepath.Join(root, "out.pdf"),
+		dir:                              filepath.Join(root, "up"),
+		filepath.Join(root, "sub"):       filepath.Join(root, "dir"),
+	}
+	for target, link := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skip("symbolic links are not supported:", err)
+		}
+	}
+
+	s := newServer(root, 1)
+	tcs := []struct {
+		path   string
+		status int
+	}{
+		{"sub/in.pdf", 200},
+		{"dir/in.pdf", 200},
+		{"../secret.pdf", 404},
+		{"sub/../../secret.pdf", 404},
+		{"out.pdf", 403},
+		{"up/secret.pdf", 403},
+		{"up/root/sub/in.pdf", 200},
+	}
+	for _, tc := range tcs {
+		var res result
+		status := do(t, s, httptest.NewRequest("GET", "/detect?path="+tc.path, nil), &res)
+		if status != tc.status {
+			t.Errorf("%s: expected %d, got %d %s", tc.path, tc.status, status, res.Error)
+		}
+	}
+}
+
+func TestAcquireCanceled(t *testing.T) {
+	s := newServer(testDataDir, 1)
+	s.sem <- struct{}{} // a detection is running
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range []*http.Request{
+		httptest.NewRequest("POST", "/detect", strings.NewReader("%PDF-1.7")),
+		httptest.NewRequest("GET", "/detect?path=pdf.pdf", nil),
+		httptest.NewRequest("POST", "/detect/batch", strings.NewReader(`{"items": [{"path": "pdf.pdf"}]}`)),
+	} {
+		var res result
+		if status := do(t, s, r.WithContext(ctx), &res); status != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: expected 503, got %d", r.Method, r.URL, status)
+		}
+	}
+}
+
+// TestShutdown checks the server stops accepting connections once stopped,
+// and lets the running requests end.
+func TestShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer("", 1)
+	active := make(chan struct{}, 1)
+	srv := &http.Server{
+		Handler: s.handler(),
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateActive {
+				active <- struct{}{}
+			}
+		},
+	}
+	stop := make(chan struct{})
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(srv, l, stop, 10*time.Second)
+	}()
+
+	// The body of the running request is sent once the server is stopped.
+	pr, pw := io.Pipe()
+	res := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Post("http://"+l.Addr().String()+"/detect", "application/octet-stream", pr)
+		if err != nil {
+			t.Error(err)
+		}
+		res <- resp
+	}()
+	if _, err := pw.Write([]byte("%PDF")); err != nil {
+		t.Fatal(err)
+	}
+	<-active
+	close(stop)
+	// Shutdown closes the listener before waiting for the requests.
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			break
+		}
+		c.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("the server still accepts connections")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	pw.Write([]byte("-1.7\n"))
+	pw.Close()
+
+	resp := <-res
+	if resp == nil {
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+	var r result
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || r.MIME != "application/pdf" {
+		t.Errorf("expected the running request to end with 200 application/pdf, got %d %s", resp.StatusCode, r.MIME)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Command mimeverify runs the detection over the files of a corpus and
+// compares the results with the MIME types reported by file(1) and
+// xdg-mime(1), listing the files on which they disagree.
+//
+// Usage:
+//
+//	mimeverify [-tools file,xdg-mime] [-limit n] dir...
+//
+// The exit status is 1 when disagreements are found.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gabriel-vasile/mimetype/internal/conformance"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments args and returns its exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mimeverify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	tools := fs.String("tools", "file", "comma separated list of tools to compare with: file, xdg-mime")
+	limit := fs.Int("limit", 0, "number of bytes examined during detection; 0 means the default")
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: mimeverify [-tools file,xdg-mime] [-limit n] dir...")
+		return 2
+	}
+
+	var oracles []conformance.Oracle
+	for _, name := range strings.Split(*tools, ",") {
+		o, ok := conformance.Oracles[strings.TrimSpace(name)]
+		if !ok {
+			fmt.Fprintf(stderr, "mimeverify: unknown tool %q\n", name)
+			return 2
+		}
+		oracles = append(oracles, o)
+	}
+
+	var ds []conformance.Disagreement
+	for _, dir := range fs.Args() {
+		for r := range mimetype.DetectDir(dir, mimetype.WithLimit(*limit)) {
+			if r.Err != nil {
+				fmt.Fprintln(stderr, "mimeverify:", r.Err)
+				continue
+			}
+			for _, o := range oracles {
+				theirs, err := o.Detect(r.Path)
+				if err != nil {
+					fmt.Fprintln(stderr, "mimeverify:", err)
+					continue
+				}
+				if !conformance.Equivalent(r.MIME.String(), theirs) {
+					ds = append(ds, conformance.Disagreement{
+						Path:   r.Path,
+						Oracle: o.Name,
+						Ours:   r.MIME.String(),
+						Theirs: theirs,
+					})
+				}
+			}
+		}
+	}
+
+	if err := conformance.Report(stdout, ds); err != nil {
+		fmt.Fprintln(stderr, "mimeverify:", err)
+		return 2
+	}
+	if len(ds) > 0 {
+		return 1
+	}
+
+	return 0
+}
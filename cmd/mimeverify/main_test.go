@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/conformance"
+)
+
+const testDataDir = "../../testdata"
+
+// copyFiles creates a temporary directory holding the named files of testdata.
+func copyFiles(t *testing.T, names ...string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "mimeverify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("the fake tools need sh:", err)
+	}
+	// The fake tools report every file as a PNG image, or fail.
+	conformance.Oracles["png"] = conformance.Oracle{Name: "png", Command: []string{"sh", "-c", "echo image/png", "sh"}}
+	conformance.Oracles["failing"] = conformance.Oracle{Name: "failing", Command: []string{"sh", "-c", "exit 1", "sh"}}
+	defer delete(conformance.Oracles, "png")
+	defer delete(conformance.Oracles, "failing")
+
+	agreeing := copyFiles(t, "png.png")
+	defer os.RemoveAll(agreeing)
+	disagreeing := copyFiles(t, "png.png", "pdf.pdf")
+	defer os.RemoveAll(disagreeing)
+	pdf := filepath.Join(disagreeing, "pdf.pdf")
+
+	tcs := []struct {
+		name   string
+		args   []string
+		status int
+		out    []string // substrings of the standard output
+		stderr string   // substring of the standard error
+	}{
+		{"no directory", []string{"-tools", "png"}, 2, nil, "usage"},
+		{"unknown tool", []string{"-tools", "png,unknown", agreeing}, 2, nil, `unknown tool "unknown"`},
+		{"unknown flag", []string{"-unknown", agreeing}, 2, nil, "-unknown"},
+		{"agreement", []string{"-tools", "png", agreeing}, 0, []string{"\n0 disagreements\n"}, ""},
+		{"disagreement", []string{"-tools", "png", disagreeing}, 1, []string{
+			pdf + ": mimetype application/pdf, png image/png\n",
+			"\n1 disagreements\n",
+			"     1  application/pdf != png image/png\n",
+		}, ""},
+		{"several directories", []string{"-tools", " png ", agreeing, disagreeing}, 1, []string{"\n1 disagreements\n"}, ""},
+		{"failing tool", []string{"-tools", "failing,png", agreeing}, 0, []string{"\n0 disagreements\n"}, "mimeverify: failing:"},
+		{"missing directory", []string{"-tools", "png", filepath.Join(agreeing, "missing")}, 0, nil, "mimeverify:"},
+	}
+	for _, tc := range tcs {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := run(tc.args, stdout, stderr)
+		if status != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.name, tc.status, status, stderr)
+		}
+		for _, s := range tc.out {
+			if !strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: expected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+		if !strings.Contains(stderr.String(), tc.stderr) {
+			t.Errorf("%s: expected %q in the errors:\n%s", tc.name, tc.stderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Command newmatcher prints the code needed to add a file format to the
+// detection, inferred from sample files of that format.
+//
+// Usage:
+//
+//	newmatcher -mime type -ext extension [-name Name] [-parent type] sample...
+//
+// The bytes found at the same offsets in all the samples, within their
+// first 64 bytes, make the signature of the format. The printed code holds
+// the matcher function, for internal/matchers, the MIME type constant, for
+// types.go, the node of the matchers tree, for tree.go, and the entry of the
+// test files map, for mime_test.go. The code is a starting point: the more
+// varied the samples are, the fewer of their common bytes end up in the
+// signature only by chance.
+//
+// The node is added under the type the samples are currently detected as,
+// unless -parent is given. The files of the testdata directory, when run
+// from the root of the repository, are checked against the signature:
+// the ones it matches are listed, since they would be detected as the new
+// format too.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const usage = "usage: newmatcher -mime type -ext extension [-name Name] [-parent type] sample..."
+
+// sigLen is the number of leading bytes of the samples searched for the
+// signature. Formats needing more are better served by a hand written
+// matcher.
+const sigLen = 64
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments args and returns its exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("newmatcher", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mime := fs.String("mime", "", "MIME type of the format")
+	ext := fs.String("ext", "", "extension of the format, without the dot")
+	name := fs.String("name", "", "name of the matcher function; derived from the extension by default")
+	parent := fs.String("parent", "", "MIME type of the parent node; the type the samples are detected as by default")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+	if *mime == "" || *ext == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	*ext = strings.TrimPrefix(*ext, ".")
+	if *name == "" {
+		*name = exportedName(*ext)
+	}
+
+	var samples [][]byte
+	for _, path := range fs.Args() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, "newmatcher:", err)
+			return 1
+		}
+		samples = append(samples, data)
+	}
+	runs := stableRuns(samples)
+	if len(runs) == 0 {
+		fmt.Fprintf(stderr, "newmatcher: the samples have no bytes in common within their first %d bytes\n", sigLen)
+		return 1
+	}
+	if len(samples) == 1 {
+		fmt.Fprintln(stderr, "newmatcher: warning: with a single sample, its first 8 bytes are taken as signature")
+	}
+
+	if *parent == "" {
+		var err error
+		if *parent, err = commonParent(fs.Args()); err != nil {
+			fmt.Fprintln(stderr, "newmatcher:", err)
+			return 1
+		}
+	}
+	if matched := matchingTestdata("testdata", runs); len(matched) > 0 {
+		fmt.Fprintf(stderr, "newmatcher: warning: the signature matches files of testdata: %s\n", strings.Join(matched, ", "))
+	}
+
+	g := generator{mime: *mime, ext: *ext, name: *name, parent: *parent, runs: runs}
+	g.print(stdout)
+
+	return 0
+}
+
+// byteRun is a sequence of bytes found at the same offset in all the samples.
+type byteRun struct {
+	offset int
+	data   []byte
+}
+
+// end returns the offset following the run.
+func (r byteRun) end() int {
+	return r.offset + len(r.data)
+}
+
+// stableRuns returns the sequences of bytes the samples have in common, at
+// the same offsets, within their first sigLen bytes. Single bytes are left
+// out, unless they are at the start of the samples, since they match too
+// many inputs by chance.
+func stableRuns(samples [][]byte) []byteRun {
+	n := sigLen
+	for _, s := range samples {
+		if len(s) < n {
+			n = len(s)
+		}
+	}
+	// With a single sample, every byte is stable, and the signature would
+	// be the whole head of the file: keep its first bytes only.
+	if len(samples) == 1 && n > 8 {
+		n = 8
+	}
+
+	var runs []byteRun
+	for i := 0; i < n; {
+		if !stableAt(samples, i) {
+			i++
+			continue
+		}
+		start := i
+		for i < n && stableAt(samples, i) {
+			i++
+		}
+		if i-start > 1 || start == 0 {
+			runs = append(runs, byteRun{offset: start, data: samples[0][start:i]})
+		}
+	}
+
+	return runs
+}
+
+func stableAt(samples [][]byte, i int) bool {
+	for _, s := range samples[1:] {
+		if s[i] != samples[0][i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches reports whether in holds all the runs.
+func matches(in []byte, runs []byteRun) bool {
+	for _, r := range runs {
+		if len(in) < r.end() || !bytes.Equal(in[r.offset:r.end()], r.data) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// commonParent returns the MIME type all the samples are detected as, or
+// application/octet-stream if they are detected as different types.
+func commonParent(paths []string) (string, error) {
+	common := ""
+	for _, path := range paths {
+		m, _, err := mimetype.DetectFile(path)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case common == "":
+			common = m
+		case common != m:
+			return "application/octet-stream", nil
+		}
+	}
+
+	return common, nil
+}
+
+// matchingTestdata returns the names of the files of the testdata
+// directory dir holding all the runs.
+func matchingTestdata(dir string, runs []byteRun) []string {
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	var matched []string
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err == nil && matches(data, runs) {
+			matched = append(matched, filepath.Base(name))
+		}
+	}
+
+	return matched
+}
+
+// exportedName returns the name of the matcher of the format having the
+// extension ext: ext with its first letter in uppercase.
+func exportedName(ext string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range ext {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Fmt" + name
+	}
+
+	return name
+}
+
+type generator struct {
+	mime, ext, name, parent string
+	runs                    []byteRun
+}
+
+// nodeName returns the name of the variable holding the node in tree.go.
+func (g generator) nodeName() string {
+	return strings.ToLower(g.name[:1]) + g.name[1:]
+}
+
+func (g generator) print(w io.Writer) {
+	depth := g.runs[len(g.runs)-1].end()
+
+	fmt.Fprintln(w, "// internal/matchers")
+	fmt.Fprintf(w, "\n// %s matches a %s file.\n", g.name, g.ext)
+	fmt.Fprintf(w, "func %s(in []byte) bool {\n", g.name)
+	var conds []string
+	if g.runs[0].offset != 0 || len(g.runs) > 1 {
+		conds = append(conds, fmt.Sprintf("len(in) >= %d", depth))
+	}
+	for _, r := range g.runs {
+		if r.offset == 0 {
+			conds = append(conds, fmt.Sprintf("bytes.HasPrefix(in, %s)", literal(r.data)))
+		} else {
+			conds = append(conds, fmt.Sprintf("bytes.Equal(in[%d:%d], %s)", r.offset, r.end(), literal(r.data)))
+		}
+	}
+	fmt.Fprintf(w, "\treturn %s\n}\n", strings.Join(conds, " &&\n\t\t"))
+
+	fmt.Fprintln(w, "\n// types.go, at the end of the const block")
+	fmt.Fprintf(w, "\n\t%s = %q\n", g.name, g.mime)
+
+	fmt.Fprintf(w, "\n// tree.go, in the children of the node of %s\n", g.parent)
+	fmt.Fprintf(w, "\n\t%s = newNode(%s, %q, matchers.%s).withDepth(%d).withMinBytes(%d)", g.nodeName(), g.name, g.ext, g.name, depth, depth)
+	if first := g.runs[0]; first.offset == 0 {
+		prefix := first.data
+		if len(prefix) > 8 {
+			prefix = prefix[:8]
+		}
+		fmt.Fprintf(w, ".withPrefix(%s)", quote(prefix))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "\n// mime_test.go, in the files map; copy a sample to testdata")
+	fmt.Fprintf(w, "\n\t\"%s.%s\": %s,\n", g.ext, g.ext, g.nodeName())
+}
+
+// literal returns the Go expression of the byte slice b: a converted string
+// when b is printable ASCII, a list of hexadecimal bytes otherwise.
+func literal(b []byte) string {
+	printable := true
+	for _, c := range b {
+		if c < 0x20 || c > 0x7E {
+			printable = false
+			break
+		}
+	}
+	if printable {
+		return fmt.Sprintf("[]byte(%s)", quote(b))
+	}
+	hex := make([]string, len(b))
+	for i, c := range b {
+		hex[i] = fmt.Sprintf("0x%02X", c)
+	}
+
+	return fmt.Sprintf("[]byte{%s}", strings.Join(hex, ", "))
+}
+
+// quote returns the Go string literal holding the bytes of b, escaping the
+// non printable ones, whether they are part of UTF-8 sequences or not.
+func quote(b []byte) string {
+	var s strings.Builder
+	s.WriteByte('"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		case c < 0x20 || c > 0x7E:
+			fmt.Fprintf(&s, "\\x%02x", c)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	s.WriteByte('"')
+
+	return s.String()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDataDir = "../../testdata"
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newmatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	samples := map[string]string{
+		"a.xyz":   "XYZF-1 first sample\n",
+		"b.xyz":   "XYZF-2 another one\n",
+		"c.other": "nothing in common\n",
+	}
+	for name, data := range samples {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b, c := filepath.Join(dir, "a.xyz"), filepath.Join(dir, "b.xyz"), filepath.Join(dir, "c.other")
+
+	tcs := []struct {
+		name   string
+		args   []string
+		status int
+		out    []string // substrings of the standard output
+		stderr string   // substring of the standard error
+	}{
+		{"no mime", []string{"-ext", "xyz", a}, 2, nil, "usage"},
+		{"no extension", []string{"-mime", "application/x-xyz", a}, 2, nil, "usage"},
+		{"no samples", []string{"-mime", "application/x-xyz", "-ext", "xyz"}, 2, nil, "usage"},
+		{"unknown flag", []string{"-unknown"}, 2, nil, "-unknown"},
+		{"missing sample", []string{"-mime", "application/x-xyz", "-ext", "xyz", filepath.Join(dir, "missing")}, 1, nil, "newmatcher:"},
+		{"nothing in common", []string{"-mime", "application/x-xyz", "-ext", "xyz", a, c}, 1, nil, "no bytes in common"},
+		{"samples", []string{"-mime", "application/x-xyz", "-ext", ".xyz", a, b}, 0, []string{
+			"// Xyz matches a xyz file.\nfunc Xyz(in []byte) bool {\n\treturn bytes.HasPrefix(in, []byte(\"XYZF-\"))\n}\n",
+			"\tXyz = \"application/x-xyz\"\n",
+			"// tree.go, in the children of the node of text/plain\n",
+			"\txyz = newNode(Xyz, \"xyz\", matchers.Xyz).withDepth(5).withMinBytes(5).withPrefix(\"XYZF-\")\n",
+			"\t\"xyz.xyz\": xyz,\n",
+		}, ""},
+		{"name and parent", []string{"-mime", "application/x-xyz", "-ext", "xyz", "-name", "XyzFile", "-parent", "application/octet-stream", a, b}, 0, []string{
+			"func XyzFile(in []byte) bool {",
+			"// tree.go, in the children of the node of application/octet-stream\n",
+			"\txyzFile = newNode(XyzFile, \"xyz\", matchers.XyzFile)",
+		}, ""},
+		{"single sample", []string{"-mime", "application/x-xyz", "-ext", "xyz", a}, 0, []string{
+			"return bytes.HasPrefix(in, []byte(\"XYZF-1 f\"))",
+		}, "with a single sample"},
+	}
+	for _, tc := range tcs {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := run(tc.args, stdout, stderr)
+		if status != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.name, tc.status, status, stderr)
+		}
+		for _, s := range tc.out {
+			if !strings.Contains(stdout.String(), s) {
+				t.Errorf("%s: expected %q in the output:\n%s", tc.name, s, stdout)
+			}
+		}
+		if !strings.Contains(stderr.String(), tc.stderr) {
+			t.Errorf("%s: expected %q in the errors:\n%s", tc.name, tc.stderr, stderr)
+		}
+	}
+}
+
+func TestStableRuns(t *testing.T) {
+	tcs := []struct {
+		name    string
+		samples []string
+		runs    []byteRun
+	}{
+		{"prefix", []string{"ABCD1", "ABCD2"}, []byteRun{{0, []byte("ABCD")}}},
+		{"offset", []string{"1xyz2ab", "3xyz4ab"}, []byteRun{{1, []byte("xyz")}, {5, []byte("ab")}}},
+		// Single stable bytes are kept only at the start of the samples.
+		{"single bytes", []string{"A1B2C", "A3B4D"}, []byteRun{{0, []byte("A")}}},
+		{"shortest sample", []string{"ABCDEF", "ABC"}, []byteRun{{0, []byte("ABC")}}},
+		{"single sample", []string{"0123456789"}, []byteRun{{0, []byte("01234567")}}},
+		{"nothing in common", []string{"ab", "cd"}, nil},
+	}
+	for _, tc := range tcs {
+		var samples [][]byte
+		for _, s := range tc.samples {
+			samples = append(samples, []byte(s))
+		}
+		if runs := stableRuns(samples); !reflect.DeepEqual(runs, tc.runs) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.runs, runs)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tcs := map[string]string{
+		"xyz":    "Xyz",
+		"tar.gz": "TarGz",
+		"x-foo":  "XFoo",
+		"3mf":    "Fmt3mf",
+		"-":      "Fmt",
+	}
+	for ext, expected := range tcs {
+		if name := exportedName(ext); name != expected {
+			t.Errorf("%s: expected %s, got %s", ext, expected, name)
+		}
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tcs := map[string]string{
+		"PK":       `[]byte("PK")`,
+		`a"b\c`:    `[]byte("a\"b\\c")`,
+		"\x89PNG":  `[]byte{0x89, 0x50, 0x4E, 0x47}`,
+		"\x00\x01": `[]byte{0x00, 0x01}`,
+	}
+	for in, expected := range tcs {
+		if l := literal([]byte(in)); l != expected {
+			t.Errorf("%q: expected %s, got %s", in, expected, l)
+		}
+	}
+	if q := quote([]byte("\x89PNG\r\n")); q != `"\x89PNG\x0d\x0a"` {
+		t.Errorf("unexpected quoted bytes %s", q)
+	}
+}
+
+func TestMatchingTestdata(t *testing.T) {
+	matched := matchingTestdata(testDataDir, []byteRun{{0, []byte("\x89PNG\r\n\x1a\n")}})
+	found := false
+	for _, name := range matched {
+		found = found || name == "png.png"
+	}
+	if !found {
+		t.Errorf("expected png.png among the files matching the PNG signature, got %v", matched)
+	}
+	if matched := matchingTestdata(testDataDir, []byteRun{{0, []byte("not a signature of testdata")}}); len(matched) != 0 {
+		t.Errorf("expected no match, got %v", matched)
+	}
+}
@@ -0,0 +1,54 @@
+package mimetype
+
+import (
+	"bytes"
+	"io"
+)
+
+// RegisterDecompressor registers the function used to decompress content of
+// the provided MIME type, replacing any previously registered one. gzip and
+// bzip2 are supported out of the box; other formats, like xz or zstd, can be
+// supported by registering decompressors from third party packages.
+//
+// RegisterDecompressor is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func RegisterDecompressor(mime string, decompress func(io.Reader) (io.Reader, error)) {
+	unwrappers[mediaType(mime)] = decompress
+}
+
+// DetectCompressed detects the MIME type of the input and, when the input
+// is a compressed stream with a registered decompressor, the MIME type of the
+// compressed content. inner is nil if the input is not a supported compressed stream.
+func DetectCompressed(in []byte, opts ...Option) (outer, inner *MIME, err error) {
+	layers, err := DetectLayers(in, 1, opts...)
+	if len(layers) > 1 {
+		inner = layers[1]
+	}
+
+	return layers[0], inner, err
+}
+
+// DetectCompressedReader is like DetectCompressed, but reads from r. Unlike
+// DetectCompressed used on a sniff buffer, it decompresses the stream until
+// enough of the compressed content is available, so inner detection is not
+// limited by the compression ratio.
+func DetectCompressedReader(r io.Reader, opts ...Option) (outer, inner *MIME, err error) {
+	c := newConfig(opts)
+	head, err := c.readHead(r)
+	if err != nil {
+		return newMIME(root, nil), nil, err
+	}
+
+	n, in := c.detectNode(head)
+	outer = c.result(n, in)
+	decompress, ok := unwrapperOf(n)
+	if !ok {
+		return outer, nil, nil
+	}
+	in, err = unwrapHead(decompress, io.MultiReader(bytes.NewReader(head), r), c.readLimit())
+	if err != nil {
+		return outer, nil, err
+	}
+
+	return outer, c.detect(in), nil
+}
@@ -0,0 +1,97 @@
+package mimetype
+
+import (
+	"bytes"
+	stdgzip "compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectCompressed(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "tar.gz.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer, inner, err := DetectCompressed(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outer.Is("application/gzip") || inner == nil || !inner.Is("application/x-tar") {
+		t.Errorf("expected gzip containing tar, got %v containing %v", outer, inner)
+	}
+
+	outer, inner, err = DetectCompressed([]byte("plain text"))
+	if err != nil || !outer.Is("text/plain") || inner != nil {
+		t.Errorf("expected text/plain with no inner type, got %v, %v, %v", outer, inner, err)
+	}
+}
+
+// TestDetectCompressedSubtypes checks the subtypes of gzip, which have a MIME
+// type of their own, are decompressed like gzip.
+func TestDetectCompressedSubtypes(t *testing.T) {
+	tcs := []struct {
+		file  string
+		outer string
+		inner string
+	}{
+		{"helm.tgz", "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "application/x-tar"},
+		{"compose.tgz", "application/x-compose-project", "application/x-compose-project"},
+		{"kustomize.tgz", "application/x-kustomize", "application/x-kustomize"},
+		{"etckeeper.tgz", "application/x-etckeeper-archive", "application/x-etckeeper-archive"},
+		{"vagrant.gz.box", "application/x-vagrant-box", "application/x-vagrant-box"},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outer, inner, err := DetectCompressed(data)
+		if err != nil || !outer.Is(tc.outer) || inner == nil || !inner.Is(tc.inner) {
+			t.Errorf("%s: expected %s containing %s, got %v containing %v, %v", tc.file, tc.outer, tc.inner, outer, inner, err)
+		}
+		outer, inner, err = DetectCompressedReader(bytes.NewReader(data))
+		if err != nil || !outer.Is(tc.outer) || inner == nil || !inner.Is(tc.inner) {
+			t.Errorf("%s: expected %s containing %s from a reader, got %v containing %v, %v", tc.file, tc.outer, tc.inner, outer, inner, err)
+		}
+	}
+}
+
+func TestDetectCompressedReader(t *testing.T) {
+	// Content larger than ReadLimit, so the stream is read past the sniffed head.
+	buf := &bytes.Buffer{}
+	w := stdgzip.NewWriter(buf)
+	for i := 0; i < 4096; i++ {
+		w.Write([]byte{byte(i * 7919 >> 3)})
+	}
+	w.Close()
+
+	outer, inner, err := DetectCompressedReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outer.Is("application/gzip") || inner == nil {
+		t.Errorf("expected gzip with inner type, got %v containing %v", outer, inner)
+	}
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	defer delete(unwrappers, "application/x-xz")
+
+	RegisterDecompressor("application/x-xz", func(r io.Reader) (io.Reader, error) {
+		return strings.NewReader("\x89PNG\x0d\x0a\x1a\x0a"), nil
+	})
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "xz.xz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer, inner, err := DetectCompressed(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outer.Is("application/x-xz") || inner == nil || !inner.Is("image/png") {
+		t.Errorf("expected xz containing png, got %v containing %v", outer, inner)
+	}
+}
@@ -0,0 +1,68 @@
+package mimetype
+
+import (
+	"bytes"
+	"strings"
+)
+
+// sniffLen is the number of bytes the MIME Sniffing Standard examines when
+// telling text from binary content.
+const sniffLen = 512
+
+// DetectContentType is a drop-in replacement for http.DetectContentType:
+// it has the same signature and, like it, always returns a valid MIME type.
+// Projects can switch to it by changing the package they call it from, and
+// get the larger set of formats detected by this package. The names of a
+// few types differ from the ones net/http uses, like audio/wav instead of
+// audio/wave, and formats net/http detects may be reported with a subtype,
+// like the docx documents it reports as application/zip.
+//
+// Text types get a charset parameter, utf-8 unless the type has one. When
+// no format is detected, the input is classified as text or as binary
+// content following the rules of the MIME Sniffing Standard used by the
+// net/http package: inputs starting with a UTF-16 or UTF-8 byte order mark,
+// and inputs whose first 512 bytes hold no binary data byte, are
+// "text/plain" with the corresponding charset, and the other ones are
+// "application/octet-stream". The empty input is "text/plain; charset=utf-8".
+//
+// https://mimesniff.spec.whatwg.org/#identifying-a-resource-with-an-unknown-mime-type
+func DetectContentType(data []byte) string {
+	m := DetectMIME(data)
+	switch {
+	case m.mime == OctetStream, m.mime == empty.mime:
+		return sniffTextOrBinary(data)
+	case m.kind == KindText && !strings.Contains(m.mime, "charset="):
+		return m.mime + "; charset=utf-8"
+	}
+
+	return m.mime
+}
+
+// sniffTextOrBinary implements the text or binary content rules of the
+// MIME Sniffing Standard.
+func sniffTextOrBinary(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "text/plain; charset=utf-16be"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "text/plain; charset=utf-16le"
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "text/plain; charset=utf-8"
+	}
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	for _, b := range data {
+		if isBinaryDataByte(b) {
+			return OctetStream
+		}
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// isBinaryDataByte reports whether b is a control character not found in
+// text: not a tab, a line feed, a form feed, a carriage return or an escape.
+func isBinaryDataByte(b byte) bool {
+	return b <= 0x08 || b == 0x0B || 0x0E <= b && b <= 0x1A || 0x1C <= b && b <= 0x1F
+}
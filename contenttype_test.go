@@ -0,0 +1,60 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// TestDetectContentTypeCompat checks that DetectContentType agrees with
+// http.DetectContentType for the formats both detect and for the inputs
+// none of the matchers pass.
+func TestDetectContentTypeCompat(t *testing.T) {
+	inputs := map[string][]byte{
+		"empty":          nil,
+		"text":           []byte("just some text\n"),
+		"escape":         []byte("\x1B[1mbold\x1B[0m\n"),
+		"binary":         []byte("\x00\x01\x02\x03abc"),
+		"utf-16be bom":   []byte("\xFE\xFF\x00h\x00i"),
+		"utf-16le bom":   []byte("\xFF\xFEh\x00i\x00"),
+		"utf-8 bom":      []byte("\xEF\xBB\xBFhi \x01"),
+		"late binary":    append(bytes.Repeat([]byte("text "), 120), 0x00),
+		"html":           []byte("<!DOCTYPE html><html><body>hi</body></html>"),
+		"xml":            []byte(`<?xml version="1.0"?><a/>`),
+		"unknown binary": {0x8A, 0x3C, 0x00, 0x11, 0xFF, 0x10},
+	}
+	for _, f := range []string{"png.png", "jpg.jpg", "pdf.pdf", "zip.zip", "mp4.mp4", "webm.webm"} {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[f] = data
+	}
+	for name, data := range inputs {
+		if got, want := DetectContentType(data), http.DetectContentType(data); got != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	tcs := []struct {
+		file, contentType string
+	}{
+		{"docx.docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"csv.csv", "text/csv; charset=utf-8"},
+		{"json.json", "application/json; charset=utf-8"},
+		{"html.html", "text/html; charset=utf-8"},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := DetectContentType(data); got != tc.contentType {
+			t.Errorf("%s: expected %q, got %q", tc.file, tc.contentType, got)
+		}
+	}
+}
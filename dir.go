@@ -0,0 +1,103 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DirResult is the detection result of a file found by DetectDir.
+// Err is set when the file, or the directory containing it, could not be read.
+type DirResult struct {
+	Path string
+	MIME *MIME
+	Err  error
+}
+
+// DetectDir walks the directory tree rooted at root and sends the detection
+// result of every regular file it finds on the returned channel. Files are
+// walked in lexical order. The channel is closed when the walk is over and it
+// must be drained by the caller, otherwise the walking goroutine leaks.
+//
+// Symbolic links are followed when WithFollowSymlinks is used and the files
+// larger than the size set by WithMaxSize are skipped.
+func DetectDir(root string, opts ...Option) <-chan DirResult {
+	c := newConfig(opts)
+	out := make(chan DirResult)
+	go func() {
+		defer close(out)
+		info, err := os.Stat(root)
+		if err != nil {
+			out <- DirResult{Path: root, Err: err}
+			return
+		}
+		buf := make([]byte, c.readLimit())
+		w := &dirWalker{
+			config: c,
+			file: func(path string, _ os.FileInfo) {
+				m, err := detectFileMIME(path, buf, c)
+				if m != nil || err != nil {
+					out <- DirResult{Path: path, MIME: m, Err: err}
+				}
+			},
+			fail: func(path string, err error) {
+				out <- DirResult{Path: path, Err: err}
+			},
+			visited: map[string]bool{},
+		}
+		w.walk(root, info)
+	}()
+
+	return out
+}
+
+type dirWalker struct {
+	*config
+	// file is called for the regular files not larger than the maximum
+	// size, and fail for the paths which could not be read.
+	file    func(path string, info os.FileInfo)
+	fail    func(path string, err error)
+	visited map[string]bool // real paths of the walked directories
+}
+
+func (w *dirWalker) walk(path string, info os.FileInfo) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !w.followSymlinks {
+			return
+		}
+		target, err := os.Stat(path)
+		if err != nil {
+			w.fail(path, err)
+			return
+		}
+		info = target
+	}
+
+	switch {
+	case info.IsDir():
+		if w.followSymlinks {
+			real, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				w.fail(path, err)
+				return
+			}
+			if w.visited[real] {
+				return
+			}
+			w.visited[real] = true
+		}
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			w.fail(path, err)
+			return
+		}
+		for _, e := range entries {
+			w.walk(filepath.Join(path, e.Name()), e)
+		}
+	case info.Mode().IsRegular():
+		if w.maxSize > 0 && info.Size() > w.maxSize {
+			return
+		}
+		w.file(path, info)
+	}
+}
@@ -0,0 +1,74 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimetype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	copyFile := func(src, dst string) {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, dst), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyFile("png.png", "a.png")
+	copyFile("pdf.pdf", "sub/b.pdf")
+	copyFile("mkv.mkv", "c.mkv")
+	if err := os.Symlink(filepath.Join(dir, "sub"), filepath.Join(dir, "link")); err != nil {
+		t.Skip("symbolic links not supported:", err)
+	}
+
+	collect := func(opts ...Option) map[string]string {
+		got := map[string]string{}
+		for r := range DetectDir(dir, opts...) {
+			if r.Err != nil {
+				t.Fatal(r.Err)
+			}
+			rel, _ := filepath.Rel(dir, r.Path)
+			got[filepath.ToSlash(rel)] = r.MIME.String()
+		}
+		return got
+	}
+
+	got := collect()
+	expected := map[string]string{
+		"a.png":     "image/png",
+		"c.mkv":     "video/x-matroska",
+		"sub/b.pdf": "application/pdf",
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	for p, m := range expected {
+		if got[p] != m {
+			t.Errorf("%s: expected %s, got %s", p, m, got[p])
+		}
+	}
+
+	// The linked directory is walked only once and c.mkv is too large.
+	got = collect(WithFollowSymlinks(), WithMaxSize(1<<20))
+	if len(got) != 2 || got["a.png"] == "" || got["link/b.pdf"] == "" && got["sub/b.pdf"] == "" {
+		t.Errorf("unexpected results when following links: %v", got)
+	}
+
+	for r := range DetectDir(filepath.Join(dir, "inexistent")) {
+		if r.Err == nil {
+			t.Errorf("expected error for inexistent directory")
+		}
+	}
+}
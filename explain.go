@@ -0,0 +1,183 @@
+package mimetype
+
+import "bytes"
+
+// explainWindow is the number of leading bytes examined to find the bytes
+// a matcher depends on, for the matchers which may inspect the whole input.
+const explainWindow = 64
+
+// Explanation describes how the detection of an input went through the
+// matchers tree, to understand unexpected results.
+type Explanation struct {
+	// MIME is the detection result, the same Detect returns.
+	MIME *MIME
+	// Steps describe, from the start of the tree to the detected type, the
+	// matching of the children of each node.
+	Steps []ExplainStep
+	// Hinted is the MIME type the hint given with WithHint selected among
+	// the descendants of the type detected from the content, if any.
+	Hinted string
+}
+
+// ExplainStep describes the matching of the children of the node of type
+// Parent.
+type ExplainStep struct {
+	Parent string
+	// Matched is the type of the child which passed, or an empty string if
+	// none did, in which case the detection ended at Parent.
+	Matched string
+	// Ranges are the ranges of bytes the matcher of Matched depends on:
+	// changing any of them makes it reject the input. Only the bytes the
+	// matcher is declared to inspect, or the first 64 bytes, are examined.
+	Ranges []ByteRange
+	// Rejected are the children tried before Matched, in the order of the
+	// tree, or all the children if none passed.
+	Rejected []Rejection
+}
+
+// ByteRange is a range of bytes of the input.
+type ByteRange struct {
+	Offset int
+	Bytes  []byte
+}
+
+// Rejection is a child of a node which did not pass, and why.
+type Rejection struct {
+	MIME   string
+	Reason string
+}
+
+// Reasons of the rejections.
+const (
+	// The input is shorter than the minimum length of the format.
+	ReasonTooShort = "input too short"
+	// The input does not start with any of the signatures of the format.
+	ReasonPrefix = "signature prefix not found"
+	// The matcher function of the format rejected the input.
+	ReasonMatcher = "rejected by the matcher"
+	// The matcher passed, but a sibling matching too scored higher.
+	ReasonScore = "scored lower than the matched sibling"
+)
+
+// Explain detects the MIME type of in, like Detect, and describes how the
+// detection went: for each level of the matchers tree, the child which
+// passed, the bytes it depends on and the siblings tried before it, with
+// the reason they were rejected. It is meant for debugging: it runs far
+// more matchers than the detection does.
+func Explain(in []byte, opts ...Option) *Explanation {
+	c := newConfig(opts)
+	if c.limit > 0 && len(in) > c.limit {
+		in = in[:c.limit]
+	}
+	e := &Explanation{MIME: c.detect(in)}
+	if len(in) == 0 {
+		return e
+	}
+
+	n := c.start(in)
+	raw := in
+	in = n.decode(in)
+	for len(n.children) > 0 {
+		step, next := explainChildren(n, in)
+		e.Steps = append(e.Steps, step)
+		if next == nil {
+			break
+		}
+		n = next
+		if n.decodeFunc != nil {
+			in = n.decodeFunc(in)
+		}
+	}
+	if c.hint != "" {
+		if h := hintedNode(n, c.hint, raw); h != n {
+			e.Hinted = h.mime
+		}
+	}
+
+	return e
+}
+
+// explainChildren describes the matching of the children of n, and returns
+// the child which passed, or nil.
+func explainChildren(n *node, in []byte) (ExplainStep, *node) {
+	step := ExplainStep{Parent: n.mime}
+	var matched *node
+	for i, c := range n.children {
+		if c.passes(in) {
+			matched = c
+			if c.scoreFunc != nil {
+				matched = bestScored(n.children[i:], in)
+				for _, s := range n.children[i:] {
+					if s != matched && s.scoreFunc != nil && s.passes(in) {
+						step.Rejected = append(step.Rejected, Rejection{s.mime, ReasonScore})
+					}
+				}
+			}
+			break
+		}
+		step.Rejected = append(step.Rejected, Rejection{c.mime, rejectionReason(c, in)})
+	}
+	if matched == nil {
+		return step, nil
+	}
+	step.Matched = matched.mime
+	step.Ranges = significantRanges(matched, in)
+
+	return step, matched
+}
+
+func rejectionReason(n *node, in []byte) string {
+	if len(in) < n.minBytes {
+		return ReasonTooShort
+	}
+	if len(n.prefixes) > 0 {
+		found := false
+		for _, p := range n.prefixes {
+			if bytes.HasPrefix(in, p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ReasonPrefix
+		}
+	}
+
+	return ReasonMatcher
+}
+
+// significantRanges returns the ranges of bytes of in whose change makes
+// the matcher of n reject in. Each byte is changed twice, flipping its
+// lowest and its highest bit, so matchers ignoring the case of letters, or
+// accepting a range of values, are caught too.
+func significantRanges(n *node, in []byte) []ByteRange {
+	window := n.depth
+	if window <= 0 || window > explainWindow {
+		window = explainWindow
+	}
+	if window > len(in) {
+		window = len(in)
+	}
+	changed := append([]byte(nil), in...)
+	var ranges []ByteRange
+	for i := 0; i < window; i++ {
+		significant := false
+		for _, flip := range []byte{0x01, 0x80} {
+			changed[i] = in[i] ^ flip
+			if !n.passes(changed) {
+				significant = true
+			}
+		}
+		changed[i] = in[i]
+		if !significant {
+			continue
+		}
+		if last := len(ranges) - 1; last >= 0 && ranges[last].Offset+len(ranges[last].Bytes) == i {
+			ranges[last].Bytes = in[ranges[last].Offset : i+1]
+		} else {
+			ranges = append(ranges, ByteRange{Offset: i, Bytes: in[i : i+1]})
+		}
+	}
+
+	return ranges
+}
@@ -0,0 +1,108 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	for f, n := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := Explain(data)
+		if m := DetectMIME(data); x.MIME.String() != m.String() {
+			t.Errorf("%s: expected %s, got %s", f, m, x.MIME)
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+		// The steps go down the tree to the detected type.
+		parent := root.mime
+		for _, s := range x.Steps {
+			if s.Parent != parent {
+				t.Errorf("%s: expected step from %s, got %s", f, parent, s.Parent)
+			}
+			if s.Matched != "" {
+				parent = s.Matched
+			}
+		}
+		if parent != n.mime {
+			t.Errorf("%s: expected steps to end at %s, got %s", f, n.mime, parent)
+		}
+	}
+}
+
+func TestExplainRanges(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := Explain(data)
+	if len(x.Steps) == 0 || x.Steps[0].Matched != PNG {
+		t.Fatalf("expected a step matching %s, got %+v", PNG, x.Steps)
+	}
+	r := x.Steps[0].Ranges
+	if len(r) != 1 || r[0].Offset != 0 || !bytes.Equal(r[0].Bytes, []byte("\x89PNG\r\n\x1A\n")) {
+		t.Errorf("expected the PNG signature at offset 0, got %+v", r)
+	}
+	for _, rej := range x.Steps[0].Rejected {
+		if rej.MIME == PNG {
+			t.Errorf("%s both matched and rejected", PNG)
+		}
+	}
+}
+
+func TestExplainRejections(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "docx.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := Explain(data)
+	if len(x.Steps) < 2 || x.Steps[0].Matched != Zip || x.Steps[1].Matched != Docx {
+		t.Fatalf("expected steps through %s to %s, got %+v", Zip, Docx, x.Steps)
+	}
+	// xlsx comes before docx among the children of zip, and shares its
+	// prefix: only its matcher can reject it.
+	found := false
+	for _, rej := range x.Steps[1].Rejected {
+		if rej.MIME == Xlsx {
+			found = true
+			if rej.Reason != ReasonMatcher {
+				t.Errorf("expected %s rejected with %q, got %q", Xlsx, ReasonMatcher, rej.Reason)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected %s among the rejected", Xlsx)
+	}
+
+	// Too short for PNG, and not starting like most formats.
+	x = Explain([]byte("\x89PNG"))
+	for _, rej := range x.Steps[0].Rejected {
+		switch rej.MIME {
+		case PNG:
+			if rej.Reason != ReasonTooShort {
+				t.Errorf("expected %s rejected with %q, got %q", PNG, ReasonTooShort, rej.Reason)
+			}
+		case PDF:
+			if rej.Reason != ReasonPrefix {
+				t.Errorf("expected %s rejected with %q, got %q", PDF, ReasonPrefix, rej.Reason)
+			}
+		}
+	}
+}
+
+func TestExplainHint(t *testing.T) {
+	x := Explain([]byte("just some text\n"), WithHint("data.csv"))
+	if x.Hinted != CSV || x.MIME.String() != CSV {
+		t.Errorf("expected %s selected by the hint, got %q and %s", CSV, x.Hinted, x.MIME)
+	}
+	if x := Explain(nil); len(x.Steps) != 0 {
+		t.Errorf("expected no steps for an empty input, got %+v", x.Steps)
+	}
+}
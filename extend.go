@@ -0,0 +1,95 @@
+package mimetype
+
+import "errors"
+
+// Extend adds a matcher for the mime type to the matchers tree, as a child
+// of the parent type. Use OctetStream as parent to add a top level matcher.
+// The match function is called only when the parent matcher passes. When
+// several formats share the parent type, like the zip, tar and gzip variants
+// of Kustomize, the matcher is added as a child of each of them.
+//
+// By default, the new matcher is tried after the existing children of the
+// parent. WithPriority changes its position: children with a higher priority
+// are tried first, and the built-in matchers have priority 0.
+//
+// Extend is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func Extend(parent, mime, extension string, match func([]byte) bool, opts ...Option) error {
+	parents := findNodes(parent)
+	if len(parents) == 0 {
+		return ErrUnknownParent
+	}
+	priority := newConfig(opts).priority
+	for _, p := range parents {
+		n := newNode(mime, extension, match)
+		n.priority = priority
+		p.appendChild(n)
+	}
+	invalidateExtTables()
+
+	return nil
+}
+
+// ErrNotChild is returned by Reorder when one of the provided MIME types
+// is not a child of the parent type.
+var ErrNotChild = errors.New("mimetype: MIME type is not a child of the parent")
+
+// Reorder changes the order in which the children of the parent type are
+// tried. The provided children are moved first, in the given order, while
+// the rest keep their relative order. For example, Reorder(Text, HTML, XML)
+// makes HTML be tried before any other subtype of text/plain. When several
+// formats share the parent type, the children of each of them are reordered.
+//
+// Reorder is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func Reorder(parent string, children ...string) error {
+	parents := findNodes(parent)
+	if len(parents) == 0 {
+		return ErrUnknownParent
+	}
+
+	// All the parents are checked before any of them is changed.
+	reordered := make([][]*node, len(parents))
+	for i, p := range parents {
+		var err error
+		if reordered[i], err = reorderedChildren(p, children); err != nil {
+			return err
+		}
+	}
+	for i, p := range parents {
+		p.children = reordered[i]
+		p.buildPrefixIndex()
+	}
+
+	return nil
+}
+
+// reorderedChildren returns the children of p, the ones having the provided
+// MIME types first, in the given order.
+func reorderedChildren(p *node, children []string) ([]*node, error) {
+	var first, rest []*node
+	moved := make(map[*node]bool)
+	for _, mime := range children {
+		found := false
+		for _, c := range p.children {
+			if mediaType(c.mime) != mediaType(mime) {
+				continue
+			}
+			if !moved[c] {
+				first = append(first, c)
+				moved[c] = true
+			}
+			found = true
+		}
+		if !found {
+			return nil, ErrNotChild
+		}
+	}
+	for _, c := range p.children {
+		if !moved[c] {
+			rest = append(rest, c)
+		}
+	}
+
+	return append(first, rest...), nil
+}
@@ -0,0 +1,95 @@
+package mimetype
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtend(t *testing.T) {
+	defer func(children []*node) {
+		root.children = children
+		invalidateExtTables()
+	}(append([]*node{}, root.children...))
+
+	isPng := func(in []byte) bool {
+		return bytes.HasPrefix(in, []byte("\x89PNG"))
+	}
+	in := []byte("\x89PNG\r\n\x1a\n")
+
+	if err := Extend(OctetStream, "image/x-custom-png", "cpng", isPng); err != nil {
+		t.Fatal(err)
+	}
+	if mime, _ := Detect(in); mime != PNG {
+		t.Errorf("matcher with default priority: expected %s, got %s", PNG, mime)
+	}
+	if err := Extend(OctetStream, "image/x-priority-png", "ppng", isPng, WithPriority(1)); err != nil {
+		t.Fatal(err)
+	}
+	if mime, _ := Detect(in); mime != "image/x-priority-png" {
+		t.Errorf("matcher with priority: expected image/x-priority-png, got %s", mime)
+	}
+	if TypeByExtension("ppng") != "image/x-priority-png" {
+		t.Errorf("extension of the extended type is not known")
+	}
+
+	if err := Extend("application/x-inexistent", "image/x-png", "", isPng); err != ErrUnknownParent {
+		t.Errorf("expected ErrUnknownParent, got %v", err)
+	}
+}
+
+func TestExtendVariants(t *testing.T) {
+	variants := []*node{kustomizeZip, kustomizeTar, kustomizeGz}
+	defer func() {
+		for _, v := range variants {
+			v.children = nil
+		}
+		invalidateExtTables()
+	}()
+
+	if err := Extend(Kustomize, "application/x-kustomize-overlay", "", func([]byte) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range variants {
+		if len(v.children) != 1 || v.children[0].parent != v {
+			t.Errorf("%s: the matcher is not a child of the %s variant", Kustomize, v.extension)
+		}
+	}
+	data := readArchiveFile(t, "kustomize.tgz")
+	if mime, _ := Detect(data); mime != "application/x-kustomize-overlay" {
+		t.Errorf("expected application/x-kustomize-overlay, got %s", mime)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	defer func(children []*node) {
+		root.children = children
+		invalidateExtTables()
+	}(append([]*node{}, root.children...))
+	count := len(root.children)
+
+	in := []byte("\x89PNG\r\n\x1a\n")
+	if err := Extend(OctetStream, "image/x-priority-png", "", func(in []byte) bool {
+		return bytes.HasPrefix(in, []byte("\x89PNG"))
+	}, WithPriority(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reorder(OctetStream, PNG, GIF); err != nil {
+		t.Fatal(err)
+	}
+	if mime, _ := Detect(in); mime != PNG {
+		t.Errorf("expected %s after reordering, got %s", PNG, mime)
+	}
+	if root.children[0] != png || root.children[1] != gif {
+		t.Errorf("reordered children are not first")
+	}
+	if len(root.children) != count+1 {
+		t.Errorf("children lost while reordering")
+	}
+
+	if err := Reorder(OctetStream, Docx); err != ErrNotChild {
+		t.Errorf("expected ErrNotChild, got %v", err)
+	}
+	if err := Reorder("application/x-inexistent", PNG); err != ErrUnknownParent {
+		t.Errorf("expected ErrUnknownParent, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package mimetype
+
+import (
+	"bufio"
+	"io"
+	stdmime "mime"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// extTables holds the extension to MIME type mappings and the reverse.
+// The tree mappings are derived from the matchers tree on first use,
+// while the imported mappings come from mime.types files and take precedence.
+var extTables = struct {
+	sync.RWMutex
+	treeBuilt   bool
+	treeExt     map[string]string   // extension -> MIME type, from the tree
+	treeMime    map[string][]string // media type -> extensions, from the tree
+	importExt   map[string]string   // extension -> MIME type, imported
+	importMimes map[string][]string // media type -> extensions, imported
+}{
+	importExt:   map[string]string{},
+	importMimes: map[string][]string{},
+}
+
+// TypeByExtension returns the MIME type associated with the file extension ext.
+// The extension may start with a dot. The lookup is case insensitive.
+// An empty string is returned if no MIME type is associated with ext.
+func TypeByExtension(ext string) string {
+	ext = normalizeExt(ext)
+	extTables.RLock()
+	defer extTables.RUnlock()
+	if m, ok := extTables.importExt[ext]; ok {
+		return m
+	}
+	if !extTables.treeBuilt {
+		// Upgrade the lock to build the tree mappings.
+		extTables.RUnlock()
+		buildTreeExtTables()
+		extTables.RLock()
+	}
+
+	return extTables.treeExt[ext]
+}
+
+// ExtensionsByType returns the extensions known to be associated with
+// the MIME type mime. MIME type parameters, like charset, are ignored.
+func ExtensionsByType(mime string) []string {
+	mime = mediaType(mime)
+	extTables.RLock()
+	defer extTables.RUnlock()
+	if !extTables.treeBuilt {
+		extTables.RUnlock()
+		buildTreeExtTables()
+		extTables.RLock()
+	}
+
+	var exts []string
+	exts = appendUnique(exts, extTables.importMimes[mime]...)
+	exts = appendUnique(exts, extTables.treeMime[mime]...)
+
+	return exts
+}
+
+// LoadMimeTypes reads an Apache style mime.types file and merges its
+// mappings into the extension lookup tables. Each line of the file holds
+// a MIME type followed by any number of whitespace separated extensions.
+// Imported mappings override the ones derived from the matchers tree.
+func LoadMimeTypes(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	extTables.Lock()
+	defer extTables.Unlock()
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.IndexByte(line, '#'); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mime := mediaType(fields[0])
+		for _, ext := range fields[1:] {
+			ext = normalizeExt(ext)
+			extTables.importExt[ext] = mime
+			extTables.importMimes[mime] = appendUnique(extTables.importMimes[mime], ext)
+		}
+	}
+
+	return s.Err()
+}
+
+// LoadMimeTypesFile is like LoadMimeTypes, but reads the mappings from the provided file.
+func LoadMimeTypesFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return LoadMimeTypes(f)
+}
+
+// SyncStdlib registers the extensions known to this package, from the
+// matchers tree and from the imported mime.types files, with the mime
+// package of the standard library, so code calling mime.TypeByExtension,
+// like http.ServeFile, knows them too. Only the extensions unknown to the
+// mime package are registered: the ones it knows keep their type, not to
+// change the Content-Type of the files already served.
+//
+// It returns the first error returned by mime.AddExtensionType, after
+// registering all the other extensions. Extensions added later, by Extend or
+// LoadMimeTypes, are not registered until SyncStdlib is called again.
+func SyncStdlib() error {
+	buildTreeExtTables()
+	extTables.RLock()
+	types := make(map[string]string, len(extTables.treeExt)+len(extTables.importExt))
+	for ext, m := range extTables.treeExt {
+		types[ext] = m
+	}
+	for ext, m := range extTables.importExt {
+		types[ext] = m
+	}
+	extTables.RUnlock()
+
+	exts := make([]string, 0, len(types))
+	for ext := range types {
+		exts = append(exts, ext)
+	}
+	// Register in a stable order, for the error returned to be too.
+	sort.Strings(exts)
+	var firstErr error
+	for _, ext := range exts {
+		if stdmime.TypeByExtension("."+ext) != "" {
+			continue
+		}
+		if err := stdmime.AddExtensionType("."+ext, types[ext]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// buildTreeExtTables derives the extension mappings from the matchers tree.
+// The first node declaring an extension wins.
+func buildTreeExtTables() {
+	extTables.Lock()
+	defer extTables.Unlock()
+	if extTables.treeBuilt {
+		return
+	}
+	extTables.treeExt = map[string]string{}
+	extTables.treeMime = map[string][]string{}
+	for _, n := range root.flatten() {
+		if n.extension == "" {
+			continue
+		}
+		if _, ok := extTables.treeExt[n.extension]; !ok {
+			extTables.treeExt[n.extension] = n.mime
+		}
+		mime := mediaType(n.mime)
+		extTables.treeMime[mime] = appendUnique(extTables.treeMime[mime], n.extension)
+	}
+	extTables.treeBuilt = true
+}
+
+// invalidateExtTables forces the tree mappings to be derived again.
+// It must be called every time the matchers tree changes.
+func invalidateExtTables() {
+	extTables.Lock()
+	extTables.treeBuilt = false
+	extTables.Unlock()
+}
+
+// mediaType returns the lowercase MIME type without its parameters.
+func mediaType(mime string) string {
+	if i := strings.IndexByte(mime, ';'); i != -1 {
+		mime = mime[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(mime))
+}
+
+func normalizeExt(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+func appendUnique(s []string, vals ...string) []string {
+outer:
+	for _, v := range vals {
+		for _, e := range s {
+			if e == v {
+				continue outer
+			}
+		}
+		s = append(s, v)
+	}
+
+	return s
+}
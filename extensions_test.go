@@ -0,0 +1,96 @@
+package mimetype
+
+import (
+	"mime"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeByExtension(t *testing.T) {
+	tcs := []struct {
+		ext, mime string
+	}{
+		{"png", "image/png"},
+		{".PNG", "image/png"},
+		{"html", "text/html; charset=utf-8"},
+		{"inexistent", ""},
+	}
+	for _, tc := range tcs {
+		if m := TypeByExtension(tc.ext); m != tc.mime {
+			t.Errorf("%s: expected %q, got %q", tc.ext, tc.mime, m)
+		}
+	}
+}
+
+func TestExtensionsByType(t *testing.T) {
+	if exts := ExtensionsByType("text/html"); !reflect.DeepEqual(exts, []string{"html"}) {
+		t.Errorf("expected [html], got %v", exts)
+	}
+	if exts := ExtensionsByType("application/x-inexistent"); len(exts) != 0 {
+		t.Errorf("expected no extensions, got %v", exts)
+	}
+}
+
+func TestLoadMimeTypes(t *testing.T) {
+	defer func() {
+		extTables.importExt = map[string]string{}
+		extTables.importMimes = map[string][]string{}
+	}()
+
+	types := `
+# MIME type			Extensions
+image/png			png
+text/html			html htm shtml
+application/x-custom		cst
+application/x-no-extension
+`
+	if err := LoadMimeTypes(strings.NewReader(types)); err != nil {
+		t.Fatal(err)
+	}
+	if m := TypeByExtension("cst"); m != "application/x-custom" {
+		t.Errorf("expected application/x-custom, got %q", m)
+	}
+	if m := TypeByExtension("htm"); m != "text/html" {
+		t.Errorf("expected text/html, got %q", m)
+	}
+	exts := ExtensionsByType("text/html; charset=utf-8")
+	if !reflect.DeepEqual(exts, []string{"html", "htm", "shtml"}) {
+		t.Errorf("expected [html htm shtml], got %v", exts)
+	}
+}
+
+func TestSyncStdlib(t *testing.T) {
+	// The extensions known to the mime package depend on the mime.types
+	// files of the system, so the expected results are computed first.
+	known := map[string]string{}
+	unknown := map[string]string{}
+	for _, n := range root.flatten() {
+		if n.extension == "" {
+			continue
+		}
+		ext := "." + n.extension
+		if m := mime.TypeByExtension(ext); m != "" {
+			known[ext] = m
+		} else {
+			unknown[ext] = TypeByExtension(ext)
+		}
+	}
+	if len(unknown) == 0 {
+		t.Skip("the mime package knows all the extensions")
+	}
+
+	if err := SyncStdlib(); err != nil {
+		t.Fatal(err)
+	}
+	for ext, m := range known {
+		if got := mime.TypeByExtension(ext); got != m {
+			t.Errorf("%s: expected the type known to the mime package, %q, got %q", ext, m, got)
+		}
+	}
+	for ext, m := range unknown {
+		if got := mime.TypeByExtension(ext); mediaType(got) != mediaType(m) {
+			t.Errorf("%s: expected %q, got %q", ext, m, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package mimetype
+
+// fastNodes are the matchers of the formats most served on the web,
+// tried by FastDetect before the whole tree.
+var fastNodes = []*node{jpg, png, gif, webp, pdf, zip, gzip, mp4}
+
+// FastDetect is like Detect, but first checks the input only against the
+// signatures of JPEG, PNG, GIF, WebP, PDF, zip, gzip and MP4 files. When one
+// of them matches, the search continues only below that format, for example
+// to tell a docx file from other zip archives, and the other root matchers
+// are not tried. Inputs of other formats are detected using the whole tree.
+//
+// FastDetect suits callers which care about the latency of detecting the
+// common formats more than about the rare inputs whose signature is shared
+// by one of them and by a format tried earlier by Detect.
+func FastDetect(in []byte, opts ...Option) (mime, extension string) {
+	c := newConfig(opts)
+	if c.subtree != "" {
+		return Detect(in, opts...)
+	}
+	if c.limit > 0 && len(in) > c.limit {
+		in = in[:c.limit]
+	}
+	for _, f := range fastNodes {
+		if !f.passes(in) {
+			continue
+		}
+		n := detectFrom(f, in, c.parallel)
+		if c.hint != "" {
+			n = hintedNode(n, c.hint, in)
+		}
+		m := c.result(n, in)
+		return m.String(), m.Extension()
+	}
+
+	return Detect(in, opts...)
+}
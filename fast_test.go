@@ -0,0 +1,54 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestFastDetect(t *testing.T) {
+	for f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mime, ext := Detect(data)
+		if fMime, fExt := FastDetect(data); fMime != mime || fExt != ext {
+			t.Errorf("%s: Detect got %s %s, FastDetect got %s %s", f, mime, ext, fMime, fExt)
+		}
+	}
+	if m, _ := FastDetect(nil); m != empty.mime {
+		t.Errorf("empty input: expected %s, got %s", empty.mime, m)
+	}
+	if m, _ := FastDetect([]byte("PK\x03\x04"), WithSubtree("application/zip")); m != "application/zip" {
+		t.Errorf("subtree: expected application/zip, got %s", m)
+	}
+}
+
+func BenchmarkFastDetect(b *testing.B) {
+	corpus := []string{"jpg.jpg", "png.png", "webp.webp", "pdf.pdf", "zip.zip", "gz.gz", "mp4.mp4"}
+	var data [][]byte
+	for _, f := range corpus {
+		d, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(d) > matchers.ReadLimit {
+			d = d[:matchers.ReadLimit]
+		}
+		data = append(data, d)
+	}
+
+	b.Run("Detect", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data[n%len(data)])
+		}
+	})
+	b.Run("FastDetect", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			FastDetect(data[n%len(data)])
+		}
+	})
+}
@@ -0,0 +1,122 @@
+//go:build go1.18
+// +build go1.18
+
+package mimetype
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// addSeeds adds the test files to the seed corpus of f, along with
+// truncated versions of them, since the matchers get inputs cut at the
+// read limit or ending early.
+func addSeeds(f *testing.F) {
+	names, err := filepath.Glob(filepath.Join(testDataDir, "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			continue // directories
+		}
+		if len(data) > matchers.ReadLimit {
+			data = data[:matchers.ReadLimit]
+		}
+		f.Add(data)
+		for _, l := range []int{1, 4, 8, 16, 64, len(data) / 2, len(data) - 1} {
+			if 0 < l && l < len(data) {
+				f.Add(data[:l])
+			}
+		}
+	}
+}
+
+// FuzzDetect checks that the detection of any input does not panic and is
+// deterministic, and that the metadata found in the end of the input can
+// be extracted too.
+func FuzzDetect(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if err := checkDeterministic(in); err != nil {
+			t.Fatal(err)
+		}
+		newConfig(nil).detectWithTail(in, in)
+	})
+}
+
+// FuzzDetectReader checks that reading the input incrementally, in chunks
+// of any size, gives the result of detecting it at once, metadata included.
+func FuzzDetectReader(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if len(in) > matchers.ReadLimit {
+			in = in[:matchers.ReadLimit]
+		}
+		want := DetectMIME(in)
+		for _, r := range []io.Reader{bytes.NewReader(in), iotest.OneByteReader(bytes.NewReader(in))} {
+			m, err := DetectReaderMIME(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.String() != want.String() {
+				t.Fatalf("DetectReader: expected %s, got %s", want, m)
+			}
+			if !reflect.DeepEqual(m.Metadata(), want.Metadata()) {
+				t.Fatalf("DetectReader: expected metadata %v, got %v", want.Metadata(), m.Metadata())
+			}
+		}
+	})
+}
+
+// FuzzMatchers runs every matcher of the tree, and its metadata, score and
+// stream functions, on any input they may be given. Going through the detection only runs
+// the matchers whose parent passed, so it would miss most of them.
+func FuzzMatchers(f *testing.F) {
+	addSeeds(f)
+	nodes := root.flatten()
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if len(in) > matchers.ReadLimit {
+			in = in[:matchers.ReadLimit]
+		}
+		for _, n := range nodes {
+			// The matchers get inputs of at least minBytes, and the metadata
+			// and score functions, inputs the matchers passed.
+			if len(in) >= n.minBytes && n.matchFunc(in) {
+				if n.metaFunc != nil {
+					n.metaFunc(in)
+				}
+				if n.scoreFunc != nil {
+					n.scoreFunc(in)
+				}
+			}
+			if n.tailMetaFunc != nil {
+				n.tailMetaFunc(in)
+			}
+			if n.streamFunc != nil {
+				s := n.streamFunc()
+				// Feed the input in small chunks, of an odd size so they
+				// do not line up with the fields of binary formats.
+				for p := in; len(p) > 0; {
+					k := 7
+					if k > len(p) {
+						k = len(p)
+					}
+					if !s.Write(p[:k]) {
+						break
+					}
+					p = p[k:]
+				}
+				s.Match()
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package mimetype
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Hooks holds optional callbacks invoked on detection events.
+// They allow recording which types are seen and how long detection takes
+// without wrapping every call site. Callbacks must be safe for concurrent use.
+type Hooks struct {
+	// OnDetect is called after each detection with the detected MIME type,
+	// the time spent matching and the number of bytes inspected.
+	OnDetect func(mime string, took time.Duration, bytesRead int)
+	// Logger, when set, receives a debug record for each detection, holding
+	// the number of bytes examined, the path of the matched node in the
+	// matchers tree and whether the detection fell back to the start node.
+	Logger Logger
+	// Metrics, when set, receives the measurements of each detection.
+	// *Metrics is a ready to use implementation.
+	Metrics MetricsSink
+}
+
+// Logger is the interface used for logging detections. It is satisfied
+// by *slog.Logger, whose arguments are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+var hooks atomic.Value
+
+// SetHooks installs the callbacks invoked on detection events, replacing
+// the previously installed ones. Use the zero Hooks value to remove them.
+func SetHooks(h Hooks) {
+	hooks.Store(h)
+}
+
+func loadHooks() Hooks {
+	h, _ := hooks.Load().(Hooks)
+	return h
+}
+
+// logDetection records the detection of node n, started from node p.
+func logDetection(l Logger, p, n *node, bytesRead int) {
+	switch {
+	case n == empty:
+		l.Debug("mimetype: empty input", "mime", n.mime)
+	case n == p:
+		l.Debug("mimetype: no matcher passed, falling back",
+			"mime", n.mime, "bytes", bytesRead, "path", nodePath(n))
+	default:
+		l.Debug("mimetype: detected",
+			"mime", n.mime, "bytes", bytesRead, "path", nodePath(n))
+	}
+}
+
+// nodePath returns the MIME types of the nodes from the root of the
+// matchers tree to n, separated by " > ".
+func nodePath(n *node) string {
+	var path []string
+	for ; n != nil; n = n.parent {
+		path = append([]string{n.mime}, path...)
+	}
+
+	return strings.Join(path, " > ")
+}
@@ -0,0 +1,87 @@
+package mimetype
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	defer SetHooks(Hooks{})
+
+	var (
+		calls    int
+		gotMime  string
+		gotBytes int
+	)
+	SetHooks(Hooks{
+		OnDetect: func(mime string, took time.Duration, bytesRead int) {
+			calls++
+			gotMime, gotBytes = mime, bytesRead
+		},
+	})
+
+	in := []byte("\x89PNG\x0d\x0a\x1a\x0a")
+	Detect(in)
+	if calls != 1 || gotMime != "image/png" || gotBytes != len(in) {
+		t.Errorf("unexpected hook call: calls=%d mime=%s bytes=%d", calls, gotMime, gotBytes)
+	}
+
+	if _, _, err := DetectReader(bytes.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("DetectReader should trigger exactly one hook call, got %d", calls-1)
+	}
+
+	SetHooks(Hooks{})
+	Detect(in)
+	if calls != 2 {
+		t.Errorf("removed hooks should not be called")
+	}
+}
+
+type recordingLogger struct {
+	msgs []string
+	args [][]interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+	l.args = append(l.args, args)
+}
+
+func TestHooksLogger(t *testing.T) {
+	defer SetHooks(Hooks{})
+
+	l := &recordingLogger{}
+	SetHooks(Hooks{Logger: l})
+
+	Detect([]byte("\x89PNG\x0d\x0a\x1a\x0a"))
+	Detect([]byte{0x00, 0x01, 0x02})
+	Detect(nil)
+
+	expected := []string{
+		"mimetype: detected",
+		"mimetype: no matcher passed, falling back",
+		"mimetype: empty input",
+	}
+	if len(l.msgs) != len(expected) {
+		t.Fatalf("expected %d records, got %d: %v", len(expected), len(l.msgs), l.msgs)
+	}
+	for i := range expected {
+		if l.msgs[i] != expected[i] {
+			t.Errorf("record %d: expected %q, got %q", i, expected[i], l.msgs[i])
+		}
+	}
+
+	// Arguments alternate keys and values.
+	args := map[interface{}]interface{}{}
+	for i := 0; i+1 < len(l.args[0]); i += 2 {
+		args[l.args[0][i]] = l.args[0][i+1]
+	}
+	if args["mime"] != "image/png" || args["bytes"] != 8 ||
+		args["path"] != "application/octet-stream > image/png" {
+		t.Errorf("unexpected record arguments: %v", l.args[0])
+	}
+}
@@ -0,0 +1,200 @@
+package mimetype
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// firstRead is the length of the first read done by DetectReader. Most
+// formats are identified from their first bytes, so reading more of the
+// input is only needed when a matcher looks past the bytes read so far.
+const firstRead = 64
+
+// matchPrefix is like match, but also reports how many bytes are needed
+// for the result to be final: 0 when all the matchers evaluated need at
+// most len(in) bytes, -1 when some of them may inspect the whole input,
+// and otherwise the largest depth of the matchers which could not be
+// evaluated. A result which is not final may change when more of the input
+// is read. Children whose declared prefixes do not start the input are
+// skipped, as they cannot pass whatever the bytes following the prefix.
+//
+// The children looking at the whole input are fed to their stream matchers,
+// kept in ss across the calls. They are skipped once rejected, and otherwise
+// need twice as many bytes as in. When limit is positive, the children
+// needing more than limit bytes are skipped too, as they are never evaluated.
+func (n *node) matchPrefix(in []byte, deepestMatch *node, ss streams, limit int) (*node, int) {
+	need := 0
+	for _, c := range n.children {
+		if c.excludedBy(in) {
+			continue
+		}
+		if c.depth == 0 {
+			if c.streamFunc == nil {
+				return nil, -1
+			}
+			if !ss.feed(c, in) {
+				continue
+			}
+			if 2*len(in) > need {
+				need = 2 * len(in)
+			}
+			continue
+		}
+		if c.depth > len(in) {
+			if limit > 0 && c.depth > limit {
+				continue
+			}
+			if c.depth > need {
+				need = c.depth
+			}
+			continue
+		}
+		if !c.passes(in) {
+			continue
+		}
+		if need > 0 {
+			return nil, need
+		}
+		// Siblings chosen by score look at the whole input: the scored
+		// nodes are not annotated with a depth, so they never get here.
+		return c.matchPrefix(in, c, ss, limit)
+	}
+	if need > 0 {
+		return nil, need
+	}
+
+	return deepestMatch, 0
+}
+
+// streams holds the stream matchers fed by readIncrementally, so each call
+// to matchPrefix only feeds them the bytes read since the previous one.
+type streams map[*node]*nodeStream
+
+type nodeStream struct {
+	s   matchers.Stream
+	fed int
+	ok  bool
+}
+
+// feed feeds the stream matcher of n with the bytes of in it has not seen
+// yet, and reports whether in may still be the beginning of an input n
+// passes for.
+func (ss streams) feed(n *node, in []byte) bool {
+	st := ss[n]
+	if st == nil {
+		st = &nodeStream{s: n.streamFunc(), ok: true}
+		ss[n] = st
+	}
+	if st.ok && len(in) > st.fed {
+		st.ok = st.s.Write(in[st.fed:])
+		st.fed = len(in)
+	}
+
+	return st.ok
+}
+
+// excludedBy reports whether n cannot pass for inputs starting with in,
+// because none of its declared prefixes is consistent with in.
+func (n *node) excludedBy(in []byte) bool {
+	if len(n.prefixes) == 0 {
+		return false
+	}
+	for _, p := range n.prefixes {
+		if len(in) < len(p) && bytes.HasPrefix(p, in) || bytes.HasPrefix(in, p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deepNeed returns the largest read limit declared by the children of n
+// which need more than len(in) bytes and may pass for inputs starting with in.
+func (n *node) deepNeed(in []byte) int {
+	need := 0
+	for _, c := range n.children {
+		if c.readLimit > len(in) && c.readLimit > need && !c.excludedBy(in) {
+			need = c.readLimit
+		}
+	}
+
+	return need
+}
+
+// deepLimit returns the number of bytes to examine for an input whose head,
+// read up to the read limit, is in: more than the read limit when the
+// default one is used, no matcher passes for in and a matcher declaring a
+// larger read limit may, like the ones of disk images, and 0 otherwise.
+func (c *config) deepLimit(in []byte) int {
+	if c.limit > 0 || len(in) < matchers.ReadLimit || c.start(in).match(in, nil) != nil {
+		return 0
+	}
+
+	return c.start(in).deepNeed(in)
+}
+
+// readIncrementally reads the head of r into buf, stopping as soon as the
+// bytes read are enough to decide the type of the input. After a first short
+// read, the matchers which could not be evaluated tell how many bytes they
+// need, and only that many bytes are read next. The text matchers are fed
+// each chunk read, and inputs they all reject stop being read. When meta is
+// true, inputs of a type having metadata are read up to the read limit, as
+// the metadata functions look past the bytes deciding the type.
+//
+// When the default read limit is used and no matcher passes for the whole
+// buffer, the input is read further for the matchers declaring a larger
+// read limit, like the ones of disk images. The returned slice is then
+// allocated, instead of being a part of buf.
+func (c *config) readIncrementally(r io.Reader, buf []byte, meta bool) ([]byte, error) {
+	have, want := 0, firstRead
+	ss := streams{}
+	for want < len(buf) {
+		n, err := io.ReadFull(r, buf[have:want])
+		have += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return buf[:have], nil
+		}
+		if err != nil {
+			return buf[:have], err
+		}
+		matched, need := c.start(buf[:have]).matchPrefix(buf[:have], nil, ss, 0)
+		if need == 0 && meta && hasMeta(matched) {
+			need = -1
+		}
+		if need == 0 {
+			return buf[:have], nil
+		}
+		want = need
+		if need < 0 {
+			want = len(buf)
+		}
+	}
+	in, err := readInto(r, buf[have:])
+	in = buf[:have+len(in)]
+	if err != nil {
+		return in, err
+	}
+
+	need := c.deepLimit(in)
+	if need == 0 {
+		return in, nil
+	}
+	grown := make([]byte, need)
+	copy(grown, in)
+	more, err := readInto(r, grown[len(in):])
+
+	return grown[:len(in)+len(more)], err
+}
+
+// hasMeta reports whether n or one of its ancestors extracts metadata.
+func hasMeta(n *node) bool {
+	for ; n != nil; n = n.parent {
+		if n.metaFunc != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+// Package conformance compares the results of the detection with the
+// MIME types reported by other tools, like file(1) and xdg-mime(1).
+//
+// Tools often use different names for the same format, so the types
+// are normalized through a table of aliases before being compared.
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Oracle is an external tool printing the MIME type of a file.
+type Oracle struct {
+	Name string
+	// Command holds the program and the arguments preceding the file path.
+	Command []string
+}
+
+// Oracles holds the known tools, indexed by name.
+var Oracles = map[string]Oracle{
+	"file":     {Name: "file", Command: []string{"file", "--brief", "--mime-type"}},
+	"xdg-mime": {Name: "xdg-mime", Command: []string{"xdg-mime", "query", "filetype"}},
+}
+
+// Detect runs the tool on the file at path and returns the MIME type it prints.
+func (o Oracle) Detect(path string) (string, error) {
+	args := append(append([]string{}, o.Command[1:]...), path)
+	out, err := exec.Command(o.Command[0], args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", o.Name, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// aliases maps the names used by other tools to the ones used by mimetype.
+var aliases = map[string]string{
+	"application/x-gzip":                "application/gzip",
+	"application/x-zip":                 "application/zip",
+	"application/x-zip-compressed":      "application/zip",
+	"application/x-rar":                 "application/x-rar-compressed",
+	"application/vnd.rar":               "application/x-rar-compressed",
+	"application/x-zstd":                "application/zstd",
+	"application/cdfv2":                 "application/x-ole-storage",
+	"application/vnd.ms-office":         "application/x-ole-storage",
+	"application/x-dosexec":             "application/vnd.microsoft.portable-executable",
+	"application/x-msdownload":          "application/vnd.microsoft.portable-executable",
+	"application/vnd.sqlite3":           "application/x-sqlite3",
+	"application/x-empty":               "inode/x-empty",
+	"application/xml":                   "text/xml",
+	"application/x-php":                 "text/x-php",
+	"text/javascript":                   "application/javascript",
+	"text/x-python":                     "application/x-python",
+	"text/x-python3":                    "application/x-python",
+	"text/x-script.python":              "application/x-python",
+	"application/vnd.ms-asf":            "video/x-ms-asf",
+	"application/x-matroska":            "video/x-matroska",
+	"application/vnd.ms-opentype":       "font/otf",
+	"font/sfnt":                         "font/otf",
+	"application/font-woff":             "font/woff",
+	"audio/x-wav":                       "audio/wav",
+	"audio/vnd.wave":                    "audio/wav",
+	"audio/x-flac":                      "audio/flac",
+	"audio/x-aiff":                      "audio/aiff",
+	"audio/x-ape":                       "audio/ape",
+	"audio/x-musepack":                  "audio/musepack",
+	"audio/x-midi":                      "audio/midi",
+	"audio/x-hx-aac-adts":               "audio/aac",
+	"image/x-ms-bmp":                    "image/bmp",
+	"image/vnd.microsoft.icon":          "image/x-icon",
+	"image/x-photoshop":                 "image/vnd.adobe.photoshop",
+	"application/x-iso9660":             "application/x-iso9660-image",
+	"application/x-cd-image":            "application/x-iso9660-image",
+	"application/x-java-archive":        "application/jar",
+	"application/java-archive":          "application/jar",
+	"application/x-debian-package":      "application/vnd.debian.binary-package",
+	"application/vnd.debian.binary-pkg": "application/vnd.debian.binary-package",
+}
+
+// Normalize returns the canonical form of mime: lowercase, without
+// parameters and with the aliases replaced by the names used by mimetype.
+func Normalize(mime string) string {
+	if i := strings.IndexByte(mime, ';'); i != -1 {
+		mime = mime[:i]
+	}
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	if a, ok := aliases[mime]; ok {
+		return a
+	}
+
+	return mime
+}
+
+// Equivalent reports whether ours and theirs name the same format.
+func Equivalent(ours, theirs string) bool {
+	return Normalize(ours) == Normalize(theirs)
+}
+
+// Disagreement is a file for which an oracle reported a different format.
+type Disagreement struct {
+	Path   string
+	Oracle string
+	Ours   string
+	Theirs string
+}
+
+// Report writes the disagreements to w, sorted by path, followed by a summary
+// counting, for each pair of types, how many times the disagreement happened.
+func Report(w io.Writer, ds []Disagreement) error {
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].Path != ds[j].Path {
+			return ds[i].Path < ds[j].Path
+		}
+		return ds[i].Oracle < ds[j].Oracle
+	})
+	pairs := map[string]int{}
+	for _, d := range ds {
+		if _, err := fmt.Fprintf(w, "%s: mimetype %s, %s %s\n", d.Path, d.Ours, d.Oracle, d.Theirs); err != nil {
+			return err
+		}
+		pairs[fmt.Sprintf("%s != %s %s", Normalize(d.Ours), d.Oracle, Normalize(d.Theirs))]++
+	}
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if _, err := fmt.Fprintf(w, "\n%d disagreements\n", len(ds)); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%6d  %s\n", pairs[k], k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
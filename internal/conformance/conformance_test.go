@@ -0,0 +1,50 @@
+package conformance
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEquivalent(t *testing.T) {
+	tCases := []struct {
+		ours, theirs string
+		expected     bool
+	}{
+		{"application/gzip", "application/x-gzip", true},
+		{"text/html; charset=utf-8", "text/html", true},
+		{"text/xml; charset=utf-8", "application/xml", true},
+		{"audio/wav", "audio/x-wav", true},
+		{"image/png", "IMAGE/PNG", true},
+		{"image/png", "image/jpeg", false},
+		{"application/zip", "application/x-gzip", false},
+	}
+
+	for _, tc := range tCases {
+		if got := Equivalent(tc.ours, tc.theirs); got != tc.expected {
+			t.Errorf("Equivalent(%q, %q): expected %t, got %t", tc.ours, tc.theirs, tc.expected, got)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	ds := []Disagreement{
+		{Path: "b.csv", Oracle: "file", Ours: "text/csv", Theirs: "text/plain"},
+		{Path: "a.csv", Oracle: "file", Ours: "text/csv", Theirs: "text/plain"},
+		{Path: "a.csv", Oracle: "xdg-mime", Ours: "text/csv", Theirs: "text/x-csv"},
+	}
+	expected := `a.csv: mimetype text/csv, file text/plain
+a.csv: mimetype text/csv, xdg-mime text/x-csv
+b.csv: mimetype text/csv, file text/plain
+
+3 disagreements
+     2  text/csv != file text/plain
+     1  text/csv != xdg-mime text/x-csv
+`
+	out := &bytes.Buffer{}
+	if err := Report(out, ds); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
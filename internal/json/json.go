@@ -32,7 +32,10 @@
 // if some slice of bytes is a valid beginning of a json string.
 package json
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type (
 	context    int
@@ -65,31 +68,87 @@ type (
 		endTop   bool
 		err      error
 		index    int
+		// errChar and errContext describe the syntax error found, formatted
+		// only when the error is asked for.
+		errChar    byte
+		errContext string
 	}
 )
 
+// The errors recorded while scanning are not formatted, so that rejecting
+// an input does not allocate.
+var (
+	errSyntax        = errors.New("invalid character")
+	errUnexpectedEnd = errors.New("unexpected end of JSON input")
+)
+
 // Scan returns the number of bytes scanned and if there was any error
 // in trying to reach the end of data
 func Scan(data []byte) (int, error) {
-	s := &scanner{}
-	_ = checkValid(data, s)
-	return s.index, s.err
-}
-
-// checkValid verifies that data is valid JSON-encoded data.
-// scan is passed in for use by checkValid to avoid an allocation.
-func checkValid(data []byte, scan *scanner) error {
-	scan.reset()
-	for _, c := range data {
-		scan.index++
-		if scan.step(scan, c) == scanError {
-			return scan.err
+	s := &Scanner{}
+	if s.Write(data) {
+		s.Close()
+	}
+	return s.Scanned(), s.Err()
+}
+
+// Scanner checks a JSON value fed to it in consecutive chunks, without
+// keeping the chunks. The zero value is ready to use.
+type Scanner struct {
+	scan   scanner
+	init   bool
+	failed bool
+}
+
+// Write feeds the next chunk of the value to the scanner. It returns false
+// once a syntax error is found, after which the following chunks are ignored.
+func (s *Scanner) Write(p []byte) bool {
+	if !s.init {
+		s.Reset()
+	}
+	if s.failed {
+		return false
+	}
+	for _, c := range p {
+		s.scan.index++
+		if s.scan.step(&s.scan, c) == scanError {
+			s.failed = true
+			return false
 		}
 	}
-	if scan.eof() == scanError {
-		return scan.err
+	return true
+}
+
+// Close tells the scanner the value has ended and reports whether it is
+// a valid JSON value. The error found otherwise is returned by Err.
+func (s *Scanner) Close() bool {
+	if !s.init {
+		s.Reset()
 	}
-	return nil
+	return s.scan.eof() != scanError
+}
+
+// Scanned returns the number of bytes scanned, up to and including
+// the one where a syntax error was found.
+func (s *Scanner) Scanned() int {
+	return s.scan.index
+}
+
+// Err returns the syntax error found so far, if any.
+func (s *Scanner) Err() error {
+	if s.scan.err == errSyntax {
+		return fmt.Errorf("invalid character <<%c>> %s", s.scan.errChar, s.scan.errContext)
+	}
+	return s.scan.err
+}
+
+// Reset prepares the scanner for a new value.
+func (s *Scanner) Reset() {
+	s.scan.reset()
+	s.scan.endTop = false
+	s.scan.index = 0
+	s.init = true
+	s.failed = false
 }
 
 func isSpace(c byte) bool {
@@ -116,7 +175,7 @@ func (s *scanner) eof() scanStatus {
 		return scanEnd
 	}
 	if s.err == nil {
-		s.err = fmt.Errorf("unexpected end of JSON input")
+		s.err = errUnexpectedEnd
 	}
 	return scanError
 }
@@ -531,6 +590,7 @@ func stateError(s *scanner, c byte) scanStatus {
 // error records an error and switches to the error state.
 func (s *scanner) error(c byte, context string) scanStatus {
 	s.step = stateError
-	s.err = fmt.Errorf("invalid character <<%c>> %s", c, context)
+	s.err = errSyntax
+	s.errChar, s.errContext = c, context
 	return scanError
 }
@@ -41,3 +41,32 @@ func TestScan(t *testing.T) {
 		}
 	}
 }
+
+func TestScannerChunks(t *testing.T) {
+	for _, st := range scanTests {
+		for size := 1; size <= 4; size++ {
+			var s Scanner
+			data := []byte(st.data)
+			for i := 0; i < len(data); i += size {
+				end := i + size
+				if end > len(data) {
+					end = len(data)
+				}
+				if !s.Write(data[i:end]) {
+					break
+				}
+			}
+			if s.Err() == nil {
+				s.Close()
+			}
+			err := s.Err()
+			if s.Scanned() != st.length {
+				t.Errorf("chunks of %d: expected %d bytes scanned, got %d; input: %s",
+					size, st.length, s.Scanned(), st.data)
+			}
+			if (err == nil) != st.ok {
+				t.Errorf("chunks of %d: unexpected error %v; input: %s", size, err, st.data)
+			}
+		}
+	}
+}
@@ -0,0 +1,496 @@
+// Package magic parses the classic libmagic magic(5) rule syntax.
+//
+// Only the subset of the syntax which can be evaluated on the head of a file
+// is supported: absolute offsets, numeric types, string, search and regex.
+// Rules using indirect or relative offsets, named rules or other unsupported
+// constructs are dropped, together with their continuation lines.
+//
+// See https://man7.org/linux/man-pages/man5/magic.5.html
+package magic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Entry is a magic rule converted into a matching function.
+// Children hold the continuation lines of the rule.
+type Entry struct {
+	// Mime is the value of the "!:mime" line following the rule.
+	// It is empty when the rule does not declare one.
+	Mime string
+	// Extension is the first value of the "!:ext" line following the rule.
+	Extension string
+	Match     func([]byte) bool
+	// Depth is the number of bytes, counted from the start of the input,
+	// the rule needs to decide. It is zero when the rule is not bounded.
+	Depth    int
+	Children []*Entry
+
+	level       int
+	unsupported bool
+}
+
+// Parse reads magic rules from r and returns the top level entries.
+// Entries for which neither the rule nor any of its continuations declare
+// a MIME type are discarded, because they are of no use for detection.
+func Parse(r io.Reader) ([]*Entry, error) {
+	var (
+		top   []*Entry
+		stack []*Entry // the last entry seen on each level
+		last  *Entry
+	)
+
+	s := bufio.NewScanner(r)
+	lineNo := 0
+	for s.Scan() {
+		lineNo++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "!:") {
+			if last != nil {
+				parseDirective(last, line[2:])
+			}
+			continue
+		}
+
+		e, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("magic: line %d: %v", lineNo, err)
+		}
+		last = e
+
+		if e.level == 0 {
+			top = append(top, e)
+			stack = append(stack[:0], e)
+			continue
+		}
+		// A continuation without a parent on the previous level is dropped.
+		if e.level > len(stack) {
+			e.unsupported = true
+			continue
+		}
+		stack = stack[:e.level]
+		parent := stack[e.level-1]
+		parent.Children = append(parent.Children, e)
+		stack = append(stack, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return prune(top), nil
+}
+
+// prune removes the unsupported entries and the ones that do not lead to a MIME type.
+func prune(entries []*Entry) []*Entry {
+	var out []*Entry
+	for _, e := range entries {
+		if e.unsupported {
+			continue
+		}
+		e.Children = prune(e.Children)
+		if e.Mime == "" && len(e.Children) == 0 {
+			continue
+		}
+		out = append(out, e)
+	}
+
+	return out
+}
+
+func parseDirective(e *Entry, d string) {
+	d = strings.TrimSpace(d)
+	switch {
+	case strings.HasPrefix(d, "mime"):
+		e.Mime = strings.TrimSpace(d[len("mime"):])
+	case strings.HasPrefix(d, "ext"):
+		ext := strings.TrimSpace(d[len("ext"):])
+		e.Extension = strings.Split(ext, "/")[0]
+	}
+}
+
+// parseLine parses a rule line of the form: [>...]offset type test [message].
+// Syntax which can not be evaluated results in an entry marked as unsupported,
+// while malformed lines result in an error.
+func parseLine(line string) (*Entry, error) {
+	e := &Entry{}
+	for e.level < len(line) && line[e.level] == '>' {
+		e.level++
+	}
+	fields := splitFields(line[e.level:], 3)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("not enough fields in %q", line)
+	}
+	// "x" is implied when the test value is missing, ie: for "default" or "clear".
+	if len(fields) == 2 {
+		fields = append(fields, "x")
+	}
+
+	offset, err := strconv.ParseInt(fields[0], 0, 64)
+	if err != nil || offset < 0 {
+		// Indirect, relative or negative offsets.
+		e.unsupported = true
+		return e, nil
+	}
+
+	t, span, err := newTest(fields[1], fields[2])
+	if err != nil {
+		e.unsupported = true
+		return e, nil
+	}
+	off := int(offset)
+	if span > 0 {
+		e.Depth = off + span
+	}
+	e.Match = func(in []byte) bool {
+		return t(in, off)
+	}
+
+	return e, nil
+}
+
+// splitFields splits s into at most n whitespace separated fields.
+// Whitespace escaped by a backslash does not separate fields.
+func splitFields(s string, n int) []string {
+	var fields []string
+	for len(fields) < n {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+		i := 0
+		for ; i < len(s) && s[i] != ' ' && s[i] != '\t'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i > len(s) {
+			i = len(s)
+		}
+		fields = append(fields, s[:i])
+		s = s[i:]
+	}
+
+	return fields
+}
+
+type test func(in []byte, offset int) bool
+
+// newTest returns the test described by typ and value, along with its span:
+// the number of bytes following the offset the test inspects, or zero
+// when the test is not bounded.
+func newTest(typ, value string) (test, int, error) {
+	switch {
+	case typ == "string" || strings.HasPrefix(typ, "string/"):
+		return newStringTest(typ, value)
+	case strings.HasPrefix(typ, "search"):
+		return newSearchTest(typ, value)
+	case strings.HasPrefix(typ, "regex"):
+		return newRegexTest(typ, value)
+	}
+
+	return newNumericTest(typ, value)
+}
+
+var numericTypes = map[string]struct {
+	size int
+	bo   binary.ByteOrder
+}{
+	"byte":    {1, binary.BigEndian},
+	"short":   {2, binary.LittleEndian},
+	"long":    {4, binary.LittleEndian},
+	"quad":    {8, binary.LittleEndian},
+	"beshort": {2, binary.BigEndian},
+	"belong":  {4, binary.BigEndian},
+	"bequad":  {8, binary.BigEndian},
+	"leshort": {2, binary.LittleEndian},
+	"lelong":  {4, binary.LittleEndian},
+	"lequad":  {8, binary.LittleEndian},
+}
+
+func newNumericTest(typ, value string) (test, int, error) {
+	mask := ^uint64(0)
+	if i := strings.IndexByte(typ, '&'); i != -1 {
+		m, err := strconv.ParseUint(typ[i+1:], 0, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		mask, typ = m, typ[:i]
+	}
+	unsigned := strings.HasPrefix(typ, "u")
+	typ = strings.TrimPrefix(typ, "u")
+	nt, ok := numericTypes[typ]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported type %q", typ)
+	}
+
+	op, value := splitOperator(value)
+	var want uint64
+	if op != 'x' {
+		v, err := parseNumber(value)
+		if err != nil {
+			return nil, 0, err
+		}
+		want = v
+	}
+	bits := uint(nt.size * 8)
+	signExtend := func(v uint64) int64 {
+		return int64(v<<(64-bits)) >> (64 - bits)
+	}
+
+	return func(in []byte, offset int) bool {
+		if offset+nt.size > len(in) {
+			return false
+		}
+		var got uint64
+		switch nt.size {
+		case 1:
+			got = uint64(in[offset])
+		case 2:
+			got = uint64(nt.bo.Uint16(in[offset:]))
+		case 4:
+			got = uint64(nt.bo.Uint32(in[offset:]))
+		case 8:
+			got = nt.bo.Uint64(in[offset:])
+		}
+		got &= mask
+		w := want
+		if bits < 64 {
+			got &= 1<<bits - 1
+			w &= 1<<bits - 1
+		}
+
+		switch op {
+		case 'x':
+			return true
+		case '=':
+			return got == w
+		case '!':
+			return got != w
+		case '&':
+			return got&w == w
+		case '^':
+			return got&w == 0
+		case '<', '>':
+			less := got < w
+			if !unsigned {
+				less = signExtend(got) < signExtend(w)
+			}
+			if op == '<' {
+				return less
+			}
+			return !less && got != w
+		}
+		return false
+	}, nt.size, nil
+}
+
+// splitOperator separates the comparison operator from the test value.
+// When no operator is present, "=" is implied.
+func splitOperator(value string) (byte, string) {
+	if value == "x" {
+		return 'x', ""
+	}
+	switch value[0] {
+	case '=', '!', '<', '>', '&', '^':
+		return value[0], strings.TrimSpace(value[1:])
+	}
+
+	return '=', value
+}
+
+func parseNumber(s string) (uint64, error) {
+	s = strings.TrimRight(s, "lLuU")
+	if strings.HasPrefix(s, "-") {
+		v, err := strconv.ParseInt(s, 0, 64)
+		return uint64(v), err
+	}
+
+	return strconv.ParseUint(s, 0, 64)
+}
+
+func newStringTest(typ, value string) (test, int, error) {
+	caseInsensitive := false
+	if i := strings.IndexByte(typ, '/'); i != -1 {
+		caseInsensitive = strings.ContainsAny(typ[i+1:], "cC")
+	}
+	op, value := splitOperator(value)
+	want, err := unescape(value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return func(in []byte, offset int) bool {
+		if op == 'x' {
+			return offset <= len(in)
+		}
+		if offset+len(want) > len(in) {
+			return op == '!'
+		}
+		got := in[offset : offset+len(want)]
+		var c int
+		if caseInsensitive {
+			c = bytes.Compare(bytes.ToLower(got), bytes.ToLower(want))
+		} else {
+			c = bytes.Compare(got, want)
+		}
+
+		switch op {
+		case '=':
+			return c == 0
+		case '!':
+			return c != 0
+		case '<':
+			return c < 0
+		case '>':
+			return c > 0
+		}
+		return false
+	}, len(want), nil
+}
+
+// newSearchTest handles the "search/N" type, which looks for the test value
+// in the N bytes following offset.
+func newSearchTest(typ, value string) (test, int, error) {
+	rng, flags := searchParams(typ)
+	op, value := splitOperator(value)
+	if op != '=' {
+		return nil, 0, fmt.Errorf("unsupported search operator %q", op)
+	}
+	want, err := unescape(value)
+	if err != nil {
+		return nil, 0, err
+	}
+	caseInsensitive := strings.ContainsAny(flags, "cC")
+	if caseInsensitive {
+		want = bytes.ToLower(want)
+	}
+	span := 0
+	if rng > 0 {
+		span = rng + len(want)
+	}
+
+	return func(in []byte, offset int) bool {
+		window := searchWindow(in, offset, rng+len(want))
+		if caseInsensitive {
+			window = bytes.ToLower(window)
+		}
+		return bytes.Contains(window, want)
+	}, span, nil
+}
+
+// newRegexTest handles the "regex/N" type using Go regular expressions.
+func newRegexTest(typ, value string) (test, int, error) {
+	rng, flags := searchParams(typ)
+	_, value = splitOperator(value)
+	expr, err := unescape(value)
+	if err != nil {
+		return nil, 0, err
+	}
+	pattern := string(expr)
+	if strings.ContainsAny(flags, "c") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return func(in []byte, offset int) bool {
+		return re.Match(searchWindow(in, offset, rng))
+	}, rng, nil
+}
+
+// searchParams splits a "search/N/flags" type into its range and flags.
+// A missing range means the search is not bounded.
+func searchParams(typ string) (int, string) {
+	parts := strings.Split(typ, "/")
+	rng, flags := 0, ""
+	for _, p := range parts[1:] {
+		if n, err := strconv.Atoi(p); err == nil {
+			rng = n
+		} else {
+			flags += p
+		}
+	}
+
+	return rng, flags
+}
+
+func searchWindow(in []byte, offset, n int) []byte {
+	if offset > len(in) {
+		return nil
+	}
+	if n <= 0 || offset+n > len(in) {
+		return in[offset:]
+	}
+
+	return in[offset : offset+n]
+}
+
+// unescape decodes the C-like escape sequences allowed in magic test values.
+func unescape(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return nil, fmt.Errorf("trailing backslash in %q", s)
+		}
+		switch c := s[i]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'v':
+			out = append(out, '\v')
+		case 'a':
+			out = append(out, '\a')
+		case 'x':
+			j := i + 1
+			for ; j < len(s) && j < i+3 && isHex(s[j]); j++ {
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid hex escape in %q", s)
+			}
+			v, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+			out = append(out, byte(v))
+			i = j - 1
+		default:
+			if '0' <= c && c <= '7' {
+				j := i
+				for ; j < len(s) && j < i+3 && '0' <= s[j] && s[j] <= '7'; j++ {
+				}
+				v, _ := strconv.ParseUint(s[i:j], 8, 8)
+				out = append(out, byte(v))
+				i = j - 1
+				continue
+			}
+			out = append(out, c)
+		}
+	}
+
+	return out, nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
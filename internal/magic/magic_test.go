@@ -0,0 +1,150 @@
+package magic
+
+import (
+	"strings"
+	"testing"
+)
+
+const rules = `
+# A comment followed by an empty line.
+
+0	string		\x89PNG\r\n\x1a\n	PNG image data
+!:mime	image/png
+!:ext	png
+
+0	belong		0xcafebabe		compiled Java class data,
+!:mime	application/x-java-applet
+>4	beshort		x			version %d.
+
+0	string		FORM			IFF data
+>8	string		AIFF			\b, AIFF audio
+!:mime	audio/x-aiff
+>8	string		8SVX			\b, 8SVX
+>8	string/c	anim			\b, animation
+!:mime	video/x-anim
+
+0	lelong&0xfffffff0	0x12345670	masked number
+!:mime	application/x-masked
+
+0	ubyte		>0xf0			greater than
+!:mime	application/x-greater
+
+0	search/32	MAGIC\ HERE		search
+!:mime	application/x-search
+
+0	regex/16	^[0-9]+[a-z]		regex
+!:mime	application/x-regex
+
+(4.l)	string		indirect		unsupported indirect offset
+!:mime	application/x-indirect
+
+0	string		NOMIME			rule without mime type
+>4	byte		1			only a description
+`
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 7 {
+		t.Fatalf("expected 7 entries, got %d", len(entries))
+	}
+
+	tcs := []struct {
+		in   string
+		mime string
+	}{
+		{"\x89PNG\r\n\x1a\n", "image/png"},
+		{"\xca\xfe\xba\xbe\x00\x32", "application/x-java-applet"},
+		{"FORM\x00\x00\x00\x00AIFF", "audio/x-aiff"},
+		{"FORM\x00\x00\x00\x00ANIM", "video/x-anim"},
+		{"FORM\x00\x00\x00\x008SVX", ""},
+		{"\x7a\x56\x34\x12", "application/x-masked"},
+		{"\x70\x56\x34\x13", ""},
+		{"\xf5", "application/x-greater"},
+		{"\xf0", ""},
+		{"some bytes then MAGIC HERE", "application/x-search"},
+		{"123abc", "application/x-regex"},
+		{"abc123", ""},
+		{"NOMIME\x00\x00\x01", ""},
+	}
+	for _, tc := range tcs {
+		if got := match(entries, []byte(tc.in), ""); got != tc.mime {
+			t.Errorf("%q: expected %q, got %q", tc.in, tc.mime, got)
+		}
+	}
+}
+
+func TestParseExtension(t *testing.T) {
+	entries, err := Parse(strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Extension != "png" {
+		t.Errorf("expected png extension, got %q", entries[0].Extension)
+	}
+}
+
+func TestParseDepth(t *testing.T) {
+	entries, err := Parse(strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcs := []struct {
+		e     *Entry
+		depth int
+	}{
+		{entries[0], 8},              // PNG string
+		{entries[1], 4},              // Java belong
+		{entries[2].Children[0], 12}, // AIFF string at offset 8
+		{entries[5], 42},             // search range and value length
+		{entries[6], 16},             // regex range
+	}
+	for i, tc := range tcs {
+		if tc.e.Depth != tc.depth {
+			t.Errorf("%d: expected depth %d, got %d", i, tc.depth, tc.e.Depth)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Parse(strings.NewReader("0\n")); err == nil {
+		t.Errorf("line with missing type should fail to parse")
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tcs := []struct {
+		in, out string
+	}{
+		{`abc`, "abc"},
+		{`\x41\102\n\ `, "AB\n "},
+		{`\0`, "\x00"},
+		{`\<html`, "<html"},
+	}
+	for _, tc := range tcs {
+		got, err := unescape(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+		}
+		if string(got) != tc.out {
+			t.Errorf("%q: expected %q, got %q", tc.in, tc.out, got)
+		}
+	}
+}
+
+// match mimics the tree matching done by the mimetype package.
+func match(entries []*Entry, in []byte, parentMime string) string {
+	for _, e := range entries {
+		if e.Match(in) {
+			mime := e.Mime
+			if mime == "" {
+				mime = parentMime
+			}
+			return match(e.Children, in, mime)
+		}
+	}
+
+	return parentMime
+}
@@ -0,0 +1,188 @@
+package matchers
+
+import "bytes"
+
+// OpenApiJson matches an OpenAPI 3 or a Swagger 2.0 description in JSON format.
+func OpenApiJson(in []byte) bool {
+	return bytes.HasPrefix([]byte(jsonStringValue(in, "openapi")), []byte("3.")) ||
+		jsonStringValue(in, "swagger") == "2.0"
+}
+
+// AsyncApiJson matches an AsyncAPI description in JSON format.
+func AsyncApiJson(in []byte) bool {
+	return jsonStringValue(in, "asyncapi") != ""
+}
+
+// OpenApiYaml matches an OpenAPI 3 or a Swagger 2.0 description in YAML format.
+func OpenApiYaml(in []byte) bool {
+	return bytes.HasPrefix(yamlTopLevelValue(in, "openapi"), []byte("3.")) ||
+		bytes.Equal(yamlTopLevelValue(in, "swagger"), []byte("2.0"))
+}
+
+// AsyncApiYaml matches an AsyncAPI description in YAML format.
+func AsyncApiYaml(in []byte) bool {
+	return len(yamlTopLevelValue(in, "asyncapi")) > 0
+}
+
+// ApiMeta extracts the specification version of OpenAPI, Swagger and
+// AsyncAPI descriptions.
+func ApiMeta(in []byte) map[string]string {
+	for _, key := range []string{"openapi", "swagger", "asyncapi"} {
+		v := jsonStringValue(in, key)
+		if v == "" {
+			v = string(yamlTopLevelValue(in, key))
+		}
+		if v != "" {
+			return map[string]string{"version": v}
+		}
+	}
+
+	return nil
+}
+
+// yamlTopLevelValue returns the unquoted scalar value of key in the root
+// mapping of a YAML document. The document must start with a mapping,
+// after the optional comments, directives and document start marker.
+// It returns nil if the key is not found.
+func yamlTopLevelValue(in []byte, key string) []byte {
+	started := false
+	for len(in) > 0 {
+		line := firstLine(in)
+		in = in[len(line):]
+		if len(in) > 0 {
+			in = in[1:]
+		}
+		line = bytes.TrimRight(line, "\r")
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case len(trimmed) == 0 || trimmed[0] == '#':
+			continue
+		case !started && (trimmed[0] == '%' || bytes.Equal(trimmed, []byte("---"))):
+			continue
+		}
+		// Only lines at the root level hold keys of the root mapping.
+		if isWS(line[0]) || line[0] == '-' {
+			if !started {
+				return nil
+			}
+			continue
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon <= 0 {
+			return nil
+		}
+		started = true
+		if string(line[:colon]) != key {
+			continue
+		}
+		v := bytes.TrimSpace(line[colon+1:])
+		if i := bytes.Index(v, []byte(" #")); i != -1 {
+			v = bytes.TrimSpace(v[:i])
+		}
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		return v
+	}
+
+	return nil
+}
+
+// graphQlDefinitions are the keywords starting the type system
+// definitions of a GraphQL schema.
+var graphQlDefinitions = [][]byte{
+	[]byte("schema"),
+	[]byte("type"),
+	[]byte("interface"),
+	[]byte("input"),
+	[]byte("enum"),
+	[]byte("union"),
+	[]byte("scalar"),
+	[]byte("directive"),
+	[]byte("extend"),
+}
+
+// GraphQl matches a GraphQL schema written in the schema definition language.
+// The first definition, after comments and descriptions, must be a type system
+// definition, and the schema must define an object, an interface or an input
+// type, or the schema itself.
+func GraphQl(in []byte) bool {
+	first := skipGraphQlIgnored(in)
+	found := false
+	for _, d := range graphQlDefinitions {
+		if bytes.HasPrefix(first, d) && len(first) > len(d) &&
+			(isWS(first[len(d)]) || first[len(d)] == '{') {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	for _, body := range [][]byte{[]byte("schema"), []byte("type"), []byte("interface"), []byte("input")} {
+		for rest := in; ; {
+			i := bytes.Index(rest, body)
+			if i == -1 {
+				break
+			}
+			if (i == 0 || rest[i-1] == '\n') && graphQlHasBody(rest[i+len(body):]) {
+				return true
+			}
+			rest = rest[i+len(body):]
+		}
+	}
+
+	return false
+}
+
+// graphQlHasBody reports whether in, following a definition keyword, is
+// made of an optional name and implemented interfaces and of a field block.
+func graphQlHasBody(in []byte) bool {
+	if len(in) == 0 || !isWS(in[0]) && in[0] != '{' {
+		return false
+	}
+	line := firstLine(in)
+	i := bytes.IndexByte(line, '{')
+	if i == -1 {
+		return false
+	}
+	for _, b := range line[:i] {
+		if !isWS(b) && b != '_' && b != '&' && b != '@' && b != '(' && b != ')' && b != ':' && b != '"' &&
+			!('a' <= b && b <= 'z') && !('A' <= b && b <= 'Z') && !('0' <= b && b <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// skipGraphQlIgnored skips the whitespace, comments and descriptions
+// found at the start of a GraphQL document.
+func skipGraphQlIgnored(in []byte) []byte {
+	for {
+		in = trimLWS(in)
+		switch {
+		case bytes.HasPrefix(in, []byte("#")):
+			i := bytes.IndexByte(in, '\n')
+			if i == -1 {
+				return nil
+			}
+			in = in[i+1:]
+		case bytes.HasPrefix(in, []byte(`"""`)):
+			i := bytes.Index(in[3:], []byte(`"""`))
+			if i == -1 {
+				return nil
+			}
+			in = in[3+i+3:]
+		case bytes.HasPrefix(in, []byte(`"`)):
+			i := bytes.IndexByte(in[1:], '"')
+			if i == -1 {
+				return nil
+			}
+			in = in[1+i+1:]
+		default:
+			return in
+		}
+	}
+}
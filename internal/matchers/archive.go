@@ -15,16 +15,6 @@ func SevenZ(in []byte) bool {
 	return bytes.HasPrefix(in, []byte{0x37, 0x7A, 0xBC, 0xAF, 0x27, 0x1C})
 }
 
-// Epub matches an EPUB file.
-func Epub(in []byte) bool {
-	return len(in) > 58 && bytes.Equal(in[30:58], []byte("mimetypeapplication/epub+zip"))
-}
-
-// Jar matches a Java archive file.
-func Jar(in []byte) bool {
-	return bytes.Contains(in, []byte("META-INF/MANIFEST.MF"))
-}
-
 // Gzip matched gzip files based on http://www.zlib.org/rfc-gzip.html#header-trailer.
 func Gzip(in []byte) bool {
 	return bytes.HasPrefix(in, []byte{0x1f, 0x8b})
@@ -72,12 +62,14 @@ func Deb(in []byte) bool {
 	})
 }
 
-// Rar matches a RAR archive file.
-func Rar(in []byte) bool {
-	if !bytes.HasPrefix(in, []byte{0x52, 0x61, 0x72, 0x21, 0x1A, 0x07}) {
-		return false
-	}
-	return len(in) > 8 && (bytes.Equal(in[6:8], []byte{0x01, 0x00}) || in[6] == 0x00)
+// Rar4 matches a RAR archive file, versions 1.5 to 4.x.
+func Rar4(in []byte) bool {
+	return len(in) > 8 && bytes.HasPrefix(in, []byte{0x52, 0x61, 0x72, 0x21, 0x1A, 0x07, 0x00})
+}
+
+// Rar5 matches a RAR archive file, version 5.
+func Rar5(in []byte) bool {
+	return len(in) > 8 && bytes.HasPrefix(in, []byte{0x52, 0x61, 0x72, 0x21, 0x1A, 0x07, 0x01, 0x00})
 }
 
 // Warc matches a Web ARChive file.
@@ -85,6 +77,11 @@ func Warc(in []byte) bool {
 	return bytes.HasPrefix(in, []byte("WARC/"))
 }
 
+// Xz matches an xz compressed stream.
+func Xz(in []byte) bool {
+	return bytes.HasPrefix(in, []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00})
+}
+
 // Zstd matches a Zstandard archive file.
 func Zstd(in []byte) bool {
 	return len(in) >= 4 &&
@@ -0,0 +1,324 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
+
+// Rar4Meta extracts the flags of the main archive header of a RAR 4 file.
+// The header follows the 7 bytes long marker block.
+//
+// https://www.rarlab.com/technote.htm
+func Rar4Meta(in []byte) map[string]string {
+	// HEAD_CRC, HEAD_TYPE, HEAD_FLAGS and HEAD_SIZE.
+	if len(in) < 14 || in[9] != 0x73 {
+		return map[string]string{"version": "4"}
+	}
+	flags := binary.LittleEndian.Uint16(in[10:])
+
+	return map[string]string{
+		"version":           "4",
+		"volume":            boolString(flags&0x0001 != 0),
+		"solid":             boolString(flags&0x0008 != 0),
+		"encrypted-headers": boolString(flags&0x0080 != 0),
+	}
+}
+
+// Rar5Meta extracts the flags of the first header of a RAR 5 file.
+// Archives with encrypted headers start with an archive encryption header,
+// the other ones start with the main archive header.
+//
+// https://www.rarlab.com/technote.htm
+func Rar5Meta(in []byte) map[string]string {
+	meta := map[string]string{"version": "5"}
+	if len(in) < 13 {
+		return meta
+	}
+	// Skip the signature and the header CRC32.
+	h := in[12:]
+	if _, h = rarVint(h); h == nil {
+		return meta
+	}
+	typ, h := rarVint(h)
+	if h == nil {
+		return meta
+	}
+	switch typ {
+	case 4:
+		meta["encrypted-headers"] = "true"
+	case 1:
+		meta["encrypted-headers"] = "false"
+		flags, h := rarVint(h)
+		if h == nil {
+			return meta
+		}
+		if flags&0x0001 != 0 {
+			if _, h = rarVint(h); h == nil {
+				return meta
+			}
+		}
+		if flags&0x0002 != 0 {
+			if _, h = rarVint(h); h == nil {
+				return meta
+			}
+		}
+		archiveFlags, h := rarVint(h)
+		if h == nil {
+			return meta
+		}
+		meta["volume"] = boolString(archiveFlags&0x0001 != 0)
+		meta["solid"] = boolString(archiveFlags&0x0004 != 0)
+	}
+
+	return meta
+}
+
+// rarVint decodes the variable length integer RAR 5 uses in its headers:
+// 7 bits per byte, least significant first, the high bit marking
+// continuation. It returns the value and the remaining bytes, or
+// nil remaining bytes if in is truncated.
+func rarVint(in []byte) (uint64, []byte) {
+	var v uint64
+	for i := 0; i < len(in) && i < 10; i++ {
+		v |= uint64(in[i]&0x7F) << (7 * uint(i))
+		if in[i]&0x80 == 0 {
+			return v, in[i+1:]
+		}
+	}
+
+	return 0, nil
+}
+
+// 7z property IDs used in the archive header.
+const (
+	sevenZEnd              = 0x00
+	sevenZHeader           = 0x01
+	sevenZMainStreamsInfo  = 0x04
+	sevenZPackInfo         = 0x06
+	sevenZUnpackInfo       = 0x07
+	sevenZSubStreamsInfo   = 0x08
+	sevenZSize             = 0x09
+	sevenZCRC              = 0x0A
+	sevenZFolder           = 0x0B
+	sevenZCodersUnpackSize = 0x0C
+	sevenZNumUnpackStream  = 0x0D
+	sevenZEncodedHeader    = 0x17
+)
+
+// sevenZAES is the ID of the 7zAES coder.
+var sevenZAES = []byte{0x06, 0xF1, 0x07, 0x01}
+
+// SevenZMeta extracts the format version of a 7z archive and, when the
+// header at the end of the archive is part of the input, whether the archive
+// is solid and whether its header is encrypted. The header of archives
+// created with default settings is compressed, in which case the solid
+// flag cannot be determined.
+//
+// https://py7zr.readthedocs.io/en/latest/archive_format.html
+func SevenZMeta(in []byte) map[string]string {
+	if len(in) < 32 {
+		return nil
+	}
+	meta := map[string]string{
+		"version": strconv.Itoa(int(in[6])) + "." + strconv.Itoa(int(in[7])),
+	}
+	off := binary.LittleEndian.Uint64(in[12:])
+	size := binary.LittleEndian.Uint64(in[20:])
+	if off > uint64(len(in)) || size > uint64(len(in)) || 32+off+size > uint64(len(in)) || size == 0 {
+		return meta
+	}
+
+	p := &sevenZParser{in: in[32+off : 32+off+size]}
+	switch p.byte() {
+	case sevenZHeader:
+		if p.byte() != sevenZMainStreamsInfo {
+			return meta
+		}
+		if s, ok := p.streamsInfo(); ok {
+			meta["solid"] = boolString(s.solid)
+			meta["encrypted-headers"] = "false"
+		}
+	case sevenZEncodedHeader:
+		if s, ok := p.streamsInfo(); ok {
+			meta["encrypted-headers"] = boolString(s.encrypted)
+		}
+	}
+
+	return meta
+}
+
+// sevenZStreams is the information gathered from a 7z StreamsInfo structure.
+type sevenZStreams struct {
+	// solid is true if a folder holds more than one file.
+	solid bool
+	// encrypted is true if a folder uses the 7zAES coder.
+	encrypted bool
+}
+
+// sevenZParser reads the structures of a 7z header. Reading past the end of
+// the input sets the err field, after which all reads return zero values.
+type sevenZParser struct {
+	in  []byte
+	err bool
+}
+
+func (p *sevenZParser) byte() byte {
+	if len(p.in) == 0 {
+		p.err = true
+		return 0
+	}
+	b := p.in[0]
+	p.in = p.in[1:]
+
+	return b
+}
+
+func (p *sevenZParser) skip(n uint64) {
+	if n > uint64(len(p.in)) {
+		p.err = true
+		p.in = nil
+		return
+	}
+	p.in = p.in[n:]
+}
+
+// number decodes a 7z NUMBER: the count of leading 1 bits of the first
+// byte is the number of extra little endian bytes following it.
+func (p *sevenZParser) number() uint64 {
+	first := p.byte()
+	mask := byte(0x80)
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if first&mask == 0 {
+			high := uint64(first) & (uint64(mask) - 1)
+			return v | high<<(8*uint(i))
+		}
+		v |= uint64(p.byte()) << (8 * uint(i))
+		mask >>= 1
+	}
+
+	return v
+}
+
+// digests skips a list of n optional CRC32 values.
+func (p *sevenZParser) digests(n uint64) {
+	defined := n
+	if p.byte() == 0 {
+		defined = 0
+		for i := uint64(0); i < (n+7)/8; i++ {
+			for b := p.byte(); b != 0; b &= b - 1 {
+				defined++
+			}
+		}
+	}
+	p.skip(4 * defined)
+}
+
+// streamsInfo parses a StreamsInfo structure, up to and including its end marker.
+func (p *sevenZParser) streamsInfo() (s sevenZStreams, ok bool) {
+	var numFolders uint64
+	for !p.err {
+		switch p.byte() {
+		case sevenZEnd:
+			return s, !p.err
+		case sevenZPackInfo:
+			p.number()
+			numPackStreams := p.number()
+			for id := p.byte(); id != sevenZEnd && !p.err; id = p.byte() {
+				switch id {
+				case sevenZSize:
+					for i := uint64(0); i < numPackStreams && !p.err; i++ {
+						p.number()
+					}
+				case sevenZCRC:
+					p.digests(numPackStreams)
+				default:
+					return s, false
+				}
+			}
+		case sevenZUnpackInfo:
+			if p.byte() != sevenZFolder {
+				return s, false
+			}
+			numFolders = p.number()
+			if p.byte() != 0 || numFolders > uint64(len(p.in)) {
+				return s, false
+			}
+			var numOutStreams uint64
+			for i := uint64(0); i < numFolders && !p.err; i++ {
+				out, encrypted := p.folder()
+				numOutStreams += out
+				s.encrypted = s.encrypted || encrypted
+			}
+			if p.byte() != sevenZCodersUnpackSize {
+				return s, false
+			}
+			for i := uint64(0); i < numOutStreams && !p.err; i++ {
+				p.number()
+			}
+			for id := p.byte(); id != sevenZEnd && !p.err; id = p.byte() {
+				if id != sevenZCRC {
+					return s, false
+				}
+				p.digests(numFolders)
+			}
+		case sevenZSubStreamsInfo:
+			id := p.byte()
+			if id == sevenZNumUnpackStream {
+				for i := uint64(0); i < numFolders && !p.err; i++ {
+					if p.number() > 1 {
+						s.solid = true
+					}
+				}
+			}
+			// The sizes and CRCs of the files are not needed.
+			return s, !p.err
+		default:
+			return s, false
+		}
+	}
+
+	return s, false
+}
+
+// folder parses a Folder structure and returns its number of output
+// streams and whether one of its coders is 7zAES.
+func (p *sevenZParser) folder() (numOutStreams uint64, encrypted bool) {
+	numCoders := p.number()
+	var numInStreams uint64
+	for i := uint64(0); i < numCoders && !p.err; i++ {
+		flags := p.byte()
+		idSize := uint64(flags & 0x0F)
+		if idSize <= uint64(len(p.in)) && bytes.Equal(p.in[:idSize], sevenZAES) {
+			encrypted = true
+		}
+		p.skip(idSize)
+		if flags&0x10 != 0 {
+			numInStreams += p.number()
+			numOutStreams += p.number()
+		} else {
+			numInStreams++
+			numOutStreams++
+		}
+		if flags&0x20 != 0 {
+			p.skip(p.number())
+		}
+	}
+	if numOutStreams == 0 || numOutStreams > uint64(len(p.in)) {
+		p.err = true
+		return 0, false
+	}
+	numBindPairs := numOutStreams - 1
+	for i := uint64(0); i < numBindPairs && !p.err; i++ {
+		p.number()
+		p.number()
+	}
+	if numInStreams > numBindPairs+1 {
+		for i := uint64(0); i < numInStreams-numBindPairs && !p.err; i++ {
+			p.number()
+		}
+	}
+
+	return numOutStreams, encrypted
+}
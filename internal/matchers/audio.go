@@ -3,6 +3,7 @@ package matchers
 import (
 	"bytes"
 	"encoding/binary"
+	"strconv"
 )
 
 // Mp3 matches an mp3 file.
@@ -43,6 +44,55 @@ func Midi(in []byte) bool {
 	return bytes.HasPrefix(in, []byte("\x4D\x54\x68\x64"))
 }
 
+// MidiMeta extracts the format of a Standard MIDI File and its number
+// of tracks from the header chunk. Format 0 files hold a single track,
+// format 1 files hold simultaneous tracks and format 2 files hold
+// independent single track patterns.
+//
+// https://www.midi.org/specifications/file-format-specifications/standard-midi-files
+func MidiMeta(in []byte) map[string]string {
+	if len(in) < 12 || !Midi(in) {
+		return nil
+	}
+
+	return map[string]string{
+		"format": strconv.Itoa(int(binary.BigEndian.Uint16(in[8:]))),
+		"tracks": strconv.Itoa(int(binary.BigEndian.Uint16(in[10:]))),
+	}
+}
+
+// Rmid matches a RIFF MIDI file: a Standard MIDI File stored
+// in the data chunk of a RIFF container.
+func Rmid(in []byte) bool {
+	return len(in) >= 12 &&
+		bytes.Equal(in[:4], []byte("RIFF")) &&
+		bytes.Equal(in[8:12], []byte("RMID"))
+}
+
+// RmidMeta extracts the metadata of the Standard MIDI File
+// held in the data chunk of a RIFF MIDI file.
+func RmidMeta(in []byte) map[string]string {
+	if len(in) < 20 || !bytes.Equal(in[12:16], []byte("data")) {
+		return nil
+	}
+
+	return MidiMeta(in[20:])
+}
+
+// Xmf matches an Extensible Music Format file, a container for MIDI
+// sequences and the DLS instruments playing them, like mobile XMF.
+//
+// https://www.midi.org/specifications/file-format-specifications/xmf-extensible-music-format
+func Xmf(in []byte) bool {
+	return len(in) >= 8 && bytes.HasPrefix(in, []byte("XMF_")) &&
+		isDigits(in[4:5]) && in[5] == '.' && isDigits(in[6:8])
+}
+
+// XmfMeta extracts the version of the XMF file format, like 2.00.
+func XmfMeta(in []byte) map[string]string {
+	return map[string]string{"version": string(in[4:8])}
+}
+
 // Ape matches a Monkey's Audio file.
 func Ape(in []byte) bool {
 	return bytes.HasPrefix(in, []byte("\x4D\x41\x43\x20\x96\x0F\x00\x00\x34\x00\x00\x00\x18\x00\x00\x00\x90\xE3"))
@@ -53,13 +103,121 @@ func MusePack(in []byte) bool {
 	return bytes.HasPrefix(in, []byte("MPCK"))
 }
 
-// Wav matches a Waveform Audio File Format file.
+// Wav matches a Waveform Audio File Format file, including its 64-bit
+// variants RF64 and BW64, the latter being used for ADM broadcast audio.
 func Wav(in []byte) bool {
 	return len(in) > 12 &&
-		bytes.Equal(in[:4], []byte("RIFF")) &&
+		(bytes.Equal(in[:4], []byte("RIFF")) ||
+			bytes.Equal(in[:4], []byte("RF64")) ||
+			bytes.Equal(in[:4], []byte("BW64"))) &&
 		bytes.Equal(in[8:12], []byte("\x57\x41\x56\x45"))
 }
 
+// wavCodecs maps the format tags of the WAVE fmt chunk to codec names.
+var wavCodecs = map[uint16]string{
+	0x0001: "pcm",
+	0x0002: "ms-adpcm",
+	0x0003: "float",
+	0x0006: "alaw",
+	0x0007: "mulaw",
+	0x0010: "oki-adpcm",
+	0x0011: "ima-adpcm",
+	0x0017: "dialogic-oki-adpcm",
+	0x0031: "gsm610",
+	0x0040: "g721",
+	0x0050: "mpeg",
+	0x0055: "mp3",
+	0x0064: "g726",
+	0x0065: "g722",
+}
+
+// WavMeta extracts the codec of the audio data from the fmt chunk.
+// For WAVE_FORMAT_EXTENSIBLE files, the codec is read from the first
+// two bytes of the sub-format GUID, which hold the format tag.
+//
+// The number of channels and the sample rate are extracted as well, and
+// recordings sampled at 8 kHz are flagged as telephony audio. Call recording
+// services store each party of a call in its own channel of such files,
+// and Dialogic VOX audio is found wrapped in them with its own format tag.
+//
+// Broadcast Wave Format files are flagged by the presence of the bext chunk,
+// and the integrated loudness is extracted from it, starting with version 2.
+// The presence of iXML production metadata and of Audio Definition Model
+// metadata, stored in the axml and chna chunks, is reported as well. Only the
+// chunks found in the input are taken into account, so metadata chunks
+// placed after the audio data are usually missed.
+//
+// https://tech.ebu.ch/docs/tech/tech3285.pdf
+func WavMeta(in []byte) map[string]string {
+	meta := map[string]string{}
+	for off := 12; off+8 <= len(in); {
+		size := int(binary.LittleEndian.Uint32(in[off+4:]))
+		chunk := in[off+8:]
+		if size >= 0 && size < len(chunk) {
+			chunk = chunk[:size]
+		}
+		switch string(in[off : off+4]) {
+		case "fmt ":
+			if len(chunk) < 2 {
+				break
+			}
+			tag := binary.LittleEndian.Uint16(chunk)
+			if tag == 0xFFFE && len(chunk) >= 26 {
+				tag = binary.LittleEndian.Uint16(chunk[24:])
+			}
+			setNonEmpty(meta, "codec", wavCodecs[tag])
+			if len(chunk) < 8 {
+				break
+			}
+			meta["channels"] = strconv.Itoa(int(binary.LittleEndian.Uint16(chunk[2:])))
+			rate := binary.LittleEndian.Uint32(chunk[4:])
+			meta["sample-rate"] = strconv.FormatUint(uint64(rate), 10)
+			if rate == 8000 {
+				meta["telephony"] = "true"
+			}
+		case "bext":
+			meta["broadcast-wave"] = "true"
+			bextMeta(chunk, meta)
+		case "iXML":
+			meta["ixml"] = "true"
+		case "axml", "chna":
+			meta["adm"] = "true"
+		}
+		// Chunks are word aligned.
+		next := off + 8 + size + size%2
+		if size < 0 || next <= off {
+			break
+		}
+		off = next
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// bextMeta extracts the version and the integrated loudness of a bext chunk.
+func bextMeta(bext []byte, meta map[string]string) {
+	// Description, Originator, OriginatorReference, OriginationDate,
+	// OriginationTime and TimeReference precede the version.
+	const versionOff = 256 + 32 + 32 + 10 + 8 + 8
+	if len(bext) < versionOff+2 {
+		return
+	}
+	version := binary.LittleEndian.Uint16(bext[versionOff:])
+	meta["bext-version"] = strconv.Itoa(int(version))
+	// The UMID follows the version, then the loudness values,
+	// stored as hundredths of LUFS. 0x7FFF marks an unset value.
+	const loudnessOff = versionOff + 2 + 64
+	if version < 2 || len(bext) < loudnessOff+2 {
+		return
+	}
+	if l := int16(binary.LittleEndian.Uint16(bext[loudnessOff:])); l != 0x7FFF {
+		meta["loudness"] = strconv.FormatFloat(float64(l)/100, 'f', 2, 64)
+	}
+}
+
 // Aiff matches Audio Interchange File Format file.
 func Aiff(in []byte) bool {
 	return len(in) > 12 &&
@@ -67,9 +225,48 @@ func Aiff(in []byte) bool {
 		bytes.Equal(in[8:12], []byte("\x41\x49\x46\x46"))
 }
 
-// Au matches a Sun Microsystems au file.
+// Au matches a Sun Microsystems au file. Besides the usual big endian
+// files, it also matches the little endian variant written by DEC systems.
 func Au(in []byte) bool {
-	return bytes.HasPrefix(in, []byte("\x2E\x73\x6E\x64"))
+	return bytes.HasPrefix(in, []byte("\x2E\x73\x6E\x64")) ||
+		bytes.HasPrefix(in, []byte("\x64\x6E\x73\x2E"))
+}
+
+// auCodecs maps the encoding field of au files to codec names.
+var auCodecs = map[uint32]string{
+	1:  "mulaw",
+	2:  "pcm8",
+	3:  "pcm16",
+	4:  "pcm24",
+	5:  "pcm32",
+	6:  "float",
+	7:  "double",
+	23: "g721-adpcm",
+	24: "g722-adpcm",
+	25: "g723.3-adpcm",
+	26: "g723.5-adpcm",
+	27: "alaw",
+}
+
+// AuMeta extracts the encoding of an au file.
+func AuMeta(in []byte) map[string]string {
+	if len(in) < 16 {
+		return nil
+	}
+	var bo binary.ByteOrder = binary.BigEndian
+	if in[0] == 0x64 {
+		bo = binary.LittleEndian
+	}
+	if c, ok := auCodecs[bo.Uint32(in[12:16])]; ok {
+		return map[string]string{"codec": c}
+	}
+
+	return nil
+}
+
+// RealAudio matches a RealAudio file.
+func RealAudio(in []byte) bool {
+	return bytes.HasPrefix(in, []byte(".ra\xFD"))
 }
 
 // Amr matches an Adaptive Multi-Rate file.
@@ -0,0 +1,62 @@
+package matchers
+
+import "bytes"
+
+// AndroidBackup matches an Android Debug Bridge backup file.
+func AndroidBackup(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("ANDROID BACKUP\n"))
+}
+
+// AndroidBackupMeta extracts the header fields of an ADB backup file.
+// The header is made of four lines: the magic, the format version,
+// a flag indicating zlib compression and the encryption algorithm.
+func AndroidBackupMeta(in []byte) map[string]string {
+	lines := bytes.SplitN(in, []byte{'\n'}, 5)
+	if len(lines) < 5 {
+		return nil
+	}
+
+	return map[string]string{
+		"version":    string(lines[1]),
+		"compressed": boolString(bytes.Equal(lines[2], []byte("1"))),
+		"encryption": string(lines[3]),
+	}
+}
+
+// ITunesBackupManifestDb matches the Manifest.db SQLite database found in
+// iTunes and Finder backups of iOS devices. The schema of the database is
+// stored at the end of its first page, so detection needs the whole page.
+func ITunesBackupManifestDb(in []byte) bool {
+	return bytes.Contains(in, []byte("CREATE TABLE Files (fileID TEXT PRIMARY KEY, domain TEXT, relativePath TEXT"))
+}
+
+// Bplist matches an Apple binary property list file.
+func Bplist(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("bplist00"))
+}
+
+// Plist matches an Apple XML property list file.
+func Plist(in []byte) bool {
+	return detect(in, plistSigs)
+}
+
+// ITunesBackupManifestPlist matches the Manifest.plist property list found
+// in iTunes and Finder backups of iOS devices.
+func ITunesBackupManifestPlist(in []byte) bool {
+	return bytes.Contains(in, []byte("BackupKeyBag")) &&
+		bytes.Contains(in, []byte("Lockdown"))
+}
+
+// TitaniumBackup matches the properties file describing an application
+// backed up by Titanium Backup.
+func TitaniumBackup(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("#Titanium Backup"))
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return "false"
+}
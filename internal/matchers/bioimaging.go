@@ -0,0 +1,126 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
+
+var zarrSigs = zipSigs{
+	{entries: []string{"*.zgroup"}},
+	{entries: []string{"*.zarray"}},
+	{entries: []string{"*zarr.json"}},
+}
+
+// Zarr matches a Zarr hierarchy, like OME-Zarr microscopy images, stored in a zip archive.
+func Zarr(in []byte) bool {
+	return zarrSigs.detect(in)
+}
+
+// ZarrMetadata matches the JSON metadata documents of a Zarr hierarchy:
+// .zarray and .zgroup files for version 2, zarr.json files for version 3.
+func ZarrMetadata(in []byte) bool {
+	return bytes.Contains(in, []byte(`"zarr_format"`))
+}
+
+// ZarrMetadataMeta extracts the Zarr format version and, when
+// available, whether the metadata describes an array or a group.
+func ZarrMetadataMeta(in []byte) map[string]string {
+	meta := map[string]string{}
+	if v := jsonNumberValue(in, "zarr_format"); v != "" {
+		meta["zarr-format"] = v
+	}
+	switch {
+	case bytes.Contains(in, []byte(`"node_type": "group"`)), bytes.Contains(in, []byte(`"node_type":"group"`)):
+		meta["node-type"] = "group"
+	case bytes.Contains(in, []byte(`"node_type": "array"`)), bytes.Contains(in, []byte(`"node_type":"array"`)),
+		bytes.Contains(in, []byte(`"chunks"`)):
+		meta["node-type"] = "array"
+	default:
+		meta["node-type"] = "group"
+	}
+
+	return meta
+}
+
+// jsonNumberValue returns the integer value of the first occurrence of key.
+func jsonNumberValue(in []byte, key string) string {
+	k := []byte(`"` + key + `"`)
+	i := bytes.Index(in, k)
+	if i == -1 {
+		return ""
+	}
+	v := trimLWS(in[i+len(k):])
+	if len(v) == 0 || v[0] != ':' {
+		return ""
+	}
+	v = trimLWS(v[1:])
+	end := 0
+	for ; end < len(v) && '0' <= v[end] && v[end] <= '9'; end++ {
+	}
+
+	return string(v[:end])
+}
+
+// Nd2 matches a Nikon NIS-Elements ND2 microscopy image file.
+func Nd2(in []byte) bool {
+	return len(in) >= 48 &&
+		bytes.HasPrefix(in, []byte{0xDA, 0xCE, 0xBE, 0x0A}) &&
+		bytes.HasPrefix(in[16:], []byte("ND2 FILE SIGNATURE CHUNK NAME01!"))
+}
+
+// Lif matches a Leica Image File.
+// The file starts with a 0x70 test value, the length of the XML header block,
+// a 0x2A test value and the header written in UTF-16.
+func Lif(in []byte) bool {
+	return len(in) > 13 &&
+		bytes.HasPrefix(in, []byte{0x70, 0x00, 0x00, 0x00}) && in[8] == 0x2A &&
+		bytes.HasPrefix(in[13:], utf16le("<LMSDataContainerHeader"))
+}
+
+// LifMeta extracts the number of images stored in a Leica Image File.
+// The count is only reported when the whole XML header is part of the input.
+func LifMeta(in []byte) map[string]string {
+	xmlLen := int(binary.LittleEndian.Uint32(in[9:13])) * 2
+	if xmlLen < 0 || 13+xmlLen > len(in) {
+		return nil
+	}
+	xml := in[13 : 13+xmlLen]
+	scenes := bytes.Count(xml, utf16le("<Image>")) + bytes.Count(xml, utf16le("<Image "))
+
+	return map[string]string{"scenes": strconv.Itoa(scenes)}
+}
+
+// Czi matches a Carl Zeiss Image file.
+func Czi(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("ZISRAWFILE\x00\x00\x00\x00\x00\x00"))
+}
+
+// CziMeta extracts the number of scenes of a Carl Zeiss Image file from its
+// metadata segment. The segment is usually located at the end of the file,
+// so the count is only reported when the segment is part of the input.
+func CziMeta(in []byte) map[string]string {
+	// The file header segment data follows the 32 bytes of segment header:
+	// major, minor, reserved1, reserved2, primary file GUID, file GUID,
+	// file part, directory position and metadata position.
+	const metaPosOffset = 32 + 4 + 4 + 4 + 4 + 16 + 16 + 4 + 8
+	if len(in) < metaPosOffset+8 {
+		return nil
+	}
+	pos := binary.LittleEndian.Uint64(in[metaPosOffset:])
+	if pos == 0 || pos > uint64(len(in)) {
+		return nil
+	}
+	seg := in[pos:]
+	// Segment header, xml size, attachment size and 248 spare bytes.
+	if len(seg) < 32+256 || !bytes.HasPrefix(seg, []byte("ZISRAWMETADATA")) {
+		return nil
+	}
+	xmlSize := int(binary.LittleEndian.Uint32(seg[32:36]))
+	xml := seg[32+256:]
+	if xmlSize < len(xml) {
+		xml = xml[:xmlSize]
+	}
+
+	return map[string]string{"scenes": strconv.Itoa(bytes.Count(xml, []byte("<Scene ")))}
+}
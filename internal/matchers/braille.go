@@ -0,0 +1,123 @@
+package matchers
+
+import "bytes"
+
+// Brf matches a Braille Ready Format file.
+//
+// BRF files are plain text written in North American Braille ASCII, where
+// each character from 0x20 to 0x5F maps to a braille cell. Lines are at most
+// 40 cells long, pages are separated by form feeds and letters are either all
+// uppercase or all lowercase. Because contractions are represented by
+// punctuation, the density of punctuation is a lot higher than in prose.
+//
+// Short lines of single case text are common in other formats too, so the
+// input must also show the marks of braille: the capital and number
+// indicators starting words, like ",THE" and "#AB", and contractions written
+// with symbols or digits inside words, like "BR[N" and "S1MPLE". Digits and
+// the delimiters of tabular formats are not counted as symbols.
+func Brf(in []byte) bool {
+	lines := bytes.Split(butLastLine(in), []byte{'\n'})
+	if len(lines) < 2 {
+		return false
+	}
+
+	letters, symbols := 0, 0
+	indicators, contractions, digits := 0, 0, 0
+	upper, lower := false, false
+	for _, l := range lines {
+		l = bytes.TrimRight(l, "\r\f")
+		l = bytes.TrimLeft(l, "\f")
+		if len(l) > 40 {
+			return false
+		}
+		for i, b := range l {
+			switch {
+			case 'A' <= b && b <= 'Z':
+				letters++
+				upper = true
+			case 'a' <= b && b <= 'z':
+				letters++
+				lower = true
+			case b == ' ':
+			case '0' <= b && b <= '9', b == ',', b == ';', b == ':', b == '|':
+			case 0x21 <= b && b <= 0x5F, b == '{', b == '}', b == '~':
+				symbols++
+			default:
+				return false
+			}
+			if isBrfIndicator(l, i) {
+				indicators++
+			}
+			if isBrfContraction(l, i) {
+				contractions++
+				if '0' <= b && b <= '9' {
+					digits++
+				}
+			}
+		}
+	}
+	if upper && lower || letters == 0 {
+		return false
+	}
+
+	// The indicators and the digits of contractions are braille marks too.
+	marks := symbols + indicators + digits
+	return indicators > 0 && indicators+contractions >= 2 && marks*10 >= letters+marks
+}
+
+// isBrfIndicator reports whether the cell at i of line is a capital
+// indicator, a comma starting a word, or a number sign starting a word of
+// the letters a to j, which stand for the digits.
+func isBrfIndicator(line []byte, i int) bool {
+	if i > 0 && line[i-1] != ' ' || i+1 == len(line) {
+		return false
+	}
+	switch line[i] {
+	case ',':
+		next := line[i+1]
+		return next != ' ' && next != ',' && (next < '0' || next > '9')
+	case '#':
+		j := i + 1
+		for ; j < len(line) && isLetter(line[j]); j++ {
+			if c := line[j] | 0x20; c > 'j' {
+				return false
+			}
+		}
+		return j > i+1
+	}
+
+	return false
+}
+
+// isBrfContraction reports whether the cell at i of line is a symbol or a
+// digit standing for a contraction between two letters of a word.
+func isBrfContraction(line []byte, i int) bool {
+	if i == 0 || i+1 == len(line) || !isLetter(line[i-1]) || !isLetter(line[i+1]) {
+		return false
+	}
+	switch b := line[i]; {
+	case '0' <= b && b <= '9':
+		return true
+	case b == ',', b == ';', b == ':', b == '|', b == '/', b == '-', b == '.', b == '\'', b == '_', b == '=':
+		return false
+	}
+
+	return !isLetter(line[i]) && line[i] != ' '
+}
+
+func isLetter(b byte) bool {
+	return 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z'
+}
+
+// butLastLine drops the bytes after the last newline of the input,
+// as they might be part of a truncated line.
+func butLastLine(in []byte) []byte {
+	if len(in) < ReadLimit {
+		return in
+	}
+	if i := bytes.LastIndexByte(in, '\n'); i != -1 {
+		return in[:i]
+	}
+
+	return in
+}
@@ -0,0 +1,135 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// cfbDir is the directory of a Compound File Binary container, also known
+// as OLE2 storage. Doc, Xls, Ppt, Msi, Msg and other formats are CFB files,
+// distinguished by the names of the streams they contain.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-cfb
+type cfbDir struct {
+	// names holds the names of all storages and streams, converted to ASCII.
+	names [][]byte
+	// rootCLSID is the class identifier of the root storage.
+	rootCLSID []byte
+	// complete is false when the directory chain goes past the input,
+	// in which case names only holds the entries of the sectors read.
+	complete bool
+}
+
+const (
+	cfbEndOfChain = 0xFFFFFFFE
+	// cfbMaxDirSectors bounds the directory chain walk, guarding against cycles.
+	cfbMaxDirSectors = 64
+)
+
+// cfbDirectory parses the directory of the CFB container in the input.
+// The directory can be anywhere in the file, so it is often not part of the
+// sniffed bytes; ok is false when the directory is not reachable, and the
+// directory is not complete when only its first sectors are.
+func cfbDirectory(in []byte) (dir cfbDir, ok bool) {
+	if len(in) < 512 || !bytes.HasPrefix(in, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}) {
+		return dir, false
+	}
+	shift := binary.LittleEndian.Uint16(in[30:32])
+	if shift != 9 && shift != 12 {
+		return dir, false
+	}
+	sectorSize := 1 << shift
+
+	sector := binary.LittleEndian.Uint32(in[48:52])
+	for i := 0; i < cfbMaxDirSectors && sector != cfbEndOfChain; i++ {
+		off := (int(sector) + 1) * sectorSize
+		if sector > 1<<24 || off+sectorSize > len(in) {
+			break
+		}
+		for e := off; e < off+sectorSize; e += 128 {
+			entry := in[e : e+128]
+			// Entry type 0 means unused.
+			if entry[66] == 0 {
+				continue
+			}
+			if len(dir.names) == 0 {
+				dir.rootCLSID = entry[80:96]
+			}
+			nameLen := int(binary.LittleEndian.Uint16(entry[64:66]))
+			if nameLen > 64 {
+				nameLen = 64
+			}
+			dir.names = append(dir.names, utf16ToASCII(entry[:nameLen]))
+		}
+		ok = true
+		next, found := cfbNextSector(in, sector, sectorSize)
+		if !found {
+			return dir, ok
+		}
+		sector = next
+	}
+	dir.complete = sector == cfbEndOfChain
+
+	return dir, ok
+}
+
+// cfbNextSector follows the sector chain using the file allocation table.
+// ok is false if the FAT sector is not part of the input.
+func cfbNextSector(in []byte, sector uint32, sectorSize int) (next uint32, ok bool) {
+	perFatSector := uint32(sectorSize / 4)
+	fatIndex := sector / perFatSector
+	// Only the first 109 FAT sectors are listed in the header.
+	if fatIndex >= 109 {
+		return 0, false
+	}
+	fatSector := binary.LittleEndian.Uint32(in[76+4*fatIndex:])
+	off := (int(fatSector)+1)*sectorSize + int(sector%perFatSector)*4
+	if fatSector > 1<<24 || off+4 > len(in) {
+		return 0, false
+	}
+
+	return binary.LittleEndian.Uint32(in[off:]), true
+}
+
+func utf16ToASCII(b []byte) []byte {
+	out := make([]byte, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			break
+		}
+		out = append(out, b[i])
+	}
+
+	return out
+}
+
+// hasStream checks whether the directory holds a stream or storage named name.
+func (d cfbDir) hasStream(name string) bool {
+	for _, n := range d.names {
+		if string(n) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Msi matches a Microsoft Windows Installer file.
+func Msi(in []byte) bool {
+	return matchOleClsid(in, []byte{
+		0x84, 0x10, 0x0C, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46,
+	})
+}
+
+// Msg matches a Microsoft Outlook message file.
+func Msg(in []byte) bool {
+	dir, ok := cfbDirectory(in)
+	return ok && (dir.hasStream("__properties_version1.0") || dir.hasStream("__nameid_version1.0"))
+}
+
+// Vsd matches a Microsoft Visio 97-2003 drawing file.
+func Vsd(in []byte) bool {
+	dir, ok := cfbDirectory(in)
+	return ok && dir.hasStream("VisioDocument")
+}
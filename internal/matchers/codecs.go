@@ -0,0 +1,454 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// codecsMeta returns the metadata holding the comma separated list of
+// codecs, in the format defined by RFC 6381, or nil if no codec was found.
+func codecsMeta(codecs []string) map[string]string {
+	if len(codecs) == 0 {
+		return nil
+	}
+
+	return map[string]string{"codecs": strings.Join(codecs, ",")}
+}
+
+// oggCodecs maps the first bytes of the identification header of a
+// logical Ogg stream to the name of its codec.
+var oggCodecs = []struct {
+	magic, codec string
+}{
+	{"\x01vorbis", "vorbis"},
+	{"OpusHead", "opus"},
+	{"\x7fFLAC", "flac"},
+	{"Speex   ", "speex"},
+	{"\x80theora", "theora"},
+}
+
+// OggCodecs extracts the codecs of the logical streams of an Ogg file.
+// Every logical stream starts with a page having the beginning of stream flag
+// set and all these pages are placed at the start of the physical stream.
+func OggCodecs(in []byte) map[string]string {
+	var codecs []string
+	for len(in) >= 27 && bytes.HasPrefix(in, []byte("OggS")) {
+		if in[5]&0x02 == 0 {
+			break
+		}
+		nSegs := int(in[26])
+		if len(in) < 27+nSegs {
+			break
+		}
+		size := 27 + nSegs
+		for _, s := range in[27 : 27+nSegs] {
+			size += int(s)
+		}
+		payload := in[27+nSegs:]
+		for _, c := range oggCodecs {
+			if bytes.HasPrefix(payload, []byte(c.magic)) {
+				codecs = appendUniqueString(codecs, c.codec)
+			}
+		}
+		if size > len(in) {
+			break
+		}
+		in = in[size:]
+	}
+
+	return codecsMeta(codecs)
+}
+
+// matroskaCodecs maps Matroska codec IDs to their RFC 6381 names.
+var matroskaCodecs = map[string]string{
+	"V_VP8":            "vp8",
+	"V_VP9":            "vp9",
+	"V_AV1":            "av01",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_FLAC":           "flac",
+	"A_AAC":            "mp4a.40.2",
+	"A_MPEG/L3":        "mp4a.6b",
+	"V_MPEG4/ISO/ASP":  "mp4v.20",
+	"V_MPEG4/ISO/AVC":  "avc1",
+	"V_MPEGH/ISO/HEVC": "hev1",
+}
+
+// MatroskaCodecs extracts the codecs of the tracks of a Matroska or WebM file.
+func MatroskaCodecs(in []byte) map[string]string {
+	var codecs []string
+	// Elements containing the track entries: Segment, Tracks and TrackEntry.
+	containers := map[uint64]bool{0x18538067: true, 0x1654AE6B: true, 0xAE: true}
+	for len(in) > 0 {
+		id, n := ebmlVint(in, false)
+		if n == 0 {
+			break
+		}
+		size, m := ebmlVint(in[n:], true)
+		if m == 0 {
+			break
+		}
+		in = in[n+m:]
+		if containers[id] {
+			continue
+		}
+		if size > uint64(len(in)) {
+			break
+		}
+		if id == 0x86 { // CodecID
+			if c, ok := matroskaCodecs[string(bytes.TrimRight(in[:size], "\x00"))]; ok {
+				codecs = appendUniqueString(codecs, c)
+			}
+		}
+		in = in[size:]
+	}
+
+	return codecsMeta(codecs)
+}
+
+// ebmlVint reads an EBML variable length integer. Element IDs keep their
+// length marker bits, while data sizes do not. Unknown sizes, having all
+// value bits set, are returned as the maximum uint64.
+func ebmlVint(in []byte, isSize bool) (uint64, int) {
+	if len(in) == 0 || in[0] == 0 {
+		return 0, 0
+	}
+	l := 1
+	for mask := byte(0x80); in[0]&mask == 0; mask >>= 1 {
+		l++
+	}
+	if len(in) < l {
+		return 0, 0
+	}
+	v := uint64(in[0])
+	if isSize {
+		v &= uint64(0xFF >> uint(l))
+	}
+	allOnes := v == uint64(0xFF>>uint(l))
+	for _, b := range in[1:l] {
+		v = v<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	if isSize && allOnes {
+		return ^uint64(0), l
+	}
+
+	return v, l
+}
+
+// Mp4Codecs extracts the codecs of the tracks of an ISO base media file,
+// like MP4 or 3GPP. The sample descriptions are located inside the moov box
+// which is often written at the end of the file, after the media data.
+func Mp4Codecs(in []byte) map[string]string {
+	var codecs []string
+	var walk func(b []byte)
+	walk = func(b []byte) {
+		for len(b) >= 8 {
+			size, typ, hdr := isoBox(b)
+			if size == 0 || size > uint64(len(b)) {
+				// The box is not fully part of the input. Containers can
+				// still be inspected because their children come first.
+				size = uint64(len(b))
+			}
+			body := b[hdr:size]
+			switch typ {
+			case "moov", "trak", "mdia", "minf", "stbl":
+				walk(body)
+			case "stsd":
+				// Full box header and entry count precede the sample entries.
+				if len(body) > 8 {
+					if c := sampleEntryCodec(body[8:]); c != "" {
+						codecs = appendUniqueString(codecs, c)
+					}
+				}
+			}
+			b = b[size:]
+		}
+	}
+	walk(in)
+
+	return codecsMeta(codecs)
+}
+
+// isoBox parses the header of an ISO base media file box.
+func isoBox(in []byte) (size uint64, typ string, hdr int) {
+	size, typ, hdr = uint64(binary.BigEndian.Uint32(in)), string(in[4:8]), 8
+	if size == 1 {
+		if len(in) < 16 {
+			return 0, typ, hdr
+		}
+		size, hdr = binary.BigEndian.Uint64(in[8:]), 16
+	}
+	if size != 0 && size < uint64(hdr) {
+		return uint64(hdr), typ, hdr
+	}
+
+	return size, typ, hdr
+}
+
+// sampleEntryCodec returns the RFC 6381 codec name of a sample entry.
+func sampleEntryCodec(in []byte) string {
+	if len(in) < 8 {
+		return ""
+	}
+	size, typ, _ := isoBox(in)
+	if size > uint64(len(in)) {
+		size = uint64(len(in))
+	}
+	entry := in[8:size]
+	switch typ {
+	case "avc1", "avc3":
+		// The visual sample entry has 78 bytes of fields before its boxes.
+		if cfg := findBox(entry, 78, "avcC"); len(cfg) >= 4 {
+			return fmt.Sprintf("%s.%02X%02X%02X", typ, cfg[1], cfg[2], cfg[3])
+		}
+	case "mp4a":
+		// The audio sample entry has 28 bytes of fields before its boxes.
+		// QuickTime sound descriptions version 1 and 2 have extra fields.
+		skip := 28
+		if len(entry) >= 10 {
+			switch binary.BigEndian.Uint16(entry[8:10]) {
+			case 1:
+				skip += 16
+			case 2:
+				skip += 36
+			}
+		}
+		esds := findBox(entry, skip, "esds")
+		if esds == nil {
+			// QuickTime nests the descriptor inside a wave box.
+			esds = findBox(findBox(entry, skip, "wave"), 0, "esds")
+		}
+		if len(esds) > 4 {
+			if c := esdsCodec(esds[4:]); c != "" {
+				return c
+			}
+		}
+	case "Opus":
+		return "opus"
+	case "fLaC":
+		return "flac"
+	}
+
+	return strings.TrimRight(typ, " ")
+}
+
+// findBox returns the body of the first box of type typ found in
+// in, after skipping the first skip bytes.
+func findBox(in []byte, skip int, typ string) []byte {
+	if len(in) < skip {
+		return nil
+	}
+	in = in[skip:]
+	for len(in) >= 8 {
+		size, t, hdr := isoBox(in)
+		if size == 0 || size > uint64(len(in)) {
+			size = uint64(len(in))
+		}
+		if t == typ {
+			return in[hdr:size]
+		}
+		in = in[size:]
+	}
+
+	return nil
+}
+
+// esdsCodec extracts the object type indication and, for MPEG-4 audio,
+// the audio object type from an elementary stream descriptor.
+func esdsCodec(in []byte) string {
+	tag, body := mp4Descriptor(in)
+	if tag != 0x03 || len(body) < 3 {
+		return ""
+	}
+	flags := body[2]
+	body = body[3:]
+	if flags&0x80 != 0 { // streamDependenceFlag
+		body = skipBytes(body, 2)
+	}
+	if flags&0x40 != 0 && len(body) > 0 { // URL_Flag
+		body = skipBytes(body, 1+int(body[0]))
+	}
+	if flags&0x20 != 0 { // OCRstreamFlag
+		body = skipBytes(body, 2)
+	}
+	tag, dcd := mp4Descriptor(body)
+	if tag != 0x04 || len(dcd) < 13 {
+		return ""
+	}
+	oti := dcd[0]
+	c := fmt.Sprintf("mp4a.%02x", oti)
+	if oti == 0x40 {
+		if tag, dsi := mp4Descriptor(dcd[13:]); tag == 0x05 && len(dsi) > 0 {
+			c += fmt.Sprintf(".%d", dsi[0]>>3)
+		}
+	}
+
+	return c
+}
+
+// mp4Descriptor parses an MPEG-4 descriptor header and returns its tag and body.
+func mp4Descriptor(in []byte) (byte, []byte) {
+	if len(in) < 2 {
+		return 0, nil
+	}
+	tag, size, i := in[0], 0, 1
+	for ; i < len(in) && i <= 4; i++ {
+		size = size<<7 | int(in[i]&0x7F)
+		if in[i]&0x80 == 0 {
+			i++
+			break
+		}
+	}
+	body := in[i:]
+	if size < len(body) {
+		body = body[:size]
+	}
+
+	return tag, body
+}
+
+// flvVideoCodecs maps the codec IDs of FLV video tags to codec names. The IDs
+// without an RFC 6381 name use the FourCC of the codec.
+var flvVideoCodecs = map[byte]string{
+	2: "flv1", // Sorenson H.263
+	3: "fsv1", // Screen video
+	4: "vp6f",
+	5: "vp6a",
+	6: "fsv2", // Screen video version 2
+	7: "avc1",
+}
+
+// flvAudioCodecs maps the sound formats of FLV audio tags to codec names.
+var flvAudioCodecs = map[byte]string{
+	0:  "pcm",
+	1:  "adpcm",
+	2:  "mp4a.6b",
+	3:  "pcm",
+	4:  "nellymoser",
+	5:  "nellymoser",
+	6:  "nellymoser",
+	7:  "alaw",
+	8:  "ulaw",
+	10: "mp4a.40.2",
+	11: "speex",
+	14: "mp4a.6b",
+}
+
+// flvFourCCs maps the FourCCs of Enhanced RTMP tags to codec names.
+var flvFourCCs = map[string]string{
+	"avc1": "avc1",
+	"hvc1": "hvc1",
+	"av01": "av01",
+	"vp08": "vp8",
+	"vp09": "vp09",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"ac-3": "ac-3",
+	"ec-3": "ec-3",
+	".mp3": "mp4a.6b",
+	"mp4a": "mp4a.40.2",
+}
+
+// FlvCodecs extracts the codecs of the first video and audio tags of an FLV
+// file. Besides the codec IDs of the original format, the tags of Enhanced
+// RTMP streams, used to carry HEVC, AV1 or Opus, are identified by a FourCC.
+//
+// https://veovera.org/docs/enhanced/enhanced-rtmp-v2
+func FlvCodecs(in []byte) map[string]string {
+	if len(in) < 9 {
+		return nil
+	}
+	var video, audio string
+	// The header length is followed by the size of the previous tag.
+	off := int(binary.BigEndian.Uint32(in[5:])) + 4
+	for off >= 13 && off+11 < len(in) && (video == "" || audio == "") {
+		size := int(in[off+1])<<16 | int(in[off+2])<<8 | int(in[off+3])
+		data := in[off+11:]
+		if size < len(data) {
+			data = data[:size]
+		}
+		switch typ := in[off] & 0x1F; {
+		case typ == 9 && video == "" && len(data) > 0:
+			video = flvVideoCodec(data)
+		case typ == 8 && audio == "" && len(data) > 0:
+			audio = flvAudioCodec(data)
+		}
+		off += 11 + size + 4
+	}
+	var codecs []string
+	for _, c := range []string{video, audio} {
+		if c != "" {
+			codecs = append(codecs, c)
+		}
+	}
+
+	return codecsMeta(codecs)
+}
+
+func flvVideoCodec(data []byte) string {
+	if data[0]&0x80 != 0 {
+		// Enhanced RTMP: the lower bits hold the packet type instead of the
+		// codec ID, and the FourCC follows, unless the packet is a multitrack
+		// one, for which it comes after the multitrack type.
+		fourCC := data[1:]
+		if data[0]&0x0F == 6 {
+			fourCC = skipBytes(data, 2)
+		}
+		if len(fourCC) < 4 {
+			return ""
+		}
+		return flvFourCCs[string(fourCC[:4])]
+	}
+	id := data[0] & 0x0F
+	// The sequence header of AVC streams holds the decoder configuration
+	// record, giving the profile and the level.
+	if id == 7 && len(data) >= 9 && data[1] == 0 && data[5] == 1 {
+		return fmt.Sprintf("avc1.%02X%02X%02X", data[6], data[7], data[8])
+	}
+
+	return flvVideoCodecs[id]
+}
+
+func flvAudioCodec(data []byte) string {
+	format := data[0] >> 4
+	if format == 9 {
+		// Enhanced RTMP: the packet type is followed by the FourCC.
+		if data[0]&0x0F == 5 {
+			data = skipBytes(data, 1)
+		}
+		if len(data) < 5 {
+			return ""
+		}
+		return flvFourCCs[string(data[1:5])]
+	}
+	// The sequence header of AAC streams holds the audio specific config,
+	// starting with the audio object type.
+	if format == 10 && len(data) >= 3 && data[1] == 0 {
+		if aot := data[2] >> 3; aot != 0 && aot != 31 {
+			return fmt.Sprintf("mp4a.40.%d", aot)
+		}
+	}
+
+	return flvAudioCodecs[format]
+}
+
+func skipBytes(in []byte, n int) []byte {
+	if n > len(in) {
+		return nil
+	}
+
+	return in[n:]
+}
+
+func appendUniqueString(s []string, v string) []string {
+	for _, e := range s {
+		if e == v {
+			return s
+		}
+	}
+
+	return append(s, v)
+}
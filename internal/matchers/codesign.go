@@ -0,0 +1,189 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+var (
+	// signedDataOID is the DER encoding of 1.2.840.113549.1.7.2, pkcs7-signedData.
+	signedDataOID = []byte{0x06, 0x09, 0x2A, 0x86, 0x48, 0x86, 0xF7, 0x0D, 0x01, 0x07, 0x02}
+	// spcIndirectDataOID is the DER encoding of 1.3.6.1.4.1.311.2.1.4,
+	// the content type of Authenticode signatures.
+	spcIndirectDataOID = []byte{0x06, 0x0A, 0x2B, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x01, 0x04}
+	// ctlOID is the DER encoding of 1.3.6.1.4.1.311.10.1, the content
+	// type of certificate trust lists, used by security catalogs.
+	ctlOID = []byte{0x06, 0x09, 0x2B, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x0A, 0x01}
+)
+
+// Pkcs7Signature matches a DER encoded PKCS #7 SignedData structure,
+// as found in detached .p7s signatures and in the signature files of
+// signed jar archives.
+func Pkcs7Signature(in []byte) bool {
+	if len(in) < 2 || in[0] != 0x30 {
+		return false
+	}
+	// The length of the outer SEQUENCE is either indefinite, short
+	// or long, in which case it is stored on at most 4 bytes.
+	hdr := 2
+	if l := in[1]; l > 0x80 {
+		if l > 0x84 {
+			return false
+		}
+		hdr += int(l & 0x7F)
+	}
+
+	return len(in) > hdr && bytes.HasPrefix(in[hdr:], signedDataOID)
+}
+
+// Authenticode matches a detached Authenticode signature, a PKCS #7
+// SignedData structure holding the digest of a Windows executable.
+func Authenticode(in []byte) bool {
+	return bytes.Contains(in, spcIndirectDataOID)
+}
+
+// SecurityCatalog matches a Windows security catalog (.cat) file,
+// the signed list of file digests used to verify drivers.
+func SecurityCatalog(in []byte) bool {
+	return bytes.Contains(in, ctlOID)
+}
+
+// AppleCodeSignature matches a detached macOS code signature: the
+// signature super blob which codesign writes to a separate file
+// instead of embedding it in the Mach-O binary.
+func AppleCodeSignature(in []byte) bool {
+	return bytes.HasPrefix(in, []byte{0xFA, 0xDE, 0x0C, 0xC1}) ||
+		bytes.HasPrefix(in, []byte{0xFA, 0xDE, 0x0C, 0xC0})
+}
+
+// JarSignatureFile matches the .SF file of a signed jar archive, listing
+// the digests of the manifest entries. It is stored in the META-INF directory,
+// next to the PKCS #7 signature of its content.
+func JarSignatureFile(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("Signature-Version: "))
+}
+
+// ExeMeta reports whether a PE executable holds an Authenticode signature,
+// based on the certificate table entry of the optional header.
+func ExeMeta(in []byte) map[string]string {
+	_, opt, ok := peHeaders(in)
+	if !ok {
+		return nil
+	}
+	// The data directories follow the Windows specific fields,
+	// which are larger for PE32+ files.
+	var numDirs, dirs int
+	switch binary.LittleEndian.Uint16(opt) {
+	case 0x10B:
+		numDirs, dirs = 92, 96
+	case 0x20B:
+		numDirs, dirs = 108, 112
+	default:
+		return nil
+	}
+	// The certificate table is the fifth data directory.
+	const certTable = 4
+	if len(opt) < dirs+8*(certTable+1) {
+		return nil
+	}
+	if binary.LittleEndian.Uint32(opt[numDirs:]) <= certTable {
+		return map[string]string{"signed": "false"}
+	}
+	size := binary.LittleEndian.Uint32(opt[dirs+8*certTable+4:])
+
+	return map[string]string{"signed": boolString(size != 0)}
+}
+
+// JarMeta reports whether a jar archive is signed, that is whether a
+// signature file is found in its META-INF directory. Only the entries found
+// in the input are inspected, so the key is missing for unsigned archives.
+func JarMeta(in []byte) map[string]string {
+	if !withZipEntries(in, jarSigned) {
+		return nil
+	}
+
+	return map[string]string{"signed": "true"}
+}
+
+// ApkMeta reports whether an Android application package is signed, along
+// with the signature schemes used. Scheme v1 is the jar signing scheme, while
+// schemes v2 and later store their signatures in the APK Signing Block,
+// located before the central directory. Only the part of the archive found
+// in the input is inspected, so the keys are missing for unsigned packages.
+//
+// https://source.android.com/docs/security/features/apksigning/v2
+func ApkMeta(in []byte) map[string]string {
+	var schemes []string
+	if withZipEntries(in, jarSigned) {
+		schemes = append(schemes, "v1")
+	}
+	if i := bytes.LastIndex(in, []byte("APK Sig Block 42")); i != -1 {
+		schemes = append(schemes, apkSigningBlockSchemes(in, i)...)
+	}
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	return map[string]string{
+		"signed":            "true",
+		"signature-schemes": strings.Join(schemes, ","),
+	}
+}
+
+// apkSigningSchemes maps the IDs of the APK Signing Block pairs to the
+// names of the signature schemes.
+var apkSigningSchemes = []struct {
+	id     uint32
+	scheme string
+}{
+	{0x7109871A, "v2"},
+	{0xF05368C0, "v3"},
+	{0x1B93AD61, "v3.1"},
+}
+
+// apkSigningBlockSchemes returns the signature schemes found in the APK Signing
+// Block whose magic starts at offset magic. The block is made of ID-value pairs
+// surrounded by two copies of its size. If the block does not fit in the input,
+// its presence is still reported, with an unknown scheme.
+func apkSigningBlockSchemes(in []byte, magic int) []string {
+	if magic < 8 {
+		return []string{"unknown"}
+	}
+	// The size counts the pairs, the second size field and the magic.
+	size := binary.LittleEndian.Uint64(in[magic-8:])
+	if size < 24 || size > uint64(magic)+16 {
+		return []string{"unknown"}
+	}
+	pairs := in[magic+16-int(size) : magic-8]
+	var schemes []string
+	for len(pairs) >= 12 {
+		l := binary.LittleEndian.Uint64(pairs)
+		if l < 4 || l > uint64(len(pairs)-8) {
+			break
+		}
+		id := binary.LittleEndian.Uint32(pairs[8:])
+		for _, s := range apkSigningSchemes {
+			if s.id == id {
+				schemes = append(schemes, s.scheme)
+			}
+		}
+		pairs = pairs[8+l:]
+	}
+	if len(schemes) == 0 {
+		return []string{"unknown"}
+	}
+
+	return schemes
+}
+
+// jarSigned reports whether the entries include a signature file.
+func jarSigned(entries []zipEntry) bool {
+	for _, e := range entries {
+		if bytes.HasPrefix(e.name, []byte("META-INF/")) && bytes.HasSuffix(e.name, []byte(".SF")) {
+			return true
+		}
+	}
+
+	return false
+}
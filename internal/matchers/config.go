@@ -0,0 +1,150 @@
+package matchers
+
+import (
+	"bytes"
+	"strings"
+)
+
+// AnsibleVault matches a file encrypted by ansible-vault. The header line
+// holds the format version, the cipher and, since version 1.2, the vault ID.
+func AnsibleVault(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("$ANSIBLE_VAULT;"))
+}
+
+// AnsibleVaultMeta extracts the format version, the cipher and the vault ID
+// label from the header line of a vault file.
+func AnsibleVaultMeta(in []byte) map[string]string {
+	fields := bytes.Split(bytes.TrimRight(firstLine(in), "\r"), []byte(";"))
+	if len(fields) < 3 {
+		return nil
+	}
+	meta := map[string]string{
+		"version": string(fields[1]),
+		"cipher":  string(fields[2]),
+	}
+	if len(fields) > 3 {
+		setNonEmpty(meta, "label", string(fields[3]))
+	}
+
+	return meta
+}
+
+// The values encrypted by SOPS start with "ENC[AES256_GCM,data:". In YAML
+// documents they are left unquoted, as mapping values or sequence items.
+var (
+	sopsYamlValue = []byte(": ENC[AES256_GCM,data:")
+	sopsYamlItem  = []byte("- ENC[AES256_GCM,data:")
+	sopsJsonValue = []byte(`"ENC[AES256_GCM,data:`)
+)
+
+// sopsKeyTypes are the keys of the SOPS metadata listing the master keys
+// the data key is encrypted with.
+var sopsKeyTypes = []string{"age", "pgp", "kms", "gcp_kms", "azure_kv", "hc_vault"}
+
+// SopsYaml matches a YAML document encrypted by SOPS, in which the values
+// are replaced by their encrypted form and the keys are kept in clear.
+// The sops metadata key is appended to the root mapping of the document.
+func SopsYaml(in []byte) bool {
+	return bytes.Contains(in, sopsYamlValue) || bytes.Contains(in, sopsYamlItem)
+}
+
+// SopsJson matches a JSON document encrypted by SOPS.
+func SopsJson(in []byte) bool {
+	return bytes.Contains(in, sopsJsonValue)
+}
+
+// SopsMeta extracts the version of SOPS which encrypted the document and the
+// types of the master keys used, from the sops metadata. The metadata comes
+// last in the document, so it is usually only found in the tail of the input.
+func SopsMeta(in []byte) map[string]string {
+	if i := bytes.LastIndex(in, []byte("\nsops:")); i != -1 {
+		return sopsYamlMeta(in[i+len("\nsops:"):])
+	}
+	if i := bytes.LastIndex(in, []byte(`"sops":`)); i != -1 {
+		return sopsJsonMeta(in[i+len(`"sops":`):])
+	}
+
+	return nil
+}
+
+// sopsYamlMeta extracts the metadata from the indented block of the sops key.
+func sopsYamlMeta(block []byte) map[string]string {
+	meta := map[string]string{}
+	var types []string
+	for _, line := range bytes.Split(block, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) > 0 && !isWS(line[0]) {
+			break
+		}
+		kv := bytes.SplitN(bytes.TrimSpace(line), []byte(":"), 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, value := string(kv[0]), string(bytes.TrimSpace(kv[1]))
+		if key == "version" {
+			meta["version"] = strings.Trim(value, `"'`)
+			continue
+		}
+		for _, t := range sopsKeyTypes {
+			if key == t && value != "[]" && value != "null" && !hasString(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+	setNonEmpty(meta, "key-types", strings.Join(types, ","))
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// sopsJsonMeta extracts the metadata from the object of the sops key.
+func sopsJsonMeta(obj []byte) map[string]string {
+	meta := map[string]string{}
+	if v := jsonStringValue(obj, "version"); v != "" {
+		meta["version"] = v
+	}
+	var types []string
+	for _, t := range sopsKeyTypes {
+		i := bytes.Index(obj, []byte(`"`+t+`":`))
+		if i == -1 {
+			continue
+		}
+		v := trimLWS(obj[i+len(t)+3:])
+		if bytes.HasPrefix(v, []byte("[")) && !bytes.HasPrefix(trimLWS(v[1:]), []byte("]")) {
+			types = append(types, t)
+		}
+	}
+	setNonEmpty(meta, "key-types", strings.Join(types, ","))
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+func hasString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+var etckeeperFiles = [][]byte{[]byte(".etckeeper")}
+
+// EtckeeperTar matches a tar archive of a configuration directory managed
+// by etckeeper, which records the file metadata git does not track in
+// the .etckeeper file at the root of the directory.
+func EtckeeperTar(in []byte) bool {
+	return hasTopLevel(tarEntries(in), etckeeperFiles)
+}
+
+// EtckeeperGzip matches a gzip compressed tar archive of a configuration
+// directory managed by etckeeper.
+func EtckeeperGzip(in []byte) bool {
+	return matchGunzipped(in, EtckeeperTar)
+}
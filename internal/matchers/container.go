@@ -0,0 +1,118 @@
+package matchers
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// tarEntries returns the names of the tar entries whose headers are
+// contained in the input. Entry names are cleaned of a leading "./".
+func tarEntries(in []byte) [][]byte {
+	var names [][]byte
+	for off := 0; off+512 <= len(in); {
+		h := in[off : off+512]
+		if !bytes.Equal(h[257:262], []byte("ustar")) {
+			return names
+		}
+		name := trimNUL(h[:100])
+		if prefix := trimNUL(h[345:500]); len(prefix) > 0 {
+			name = append(append(append([]byte{}, prefix...), '/'), name...)
+		}
+		names = append(names, bytes.TrimPrefix(name, []byte("./")))
+
+		size, err := strconv.ParseInt(string(bytes.Trim(h[124:136], " \x00")), 8, 64)
+		if err != nil || size < 0 {
+			return names
+		}
+		off += 512 + int((size+511)/512*512)
+	}
+
+	return names
+}
+
+func trimNUL(b []byte) []byte {
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		return b[:i]
+	}
+
+	return b
+}
+
+// OciLayout matches a tar archive of an OCI image layout, as produced by
+// "docker save" since Docker 25 or by tools like skopeo and buildah.
+func OciLayout(in []byte) bool {
+	for _, n := range tarEntries(in) {
+		if bytes.Equal(n, []byte("oci-layout")) || bytes.HasPrefix(n, []byte("blobs/sha256/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DockerArchive matches a tar archive in the legacy "docker save" format.
+// It holds a manifest.json and a repositories file, and one directory
+// named by the layer ID for each image layer.
+func DockerArchive(in []byte) bool {
+	for _, n := range tarEntries(in) {
+		if bytes.Equal(n, []byte("manifest.json")) || bytes.Equal(n, []byte("repositories")) {
+			return true
+		}
+		// Layer directories contain the VERSION, json and layer.tar files.
+		if len(n) > 65 && n[64] == '/' && isHexString(n[:64]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isHexString(b []byte) bool {
+	for _, c := range b {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// OciManifest matches an OCI image manifest.
+func OciManifest(in []byte) bool {
+	return jsonMediaType(in, "application/vnd.oci.image.manifest.v1+json")
+}
+
+// OciIndex matches an OCI image index.
+func OciIndex(in []byte) bool {
+	return jsonMediaType(in, "application/vnd.oci.image.index.v1+json")
+}
+
+// DockerManifest matches a Docker image manifest, schema version 2.
+func DockerManifest(in []byte) bool {
+	return jsonMediaType(in, "application/vnd.docker.distribution.manifest.v2+json")
+}
+
+// DockerManifestList matches a Docker manifest list.
+func DockerManifestList(in []byte) bool {
+	return jsonMediaType(in, "application/vnd.docker.distribution.manifest.list.v2+json")
+}
+
+// jsonMediaType checks whether the first "mediaType" key of the JSON
+// input has the provided value. Image manifests and indexes declare their own
+// media type before the ones of the descriptors they reference.
+func jsonMediaType(in []byte, mediaType string) bool {
+	if !bytes.Contains(in, []byte(`"schemaVersion"`)) {
+		return false
+	}
+	key := []byte(`"mediaType"`)
+	i := bytes.Index(in, key)
+	if i == -1 {
+		return false
+	}
+	v := trimLWS(in[i+len(key):])
+	if len(v) == 0 || v[0] != ':' {
+		return false
+	}
+
+	return bytes.HasPrefix(trimLWS(v[1:]), []byte(`"`+mediaType+`"`))
+}
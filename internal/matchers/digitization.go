@@ -0,0 +1,157 @@
+package matchers
+
+import "bytes"
+
+var (
+	metsSigs = []sig{
+		newXmlSig("mets", "http://www.loc.gov/METS/"),
+	}
+	altoSigs = []sig{
+		newXmlSig("alto", "http://www.loc.gov/standards/alto/"),
+		newXmlSig("alto", "http://schema.ccs-gmbh.com/ALTO"),
+	}
+	mixSigs = []sig{
+		newXmlSig("mix", "http://www.loc.gov/mix/"),
+	}
+)
+
+// Mets matches a Metadata Encoding and Transmission Standard document,
+// which describes the structure and the files of a digitized object.
+//
+// https://www.loc.gov/standards/mets/
+func Mets(in []byte) bool {
+	return detect(in, metsSigs)
+}
+
+// MetsMeta extracts the profile the document conforms to.
+func MetsMeta(in []byte) map[string]string {
+	root, ok := xmlRootOf(in)
+	if !ok {
+		return nil
+	}
+	if p := root.attr(in, "PROFILE"); len(p) > 0 {
+		return map[string]string{"profile": string(p)}
+	}
+
+	return nil
+}
+
+// Alto matches an Analyzed Layout and Text Object document, holding the
+// layout and the recognized text of a scanned page.
+//
+// https://www.loc.gov/standards/alto/
+func Alto(in []byte) bool {
+	return detect(in, altoSigs)
+}
+
+// AltoMeta extracts the major version of the ALTO schema from the namespace.
+// Documents in the version 1 namespace, predating the Library of Congress
+// maintenance of the standard, are reported as version 1.
+func AltoMeta(in []byte) map[string]string {
+	root, ok := xmlRootOf(in)
+	if !ok {
+		return nil
+	}
+	tag := root.tag(in)
+	if bytes.Contains(tag, []byte(`"http://schema.ccs-gmbh.com/ALTO"`)) {
+		return map[string]string{"version": "1"}
+	}
+	ns := []byte("http://www.loc.gov/standards/alto/ns-v")
+	i := bytes.Index(tag, ns)
+	if i == -1 {
+		return nil
+	}
+	v := tag[i+len(ns):]
+	end := 0
+	for end < len(v) && '0' <= v[end] && v[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return nil
+	}
+
+	return map[string]string{"version": string(v[:end])}
+}
+
+// Mix matches a NISO Metadata for Images in XML document, holding the
+// technical metadata of digital still images.
+//
+// https://www.loc.gov/standards/mix/
+func Mix(in []byte) bool {
+	return detect(in, mixSigs)
+}
+
+// MixMeta extracts the version of the MIX schema from the namespace,
+// like "2.0" for http://www.loc.gov/mix/v20.
+func MixMeta(in []byte) map[string]string {
+	root, ok := xmlRootOf(in)
+	if !ok {
+		return nil
+	}
+	tag := root.tag(in)
+	ns := []byte("http://www.loc.gov/mix/v")
+	i := bytes.Index(tag, ns)
+	if i == -1 || len(tag) < i+len(ns)+2 {
+		return nil
+	}
+	v := tag[i+len(ns) : i+len(ns)+2]
+	if !isDigits(v) {
+		return nil
+	}
+
+	return map[string]string{"version": string(v[:1]) + "." + string(v[1:])}
+}
+
+// JpipStream matches a JPP-stream, the JPEG 2000 Interactive Protocol media
+// type holding the data-bins of an image, as cached by JPIP clients.
+// Streams carry no signature, but start with the message of the main
+// header data-bin, whose body starts with the codestream markers SOC and SIZ.
+//
+// https://www.itu.int/rec/T-REC-T.808
+func JpipStream(in []byte) bool {
+	// The Bin-ID of the first message must declare its class, 6 for the main
+	// header data-bin: the high bits give the class indicator and the low bits
+	// the in-class ID, always zero for the main header. The completeness bit
+	// is ignored.
+	if len(in) < 8 || in[0]&0xEF != 0x40 && in[0]&0xEF != 0x60 || in[1] != 6 {
+		return false
+	}
+	off := 2
+	var n int
+	if in[0]&0xEF == 0x60 {
+		// The codestream number.
+		if _, n = vbas(in[off:]); n == 0 {
+			return false
+		}
+		off += n
+	}
+	msgOffset, n := vbas(in[off:])
+	if n == 0 || msgOffset != 0 {
+		return false
+	}
+	off += n
+	if _, n = vbas(in[off:]); n == 0 {
+		return false
+	}
+	off += n
+
+	return bytes.HasPrefix(in[off:], []byte{0xFF, 0x4F, 0xFF, 0x51})
+}
+
+// vbas decodes a variable-length byte-aligned segment, made of 7 bit groups
+// with the high bit set on all the bytes but the last. It returns the value
+// and the number of bytes read, zero for a truncated or overlong segment.
+func vbas(in []byte) (uint64, int) {
+	var v uint64
+	for i, b := range in {
+		if i == 9 {
+			return 0, 0
+		}
+		v = v<<7 | uint64(b&0x7F)
+		if b&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+
+	return 0, 0
+}
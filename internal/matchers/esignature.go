@@ -0,0 +1,102 @@
+package matchers
+
+import (
+	"bytes"
+	"strings"
+)
+
+var (
+	// xmlDsigNs is the namespace of the XML Signature syntax.
+	xmlDsigNs = []byte("http://www.w3.org/2000/09/xmldsig#")
+	// xadesNs is the prefix of the namespaces of XAdES, v1.3.2 and v1.4.1.
+	// It is also used by the Type attribute of the reference to the signed
+	// properties, which is found early in the SignedInfo element.
+	xadesNs = []byte("http://uri.etsi.org/01903/")
+)
+
+// XmlDsig matches an XML document holding an XML Signature, either as
+// its root element or enveloped in the signed document.
+//
+// https://www.w3.org/TR/xmldsig-core1/
+func XmlDsig(in []byte) bool {
+	if !bytes.Contains(in, xmlDsigNs) {
+		return false
+	}
+	for rest := in; ; {
+		i := bytes.Index(rest, []byte("Signature"))
+		if i == -1 {
+			return false
+		}
+		// The element can be unqualified or use the prefix bound to the namespace.
+		if i > 0 && (rest[i-1] == '<' || rest[i-1] == ':') &&
+			len(rest) > i+len("Signature") && (isWS(rest[i+len("Signature")]) || rest[i+len("Signature")] == '>') {
+			return true
+		}
+		rest = rest[i+len("Signature"):]
+	}
+}
+
+// Xades matches an XML Advanced Electronic Signature: an XML Signature
+// with qualifying properties, like the signing time and certificate.
+//
+// https://www.etsi.org/deliver/etsi_en/319100_319199/31913201/
+func Xades(in []byte) bool {
+	return bytes.Contains(in, xadesNs)
+}
+
+var (
+	asicESigs = zipSigs{{mimetype: "application/vnd.etsi.asic-e+zip"}}
+	asicSSigs = zipSigs{{mimetype: "application/vnd.etsi.asic-s+zip"}}
+)
+
+// AsicE matches an Associated Signature Container Extended, a zip archive
+// holding several files and the signatures covering them.
+//
+// https://www.etsi.org/deliver/etsi_en/319100_319199/31916201/
+func AsicE(in []byte) bool {
+	return asicESigs.detect(in)
+}
+
+// AsicS matches an Associated Signature Container Simple, a zip archive
+// holding a single file and its signature.
+func AsicS(in []byte) bool {
+	return asicSSigs.detect(in)
+}
+
+// AsicMeta extracts the formats of the signatures stored in the META-INF
+// directory of an ASiC container: XAdES signatures are stored in files named
+// like signatures*.xml, while CAdES and timestamp tokens use the .p7s
+// and .tst extensions. Only the entries found in the input are inspected.
+func AsicMeta(in []byte) map[string]string {
+	var formats []string
+	add := func(f string) {
+		for _, have := range formats {
+			if have == f {
+				return
+			}
+		}
+		formats = append(formats, f)
+	}
+	withZipEntries(in, func(entries []zipEntry) bool {
+		for _, e := range entries {
+			if !bytes.HasPrefix(e.name, []byte("META-INF/")) {
+				continue
+			}
+			name := e.name[len("META-INF/"):]
+			switch {
+			case bytes.Contains(name, []byte("signatures")) && bytes.HasSuffix(name, []byte(".xml")):
+				add("xades")
+			case bytes.HasSuffix(name, []byte(".p7s")):
+				add("cades")
+			case bytes.HasSuffix(name, []byte(".tst")):
+				add("timestamp")
+			}
+		}
+		return len(formats) > 0
+	})
+	if len(formats) == 0 {
+		return nil
+	}
+
+	return map[string]string{"signature-formats": strings.Join(formats, ",")}
+}
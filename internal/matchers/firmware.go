@@ -0,0 +1,77 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// ifdSignature is the FLVALSIG signature of the Intel Flash Descriptor.
+var ifdSignature = []byte{0x5A, 0xA5, 0xF0, 0x0F}
+
+// IntelFlashImage matches an SPI flash image of an Intel platform, which
+// starts with the Intel Flash Descriptor. The descriptor signature is at
+// offset 16, after a reserved area, or at offset 0 for ICH8 and older chipsets.
+func IntelFlashImage(in []byte) bool {
+	return ifdOffset(in) != -1
+}
+
+// ifdOffset returns the offset of the signature of the flash descriptor,
+// or -1 if in is not a flash image.
+func ifdOffset(in []byte) int {
+	switch {
+	case len(in) >= 20 && bytes.Equal(in[16:20], ifdSignature):
+		return 16
+	case bytes.HasPrefix(in, ifdSignature):
+		return 0
+	}
+
+	return -1
+}
+
+// ifdRegions are the names of the flash regions, in the order of the
+// FLREG registers of the descriptor.
+var ifdRegions = []string{"descriptor", "bios", "me", "gbe", "pdr", "devexp", "bios2", "microcode", "ec"}
+
+// IntelFlashImageMeta extracts the number of flash components and the regions
+// defined by the flash descriptor, like descriptor, bios, me and gbe.
+func IntelFlashImageMeta(in []byte) map[string]string {
+	off := ifdOffset(in)
+	if off == -1 || len(in) < off+8 {
+		return nil
+	}
+	// FLMAP0 holds the number of components, minus one, and the base
+	// of the region section, in units of 16 bytes from the descriptor start.
+	flmap0 := binary.LittleEndian.Uint32(in[off+4:])
+	meta := map[string]string{"components": strconv.Itoa(int(flmap0>>8&0x3) + 1)}
+
+	// The descriptor starts at offset 0 even when the signature is at 16.
+	frba := int(flmap0>>16&0xFF) * 16
+	var regions []string
+	for i, name := range ifdRegions {
+		reg := frba + 4*i
+		if reg+4 > len(in) {
+			break
+		}
+		// Unused regions have a base greater than their limit, or are left
+		// erased. Only the descriptor region can start at the beginning.
+		flreg := binary.LittleEndian.Uint32(in[reg:])
+		base, limit := flreg&0x7FFF, flreg>>16&0x7FFF
+		if base <= limit && flreg != 0xFFFFFFFF && (flreg != 0 || i == 0) {
+			regions = append(regions, name)
+		}
+	}
+	if len(regions) > 0 {
+		meta["regions"] = strings.Join(regions, ",")
+	}
+
+	return meta
+}
+
+// UefiFirmwareVolume matches a UEFI firmware volume, the storage unit of the
+// firmware found in the BIOS region of flash images, starting with the
+// EFI_FIRMWARE_VOLUME_HEADER structure.
+func UefiFirmwareVolume(in []byte) bool {
+	return len(in) >= 44 && bytes.Equal(in[40:44], []byte("_FVH"))
+}
@@ -0,0 +1,69 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Ps1MemoryCard matches a PlayStation memory card image, either a raw dump
+// or one of the DexDrive copies, which prefix the raw dump with their own header.
+func Ps1MemoryCard(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("MC\x00\x00")) ||
+		bytes.HasPrefix(in, []byte("123-456-STD\x00"))
+}
+
+// Ps1MemoryCardMeta reports the confidence of the match. The "MC" signature
+// of raw dumps is short, so the match is only trusted when the XOR checksum
+// stored in the last byte of the header frame is valid.
+func Ps1MemoryCardMeta(in []byte) map[string]string {
+	frame := in
+	if bytes.HasPrefix(in, []byte("123-456-STD")) {
+		// The DexDrive header is 3904 bytes long.
+		frame = skipBytes(in, 3904)
+	}
+	if len(frame) < 128 || !bytes.HasPrefix(frame, []byte("MC")) {
+		return map[string]string{"confidence": "low"}
+	}
+	var sum byte
+	for _, b := range frame[:127] {
+		sum ^= b
+	}
+	if sum != frame[127] {
+		return map[string]string{"confidence": "low"}
+	}
+
+	return map[string]string{"confidence": "high"}
+}
+
+// Ps2MemoryCard matches a PlayStation 2 memory card image.
+func Ps2MemoryCard(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("Sony PS2 Memory Card Format "))
+}
+
+// GbaGameSharkSave matches a Game Boy Advance save exported by GameShark SP.
+func GbaGameSharkSave(in []byte) bool {
+	return len(in) > 0x14 && bytes.HasPrefix(in[0x0C:], []byte("ADVSAVEG"))
+}
+
+// GbaSharkPortSave matches a Game Boy Advance save in the SharkPort format,
+// which starts with the length prefixed "SharkPortSave" string.
+func GbaSharkPortSave(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("\x0D\x00\x00\x00SharkPortSave"))
+}
+
+// SwitchSave matches a Nintendo Switch save data file. The file starts with
+// an AES-CMAC of the header, followed by padding and the "DISF" magic at 0x100.
+func SwitchSave(in []byte) bool {
+	return len(in) >= 0x108 && bytes.HasPrefix(in[0x100:], []byte("DISF"))
+}
+
+// SwitchSaveMeta reports the confidence of the match. The four bytes magic
+// is weak, so the match is only trusted when the header version is known.
+func SwitchSaveMeta(in []byte) map[string]string {
+	switch binary.LittleEndian.Uint32(in[0x104:]) {
+	case 0x40000, 0x50000:
+		return map[string]string{"confidence": "high"}
+	}
+
+	return map[string]string{"confidence": "low"}
+}
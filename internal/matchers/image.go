@@ -1,6 +1,10 @@
 package matchers
 
-import "bytes"
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+)
 
 // Png matches a Portable Network Graphics file.
 func Png(in []byte) bool {
@@ -82,6 +86,155 @@ func Tiff(in []byte) bool {
 		bytes.HasPrefix(in, []byte{0x4D, 0x4D, 0x00, 0x2A})
 }
 
+// TiffFx matches a TIFF-FX file, the TIFF profiles for Internet fax.
+// The files declare their profile in the IFD pointed to by the
+// GlobalParametersIFD tag of their first IFD. The tag is optional for the
+// minimal profile S, so the fax files not using it stay plain TIFF files.
+//
+// https://www.rfc-editor.org/rfc/rfc3949
+func TiffFx(in []byte) bool {
+	_, ok := tiffFxGlobalIfd(in)
+	return ok
+}
+
+// tiffFaxProfiles are the names of the profiles, indexed by the value of
+// the FaxProfile tag.
+var tiffFaxProfiles = []string{"", "S", "F", "J", "C", "L", "M", "T"}
+
+// TiffFxMeta extracts the profile a TIFF-FX file conforms to and the year
+// of the version of the specification it follows.
+func TiffFxMeta(in []byte) map[string]string {
+	global, ok := tiffFxGlobalIfd(in)
+	if !ok {
+		return nil
+	}
+	order := tiffByteOrder(in)
+	meta := map[string]string{}
+	// FaxProfile and VersionYear have the BYTE type, so their values are
+	// stored in the value field of the entries.
+	if v, ok := tiffTag(in, order, global, 402); ok {
+		switch p := int(v[0]); {
+		case p == 255:
+			meta["profile"] = "multiple"
+		case p < len(tiffFaxProfiles):
+			setNonEmpty(meta, "profile", tiffFaxProfiles[p])
+		}
+	}
+	if v, ok := tiffTag(in, order, global, 404); ok && isDigits(v) {
+		meta["version"] = string(v)
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// tiffFxGlobalIfd returns the offset of the global parameters IFD, the
+// value of the GlobalParametersIFD tag of the first IFD.
+func tiffFxGlobalIfd(in []byte) (uint32, bool) {
+	if len(in) < 8 {
+		return 0, false
+	}
+	order := tiffByteOrder(in)
+	v, ok := tiffTag(in, order, order.Uint32(in[4:]), 400)
+	if !ok {
+		return 0, false
+	}
+
+	return order.Uint32(v), true
+}
+
+func tiffByteOrder(in []byte) binary.ByteOrder {
+	if in[0] == 'I' {
+		return binary.LittleEndian
+	}
+
+	return binary.BigEndian
+}
+
+// tiffTag returns the value field of the entry of tag in the IFD at offset
+// ifd, holding either the value itself, left-justified, or its offset.
+func tiffTag(in []byte, order binary.ByteOrder, ifd uint32, tag uint16) ([]byte, bool) {
+	if ifd < 8 || uint64(ifd)+2 > uint64(len(in)) {
+		return nil, false
+	}
+	entries := in[ifd+2:]
+	for i := 0; i < int(order.Uint16(in[ifd:])) && len(entries) >= 12*(i+1); i++ {
+		e := entries[12*i : 12*(i+1)]
+		if order.Uint16(e) == tag {
+			return e[8:12], true
+		}
+	}
+
+	return nil, false
+}
+
+// Cog matches a Cloud Optimized GeoTIFF, a tiled GeoTIFF whose IFDs are
+// stored before the image data, so that clients can read the tiles they
+// need with HTTP range requests. GDAL writes the layout in a ghost area
+// following the TIFF header. Without it, the first IFD must follow the
+// header, describe a tiled GeoTIFF image and precede its first tile.
+//
+// https://docs.ogc.org/is/21-026/21-026.html
+func Cog(in []byte) bool {
+	if len(in) < 8 {
+		return false
+	}
+	order := tiffByteOrder(in)
+	ifd := order.Uint32(in[4:])
+	if _, tiled := tiffTag(in, order, ifd, 322); !tiled {
+		return false
+	}
+	if ghost := cogGhostArea(in); ghost != nil {
+		return bytes.Contains(ghost, []byte("LAYOUT=IFDS_BEFORE_DATA"))
+	}
+	if _, geo := tiffTag(in, order, ifd, 34735); !geo || ifd != 8 {
+		return false
+	}
+	offsets, ok := tiffTag(in, order, ifd, 324)
+
+	return ok && order.Uint32(offsets) > ifd
+}
+
+// CogMeta extracts the order of the tiles from the ghost area, like
+// "ROW_MAJOR".
+func CogMeta(in []byte) map[string]string {
+	ghost := cogGhostArea(in)
+	key := []byte("BLOCK_ORDER=")
+	i := bytes.Index(ghost, key)
+	if i == -1 {
+		return nil
+	}
+	order := firstLine(ghost[i+len(key):])
+	if len(order) == 0 {
+		return nil
+	}
+
+	return map[string]string{"block-order": string(order)}
+}
+
+// cogGhostArea returns the "KEY=VALUE" lines of the ghost area GDAL writes
+// after the header of COG files, or nil if there is none. The area starts
+// with its size: "GDAL_STRUCTURAL_METADATA_SIZE=000140 bytes\n".
+func cogGhostArea(in []byte) []byte {
+	prefix := []byte("GDAL_STRUCTURAL_METADATA_SIZE=")
+	if len(in) < 8+len(prefix)+13 || !bytes.HasPrefix(in[8:], prefix) {
+		return nil
+	}
+	in = in[8+len(prefix):]
+	if !isDigits(in[:6]) || !bytes.HasPrefix(in[6:], []byte(" bytes\n")) {
+		return nil
+	}
+	size, _ := strconv.Atoi(string(in[:6]))
+	in = in[13:]
+	if size < len(in) {
+		in = in[:size]
+	}
+
+	return in
+}
+
 // Bpg matches a Better Portable Graphics file.
 func Bpg(in []byte) bool {
 	return bytes.HasPrefix(in, []byte{0x42, 0x50, 0x47, 0xFB})
@@ -0,0 +1,167 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/gabriel-vasile/mimetype/internal/json"
+)
+
+var xrmlSigs = []sig{
+	newXmlSig("licenseGroup", "urn:mpeg:mpeg21:2003:01-REL-R-NS"),
+	newXmlSig("license", "urn:mpeg:mpeg21:2003:01-REL-R-NS"),
+}
+
+// Xrml matches an eXtensible rights Markup Language license, like the
+// .xrm-ms files Windows and Office use for their licenses, holding the
+// product keys configuration and the activation certificates.
+func Xrml(in []byte) bool {
+	return detect(in, xrmlSigs)
+}
+
+// flexLmKeywords are the keywords starting the lines of FlexLM license
+// files. INCREMENT and FEATURE lines are the licenses themselves.
+var flexLmKeywords = [][]byte{
+	[]byte("SERVER"), []byte("VENDOR"), []byte("DAEMON"), []byte("USE_SERVER"),
+	[]byte("FEATURE"), []byte("INCREMENT"), []byte("PACKAGE"), []byte("UPGRADE"),
+}
+
+// FlexLm matches a FlexNet Publisher (FlexLM) license file. Its lines,
+// after the comments starting with "#", are made of a keyword followed by
+// fields separated by spaces, and may continue over the next line when they
+// end with a backslash. A license file holds at least one FEATURE or
+// INCREMENT line, with the feature, the vendor daemon, the version, the
+// expiration date and the number of licenses.
+func FlexLm(in []byte) bool {
+	found := false
+	ok := flexLmLines(in, func(keyword []byte, fields [][]byte) bool {
+		if bytes.Equal(keyword, []byte("FEATURE")) || bytes.Equal(keyword, []byte("INCREMENT")) {
+			found = len(fields) >= 5
+			return !found
+		}
+		return true
+	})
+
+	return ok && found
+}
+
+// FlexLmMeta extracts the name of the vendor daemon serving the licenses.
+func FlexLmMeta(in []byte) map[string]string {
+	vendor := ""
+	flexLmLines(in, func(keyword []byte, fields [][]byte) bool {
+		switch {
+		case bytes.Equal(keyword, []byte("VENDOR")), bytes.Equal(keyword, []byte("DAEMON")):
+			vendor = string(fields[0])
+		case bytes.Equal(keyword, []byte("FEATURE")), bytes.Equal(keyword, []byte("INCREMENT")):
+			if len(fields) > 1 {
+				vendor = string(fields[1])
+			}
+		}
+		return vendor == ""
+	})
+	if vendor == "" {
+		return nil
+	}
+
+	return map[string]string{"vendor": vendor}
+}
+
+// flexLmLines calls f with the keyword and the fields of the lines of a
+// FlexLM license file, until f returns false. It returns false if a line
+// does not start with a keyword followed by at least one field, unless the
+// input ends inside that line.
+func flexLmLines(in []byte, f func(keyword []byte, fields [][]byte) bool) bool {
+	for len(in) > 0 {
+		line := firstLine(in)
+		// Join the continued lines.
+		end := len(line)
+		for end < len(in) && bytes.HasSuffix(bytes.TrimRight(in[:end], " \t\r"), []byte("\\")) {
+			end += 1 + len(firstLine(in[end+1:]))
+		}
+		// The input may end inside the last line.
+		partial := end == len(in)
+		line, in = in[:end], in[end:]
+		if len(in) > 0 {
+			in = in[1:]
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		fields := bytes.Fields(bytes.Replace(line, []byte("\\"), []byte(" "), -1))
+		if len(fields) == 0 || !isFlexLmKeyword(fields[0]) || len(fields) < 2 && !bytes.Equal(fields[0], []byte("USE_SERVER")) {
+			return partial
+		}
+		if !f(fields[0], fields[1:]) {
+			return true
+		}
+	}
+
+	return true
+}
+
+func isFlexLmKeyword(b []byte) bool {
+	for _, k := range flexLmKeywords {
+		if bytes.Equal(b, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Jwt matches a JSON Web Token stored in a file, in the compact
+// serialization: three base64url segments separated by dots for signed
+// tokens (JWS), or five for encrypted ones (JWE). The first segment is the
+// JSON header, naming the algorithm used.
+//
+// https://www.rfc-editor.org/rfc/rfc7519
+func Jwt(in []byte) bool {
+	return jwtHeader(in) != nil
+}
+
+// JwtMeta extracts the algorithm and the type of the token, and, for the
+// encrypted tokens, the content encryption algorithm.
+func JwtMeta(in []byte) map[string]string {
+	hdr := jwtHeader(in)
+	if hdr == nil {
+		return nil
+	}
+	meta := map[string]string{}
+	setNonEmpty(meta, "alg", jsonStringValue(hdr, "alg"))
+	setNonEmpty(meta, "typ", jsonStringValue(hdr, "typ"))
+	setNonEmpty(meta, "enc", jsonStringValue(hdr, "enc"))
+
+	return meta
+}
+
+// jwtHeader returns the decoded header of a compact JSON Web Token, or nil
+// if in is not one. The token may be followed by a line ending.
+func jwtHeader(in []byte) []byte {
+	in = bytes.TrimRight(in, "\r\n")
+	dots := 0
+	for _, c := range in {
+		switch {
+		case c == '.':
+			dots++
+		case !isBase64Url(c):
+			return nil
+		}
+	}
+	if dots != 2 && dots != 4 {
+		return nil
+	}
+	hdr, err := base64.RawURLEncoding.DecodeString(string(in[:bytes.IndexByte(in, '.')]))
+	if err != nil || len(hdr) == 0 || hdr[0] != '{' {
+		return nil
+	}
+	if _, err := json.Scan(hdr); err != nil || jsonStringValue(hdr, "alg") == "" {
+		return nil
+	}
+
+	return hdr
+}
+
+func isBase64Url(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
@@ -0,0 +1,119 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// VagrantBox matches a Vagrant box stored as a tar archive. Besides the
+// metadata.json file naming the provider, boxes hold a Vagrantfile or the
+// disk images and machine description of the provider.
+func VagrantBox(in []byte) bool {
+	hasMetadata, hasMachine := false, false
+	for _, n := range tarEntries(in) {
+		switch {
+		case bytes.Equal(n, []byte("metadata.json")):
+			hasMetadata = true
+		case bytes.Equal(n, []byte("Vagrantfile")),
+			bytes.Equal(n, []byte("box.ovf")),
+			bytes.Equal(n, []byte("box.img")),
+			bytes.HasSuffix(n, []byte(".vmdk")):
+			hasMachine = true
+		}
+	}
+
+	return hasMetadata && hasMachine
+}
+
+// VagrantBoxGzip matches a Vagrant box stored as a gzip compressed tar archive.
+// Only the head of the compressed stream is inflated.
+func VagrantBoxGzip(in []byte) bool {
+	return matchGunzipped(in, VagrantBox)
+}
+
+// iso9660 sector size and the offset of the primary volume descriptor.
+const (
+	isoSector = 2048
+	isoPvd    = 16 * isoSector
+)
+
+// Iso9660 matches an ISO 9660 CD-ROM filesystem image. The first volume
+// descriptor starts at offset 0x8000, after the system area, so the input
+// must be larger than the default read limit for the image to be detected.
+func Iso9660(in []byte) bool {
+	return len(in) >= isoPvd+6 && bytes.Equal(in[isoPvd+1:isoPvd+6], []byte("CD001"))
+}
+
+// isoVolumeID returns the volume identifier of the primary volume descriptor.
+func isoVolumeID(in []byte) []byte {
+	if len(in) < isoPvd+72 || in[isoPvd] != 1 {
+		return nil
+	}
+
+	return bytes.TrimRight(in[isoPvd+40:isoPvd+72], " ")
+}
+
+// isoRootHasFile checks whether the root directory of the image holds a file
+// whose ISO 9660 name, without version, is equal to name, ignoring case.
+// Dashes are not valid in strict ISO 9660 names and are often replaced
+// by underscores, so the two are considered equal.
+func isoRootHasFile(in []byte, name string) bool {
+	if len(in) < isoPvd+190 || in[isoPvd] != 1 {
+		return false
+	}
+	// The root directory record is stored at offset 156 of the descriptor.
+	rec := in[isoPvd+156:]
+	extent := int(binary.LittleEndian.Uint32(rec[2:6])) * isoSector
+	size := int(binary.LittleEndian.Uint32(rec[10:14]))
+	if extent <= 0 || extent >= len(in) {
+		return false
+	}
+	dir := in[extent:]
+	if size < len(dir) {
+		dir = dir[:size]
+	}
+	for len(dir) > 33 {
+		l := int(dir[0])
+		if l == 0 {
+			// Records do not cross sector boundaries; skip the padding.
+			next := isoSector - (len(in)-len(dir)-extent)%isoSector
+			if next >= len(dir) {
+				return false
+			}
+			dir = dir[next:]
+			continue
+		}
+		if l < 34 || l > len(dir) {
+			return false
+		}
+		nl := int(dir[32])
+		if 33+nl <= l {
+			n := dir[33 : 33+nl]
+			if i := bytes.IndexByte(n, ';'); i != -1 {
+				n = n[:i]
+			}
+			n = bytes.Replace(bytes.TrimSuffix(n, []byte(".")), []byte("_"), []byte("-"), -1)
+			if bytes.EqualFold(n, []byte(name)) {
+				return true
+			}
+		}
+		dir = dir[l:]
+	}
+
+	return false
+}
+
+// CloudInitSeed matches a cloud-init NoCloud seed image. NoCloud requires
+// the volume to be labeled "cidata", which is checked along with the
+// presence of the user-data file.
+func CloudInitSeed(in []byte) bool {
+	return bytes.EqualFold(isoVolumeID(in), []byte("cidata")) ||
+		isoRootHasFile(in, "user-data") && isoRootHasFile(in, "meta-data")
+}
+
+// OvfEnvironment matches an image holding the OVF environment document of a
+// virtual machine, as attached by vSphere and other OVF deployment tools.
+func OvfEnvironment(in []byte) bool {
+	return bytes.Equal(isoVolumeID(in), []byte("OVF ENV")) ||
+		isoRootHasFile(in, "ovf-env.xml")
+}
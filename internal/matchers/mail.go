@@ -0,0 +1,210 @@
+package matchers
+
+import "bytes"
+
+// mailHeader is a field of the header section of an email message.
+type mailHeader struct {
+	name, value []byte
+}
+
+// mailHeaders parses the header section of an email message, up to the empty
+// line ending it or to the end of the input, and reports whether the empty
+// line was found. It returns no fields if a line is neither a header field
+// nor the continuation of one.
+//
+// https://www.rfc-editor.org/rfc/rfc5322#section-2.2
+func mailHeaders(in []byte) (headers []mailHeader, ended bool) {
+	for len(in) > 0 {
+		line := firstLine(in)
+		next := in[len(line):]
+		if len(next) > 0 {
+			next = next[1:]
+		} else if len(headers) > 0 {
+			// The last line may be truncated by the read limit.
+			return headers, false
+		}
+		in = next
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			return headers, len(headers) > 0
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(headers) == 0 {
+				return nil, false
+			}
+			continue
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon <= 0 {
+			return nil, false
+		}
+		for _, b := range line[:colon] {
+			if b < 33 || b > 126 {
+				return nil, false
+			}
+		}
+		headers = append(headers, mailHeader{line[:colon], bytes.TrimSpace(line[colon+1:])})
+	}
+
+	return headers, false
+}
+
+// Eml matches an email message in the Internet Message Format. The header
+// section must be ended by an empty line, unless the input is cut by the read
+// limit, and hold a From field with an address and one of the Received,
+// Message-ID or MIME-Version fields, which other text made of "key: value"
+// lines, like YAML documents, does not have.
+func Eml(in []byte) bool {
+	headers, ended := mailHeaders(in)
+	if !ended && len(in) < ReadLimit {
+		return false
+	}
+	var from, mailOnly bool
+	for _, h := range headers {
+		switch {
+		case bytes.EqualFold(h.name, []byte("From")):
+			from = from || hasMailAddress(h.value)
+		case bytes.EqualFold(h.name, []byte("Received")),
+			bytes.EqualFold(h.name, []byte("Message-ID")),
+			bytes.EqualFold(h.name, []byte("MIME-Version")):
+			mailOnly = true
+		}
+	}
+
+	return from && mailOnly
+}
+
+// hasMailAddress reports whether the value of an address field, like
+// "Alice <alice@example.org>", holds a mailbox address.
+func hasMailAddress(value []byte) bool {
+	words := bytes.FieldsFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '<' || r == '>' || r == ',' || r == '"'
+	})
+	for _, w := range words {
+		if isMailAddress(w) && w[len(w)-1] != '@' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Maildir matches a message file of a maildir mailbox. Messages are stored
+// by the local delivery agent, which prepends the Return-Path and the
+// Delivered-To fields to the header section.
+func Maildir(in []byte) bool {
+	headers, _ := mailHeaders(in)
+	if len(headers) < 2 || !bytes.EqualFold(headers[0].name, []byte("Return-Path")) {
+		return false
+	}
+	for _, h := range headers[1:] {
+		if bytes.EqualFold(h.name, []byte("Delivered-To")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// QmailQueue matches the envelope files of the qmail queue, the todo and
+// intd files written by qmail-queue. They hold NUL terminated records, whose
+// first byte tells their kind: u and p for the user and process IDs of the
+// sender, F for the envelope sender and T for recipients, turned into D once
+// delivered. Both IDs, the sender and at least one recipient are required.
+//
+// https://cr.yp.to/qmail/qmail-1.03/qmail-queue.8
+func QmailQueue(in []byte) bool {
+	var ids, senders, recipients int
+	for len(in) > 0 {
+		end := bytes.IndexByte(in, 0)
+		if end == -1 {
+			break
+		}
+		rec := in[:end]
+		in = in[end+1:]
+		if len(rec) == 0 {
+			// An empty record ends the envelope of todo files.
+			break
+		}
+		switch rec[0] {
+		case 'u', 'p':
+			if len(rec) == 1 || !isDigits(rec[1:]) || senders+recipients > 0 {
+				return false
+			}
+			ids++
+		case 'F':
+			if recipients > 0 || !isMailAddress(rec[1:]) {
+				return false
+			}
+			senders++
+		case 'T', 'D':
+			if len(rec) == 1 || !isMailAddress(rec[1:]) {
+				return false
+			}
+			recipients++
+		default:
+			return false
+		}
+	}
+
+	return ids == 2 && senders == 1 && recipients > 0
+}
+
+// isDigits reports whether in is made of ASCII digits only.
+func isDigits(in []byte) bool {
+	for _, b := range in {
+		if b < '0' || b > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isMailAddress reports whether in can be an envelope address: printable
+// ASCII, without spaces, with an @ unless empty, as for bounces.
+func isMailAddress(in []byte) bool {
+	for _, b := range in {
+		if b <= ' ' || b > '~' {
+			return false
+		}
+	}
+
+	return len(in) == 0 || bytes.IndexByte(in, '@') > 0
+}
+
+// SmtpSession matches the transcript of an SMTP session, as dumped by mail
+// servers and debugging proxies. It starts with the greeting of the server
+// or with the HELO, EHLO or LHLO command of the client, and the envelope
+// of the message is given with the MAIL FROM command.
+func SmtpSession(in []byte) bool {
+	line := firstLine(in)
+	if !bytes.HasPrefix(line, []byte("220 ")) && !bytes.HasPrefix(line, []byte("220-")) &&
+		!ciSig("EHLO ").detect(line) && !ciSig("HELO ").detect(line) && !ciSig("LHLO ").detect(line) {
+		return false
+	}
+
+	return smtpCommand(in, "MAIL FROM:") != -1
+}
+
+// SmtpSessionMeta reports whether the message is transferred with the BDAT
+// command of the CHUNKING extension, instead of the DATA command.
+//
+// https://www.rfc-editor.org/rfc/rfc3030
+func SmtpSessionMeta(in []byte) map[string]string {
+	return map[string]string{"chunking": boolString(smtpCommand(in, "BDAT ") != -1)}
+}
+
+// smtpCommand returns the offset of the first line starting with the
+// command cmd, compared case insensitively, or -1 if there is none.
+func smtpCommand(in []byte, cmd string) int {
+	for off := 0; off < len(in); {
+		line := firstLine(in[off:])
+		if ciSig(cmd).detect(line) {
+			return off
+		}
+		off += len(line) + 1
+	}
+
+	return -1
+}
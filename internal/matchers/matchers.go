@@ -1,6 +1,8 @@
 // Package matchers holds the matching functions used to find mime types.
 package matchers
 
+import "bytes"
+
 // ReadLimit is the maximum number of bytes read
 // from the input when detecting a reader.
 const ReadLimit = 2048
@@ -29,11 +31,11 @@ func trimRWS(in []byte) []byte {
 }
 
 func firstLine(in []byte) []byte {
-	lineEnd := 0
-	for ; lineEnd < len(in) && in[lineEnd] != '\n'; lineEnd++ {
+	if lineEnd := bytes.IndexByte(in, '\n'); lineEnd != -1 {
+		return in[:lineEnd]
 	}
 
-	return in[:lineEnd]
+	return in
 }
 
 func isWS(b byte) bool {
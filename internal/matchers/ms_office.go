@@ -5,19 +5,25 @@ import (
 	"encoding/binary"
 )
 
+var (
+	xlsxSigs = zipSigs{{entries: []string{"xl/"}}}
+	docxSigs = zipSigs{{entries: []string{"word/"}}}
+	pptxSigs = zipSigs{{entries: []string{"ppt/"}}}
+)
+
 // Xlsx matches a Microsoft Excel 2007 file.
 func Xlsx(in []byte) bool {
-	return bytes.Contains(in, []byte("xl/"))
+	return xlsxSigs.detect(in)
 }
 
 // Docx matches a Microsoft Office 2007 file.
 func Docx(in []byte) bool {
-	return bytes.Contains(in, []byte("word/"))
+	return docxSigs.detect(in)
 }
 
 // Pptx matches a Microsoft PowerPoint 2007 file.
 func Pptx(in []byte) bool {
-	return bytes.Contains(in, []byte("ppt/"))
+	return pptxSigs.detect(in)
 }
 
 // Ole matches an Open Linking and Embedding file.
@@ -28,18 +34,28 @@ func Ole(in []byte) bool {
 }
 
 // Doc matches a Microsoft Office 97-2003 file.
+// Doc files are identified by the WordDocument stream of the Ole directory.
 //
-// BUG(gabriel-vasile): Doc should look for subheaders like Ppt and Xls does.
-//
-// Ole is a container for Doc, Ppt, Pub and Xls.
-// Right now, when an Ole file is detected, it is considered to be a Doc file
-// if the checks for Ppt, Pub and Xls failed.
+// BUG(gabriel-vasile): When the Ole directory is not entirely part of the input,
+// an Ole file is considered to be a Doc file if the checks for Ppt, Pub and Xls failed.
 func Doc(in []byte) bool {
+	if dir, ok := cfbDirectory(in); ok {
+		return dir.hasStream("WordDocument") || !dir.complete
+	}
+
 	return true
 }
 
 // Ppt  matches a Microsoft PowerPoint 97-2003 file.
 func Ppt(in []byte) bool {
+	if dir, ok := cfbDirectory(in); ok {
+		if dir.hasStream("PowerPoint Document") {
+			return true
+		}
+		if dir.complete {
+			return false
+		}
+	}
 	if len(in) < 520 {
 		return false
 	}
@@ -65,6 +81,14 @@ func Ppt(in []byte) bool {
 
 // Xls  matches a Microsoft Excel 97-2003 file.
 func Xls(in []byte) bool {
+	if dir, ok := cfbDirectory(in); ok {
+		if dir.hasStream("Workbook") || dir.hasStream("Book") {
+			return true
+		}
+		if dir.complete {
+			return false
+		}
+	}
 	if len(in) <= 512 {
 		return false
 	}
@@ -100,19 +124,106 @@ func Pub(in []byte) bool {
 //
 // http://fileformats.archiveteam.org/wiki/Microsoft_Compound_File
 func matchOleClsid(in []byte, clsid []byte) bool {
-	if len(in) <= 512 {
-		return false
+	dir, ok := cfbDirectory(in)
+	return ok && bytes.Equal(dir.rootCLSID, clsid)
+}
+
+// OoxmlEncrypted matches an Office Open XML document protected by a password.
+// Encrypted documents are stored in an OLE container holding the
+// EncryptionInfo and EncryptedPackage streams.
+func OoxmlEncrypted(in []byte) bool {
+	return bytes.Contains(in, utf16le("EncryptionInfo")) &&
+		bytes.Contains(in, utf16le("EncryptedPackage"))
+}
+
+// OoxmlEncryptedMeta extracts the encryption scheme of an encrypted OOXML
+// document, along with the cipher and hash algorithms, from the EncryptionInfo stream.
+//
+// https://docs.microsoft.com/en-us/openspecs/office_file_formats/ms-offcrypto
+func OoxmlEncryptedMeta(in []byte) map[string]string {
+	// Agile encryption: version 4.4, reserved 0x40, followed by an XML descriptor.
+	if i := bytes.Index(in, []byte{0x04, 0x00, 0x04, 0x00, 0x40, 0x00, 0x00, 0x00}); i != -1 {
+		meta := map[string]string{"encryption": "agile"}
+		xml := in[i+8:]
+		if j := bytes.Index(xml, []byte("<keyData ")); j != -1 {
+			keyData := xml[j:]
+			if k := bytes.IndexByte(keyData, '>'); k != -1 {
+				keyData = keyData[:k]
+			}
+			cipher := xmlAttr(keyData, "cipherAlgorithm")
+			if bits := xmlAttr(keyData, "keyBits"); cipher != "" && bits != "" {
+				cipher += "-" + bits
+			}
+			setNonEmpty(meta, "cipher", cipher)
+			setNonEmpty(meta, "hash", xmlAttr(keyData, "hashAlgorithm"))
+			setNonEmpty(meta, "chaining", xmlAttr(keyData, "cipherChaining"))
+		}
+		return meta
 	}
 
-	// SecID of first sector of the directory stream
-	firstSecID := int(binary.LittleEndian.Uint32(in[48:52]))
+	// Standard and extensible encryption: a binary EncryptionHeader follows the version.
+	for off := 0; off+40 <= len(in); off++ {
+		major, minor := in[off], in[off+2]
+		if in[off+1] != 0 || in[off+3] != 0 || major < 2 || major > 4 || minor < 2 || minor > 3 {
+			continue
+		}
+		flags := binary.LittleEndian.Uint32(in[off+4:])
+		headerFlags := binary.LittleEndian.Uint32(in[off+12:])
+		// fCryptoAPI must be set, and the header repeats the flags.
+		if flags&0x04 == 0 || flags != headerFlags {
+			continue
+		}
+		cipher, ok := cryptoAlgIDs[binary.LittleEndian.Uint32(in[off+20:])]
+		if !ok {
+			continue
+		}
+		meta := map[string]string{"encryption": "standard", "cipher": cipher}
+		if minor == 3 {
+			meta["encryption"] = "extensible"
+		}
+		if binary.LittleEndian.Uint32(in[off+24:]) == 0x8004 {
+			meta["hash"] = "SHA1"
+		}
+		return meta
+	}
 
-	// Expected offset of CLSID for root storage object
-	clsidOffset := 512*(1+firstSecID) + 80
+	return nil
+}
 
-	if len(in) <= clsidOffset+16 {
-		return false
+var cryptoAlgIDs = map[uint32]string{
+	0x6801: "RC4",
+	0x660E: "AES-128",
+	0x660F: "AES-192",
+	0x6610: "AES-256",
+}
+
+// xmlAttr returns the value of the attribute name found in the tag.
+func xmlAttr(tag []byte, name string) string {
+	key := []byte(" " + name + `="`)
+	i := bytes.Index(tag, key)
+	if i == -1 {
+		return ""
+	}
+	val := tag[i+len(key):]
+	if j := bytes.IndexByte(val, '"'); j != -1 {
+		return string(val[:j])
+	}
+
+	return ""
+}
+
+func setNonEmpty(m map[string]string, key, val string) {
+	if val != "" {
+		m[key] = val
+	}
+}
+
+// utf16le returns the UTF-16 little endian encoding of an ASCII string.
+func utf16le(s string) []byte {
+	out := make([]byte, 0, 2*len(s))
+	for i := 0; i < len(s); i++ {
+		out = append(out, s[i], 0)
 	}
 
-	return bytes.HasPrefix(in[clsidOffset:], clsid)
+	return out
 }
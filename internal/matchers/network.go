@@ -0,0 +1,273 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Pcap matches a libpcap capture file, as written by tcpdump. The magic
+// number is written in the byte order of the capturing host, and tells
+// whether the timestamps have a microsecond or a nanosecond resolution.
+func Pcap(in []byte) bool {
+	_, ok := pcapHeader(in)
+	return ok
+}
+
+// pcapHeader returns the byte order of a pcap file.
+func pcapHeader(in []byte) (binary.ByteOrder, bool) {
+	if len(in) < 24 {
+		return nil, false
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(in) {
+		case 0xA1B2C3D4, 0xA1B23C4D:
+			return order, order.Uint16(in[4:]) == 2
+		}
+	}
+
+	return nil, false
+}
+
+// Pcapng matches a pcapng capture file, the default format of Wireshark.
+// It starts with a section header block, holding a byte-order magic.
+func Pcapng(in []byte) bool {
+	_, ok := pcapngHeader(in)
+	return ok
+}
+
+// pcapngHeader returns the byte order of the first section of a pcapng file.
+func pcapngHeader(in []byte) (binary.ByteOrder, bool) {
+	if len(in) < 28 || !bytes.HasPrefix(in, []byte("\x0A\x0D\x0D\x0A")) {
+		return nil, false
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		if order.Uint32(in[8:]) == 0x1A2B3C4D {
+			return order, order.Uint16(in[12:]) == 1
+		}
+	}
+
+	return nil, false
+}
+
+// capturedPackets calls f with the link-layer type and the data of each
+// packet of a pcap or pcapng capture fully part of the input, until f
+// returns false.
+func capturedPackets(in []byte, f func(linkType uint32, data []byte) bool) {
+	if order, ok := pcapHeader(in); ok {
+		linkType := order.Uint32(in[20:]) & 0xFFFF
+		for off := 24; off+16 <= len(in); {
+			l := int(order.Uint32(in[off+8:]))
+			if l < 0 || off+16+l > len(in) || !f(linkType, in[off+16:off+16+l]) {
+				return
+			}
+			off += 16 + l
+		}
+		return
+	}
+	order, ok := pcapngHeader(in)
+	if !ok {
+		return
+	}
+	var linkTypes []uint32
+	for off := 0; off+12 <= len(in); {
+		typ, l := order.Uint32(in[off:]), int(order.Uint32(in[off+4:]))
+		if l < 12 || l%4 != 0 || off+l > len(in) {
+			return
+		}
+		body := in[off+8 : off+l-4]
+		switch typ {
+		case 0x0A0D0D0A: // section header block
+			linkTypes = linkTypes[:0]
+		case 1: // interface description block
+			if len(body) >= 2 {
+				linkTypes = append(linkTypes, uint32(order.Uint16(body)))
+			}
+		case 6: // enhanced packet block
+			if len(body) < 20 {
+				return
+			}
+			iface, captured := int(order.Uint32(body)), int(order.Uint32(body[12:]))
+			if iface >= len(linkTypes) || captured < 0 || 20+captured > len(body) ||
+				!f(linkTypes[iface], body[20:20+captured]) {
+				return
+			}
+		case 3: // simple packet block, captured on the first interface
+			if len(body) < 4 || len(linkTypes) == 0 || !f(linkTypes[0], body[4:]) {
+				return
+			}
+		}
+		off += l
+	}
+}
+
+// udpPayload returns the payload of a UDP datagram carried by a captured
+// packet, or nil if the packet is not a UDP datagram over IP.
+func udpPayload(linkType uint32, data []byte) []byte {
+	var ipVersion byte
+	switch linkType {
+	case 0: // BSD loopback, with the address family in the host byte order
+		if len(data) < 4 {
+			return nil
+		}
+		data = data[4:]
+	case 1: // Ethernet
+		if len(data) < 14 {
+			return nil
+		}
+		etherType := binary.BigEndian.Uint16(data[12:])
+		data = data[14:]
+		if etherType == 0x8100 && len(data) >= 4 { // 802.1Q VLAN tag
+			etherType = binary.BigEndian.Uint16(data[2:])
+			data = data[4:]
+		}
+		switch etherType {
+		case 0x0800:
+			ipVersion = 4
+		case 0x86DD:
+			ipVersion = 6
+		default:
+			return nil
+		}
+	case 12, 14, 101, 228, 229: // raw IP
+	case 113: // Linux cooked capture
+		if len(data) < 16 {
+			return nil
+		}
+		data = data[16:]
+	case 276: // Linux cooked capture v2
+		if len(data) < 20 {
+			return nil
+		}
+		data = data[20:]
+	default:
+		return nil
+	}
+	if len(data) == 0 || ipVersion != 0 && data[0]>>4 != ipVersion {
+		return nil
+	}
+	switch data[0] >> 4 {
+	case 4:
+		hdr := int(data[0]&0x0F) * 4
+		// Only the first fragment holds the UDP header.
+		if hdr < 20 || len(data) < hdr+8 || data[9] != 17 ||
+			binary.BigEndian.Uint16(data[6:])&0x1FFF != 0 {
+			return nil
+		}
+		return data[hdr+8:]
+	case 6:
+		if len(data) < 48 || data[6] != 17 {
+			return nil
+		}
+		return data[48:]
+	}
+
+	return nil
+}
+
+// SrtCapture matches a packet capture of a Secure Reliable Transport
+// session, the UDP protocol used to carry live streams to ingest servers.
+// The capture must include a handshake packet: captures started after the
+// connection are made of data packets which cannot be told apart from
+// other UDP traffic.
+//
+// https://datatracker.ietf.org/doc/html/draft-sharabayko-srt
+func SrtCapture(in []byte) bool {
+	found := false
+	capturedPackets(in, func(linkType uint32, data []byte) bool {
+		found = srtHandshake(udpPayload(linkType, data)) != nil
+		return !found
+	})
+
+	return found
+}
+
+// srtHandshake returns the content of an SRT handshake control packet, or
+// nil if p is not one.
+func srtHandshake(p []byte) []byte {
+	// The header is followed by the version, the encryption and extension
+	// fields, the initial sequence number, the MTU, the flow window and
+	// the handshake type.
+	if len(p) < 16+48 || p[0] != 0x80 || p[1] != 0 {
+		return nil
+	}
+	hs := p[16:]
+	version := binary.BigEndian.Uint32(hs)
+	mtu := binary.BigEndian.Uint32(hs[12:])
+	if version != 4 && version != 5 || mtu < 76 || mtu > 65536 {
+		return nil
+	}
+	switch binary.BigEndian.Uint32(hs[20:]) {
+	case 1, 0, 0xFFFFFFFF, 0xFFFFFFFE: // induction, wave-a-hand, conclusion, agreement
+		return hs
+	}
+
+	return nil
+}
+
+// srtCiphers are the names of the ciphers, indexed by the encryption field
+// of version 5 handshakes.
+var srtCiphers = []string{"", "", "aes-128", "aes-192", "aes-256"}
+
+// SrtCaptureMeta reports the cipher protecting the stream when the sender
+// was given a passphrase, as advertised by the conclusion handshake. When
+// only encrypted data packets are found, the cipher is reported as "aes".
+func SrtCaptureMeta(in []byte) map[string]string {
+	cipher := ""
+	capturedPackets(in, func(linkType uint32, data []byte) bool {
+		p := udpPayload(linkType, data)
+		if hs := srtHandshake(p); hs != nil {
+			if binary.BigEndian.Uint32(hs) == 5 && binary.BigEndian.Uint32(hs[20:]) == 0xFFFFFFFF {
+				if enc := int(binary.BigEndian.Uint16(hs[4:])); enc < len(srtCiphers) && srtCiphers[enc] != "" {
+					cipher = srtCiphers[enc]
+				}
+			}
+			return cipher == ""
+		}
+		// The key-based encryption flags of data packets are set when the
+		// payload is encrypted with the even or the odd key.
+		if len(p) >= 16 && p[0]&0x80 == 0 && p[4]>>3&0x03 != 0 {
+			cipher = "aes"
+		}
+		return cipher == ""
+	})
+	if cipher == "" {
+		return nil
+	}
+
+	return map[string]string{"encryption": cipher}
+}
+
+// rtmpHandshakeLen is the length of the handshake a client sends before
+// its first RTMP message: the version byte C0, followed by the 1536 bytes of
+// C1 and of C2.
+const rtmpHandshakeLen = 1 + 2*1536
+
+// RtmpDump matches the data a client sends to an RTMP server, as saved from
+// a capture of the TCP connection. The handshake is made of random bytes,
+// so the first chunk following it is checked: it has a full header and
+// carries a command or protocol control message on the stream 0, like
+// "connect" or "Set Chunk Size".
+//
+// https://rtmp.veriskope.com/docs/spec/
+func RtmpDump(in []byte) bool {
+	if len(in) < rtmpHandshakeLen+12 || in[0] != 3 {
+		return false
+	}
+	chunk := in[rtmpHandshakeLen:]
+	// Chunk basic header of type 0, for a chunk stream ID of 2 to 63.
+	if chunk[0]>>6 != 0 || chunk[0]&0x3F < 2 {
+		return false
+	}
+	length := uint32(chunk[4])<<16 | uint32(chunk[5])<<8 | uint32(chunk[6])
+	if length == 0 || binary.LittleEndian.Uint32(chunk[8:]) != 0 {
+		return false
+	}
+	switch chunk[7] {
+	case 1, 2, 3, 4, 5, 6: // protocol control messages
+		return true
+	case 20: // AMF0 command
+		return bytes.HasPrefix(chunk[12:], []byte("\x02\x00\x07connect"))
+	}
+
+	return false
+}
@@ -1,48 +1,58 @@
 package matchers
 
-import "bytes"
+var (
+	odtSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.text"}}
+	ottSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.text-template"}}
+	odsSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.spreadsheet"}}
+	otsSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.spreadsheet-template"}}
+	odpSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.presentation"}}
+	otpSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.presentation-template"}}
+	odgSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.graphics"}}
+	otgSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.graphics-template"}}
+	odfSigs = zipSigs{{mimetype: "application/vnd.oasis.opendocument.formula"}}
+)
 
 // Odt matches an OpenDocument Text file.
 func Odt(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.text"))
+	return odtSigs.detect(in)
 }
 
 // Ott matches an OpenDocument Text Template file.
 func Ott(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.text-template"))
+	return ottSigs.detect(in)
 }
 
 // Ods matches an OpenDocument Spreadsheet file.
 func Ods(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.spreadsheet"))
+	return odsSigs.detect(in)
 }
 
 // Ots matches an OpenDocument Spreadsheet Template file.
 func Ots(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.spreadsheet-template"))
+	return otsSigs.detect(in)
 }
 
 // Odp matches an OpenDocument Presentation file.
 func Odp(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.presentation"))
+	return odpSigs.detect(in)
 }
 
 // Otp matches an OpenDocument Presentation Template file.
 func Otp(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.presentation-template"))
+	return otpSigs.detect(in)
 }
 
 // Odg matches an OpenDocument Drawing file.
 func Odg(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.graphics"))
+	return odgSigs.detect(in)
 }
 
 // Otg matches an OpenDocument Drawing Template file.
 func Otg(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.graphics-template"))
+	return otgSigs.detect(in)
 }
 
 // Odf matches an OpenDocument Formula file.
 func Odf(in []byte) bool {
-	return bytes.Contains(in, []byte("mimetypeapplication/vnd.oasis.opendocument.formula"))
+	return odfSigs.detect(in)
 }
@@ -0,0 +1,149 @@
+package matchers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+var (
+	kustomizationFiles = [][]byte{
+		[]byte("kustomization.yaml"),
+		[]byte("kustomization.yml"),
+		[]byte("Kustomization"),
+	}
+	composeFiles = [][]byte{
+		[]byte("compose.yaml"),
+		[]byte("compose.yml"),
+		[]byte("docker-compose.yaml"),
+		[]byte("docker-compose.yml"),
+	}
+)
+
+// gunzipper holds the state needed to inflate the head of a gzip stream.
+// Each gzip input is inflated by several matchers, so gunzippers are pooled
+// to spare the allocation of the decompressor window on every call.
+type gunzipper struct {
+	r   *gzip.Reader
+	src bytes.Reader
+	out [ReadLimit]byte
+}
+
+var gunzippers = sync.Pool{
+	New: func() interface{} { return &gunzipper{} },
+}
+
+// matchGunzipped reports whether f matches the head of the gzip stream in,
+// inflated up to ReadLimit bytes. f must not retain its argument.
+func matchGunzipped(in []byte, f func([]byte) bool) bool {
+	g := gunzippers.Get().(*gunzipper)
+	defer gunzippers.Put(g)
+
+	g.src.Reset(in)
+	var err error
+	if g.r == nil {
+		g.r, err = gzip.NewReader(&g.src)
+	} else {
+		err = g.r.Reset(&g.src)
+	}
+	if err != nil {
+		return false
+	}
+	n, _ := io.ReadFull(g.r, g.out[:])
+
+	return f(g.out[:n])
+}
+
+// topLevelName returns the base name of an archive entry stored at the root
+// of the archive or in one of its top level directories, and nil for the
+// entries stored deeper. Projects are often archived with their directory.
+func topLevelName(n []byte) []byte {
+	if i := bytes.IndexByte(n, '/'); i != -1 {
+		n = n[i+1:]
+	}
+	if bytes.IndexByte(n, '/') != -1 {
+		return nil
+	}
+
+	return n
+}
+
+func hasTopLevel(names [][]byte, files [][]byte) bool {
+	for _, n := range names {
+		base := topLevelName(n)
+		for _, f := range files {
+			if bytes.Equal(base, f) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HelmChart matches a packaged Helm chart: a gzip compressed tar archive
+// holding a single directory, named after the chart, with the Chart.yaml
+// file describing the chart. "helm package" stores Chart.yaml first.
+func HelmChart(in []byte) bool {
+	return matchGunzipped(in, helmChart)
+}
+
+func helmChart(in []byte) bool {
+	for _, n := range tarEntries(in) {
+		if i := bytes.IndexByte(n, '/'); i > 0 && bytes.Equal(n[i+1:], []byte("Chart.yaml")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KustomizeTar matches a tar archive of a Kustomize directory, holding
+// a kustomization file.
+func KustomizeTar(in []byte) bool {
+	return hasTopLevel(tarEntries(in), kustomizationFiles)
+}
+
+// KustomizeGzip matches a gzip compressed tar archive of a Kustomize directory.
+func KustomizeGzip(in []byte) bool {
+	return matchGunzipped(in, KustomizeTar)
+}
+
+// KustomizeZip matches a zip archive of a Kustomize directory.
+func KustomizeZip(in []byte) bool {
+	return withZipEntries(in, func(entries []zipEntry) bool {
+		return hasTopLevelZipEntry(entries, kustomizationFiles)
+	})
+}
+
+// ComposeTar matches a tar archive of a Compose project, holding
+// a Compose file.
+func ComposeTar(in []byte) bool {
+	return hasTopLevel(tarEntries(in), composeFiles)
+}
+
+// ComposeGzip matches a gzip compressed tar archive of a Compose project.
+func ComposeGzip(in []byte) bool {
+	return matchGunzipped(in, ComposeTar)
+}
+
+// ComposeZip matches a zip archive of a Compose project.
+func ComposeZip(in []byte) bool {
+	return withZipEntries(in, func(entries []zipEntry) bool {
+		return hasTopLevelZipEntry(entries, composeFiles)
+	})
+}
+
+func hasTopLevelZipEntry(entries []zipEntry, files [][]byte) bool {
+	for _, e := range entries {
+		base := topLevelName(e.name)
+		for _, f := range files {
+			if bytes.Equal(base, f) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
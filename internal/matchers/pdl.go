@@ -0,0 +1,244 @@
+package matchers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+)
+
+// PsMeta extracts the PostScript language level a document requires, as
+// declared by the %%LanguageLevel comment of its DSC header. Documents
+// not declaring it only use level 1 features, but since the header may
+// be longer than the input, the key is missing in that case.
+func PsMeta(in []byte) map[string]string {
+	for off := 0; off < len(in); {
+		line := bytes.TrimRight(firstLine(in[off:]), "\r")
+		off += len(line) + 1
+		if bytes.HasPrefix(line, []byte("%%EndComments")) || len(line) > 0 && line[0] != '%' {
+			break
+		}
+		if v := bytes.TrimPrefix(line, []byte("%%LanguageLevel:")); len(v) != len(line) {
+			if v = bytes.TrimSpace(v); len(v) > 0 && isDigits(v) {
+				return map[string]string{"level": string(v)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// PdfMeta reports the features of a PDF document that need a closer look
+// before the document is accepted:
+//   - "xfa": it holds XFA form data, which needs an XFA processor to be rendered.
+//   - "postscript": it embeds PostScript code in PostScript XObjects.
+//   - "javascript": it runs JavaScript actions, like an /OpenAction /JS.
+//   - "attachments": it embeds files, listed in /EmbeddedFiles or attached
+//     to pages as file attachment annotations.
+//   - "portfolio": it is a PDF portfolio, whose embedded files are
+//     presented as a collection.
+//   - "encrypted": it is encrypted, and may need a password to be opened.
+//
+// They are found by looking for their keys in the dictionaries of the
+// objects found in the input: linearized documents have them near the
+// start, but for the others the keys are missing unless the whole file is
+// examined. The trailer holding /Encrypt is at the end of the file.
+func PdfMeta(in []byte) map[string]string {
+	meta := map[string]string{}
+	pdfObjects(in, func(objs []byte) {
+		if bytes.Contains(objs, []byte("/XFA")) {
+			meta["xfa"] = "true"
+		}
+		if pdfHasName(objs, "/Subtype", "/PS") {
+			meta["postscript"] = "true"
+		}
+		if pdfHasKey(objs, "/JS") || pdfHasKey(objs, "/JavaScript") {
+			meta["javascript"] = "true"
+		}
+		if pdfHasKey(objs, "/EmbeddedFiles") || pdfHasKey(objs, "/EmbeddedFile") ||
+			pdfHasKey(objs, "/FileAttachment") {
+			meta["attachments"] = "true"
+		}
+		if pdfHasKey(objs, "/Collection") {
+			meta["portfolio"] = "true"
+		}
+		if pdfHasKey(objs, "/Encrypt") {
+			meta["encrypted"] = "true"
+		}
+	})
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// pdfInflateLimit bounds the number of bytes inflated from the object
+// streams of a document, so compressed inputs cannot make PdfMeta expand
+// more than this.
+const pdfInflateLimit = 1 << 20
+
+// pdfObjects calls f with the parts of in holding objects, skipping the
+// data of streams, where the keys of dictionaries can appear by chance.
+// Object streams compressed with FlateDecode, used since PDF 1.5 to hold
+// most of the objects of a document, are inflated and passed to f too,
+// up to pdfInflateLimit bytes in total.
+func pdfObjects(in []byte, f func([]byte)) {
+	inflated := 0
+	for len(in) > 0 {
+		i := bytes.Index(in, []byte("stream"))
+		if i == -1 {
+			f(in)
+			return
+		}
+		if i >= 3 && bytes.Equal(in[i-3:i], []byte("end")) {
+			// The input starts in the middle of a stream, like the tail of
+			// a file does.
+			in = in[i+len("stream"):]
+			continue
+		}
+		objs := in[:i]
+		// The stream keyword follows the dictionary of the stream.
+		if !bytes.HasSuffix(trimRWS(objs), []byte(">>")) {
+			f(in[:i+len("stream")])
+			in = in[i+len("stream"):]
+			continue
+		}
+		f(objs)
+		data := bytes.TrimPrefix(in[i+len("stream"):], []byte("\r"))
+		data = bytes.TrimPrefix(data, []byte("\n"))
+		end := bytes.Index(data, []byte("endstream"))
+		if end == -1 {
+			return
+		}
+		dict := objs
+		if k := bytes.LastIndex(objs, []byte("obj")); k != -1 {
+			dict = objs[k:]
+		}
+		// The filter may be given alone or in an array.
+		if inflated < pdfInflateLimit && pdfHasName(dict, "/Type", "/ObjStm") && pdfHasKey(dict, "/FlateDecode") {
+			if r, err := zlib.NewReader(bytes.NewReader(data[:end])); err == nil {
+				content, _ := ioutil.ReadAll(io.LimitReader(r, int64(pdfInflateLimit-inflated)))
+				inflated += len(content)
+				f(content)
+			}
+		}
+		in = data[end+len("endstream"):]
+	}
+}
+
+// pdfHasKey reports whether in holds the name key, used as a key or as
+// a value of a dictionary.
+func pdfHasKey(in []byte, key string) bool {
+	for rest := in; ; {
+		i := bytes.Index(rest, []byte(key))
+		if i == -1 {
+			return false
+		}
+		rest = rest[i+len(key):]
+		if !pdfNameContinues(rest) {
+			return true
+		}
+	}
+}
+
+// pdfHasName reports whether in holds the key of a PDF dictionary followed
+// by the name value, with or without whitespace in between.
+func pdfHasName(in []byte, key, value string) bool {
+	for rest := in; ; {
+		i := bytes.Index(rest, []byte(key))
+		if i == -1 {
+			return false
+		}
+		rest = trimLWS(rest[i+len(key):])
+		if bytes.HasPrefix(rest, []byte(value)) && !pdfNameContinues(rest[len(value):]) {
+			return true
+		}
+	}
+}
+
+// pdfNameContinues reports whether the name found just before in continues,
+// as /PS does in /PSfoo.
+func pdfNameContinues(in []byte) bool {
+	return len(in) > 0 && ('a' <= in[0] && in[0] <= 'z' || 'A' <= in[0] && in[0] <= 'Z' || '0' <= in[0] && in[0] <= '9')
+}
+
+// Afp matches an IBM Advanced Function Presentation print file, a MO:DCA
+// data stream. The stream is a sequence of structured fields, each one
+// introduced by the 0x5A carriage control character, its length and its
+// identifier, whose class code is 0xD3. Streams start with a Begin
+// structured field, like Begin Print File or Begin Document, or with a
+// No Operation one holding a comment. The structured fields found in the
+// input must follow each other.
+func Afp(in []byte) bool {
+	if len(in) < 9 || in[4] != 0xA8 && (in[4] != 0xEE || in[5] != 0xEE) {
+		return false
+	}
+	for off := 0; off+9 <= len(in); {
+		if in[off] != 0x5A || in[off+3] != 0xD3 {
+			return false
+		}
+		// The length counts itself and the 6 bytes following it,
+		// but not the carriage control character.
+		l := int(binary.BigEndian.Uint16(in[off+1:]))
+		if l < 8 || l > 32767 {
+			return false
+		}
+		off += 1 + l
+	}
+
+	return true
+}
+
+// uel is the Universal Exit Language command, starting the PJL job header
+// sent to a printer before the page description.
+var uel = []byte("\x1B%-12345X")
+
+var pclXlHeaders = [][]byte{[]byte(") HP-PCL XL;"), []byte("( HP-PCL XL;")}
+
+// PclXl matches an HP PCL XL (PCL 6) print stream. The stream header names
+// the binding of the data, ")" for little-endian and "(" for big-endian,
+// and the protocol version. It is usually preceded by a PJL job header,
+// whose last line switches the printer language to PCL XL.
+func PclXl(in []byte) bool {
+	return pclXlStream(in) != nil
+}
+
+// PclXlMeta extracts the protocol class and revision of a PCL XL stream,
+// like "2.0".
+func PclXlMeta(in []byte) map[string]string {
+	s := pclXlStream(in)
+	if s == nil {
+		return nil
+	}
+	fields := bytes.SplitN(firstLine(s[len(pclXlHeaders[0]):]), []byte(";"), 3)
+	if len(fields) < 2 || len(fields[0]) == 0 || len(fields[1]) == 0 ||
+		!isDigits(fields[0]) || !isDigits(fields[1]) {
+		return nil
+	}
+
+	return map[string]string{"version": string(fields[0]) + "." + string(fields[1])}
+}
+
+// pclXlStream returns the part of in starting with the header of the PCL XL
+// stream, after the PJL job header, or nil if there is no such stream.
+func pclXlStream(in []byte) []byte {
+	if bytes.HasPrefix(in, uel) {
+		in = in[len(uel):]
+		for hasUpperPrefix(in, []byte("@PJL")) {
+			line := firstLine(in)
+			in = in[len(line):]
+			if len(in) > 0 {
+				in = in[1:]
+			}
+		}
+	}
+	for _, h := range pclXlHeaders {
+		if bytes.HasPrefix(in, h) {
+			return in
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package matchers
+
+import "bytes"
+
+// jsonStringValue returns the value of the first occurrence of key,
+// if the value is a string without escape sequences.
+func jsonStringValue(in []byte, key string) string {
+	k := []byte(`"` + key + `"`)
+	i := bytes.Index(in, k)
+	if i == -1 {
+		return ""
+	}
+	v := trimLWS(in[i+len(k):])
+	if len(v) == 0 || v[0] != ':' {
+		return ""
+	}
+	v = trimLWS(v[1:])
+	if len(v) == 0 || v[0] != '"' {
+		return ""
+	}
+	end := bytes.IndexAny(v[1:], "\"\\")
+	if end == -1 {
+		return ""
+	}
+
+	return string(v[1 : 1+end])
+}
+
+// Sarif matches a Static Analysis Results Interchange Format log.
+func Sarif(in []byte) bool {
+	return bytes.Contains(in, []byte(`"runs"`)) &&
+		(bytes.Contains(in, []byte("sarif-schema")) || bytes.Contains(in, []byte("/sarif-")))
+}
+
+// SpdxJson matches a Software Package Data Exchange document in JSON format.
+func SpdxJson(in []byte) bool {
+	return bytes.HasPrefix([]byte(jsonStringValue(in, "spdxVersion")), []byte("SPDX-"))
+}
+
+// SpdxTagValue matches a Software Package Data Exchange document in the
+// tag-value format. The first tag of the document is SPDXVersion, possibly
+// preceded by comment lines.
+func SpdxTagValue(in []byte) bool {
+	return spdxVersionTag(in) != nil
+}
+
+// spdxVersionTag returns the input starting with the value of the SPDXVersion
+// tag, or nil if the input does not start with the tag, after skipping comments.
+func spdxVersionTag(in []byte) []byte {
+	for {
+		in = trimLWS(in)
+		if !bytes.HasPrefix(in, []byte("#")) {
+			break
+		}
+		i := bytes.IndexByte(in, '\n')
+		if i == -1 {
+			return nil
+		}
+		in = in[i+1:]
+	}
+	if !bytes.HasPrefix(in, []byte("SPDXVersion:")) {
+		return nil
+	}
+
+	return in[len("SPDXVersion:"):]
+}
+
+// SbomMeta extracts the specification version of SPDX and CycloneDX documents.
+func SbomMeta(in []byte) map[string]string {
+	v := jsonStringValue(in, "spdxVersion")
+	if v == "" {
+		v = jsonStringValue(in, "specVersion")
+	}
+	if t := spdxVersionTag(in); v == "" && t != nil {
+		v = string(bytes.TrimSpace(firstLine(t)))
+	}
+	// CycloneDX XML documents declare their version in the namespace.
+	ns := []byte("cyclonedx.org/schema/bom/")
+	if i := bytes.Index(in, ns); v == "" && i != -1 {
+		if end := bytes.IndexByte(in[i+len(ns):], '"'); end != -1 {
+			v = string(in[i+len(ns) : i+len(ns)+end])
+		}
+	}
+	if v == "" {
+		return nil
+	}
+
+	return map[string]string{"version": v}
+}
+
+// CycloneDxJson matches a CycloneDX bill of materials in JSON format.
+func CycloneDxJson(in []byte) bool {
+	return jsonStringValue(in, "bomFormat") == "CycloneDX"
+}
+
+var cycloneDxXmlSigs = []sig{
+	newXmlSig("bom", "http://cyclonedx.org/schema/bom/"),
+}
+
+// CycloneDxXml matches a CycloneDX bill of materials in XML format.
+func CycloneDxXml(in []byte) bool {
+	return detect(in, cycloneDxXmlSigs)
+}
+
+// OpenVex matches an OpenVEX vulnerability exploitability exchange document.
+func OpenVex(in []byte) bool {
+	return bytes.HasPrefix([]byte(jsonStringValue(in, "@context")), []byte("https://openvex.dev/ns"))
+}
+
+// Csaf matches a Common Security Advisory Framework document,
+// which includes the CSAF profile of VEX documents.
+func Csaf(in []byte) bool {
+	return jsonStringValue(in, "csaf_version") != ""
+}
+
+// CsafMeta extracts the category of a CSAF document, like csaf_vex.
+func CsafMeta(in []byte) map[string]string {
+	if c := jsonStringValue(in, "category"); c != "" {
+		return map[string]string{"category": c}
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package matchers
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Grib matches a WMO GRIdded Binary file, editions 1 and 2.
+func Grib(in []byte) bool {
+	return len(in) > 7 && bytes.HasPrefix(in, []byte("GRIB")) &&
+		(in[7] == 1 || in[7] == 2)
+}
+
+// GribMeta extracts the edition of a GRIB file.
+func GribMeta(in []byte) map[string]string {
+	return editionMeta(in)
+}
+
+// Bufr matches a WMO Binary Universal Form for the Representation
+// of meteorological data file.
+func Bufr(in []byte) bool {
+	return len(in) > 7 && bytes.HasPrefix(in, []byte("BUFR")) &&
+		1 <= in[7] && in[7] <= 4
+}
+
+// BufrMeta extracts the edition of a BUFR file.
+func BufrMeta(in []byte) map[string]string {
+	return editionMeta(in)
+}
+
+// editionMeta returns the edition number found at offset 7,
+// which both GRIB and BUFR indicator sections use.
+func editionMeta(in []byte) map[string]string {
+	if len(in) < 8 {
+		return nil
+	}
+
+	return map[string]string{"edition": strconv.Itoa(int(in[7]))}
+}
+
+// Hdf4 matches a Hierarchical Data Format 4 file.
+func Hdf4(in []byte) bool {
+	return bytes.HasPrefix(in, []byte{0x0E, 0x03, 0x13, 0x01})
+}
+
+// Hdf5 matches a Hierarchical Data Format 5 file.
+func Hdf5(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("\x89HDF\r\n\x1A\n"))
+}
+
+// HdfEos matches an HDF-EOS file, the HDF profile used by NASA's
+// Earth Observing System. HDF-EOS files store their structural metadata
+// in attributes named "StructMetadata.0" and "HDFEOSVersion".
+func HdfEos(in []byte) bool {
+	return bytes.Contains(in, []byte("HDFEOSVersion")) ||
+		bytes.Contains(in, []byte("StructMetadata.0"))
+}
+
+// Asdf matches an Advanced Scientific Data Format file.
+func Asdf(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("#ASDF "))
+}
+
+// AsdfMeta extracts the file format version of an ASDF file.
+func AsdfMeta(in []byte) map[string]string {
+	v := bytes.TrimSpace(firstLine(in[len("#ASDF "):]))
+	if len(v) == 0 {
+		return nil
+	}
+
+	return map[string]string{"version": string(v)}
+}
+
+// Ecsv matches an Enhanced Character Separated Values file, the
+// table format used by Astropy.
+func Ecsv(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("# %ECSV "))
+}
+
+// CasaTable matches the table.dat file describing a CASA table.
+// The file is an AipsIO stream, starting with the 0xBEBEBEBE magic,
+// the object length and the object type, which is "Table".
+func CasaTable(in []byte) bool {
+	return len(in) >= 17 &&
+		bytes.HasPrefix(in, []byte{0xBE, 0xBE, 0xBE, 0xBE}) &&
+		bytes.Equal(in[8:17], []byte("\x00\x00\x00\x05Table"))
+}
+
+// Nitf matches a National Imagery Transmission Format file, the container
+// of military and commercial satellite imagery, or its NATO profile NSIF.
+// The file header starts with the format and its version, followed by the
+// two digits of the complexity level and the "BF01" system type.
+//
+// https://gwg.nga.mil/gwg/focus-groups/NITFS.html
+func Nitf(in []byte) bool {
+	if len(in) < 15 {
+		return false
+	}
+	switch string(in[:9]) {
+	case "NITF02.10", "NITF02.00", "NSIF01.00":
+	default:
+		return false
+	}
+
+	return isDigits(in[9:11]) && bytes.Equal(in[11:15], []byte("BF01"))
+}
+
+// NitfMeta extracts the version, the complexity level and the title of a
+// NITF file. NSIF files are reported with the "NSIF" profile.
+func NitfMeta(in []byte) map[string]string {
+	meta := map[string]string{
+		"version":    string(bytes.TrimLeft(in[4:9], "0")),
+		"complexity": string(in[9:11]),
+	}
+	if in[1] == 'S' {
+		meta["profile"] = "NSIF"
+	}
+	// The title follows the originating station and the date fields.
+	if len(in) >= 119 {
+		setNonEmpty(meta, "title", string(bytes.TrimSpace(in[39:119])))
+	}
+
+	return meta
+}
+
+// EnviHeader matches the header file of an ENVI raster, the text file
+// describing the layout of the binary image stored next to it. It starts
+// with the "ENVI" line followed by "key = value" lines, like "bands = 4".
+//
+// https://www.nv5geospatialsoftware.com/docs/ENVIHeaderFiles.html
+func EnviHeader(in []byte) bool {
+	line := firstLine(in)
+	if !bytes.Equal(bytes.TrimRight(line, " \t\r"), []byte("ENVI")) {
+		return false
+	}
+	_, ok := enviNextField(in[len(line):])
+
+	return ok
+}
+
+// EnviHeaderMeta extracts the number of bands and the interleave of the
+// described image: bsq, bil or bip.
+func EnviHeaderMeta(in []byte) map[string]string {
+	meta := map[string]string{}
+	rest := in[len(firstLine(in)):]
+	for {
+		field, ok := enviNextField(rest)
+		if !ok {
+			break
+		}
+		rest = rest[field.end:]
+		switch string(bytes.ToLower(field.key)) {
+		case "bands":
+			if isDigits(field.value) {
+				setNonEmpty(meta, "bands", string(field.value))
+			}
+		case "interleave":
+			setNonEmpty(meta, "interleave", string(bytes.ToLower(field.value)))
+		}
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// enviField is a "key = value" line of an ENVI header. end is the offset
+// of the end of the line in the input it was read from.
+type enviField struct {
+	key, value []byte
+	end        int
+}
+
+// enviNextField returns the first field of in, skipping the blank lines.
+// Values in braces may span lines; only their first line is returned.
+func enviNextField(in []byte) (enviField, bool) {
+	off := 0
+	for off < len(in) {
+		line := firstLine(in[off:])
+		off += len(line)
+		if off < len(in) {
+			off++
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		i := bytes.IndexByte(line, '=')
+		if i < 1 {
+			return enviField{}, false
+		}
+		key := bytes.TrimSpace(line[:i])
+		if len(key) == 0 {
+			return enviField{}, false
+		}
+		value := bytes.TrimSpace(line[i+1:])
+		if bytes.HasPrefix(value, []byte("{")) && bytes.IndexByte(value, '}') == -1 {
+			end := bytes.IndexByte(in[off:], '}')
+			if end == -1 {
+				end = len(in) - off
+			}
+			off += end + len(firstLine(in[off+end:]))
+		}
+		return enviField{key: key, value: value, end: off}, true
+	}
+
+	return enviField{}, false
+}
+
+var safeManifestSigs = []sig{
+	newXmlSig("XFDU", "urn:ccsds:schema:xfdu:1"),
+}
+
+// SafeManifest matches the manifest.safe file of a Standard Archive Format
+// for Europe product, the layout of the Sentinel satellite products. The
+// manifest is an XML Formatted Data Unit document.
+//
+// https://sentinels.copernicus.eu/web/sentinel/user-guides/sentinel-1-sar/data-formats/safe-specification
+func SafeManifest(in []byte) bool {
+	return detect(in, safeManifestSigs)
+}
+
+// SafeZip matches a zipped SAFE product: its entries are stored in the
+// product directory, whose name ends with ".SAFE".
+func SafeZip(in []byte) bool {
+	return withZipEntries(in, func(entries []zipEntry) bool {
+		return safeProduct(entries) != nil
+	})
+}
+
+// SafeZipMeta extracts the name of the product, like
+// "S2A_MSIL1C_20230615T102031_N0509_R065_T32TQM_20230615T140459".
+func SafeZipMeta(in []byte) map[string]string {
+	var meta map[string]string
+	withZipEntries(in, func(entries []zipEntry) bool {
+		if p := safeProduct(entries); p != nil {
+			meta = map[string]string{"product": string(p)}
+		}
+		return true
+	})
+
+	return meta
+}
+
+// safeProduct returns the name of the SAFE product directory holding the
+// first entry, without the extension, or nil if it is not one.
+func safeProduct(entries []zipEntry) []byte {
+	if len(entries) == 0 {
+		return nil
+	}
+	n := entries[0].name
+	i := bytes.IndexByte(n, '/')
+	if i == -1 || !bytes.HasSuffix(n[:i], []byte(".SAFE")) || i == len(".SAFE") {
+		return nil
+	}
+
+	return n[:i-len(".SAFE")]
+}
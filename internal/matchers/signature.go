@@ -1,19 +1,16 @@
 package matchers
 
-import (
-	"bytes"
-	"fmt"
-)
+import "bytes"
 
 type (
 	markupSig  []byte
 	ciSig      []byte // case insensitive signature
-	shebangSig []byte // matches !# followed by the signature
+	shebangSig []byte // an interpreter, matched against the shebang line
 	ftypSig    []byte // matches audio/video files. www.ftyps.com
 	xmlSig     struct {
-		// the local name of the root tag
+		// the local name of the root tag, compared ignoring case
 		localName []byte
-		// the namespace of the XML document
+		// the namespace URI of the root tag
 		xmlns []byte
 	}
 	sig interface {
@@ -22,32 +19,44 @@ type (
 )
 
 func newXmlSig(localName, xmlns string) xmlSig {
-	ret := xmlSig{xmlns: []byte(xmlns)}
-	if localName != "" {
-		ret.localName = []byte(fmt.Sprintf("<%s", localName))
+	return xmlSig{localName: []byte(localName), xmlns: []byte(xmlns)}
+}
+
+// upperCase maps the ASCII lowercase letters to uppercase
+// and all the other bytes to themselves.
+var upperCase = func() (t [256]byte) {
+	for b := range t {
+		t[b] = byte(b)
+		if 'a' <= b && b <= 'z' {
+			t[b] -= 'a' - 'A'
+		}
 	}
+	return t
+}()
 
-	return ret
+// hasUpperPrefix reports whether in starts with sig, ignoring the case of the
+// input letters matched against the uppercase letters of sig.
+func hasUpperPrefix(in, sig []byte) bool {
+	if len(in) < len(sig) {
+		return false
+	}
+	for i, b := range sig {
+		if db := in[i]; db != b && upperCase[db] != b {
+			return false
+		}
+	}
+
+	return true
 }
 
 // Implement sig interface.
 func (hSig markupSig) detect(in []byte) bool {
-	if len(in) < len(hSig)+1 {
+	if len(in) < len(hSig)+1 || !hasUpperPrefix(in, hSig) {
 		return false
 	}
-
-	// perform case insensitive check
-	for i, b := range hSig {
-		db := in[i]
-		if 'A' <= b && b <= 'Z' {
-			db &= 0xDF
-		}
-		if b != db {
-			return false
-		}
-	}
-	// Next byte must be space or right angle bracket.
-	if db := in[len(hSig)]; db != ' ' && db != '>' {
+	// Next byte must be whitespace, a right angle bracket, or the slash of
+	// an empty element.
+	if db := in[len(hSig)]; !isWS(db) && db != '>' && db != '/' {
 		return false
 	}
 
@@ -56,40 +65,36 @@ func (hSig markupSig) detect(in []byte) bool {
 
 // Implement sig interface.
 func (tSig ciSig) detect(in []byte) bool {
-	if len(in) < len(tSig)+1 {
-		return false
-	}
+	return len(in) >= len(tSig)+1 && hasUpperPrefix(in, tSig)
+}
 
-	// perform case insensitive check
-	for i, b := range tSig {
-		db := in[i]
-		if 'A' <= b && b <= 'Z' {
-			db &= 0xDF
-		}
-		if b != db {
-			return false
-		}
+// shebang returns the interpreter named on the shebang line starting in,
+// with its arguments, or nil if in does not start with a shebang line.
+// A valid shebang starts with the "#!" characters followed by any number
+// of spaces, followed by the path to the interpreter and optionally,
+// the args for the interpreter.
+func shebang(in []byte) []byte {
+	if len(in) < 3 || in[0] != '#' || in[1] != '!' {
+		return nil
 	}
 
-	return true
+	return trimLWS(trimRWS(firstLine(in[2:])))
 }
 
-// a valid shebang starts with the "#!" characters
-// followed by any number of spaces
-// followed by the path to the interpreter and optionally, the args for the interpreter
-func (sSig shebangSig) detect(in []byte) bool {
-	in = firstLine(in)
-
-	if len(in) < len(sSig)+2 {
+// hasShebang reports whether in starts with a shebang line naming one of
+// the interpreters. The line is scanned once, whatever their number.
+func hasShebang(in []byte, interpreters []shebangSig) bool {
+	line := shebang(in)
+	if len(line) == 0 {
 		return false
 	}
-	if in[0] != '#' || in[1] != '!' {
-		return false
+	for _, i := range interpreters {
+		if bytes.Equal(line, i) {
+			return true
+		}
 	}
 
-	in = trimLWS(trimRWS(in[2:]))
-
-	return bytes.Equal(in, sSig)
+	return false
 }
 
 // Implement sig interface.
@@ -99,23 +104,26 @@ func (fSig ftypSig) detect(in []byte) bool {
 		bytes.Equal(in[8:12], fSig)
 }
 
-// Implement sig interface.
+// Implement sig interface. The root element must be in the namespace of the
+// signature, whatever the prefix bound to it. Signatures without a local
+// name, and roots in no namespace, only need to declare the namespace, for
+// the descendants of the root.
 func (xSig xmlSig) detect(in []byte) bool {
-	l := 512
-	if len(in) < l {
-		l = len(in)
+	root, ok := xmlRootOf(in)
+	if !ok {
+		return false
 	}
-	in = in[:l]
-
-	if len(xSig.localName) == 0 {
-		return bytes.Index(in, xSig.xmlns) > 0
+	if len(xSig.localName) > 0 && !bytes.EqualFold(root.localName(in), xSig.localName) {
+		return false
 	}
 	if len(xSig.xmlns) == 0 {
-		return bytes.Index(in, xSig.localName) > 0
+		return true
+	}
+	if ns := root.namespace(in); len(xSig.localName) > 0 && ns != nil {
+		return nsMatch(ns, xSig.xmlns)
 	}
 
-	localNameIndex := bytes.Index(in, xSig.localName)
-	return localNameIndex != -1 && localNameIndex < bytes.Index(in, xSig.xmlns)
+	return root.declares(in, xSig.xmlns)
 }
 
 // detect returns true if any of the provided signatures pass for in input.
@@ -0,0 +1,227 @@
+package matchers
+
+import (
+	"encoding/binary"
+	"sync"
+	"unicode"
+
+	"github.com/gabriel-vasile/mimetype/internal/json"
+)
+
+// Stream is a matcher fed the input in consecutive chunks, for the text
+// formats whose matchers look at the whole input. It does not keep the
+// chunks, and rejects the input as soon as a chunk makes it impossible to
+// match, without waiting for the rest.
+type Stream interface {
+	// Write feeds the next chunk of the input. It returns false once the
+	// input is rejected, whatever the following chunks are.
+	Write(p []byte) bool
+	// Match reports whether the input fed so far matches, as if it was
+	// complete. It must be the last call made on the stream.
+	Match() bool
+}
+
+// NewTxtStream returns a Stream equivalent to Txt.
+func NewTxtStream() Stream {
+	return &txtStream{}
+}
+
+// txtStream rejects the inputs holding control characters. Inputs encoded
+// in UTF-16 or UTF-32 are checked one code unit at a time, instead of one
+// byte, their charset being decided once wideHead bytes are fed.
+type txtStream struct {
+	binary  bool
+	decided bool
+	head    []byte // the bytes fed before the charset is decided
+	size    int    // size of the code units, 1 for the byte charsets
+	order   binary.ByteOrder
+	unit    []byte // the start of a code unit cut short by a chunk
+}
+
+// wideHead is the number of bytes needed to tell wide text apart: two code
+// units of UTF-32.
+const wideHead = 8
+
+func (s *txtStream) Write(p []byte) bool {
+	if s.binary {
+		return false
+	}
+	if !s.decided {
+		if len(s.head)+len(p) < wideHead {
+			s.head = append(s.head, p...)
+			return true
+		}
+		if len(s.head) > 0 {
+			p, s.head = append(s.head, p...), nil
+		}
+		s.decide(p)
+	}
+	s.check(p)
+
+	return !s.binary
+}
+
+func (s *txtStream) decide(head []byte) {
+	s.decided, s.size = true, 1
+	if cs := WideCharset(head); cs != "" {
+		s.size, s.order = wideUnit(cs)
+	}
+}
+
+func (s *txtStream) check(p []byte) {
+	if s.size == 1 {
+		for _, b := range p {
+			if binaryChars[b] {
+				s.binary = true
+				return
+			}
+		}
+		return
+	}
+
+	if len(s.unit) > 0 {
+		n := s.size - len(s.unit)
+		if n > len(p) {
+			s.unit = append(s.unit, p...)
+			return
+		}
+		s.unit = append(s.unit, p[:n]...)
+		s.checkUnit(unitAt(s.unit, s.size, s.order))
+		s.unit, p = s.unit[:0], p[n:]
+	}
+	for ; len(p) >= s.size && !s.binary; p = p[s.size:] {
+		s.checkUnit(unitAt(p, s.size, s.order))
+	}
+	s.unit = append(s.unit, p...)
+}
+
+func (s *txtStream) checkUnit(u uint32) {
+	if u < 256 && binaryChars[u] || u > unicode.MaxRune {
+		s.binary = true
+	}
+}
+
+func (s *txtStream) Match() bool {
+	if !s.decided {
+		s.decide(s.head)
+		s.check(s.head)
+	}
+
+	return !s.binary
+}
+
+// NewJsonStream returns a Stream equivalent to Json.
+func NewJsonStream() Stream {
+	return &jsonStream{}
+}
+
+// jsonStream checks the input is a JSON value. Inputs reaching the read
+// limit are truncated, so they only need to be a valid beginning of a value.
+type jsonStream struct {
+	scanner json.Scanner
+	fed     int
+}
+
+// The JSON scanners escape to the heap, so the streams used by the slice
+// matchers are pooled, keeping the stacks of the scanners between the calls.
+var (
+	jsonStreams = sync.Pool{
+		New: func() interface{} { return &jsonStream{} },
+	}
+	ndJsonStreams = sync.Pool{
+		New: func() interface{} { return &ndJsonStream{} },
+	}
+)
+
+func (s *jsonStream) reset() {
+	s.scanner.Reset()
+	s.fed = 0
+}
+
+func (s *jsonStream) Write(p []byte) bool {
+	s.fed += len(p)
+	return s.scanner.Write(p)
+}
+
+func (s *jsonStream) Match() bool {
+	if s.fed >= ReadLimit {
+		return s.scanner.Scanned() == s.fed
+	}
+
+	return s.scanner.Close()
+}
+
+// NewNdJsonStream returns a Stream equivalent to NdJson.
+func NewNdJsonStream() Stream {
+	return &ndJsonStream{}
+}
+
+// ndJsonStream checks each line of the input is a JSON value. Lines end
+// with "\n" or "\r\n", and empty lines are allowed. The last line of inputs
+// reaching the read limit only needs to be a valid beginning of a value.
+type ndJsonStream struct {
+	line     json.Scanner
+	lineLen  int
+	fed      int
+	cr       bool // the last byte fed was a carriage return
+	rejected bool
+}
+
+func (s *ndJsonStream) reset() {
+	s.line.Reset()
+	s.lineLen, s.fed = 0, 0
+	s.cr, s.rejected = false, false
+}
+
+func (s *ndJsonStream) Write(p []byte) bool {
+	if s.rejected {
+		return false
+	}
+	s.fed += len(p)
+	for i := 0; i < len(p); {
+		b := p[i]
+		if s.cr && b != '\n' {
+			s.rejected = true
+			return false
+		}
+		switch b {
+		case '\r':
+			s.cr = true
+			i++
+		case '\n':
+			s.cr = false
+			if s.lineLen > 0 && !s.line.Close() {
+				s.rejected = true
+				return false
+			}
+			s.line.Reset()
+			s.lineLen = 0
+			i++
+		default:
+			// Feed the rest of the line at once.
+			end := i + 1
+			for end < len(p) && p[end] != '\n' && p[end] != '\r' {
+				end++
+			}
+			if !s.line.Write(p[i:end]) {
+				s.rejected = true
+				return false
+			}
+			s.lineLen += end - i
+			i = end
+		}
+	}
+
+	return true
+}
+
+func (s *ndJsonStream) Match() bool {
+	if s.rejected || s.cr {
+		return false
+	}
+	if s.lineLen == 0 || s.fed >= ReadLimit {
+		return true
+	}
+
+	return s.line.Close()
+}
@@ -1,14 +1,10 @@
 package matchers
 
-import (
-	"bytes"
-
-	"github.com/gabriel-vasile/mimetype/internal/json"
-)
+import "bytes"
 
 var (
+	// The doctype and the comments are handled by Html.
 	htmlSigs = []sig{
-		markupSig("<!DOCTYPE HTML"),
 		markupSig("<HTML"),
 		markupSig("<HEAD"),
 		markupSig("<SCRIPT"),
@@ -24,48 +20,56 @@ var (
 		markupSig("<BODY"),
 		markupSig("<BR"),
 		markupSig("<P"),
-		markupSig("<!--"),
 	}
 	xmlSigs = []sig{
 		markupSig("<?XML"),
 	}
+	svgSigs = []sig{
+		newXmlSig("svg", "http://www.w3.org/2000/svg"),
+	}
 	rssSigs = []sig{
 		newXmlSig("rss", ""),
 	}
 	atomSigs = []sig{
-		newXmlSig("feed", `xmlns="http://www.w3.org/2005/Atom"`),
+		newXmlSig("feed", "http://www.w3.org/2005/Atom"),
 	}
 	kmlSigs = []sig{
-		newXmlSig("kml", `xmlns="http://www.opengis.net/kml/2.2"`),
-		newXmlSig("kml", `xmlns="http://earth.google.com/kml/2.0"`),
-		newXmlSig("kml", `xmlns="http://earth.google.com/kml/2.1"`),
-		newXmlSig("kml", `xmlns="http://earth.google.com/kml/2.2"`),
+		newXmlSig("kml", "http://www.opengis.net/kml/2.2"),
+		newXmlSig("kml", "http://earth.google.com/kml/2.0"),
+		newXmlSig("kml", "http://earth.google.com/kml/2.1"),
+		newXmlSig("kml", "http://earth.google.com/kml/2.2"),
 	}
 	xliffSigs = []sig{
-		newXmlSig("xliff", `xmlns="urn:oasis:names:tc:xliff:document:1.2"`),
+		newXmlSig("xliff", "urn:oasis:names:tc:xliff:document:1.2"),
 	}
 	colladaSigs = []sig{
-		newXmlSig("COLLADA", `xmlns="http://www.collada.org/2005/11/COLLADASchema"`),
+		newXmlSig("COLLADA", "http://www.collada.org/2005/11/COLLADASchema"),
 	}
 	gmlSigs = []sig{
-		newXmlSig("", `xmlns:gml="http://www.opengis.net/gml"`),
-		newXmlSig("", `xmlns:gml="http://www.opengis.net/gml/3.2"`),
-		newXmlSig("", `xmlns:gml="http://www.opengis.net/gml/3.3/exr"`),
+		newXmlSig("", "http://www.opengis.net/gml"),
+		newXmlSig("", "http://www.opengis.net/gml/3.2"),
+		newXmlSig("", "http://www.opengis.net/gml/3.3/exr"),
 	}
 	gpxSigs = []sig{
-		newXmlSig("gpx", `xmlns="http://www.topografix.com/GPX/1/1"`),
+		newXmlSig("gpx", "http://www.topografix.com/GPX/1/1"),
 	}
 	tcxSigs = []sig{
-		newXmlSig("TrainingCenterDatabase", `xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"`),
+		newXmlSig("TrainingCenterDatabase", "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"),
 	}
 	x3dSigs = []sig{
-		newXmlSig("X3D", `xmlns:xsd="http://www.w3.org/2001/XMLSchema-instance"`),
+		newXmlSig("X3D", "http://www.w3.org/2001/XMLSchema-instance"),
+	}
+	dtbookSigs = []sig{
+		newXmlSig("dtbook", "http://www.daisy.org/z3986/2005/dtbook/"),
+	}
+	plistSigs = []sig{
+		newXmlSig("plist", ""),
 	}
 	amfSigs = []sig{
 		newXmlSig("amf", ""),
 	}
 	threemfSigs = []sig{
-		newXmlSig("model", `xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02"`),
+		newXmlSig("model", "http://schemas.microsoft.com/3dmanufacturing/core/2015/02"),
 	}
 	vCardSigs = []sig{
 		ciSig("BEGIN:VCARD\n"),
@@ -80,11 +84,13 @@ var (
 		ciSig("<?\n"),
 		ciSig("<?\r"),
 		ciSig("<? "),
+	}
+	phpInterpreters = []shebangSig{
 		shebangSig("/usr/local/bin/php"),
 		shebangSig("/usr/bin/php"),
 		shebangSig("/usr/bin/env php"),
 	}
-	jsSigs = []sig{
+	jsInterpreters = []shebangSig{
 		shebangSig("/bin/node"),
 		shebangSig("/usr/bin/node"),
 		shebangSig("/bin/nodejs"),
@@ -92,21 +98,21 @@ var (
 		shebangSig("/usr/bin/env node"),
 		shebangSig("/usr/bin/env nodejs"),
 	}
-	luaSigs = []sig{
+	luaInterpreters = []shebangSig{
 		shebangSig("/usr/bin/lua"),
 		shebangSig("/usr/local/bin/lua"),
 		shebangSig("/usr/bin/env lua"),
 	}
-	perlSigs = []sig{
+	perlInterpreters = []shebangSig{
 		shebangSig("/usr/bin/perl"),
 		shebangSig("/usr/bin/env perl"),
 	}
-	pythonSigs = []sig{
+	pythonInterpreters = []shebangSig{
 		shebangSig("/usr/bin/python"),
 		shebangSig("/usr/local/bin/python"),
 		shebangSig("/usr/bin/env python"),
 	}
-	tclSigs = []sig{
+	tclInterpreters = []shebangSig{
 		shebangSig("/usr/bin/tcl"),
 		shebangSig("/usr/local/bin/tcl"),
 		shebangSig("/usr/bin/env tcl"),
@@ -119,34 +125,100 @@ var (
 	}
 )
 
-// Txt matches a text file.
-func Txt(in []byte) bool {
-	in = trimLWS(in)
-	for _, b := range in {
-		if b <= 0x08 ||
+// binaryChars holds the control bytes which do not appear in text files.
+var binaryChars = func() (t [256]bool) {
+	for b := 0; b < 256; b++ {
+		t[b] = b <= 0x08 ||
 			b == 0x0B ||
 			0x0E <= b && b <= 0x1A ||
-			0x1C <= b && b <= 0x1F {
-			return false
-		}
+			0x1C <= b && b <= 0x1F
 	}
+	return t
+}()
 
-	return true
+// IsBinaryChar reports whether b is a control byte which does not appear in
+// text files, unlike the tab, the line feed or the escape character.
+func IsBinaryChar(b byte) bool {
+	return binaryChars[b]
+}
+
+// Txt matches a text file.
+func Txt(in []byte) bool {
+	var s txtStream
+	return s.Write(in) && s.Match()
 }
 
-// Html matches a Hypertext Markup Language file.
+// Html matches a Hypertext Markup Language file. The byte order mark, the
+// whitespace and the comments preceding the first tag are skipped, and the
+// tags are matched case-insensitively.
 func Html(in []byte) bool {
-	in = trimLWS(in)
+	in, comments := skipHtmlPrologue(in)
+	// Documents whose comments fill the whole input, like long license
+	// headers cut by the read limit, are given the benefit of the doubt.
 	if len(in) == 0 {
+		return comments
+	}
+	// All the signatures are tags.
+	if in[0] != '<' {
+		return false
+	}
+	return isHtmlDoctype(in) || detect(in, htmlSigs)
+}
+
+var (
+	utf8BOM        = []byte{0xEF, 0xBB, 0xBF}
+	commentStart   = []byte("<!--")
+	commentEnd     = []byte("-->")
+	downlevelStart = []byte("<![")
+)
+
+// skipHtmlPrologue returns in past its UTF-8 byte order mark, and past the
+// whitespace, comments and conditional comments preceding the first tag of
+// an HTML document. comments reports whether any comment was skipped. The
+// returned slice is empty when a comment is not closed within in.
+func skipHtmlPrologue(in []byte) (rest []byte, comments bool) {
+	in = bytes.TrimPrefix(in, utf8BOM)
+	for {
+		in = trimLWS(in)
+		var start, end []byte
+		switch {
+		case bytes.HasPrefix(in, commentStart):
+			start, end = commentStart, commentEnd
+		// Like <![if !IE]>, revealed to the browsers other than Internet
+		// Explorer.
+		case bytes.HasPrefix(in, downlevelStart):
+			start, end = downlevelStart, []byte(">")
+		default:
+			return in, comments
+		}
+		comments = true
+		i := bytes.Index(in[len(start):], end)
+		if i == -1 {
+			return nil, true
+		}
+		in = in[len(start)+i+len(end):]
+	}
+}
+
+// isHtmlDoctype reports whether in starts with the HTML doctype, written in
+// any case and with any whitespace between its words.
+func isHtmlDoctype(in []byte) bool {
+	if !hasUpperPrefix(in, []byte("<!DOCTYPE")) {
+		return false
+	}
+	in = in[len("<!DOCTYPE"):]
+	rest := trimLWS(in)
+	if len(rest) == len(in) {
 		return false
 	}
-	return detect(in, htmlSigs)
+
+	return markupSig("HTML").detect(rest)
 }
 
 // Xml matches an Extensible Markup Language file.
 func Xml(in []byte) bool {
 	in = trimLWS(in)
-	if len(in) == 0 {
+	if len(in) == 0 || in[0] != '<' {
 		return false
 	}
 	return detect(in, xmlSigs)
@@ -154,17 +226,26 @@ func Xml(in []byte) bool {
 
 // Php matches a PHP: Hypertext Preprocessor file.
 func Php(in []byte) bool {
-	return detect(in, phpSigs)
+	if len(in) == 0 {
+		return false
+	}
+	switch in[0] {
+	case '<':
+		return detect(in, phpSigs)
+	case '#':
+		return hasShebang(in, phpInterpreters)
+	}
+
+	return false
 }
 
 // Json matches a JavaScript Object Notation file.
 func Json(in []byte) bool {
-	parsed, err := json.Scan(in)
-	if len(in) < ReadLimit {
-		return err == nil
-	}
+	s := jsonStreams.Get().(*jsonStream)
+	defer jsonStreams.Put(s)
+	s.reset()
 
-	return parsed == len(in)
+	return s.Write(in) && s.Match()
 }
 
 // GeoJson matches a RFC 7946 GeoJSON file.
@@ -197,8 +278,12 @@ func GeoJson(in []byte) bool {
 	in = in[si+sl:]
 	// skip any whitespace before the colon
 	in = trimLWS(in)
+	// the input may end, or be invalid, before the colon when the matcher
+	// is called without the json matcher checking it first
+	if len(in) == 0 || in[0] != ':' {
+		return false
+	}
 	// skip any whitesapce after the colon
-	// not checking if char is colon because json matcher already did check
 	in = trimLWS(in[1:])
 
 	geoJsonTypes := [][]byte{
@@ -223,70 +308,104 @@ func GeoJson(in []byte) bool {
 
 // NdJson matches a Newline delimited JSON file.
 func NdJson(in []byte) bool {
-	// Separator with carriage return and new line `\r\n`
-	srn := []byte{0x0D, 0x0A}
+	s := ndJsonStreams.Get().(*ndJsonStream)
+	defer ndJsonStreams.Put(s)
+	s.reset()
 
-	// Separator with only new line `\n`
-	sn := []byte{0x0A}
+	return s.Write(in) && s.Match()
+}
 
-	// total bytes scanned
-	parsed := 0
+// StrictJson matches a JSON document whose root value is an object or an
+// array, validated from its first byte, after a UTF-8 byte order mark and
+// any amount of whitespace, to the end of the input. Unlike Json, it rejects
+// the bare strings, numbers and literals many plain text files are made of.
+// Inputs reaching the read limit only need to be a valid beginning.
+func StrictJson(in []byte) bool {
+	truncated := len(in) >= ReadLimit
+	in = bytes.TrimPrefix(in, utf8BOM)
+	if !isJsonContainer(in) {
+		return false
+	}
+	s := jsonStreams.Get().(*jsonStream)
+	defer jsonStreams.Put(s)
+	s.reset()
 
-	// Split by `srn`
-	for rni, insrn := range bytes.Split(in, srn) {
-		// separator byte count should be added only after the first split
-		if rni != 0 {
-			// Add two as `\r\n` is used for split
-			parsed += 2
+	return s.Write(in) && (truncated || s.scanner.Close())
+}
+
+// StrictNdJson matches a newline delimited JSON file holding at least two
+// values, each an object or an array on a line of its own. Empty lines are
+// allowed. The last line of inputs reaching the read limit only needs to be
+// a valid beginning of a value.
+func StrictNdJson(in []byte) bool {
+	truncated := len(in) >= ReadLimit
+	in = bytes.TrimPrefix(in, utf8BOM)
+	s := jsonStreams.Get().(*jsonStream)
+	defer jsonStreams.Put(s)
+
+	values := 0
+	for len(in) > 0 {
+		line, last := in, true
+		if i := bytes.IndexByte(in, '\n'); i >= 0 {
+			line, in, last = in[:i], in[i+1:], false
+		} else {
+			in = nil
+		}
+		if len(bytes.Trim(line, " \t\r")) == 0 {
+			continue
 		}
-		// Return false if there is a carriage return `\r`
-		if bytes.Contains(insrn, []byte{0x0D}) {
+		if !isJsonContainer(line) {
 			return false
 		}
-		// Split again by `sn`
-		for ni, insn := range bytes.Split(insrn, sn) {
-			// separator byte count should be added only after the first split
-			if ni != 0 {
-				// Add one as `\n` is used for split
-				parsed++
-			}
-			// Empty line is valid
-			if len(insn) == 0 {
-				continue
-			}
-			p, err := json.Scan(insn)
-			parsed += p
-			if parsed < ReadLimit && err != nil {
-				return false
-			}
+		s.reset()
+		if !s.Write(line) || !(last && truncated) && !s.scanner.Close() {
+			return false
 		}
+		values++
 	}
-	return parsed == len(in)
+
+	return values >= 2
+}
+
+// isJsonContainer reports whether the first value of in, after the JSON
+// whitespace, is an object or an array.
+func isJsonContainer(in []byte) bool {
+	for _, b := range in {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
 }
 
 // Js matches a Javascript file.
 func Js(in []byte) bool {
-	return detect(in, jsSigs)
+	return hasShebang(in, jsInterpreters)
 }
 
 // Lua matches a Lua programming language file.
 func Lua(in []byte) bool {
-	return detect(in, luaSigs)
+	return hasShebang(in, luaInterpreters)
 }
 
 // Perl matches a Perl programming language file.
 func Perl(in []byte) bool {
-	return detect(in, perlSigs)
+	return hasShebang(in, perlInterpreters)
 }
 
 // Python matches a Python programming language file.
 func Python(in []byte) bool {
-	return detect(in, pythonSigs)
+	return hasShebang(in, pythonInterpreters)
 }
 
 // Tcl matches a Tcl programming language file.
 func Tcl(in []byte) bool {
-	return detect(in, tclSigs)
+	return hasShebang(in, tclInterpreters)
 }
 
 // Rtf matches a Rich Text Format file.
@@ -294,9 +413,10 @@ func Rtf(in []byte) bool {
 	return bytes.HasPrefix(in, []byte("{\\rtf1"))
 }
 
-// Svg matches a SVG file.
+// Svg matches a SVG file: one having an svg element, or whose root element
+// is in the SVG namespace, whatever the prefix bound to it.
 func Svg(in []byte) bool {
-	return bytes.Contains(in, []byte("<svg"))
+	return bytes.Contains(in, []byte("<svg")) || detect(in, svgSigs)
 }
 
 // Rss matches a Rich Site Summary file.
@@ -354,6 +474,11 @@ func X3d(in []byte) bool {
 	return detect(in, x3dSigs)
 }
 
+// Dtbook matches a DAISY Digital Talking Book XML file.
+func Dtbook(in []byte) bool {
+	return detect(in, dtbookSigs)
+}
+
 // VCard matches a Virtual Contact File.
 func VCard(in []byte) bool {
 	return detect(in, vCardSigs)
@@ -2,13 +2,18 @@ package matchers
 
 import (
 	"bytes"
-	"encoding/csv"
-	"io"
+	"strconv"
 )
 
-// Csv matches a comma-separated values file.
+// csvDelimiters are the delimiters of comma-separated values files, in
+// order of preference: the comma, and the semicolon used where the comma is
+// the decimal separator, like in most of Europe.
+var csvDelimiters = [...]byte{',', ';'}
+
+// Csv matches a comma-separated values file, delimited by commas or by
+// semicolons.
 func Csv(in []byte) bool {
-	return sv(in, ',')
+	return CsvDelimiter(in) != 0
 }
 
 // Tsv matches a tab-separated values file.
@@ -16,31 +21,248 @@ func Tsv(in []byte) bool {
 	return sv(in, '\t')
 }
 
-func sv(in []byte, comma rune) bool {
-	r := csv.NewReader(butLastLineReader(in, ReadLimit))
-	r.Comma = comma
-	r.TrimLeadingSpace = true
-	r.LazyQuotes = true
-	r.Comment = '#'
+func sv(in []byte, comma byte) bool {
+	_, ok := svFields(in, comma)
+	return ok
+}
+
+// svFields returns the number of fields of the records of in, delimited by
+// comma, and whether they make a delimited file.
+func svFields(in []byte, comma byte) (int, bool) {
+	// Records with more than one field have at least one delimiter.
+	if bytes.IndexByte(in, comma) == -1 {
+		return 0, false
+	}
+	s := svStream{comma: comma}
+	if !s.Write(in) || !s.Match() {
+		return 0, false
+	}
+
+	return s.perRecord, true
+}
+
+// CsvDelimiter infers the delimiter of a comma-separated values file: the
+// one splitting all the records in the same number of fields, quoted fields
+// being kept whole. When several do, the one giving the most fields wins, so
+// semicolon-delimited records holding decimal commas are told apart from
+// comma-delimited ones. Semicolons need three records or three fields per
+// record. It returns 0 when in is not delimited by any.
+func CsvDelimiter(in []byte) byte {
+	delimiter, most := byte(0), 0
+	for _, comma := range csvDelimiters {
+		if fields, ok := svFields(in, comma); ok && fields > most {
+			delimiter, most = comma, fields
+		}
+	}
+
+	return delimiter
+}
+
+// CsvMeta returns the delimiter of a comma-separated values file and the
+// number of columns of its records.
+func CsvMeta(in []byte) map[string]string {
+	comma := CsvDelimiter(in)
+	if comma == 0 {
+		return nil
+	}
+	fields, _ := svFields(in, comma)
+
+	return map[string]string{
+		"delimiter": string(comma),
+		"columns":   strconv.Itoa(fields),
+	}
+}
+
+// NewCsvStream returns a Stream equivalent to Csv.
+func NewCsvStream() Stream {
+	s := &csvStream{}
+	for i, comma := range csvDelimiters {
+		s.delimited[i].comma = comma
+	}
+
+	return s
+}
+
+// NewTsvStream returns a Stream equivalent to Tsv.
+func NewTsvStream() Stream {
+	return &svStream{comma: '\t'}
+}
+
+// csvStream parses the input with each of the csvDelimiters at once.
+type csvStream struct {
+	delimited [len(csvDelimiters)]svStream
+}
+
+func (s *csvStream) Write(p []byte) bool {
+	ok := false
+	for i := range s.delimited {
+		if s.delimited[i].Write(p) {
+			ok = true
+		}
+	}
 
-	lines, err := r.ReadAll()
-	return err == nil && r.FieldsPerRecord > 1 && len(lines) > 1
+	return ok
 }
 
-// butLastLineReader returns a reader to the provided byte slice.
-// the reader is guaranteed to reach EOF before it reads `cutAt` bytes.
-// bytes after the last newline are dropped from the input.
-func butLastLineReader(in []byte, cutAt int) io.Reader {
-	if len(in) >= cutAt {
-		for i := cutAt - 1; i > 0; i-- {
-			if in[i] == '\n' {
-				return bytes.NewReader(in[:i])
+func (s *csvStream) Match() bool {
+	for i := range s.delimited {
+		if s.delimited[i].Match() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The states of svStream.
+const (
+	svRecordStart = iota // at the start of a line, between records
+	svComment            // in a comment line
+	svFieldStart         // at the start of a field
+	svField              // in an unquoted field
+	svQuoted             // in a quoted field
+	svQuote              // after a quote in a quoted field
+)
+
+// svStream parses delimiter-separated records the way encoding/csv does
+// with LazyQuotes, TrimLeadingSpace and '#' as the comment character: empty
+// lines and comment lines are skipped, "\r\n" ends lines like "\n" does,
+// quoted fields may span lines and stray quotes are kept as they are.
+// All the records must have as many fields as the first one.
+//
+// Inputs reaching the read limit are truncated, so the record cut short
+// by the limit is ignored.
+type svStream struct {
+	comma     byte
+	state     int
+	cr        bool // the last byte fed was a carriage return
+	fields    int  // fields of the current record
+	perRecord int  // fields of the first record
+	records   int
+	fed       int
+	rejected  bool
+}
+
+func (s *svStream) Write(p []byte) bool {
+	if s.rejected {
+		return false
+	}
+	if s.fed+len(p) > ReadLimit {
+		p = p[:ReadLimit-s.fed]
+	}
+	s.fed += len(p)
+	for _, b := range p {
+		if s.cr {
+			s.cr = false
+			if b != '\n' {
+				s.step('\r')
 			}
 		}
+		if b == '\r' {
+			s.cr = true
+			continue
+		}
+		s.step(b)
+		if s.rejected {
+			return false
+		}
+	}
+
+	return true
+}
 
-		// no newline was found between the 0 index and cutAt
-		return bytes.NewReader(in[:cutAt])
+// step advances the parser by one byte, with "\r\n" already turned into "\n".
+func (s *svStream) step(b byte) {
+	switch s.state {
+	case svRecordStart:
+		switch b {
+		case '\n':
+		case '#':
+			s.state = svComment
+		default:
+			s.state = svFieldStart
+			s.step(b)
+		}
+	case svComment:
+		if b == '\n' {
+			s.state = svRecordStart
+		}
+	case svFieldStart:
+		switch {
+		case b == '\n':
+			s.endRecord()
+		case b == s.comma && !isSpaceByte(b):
+			s.fields++
+		case isSpaceByte(b):
+		case b == '"':
+			s.state = svQuoted
+		default:
+			s.state = svField
+		}
+	case svField:
+		switch b {
+		case '\n':
+			s.endRecord()
+		case s.comma:
+			s.fields++
+			s.state = svFieldStart
+		}
+	case svQuoted:
+		if b == '"' {
+			s.state = svQuote
+		}
+	case svQuote:
+		switch b {
+		case '\n':
+			s.endRecord()
+		case s.comma:
+			s.fields++
+			s.state = svFieldStart
+		default:
+			// An escaped quote, or a stray one followed by the field.
+			s.state = svQuoted
+		}
 	}
+}
+
+// isSpaceByte reports whether b is trimmed from the start of the fields.
+// Like the delimiter, the line feed is handled by the caller.
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\v' || b == '\f' || b == '\r'
+}
 
-	return bytes.NewReader(in)
+func (s *svStream) endRecord() {
+	s.fields++
+	s.records++
+	if s.records == 1 {
+		s.perRecord = s.fields
+	}
+	// Records having a single field do not make a delimited file.
+	if s.fields != s.perRecord || s.perRecord < 2 {
+		s.rejected = true
+	}
+	s.fields = 0
+	s.state = svRecordStart
+}
+
+// finish ends the last record, unless it was cut short by the read limit,
+// and reports whether all the records have the same number of fields.
+func (s *svStream) finish() bool {
+	if s.rejected {
+		return false
+	}
+	if s.state != svRecordStart && s.state != svComment && s.fed < ReadLimit {
+		s.endRecord()
+	}
+
+	return !s.rejected
+}
+
+func (s *svStream) Match() bool {
+	if !s.finish() || s.records < 2 {
+		return false
+	}
+	// Semicolons also punctuate prose, so two lines holding one each
+	// are not enough to tell a delimited file.
+	return s.comma != ';' || s.records >= 3 || s.perRecord >= 3
 }
@@ -0,0 +1,83 @@
+package matchers
+
+import "bytes"
+
+// The score functions rate, between 0 and 1, how likely an input which
+// already passed the matcher of a text format is to be of that format.
+// They are used to choose between text formats whose matchers all pass,
+// like a tab-separated file having a comma in each of its lines.
+
+// CsvScore rates a comma-separated values file, by its inferred delimiter.
+func CsvScore(in []byte) float64 {
+	comma := CsvDelimiter(in)
+	if comma == 0 {
+		return 0
+	}
+
+	return svScore(in, comma)
+}
+
+// TsvScore rates a tab-separated values file.
+func TsvScore(in []byte) float64 {
+	return svScore(in, '\t')
+}
+
+// svScore grows with the number of fields of the records: a delimiter which
+// consistently splits the lines in many fields is more likely to be the
+// actual delimiter than one splitting them in two.
+func svScore(in []byte, comma byte) float64 {
+	s := svStream{comma: comma}
+	if !s.Write(in) || !s.finish() || s.perRecord < 2 {
+		return 0
+	}
+
+	return 1 - 1/float64(s.perRecord)
+}
+
+// NdJsonScore rates a newline delimited JSON file. Each line being a valid
+// JSON value is stronger evidence than any delimiter consistency.
+func NdJsonScore(in []byte) float64 {
+	return 1
+}
+
+// BrfScore rates a Braille Ready Format file. Its lines often split alike on
+// commas, but the braille indicators it requires are stronger evidence than
+// any delimiter consistency.
+func BrfScore(in []byte) float64 {
+	return 1
+}
+
+// HtmlScore rates an HTML document. Documents starting with the doctype or
+// the html element are certain, the other ones are rated by the number of
+// their closing tags.
+func HtmlScore(in []byte) float64 {
+	in, _ = skipHtmlPrologue(in)
+	if isHtmlDoctype(in) || markupSig("<HTML").detect(in) {
+		return 1
+	}
+	closing := bytes.Count(in, []byte("</"))
+	if closing > 8 {
+		closing = 8
+	}
+
+	return 0.8 + 0.2*float64(closing)/8
+}
+
+// PhpScore rates a PHP file. The short open tag is also used by other
+// templating languages, so it is rated lower than the full one.
+func PhpScore(in []byte) float64 {
+	if ciSig("<?PHP").detect(in) || bytes.HasPrefix(in, []byte("#!")) {
+		return 1
+	}
+	if bytes.Contains(in, []byte("$")) && bytes.Contains(in, []byte(";")) {
+		return 0.8
+	}
+
+	return 0.5
+}
+
+// JsScore rates a JavaScript file. Only scripts with a node shebang are
+// matched, so the rate is based on the interpreter.
+func JsScore(in []byte) float64 {
+	return 1
+}
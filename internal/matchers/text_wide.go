@@ -0,0 +1,128 @@
+package matchers
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// wideSample is the number of leading bytes examined to recognize wide text
+// without a byte order mark.
+const wideSample = 256
+
+// WideCharset returns the charset of inputs holding text encoded in UTF-16
+// or UTF-32, recognized by their byte order mark or, without one, by the
+// NUL bytes interleaved with the ASCII characters: "utf-16le", "utf-16be",
+// "utf-32le" or "utf-32be". It returns an empty string for other inputs.
+func WideCharset(in []byte) string {
+	switch {
+	// The UTF-32LE mark starts with the UTF-16LE one.
+	case len(in) >= 4 && in[0] == 0xFF && in[1] == 0xFE && in[2] == 0 && in[3] == 0:
+		return "utf-32le"
+	case len(in) >= 4 && in[0] == 0 && in[1] == 0 && in[2] == 0xFE && in[3] == 0xFF:
+		return "utf-32be"
+	case len(in) >= 2 && in[0] == 0xFF && in[1] == 0xFE:
+		return "utf-16le"
+	case len(in) >= 2 && in[0] == 0xFE && in[1] == 0xFF:
+		return "utf-16be"
+	}
+	if len(in) > wideSample {
+		in = in[:wideSample]
+	}
+	switch {
+	case interleaved(in, 4, 0):
+		return "utf-32le"
+	case interleaved(in, 4, 3):
+		return "utf-32be"
+	case interleaved(in, 2, 0):
+		return "utf-16le"
+	case interleaved(in, 2, 1):
+		return "utf-16be"
+	}
+
+	return ""
+}
+
+// interleaved reports whether in looks like ASCII text encoded in code
+// units of size bytes, the character being at offset low of each unit: at
+// least two units, the byte at low is never NUL, and nine units out of ten
+// at least hold a printable ASCII character or a space, leaving room for
+// some other characters.
+func interleaved(in []byte, size, low int) bool {
+	units := len(in) / size
+	if units < 2 {
+		return false
+	}
+	ascii := 0
+	for i := 0; i < units; i++ {
+		u := in[i*size : i*size+size]
+		if u[low] == 0 {
+			return false
+		}
+		zeros := 0
+		for j, b := range u {
+			if j != low && b == 0 {
+				zeros++
+			}
+		}
+		if c := u[low]; zeros == size-1 && (0x20 <= c && c <= 0x7E || 0x09 <= c && c <= 0x0D) {
+			ascii++
+		}
+	}
+
+	return ascii*10 >= units*9
+}
+
+// DecodeWide returns the UTF-8 encoding of in, without its byte order mark,
+// when in holds text encoded in UTF-16 or UTF-32, as told by WideCharset, so
+// the text matchers can be used on it. Other inputs are returned unchanged.
+// A code unit cut short by the end of the input is left out.
+func DecodeWide(in []byte) []byte {
+	cs := WideCharset(in)
+	if cs == "" {
+		return in
+	}
+	size, order := wideUnit(cs)
+	if len(in) >= size && unitAt(in, size, order) == 0xFEFF {
+		in = in[size:]
+	}
+
+	out := make([]byte, 0, len(in)/size)
+	var buf [utf8.UTFMax]byte
+	for i := 0; i+size <= len(in); i += size {
+		r := rune(unitAt(in[i:], size, order))
+		if size == 2 && utf16.IsSurrogate(r) && i+2*size <= len(in) {
+			if d := utf16.DecodeRune(r, rune(unitAt(in[i+size:], size, order))); d != utf8.RuneError {
+				r = d
+				i += size
+			}
+		}
+		n := utf8.EncodeRune(buf[:], r)
+		out = append(out, buf[:n]...)
+	}
+
+	return out
+}
+
+// wideUnit returns the size of the code units of the wide charset cs and
+// their byte order.
+func wideUnit(cs string) (int, binary.ByteOrder) {
+	switch cs {
+	case "utf-32le":
+		return 4, binary.LittleEndian
+	case "utf-32be":
+		return 4, binary.BigEndian
+	case "utf-16be":
+		return 2, binary.BigEndian
+	}
+
+	return 2, binary.LittleEndian
+}
+
+func unitAt(in []byte, size int, order binary.ByteOrder) uint32 {
+	if size == 2 {
+		return uint32(order.Uint16(in))
+	}
+
+	return order.Uint32(in)
+}
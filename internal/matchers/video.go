@@ -69,3 +69,24 @@ func Asf(in []byte) bool {
 		0xA6, 0xD9, 0x00, 0xAA, 0x00, 0x62, 0xCE, 0x6C,
 	})
 }
+
+// ASF stream type GUIDs, as stored in the stream properties objects.
+var (
+	asfAudioMedia = []byte{0x40, 0x9E, 0x69, 0xF8, 0x4D, 0x5B, 0xCF, 0x11, 0xA8, 0xFD, 0x00, 0x80, 0x5F, 0x5C, 0x44, 0x2B}
+	asfVideoMedia = []byte{0xC0, 0xEF, 0x19, 0xBC, 0x4D, 0x5B, 0xCF, 0x11, 0xA8, 0xFD, 0x00, 0x80, 0x5F, 0x5C, 0x44, 0x2B}
+)
+
+// Wmv matches an ASF file holding at least one video stream.
+func Wmv(in []byte) bool {
+	return bytes.Contains(in, asfVideoMedia)
+}
+
+// Wma matches an ASF file holding audio streams only.
+func Wma(in []byte) bool {
+	return bytes.Contains(in, asfAudioMedia) && !bytes.Contains(in, asfVideoMedia)
+}
+
+// RealMedia matches a RealMedia file.
+func RealMedia(in []byte) bool {
+	return bytes.HasPrefix(in, []byte(".RMF"))
+}
@@ -0,0 +1,236 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// peHeaders returns the COFF file header and the optional header of a PE
+// image, or false if the input is too short to hold them.
+func peHeaders(in []byte) (coff, opt []byte, ok bool) {
+	if len(in) < 0x40 {
+		return nil, nil, false
+	}
+	pe := int(binary.LittleEndian.Uint32(in[0x3C:]))
+	if pe < 0 || pe+24 > len(in) || !bytes.Equal(in[pe:pe+4], []byte("PE\x00\x00")) {
+		return nil, nil, false
+	}
+	coff, opt = in[pe+4:pe+24], in[pe+24:]
+	if len(opt) < 2 {
+		return nil, nil, false
+	}
+
+	return coff, opt, true
+}
+
+// peSubsystem returns the subsystem required to run a PE image, stored
+// at the same offset in the optional headers of PE32 and PE32+ files.
+func peSubsystem(opt []byte) (uint16, bool) {
+	if len(opt) < 70 {
+		return 0, false
+	}
+	switch binary.LittleEndian.Uint16(opt) {
+	case 0x10B, 0x20B:
+		return binary.LittleEndian.Uint16(opt[68:]), true
+	}
+
+	return 0, false
+}
+
+// WindowsDriver matches a Windows kernel-mode driver (.sys), a PE image
+// built for the native subsystem, loaded by the kernel instead of running
+// in a Win32 process. The few native user-mode programs, like smss.exe,
+// are matched too.
+func WindowsDriver(in []byte) bool {
+	_, opt, ok := peHeaders(in)
+	if !ok {
+		return false
+	}
+	subsystem, ok := peSubsystem(opt)
+
+	return ok && subsystem == 1
+}
+
+// peMachines maps the machine types of PE images to the architecture names
+// used by driver packages.
+var peMachines = map[uint16]string{
+	0x014C: "x86",
+	0x8664: "amd64",
+	0x01C4: "arm",
+	0xAA64: "arm64",
+}
+
+// WindowsDriverMeta extracts the architecture a driver is built for.
+func WindowsDriverMeta(in []byte) map[string]string {
+	coff, _, ok := peHeaders(in)
+	if !ok {
+		return nil
+	}
+	if arch, ok := peMachines[binary.LittleEndian.Uint16(coff)]; ok {
+		return map[string]string{"architecture": arch}
+	}
+
+	return nil
+}
+
+// Mui matches a Windows Multilingual User Interface resource file (.mui),
+// the resource-only DLL holding the localized resources of an executable.
+// It has neither code nor entry point, and its resources include one of
+// the named type "MUI", describing the language of the file.
+func Mui(in []byte) bool {
+	coff, opt, ok := peHeaders(in)
+	// IMAGE_FILE_DLL is set in the characteristics of the file header.
+	if !ok || binary.LittleEndian.Uint16(coff[18:])&0x2000 == 0 || len(opt) < 20 {
+		return false
+	}
+	sizeOfCode, entryPoint := binary.LittleEndian.Uint32(opt[4:]), binary.LittleEndian.Uint32(opt[16:])
+	if sizeOfCode != 0 || entryPoint != 0 {
+		return false
+	}
+	rsrc := peSection(in, coff, opt, ".rsrc")
+	if rsrc == nil {
+		return false
+	}
+
+	return hasNamedResourceType(rsrc, "MUI")
+}
+
+// peSection returns the raw data of the section named name, cut at the end
+// of the input, or nil if there is no such section in the input.
+func peSection(in, coff, opt []byte, name string) []byte {
+	numSections := int(binary.LittleEndian.Uint16(coff[2:]))
+	sizeOfOpt := int(binary.LittleEndian.Uint16(coff[16:]))
+	if sizeOfOpt > len(opt) {
+		return nil
+	}
+	table := opt[sizeOfOpt:]
+	for i := 0; i < numSections && len(table) >= 40*(i+1); i++ {
+		hdr := table[40*i : 40*(i+1)]
+		if string(bytes.TrimRight(hdr[:8], "\x00")) != name {
+			continue
+		}
+		off := int(binary.LittleEndian.Uint32(hdr[20:]))
+		if off <= 0 || off >= len(in) {
+			return nil
+		}
+		return in[off:]
+	}
+
+	return nil
+}
+
+// hasNamedResourceType reports whether the root of the resource directory
+// rsrc has an entry for the resource type named name. Named entries come
+// before the ones identified by an integer, and their names are counted
+// UTF-16 strings stored in the resource section.
+func hasNamedResourceType(rsrc []byte, name string) bool {
+	if len(rsrc) < 16 {
+		return false
+	}
+	named := int(binary.LittleEndian.Uint16(rsrc[12:]))
+	for i := 0; i < named; i++ {
+		e := 16 + 8*i
+		if e+8 > len(rsrc) {
+			return false
+		}
+		off := int(binary.LittleEndian.Uint32(rsrc[e:]) &^ 0x80000000)
+		if off+2+2*len(name) > len(rsrc) ||
+			int(binary.LittleEndian.Uint16(rsrc[off:])) != len(name) {
+			continue
+		}
+		if utf16Equal(rsrc[off+2:], name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// utf16Equal reports whether in starts with the UTF-16LE encoding of the
+// ASCII string s.
+func utf16Equal(in []byte, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if in[2*i] != s[i] || in[2*i+1] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// infSignatures are the values of the Signature key of the [Version] section
+// of setup information files, which name the operating systems they target.
+var infSignatures = []string{`$WINDOWS NT$`, `$CHICAGO$`, `$WINDOWS 95$`}
+
+// WindowsInf matches a Windows setup information file (.inf), describing
+// how to install a driver. The first section of these files is [Version],
+// whose Signature key names the targeted operating system family.
+// Only the files encoded in ASCII or UTF-8 are matched: the UTF-16 ones,
+// common among inbox drivers, do not pass the text matcher.
+func WindowsInf(in []byte) bool {
+	return infVersionValue(in, "Signature") != nil
+}
+
+// WindowsInfMeta extracts the signature and the setup class of the devices
+// installed by the file, like "Net" or "Display".
+func WindowsInfMeta(in []byte) map[string]string {
+	meta := map[string]string{}
+	if sig := infVersionValue(in, "Signature"); sig != nil {
+		meta["signature"] = string(sig)
+	}
+	setNonEmpty(meta, "class", string(infVersionValue(in, "Class")))
+	if len(meta) == 0 {
+		return nil
+	}
+
+	return meta
+}
+
+// infVersionValue returns the unquoted value of key in the [Version] section,
+// which must be the first one of the file. For the Signature key, the value
+// must be one of the known signatures.
+func infVersionValue(in []byte, key string) []byte {
+	in = bytes.TrimPrefix(in, []byte{0xEF, 0xBB, 0xBF})
+	inVersion := false
+	for len(in) > 0 {
+		line := firstLine(in)
+		in = in[len(line):]
+		if len(in) > 0 {
+			in = in[1:]
+		}
+		line = trimLWS(bytes.TrimRight(line, " \t\r"))
+		if len(line) == 0 || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if inVersion || !bytes.EqualFold(line, []byte("[Version]")) {
+				return nil
+			}
+			inVersion = true
+			continue
+		}
+		if !inVersion {
+			return nil
+		}
+		eq := bytes.IndexByte(line, '=')
+		if eq == -1 || !bytes.EqualFold(bytes.TrimSpace(line[:eq]), []byte(key)) {
+			continue
+		}
+		value := bytes.TrimSpace(line[eq+1:])
+		if c := bytes.IndexByte(value, ';'); c != -1 {
+			value = bytes.TrimSpace(value[:c])
+		}
+		value = bytes.Trim(value, `"`)
+		if key != "Signature" {
+			return value
+		}
+		for _, s := range infSignatures {
+			if bytes.EqualFold(value, []byte(s)) {
+				return value
+			}
+		}
+		return nil
+	}
+
+	return nil
+}
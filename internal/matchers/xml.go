@@ -0,0 +1,284 @@
+package matchers
+
+import (
+	"bytes"
+	"sync"
+)
+
+// xmlRoot locates the start tag of the root element of an XML document.
+// The offsets are relative to the beginning of the document.
+type xmlRoot struct {
+	tagStart, nameEnd, tagEnd int
+	// closed is false when the input ends inside the start tag.
+	closed bool
+}
+
+// name returns the qualified name of the root element, like "gml:Point".
+func (r xmlRoot) name(in []byte) []byte {
+	return in[r.tagStart+1 : r.nameEnd]
+}
+
+// localName returns the name of the root element, without its prefix.
+func (r xmlRoot) localName(in []byte) []byte {
+	n := r.name(in)
+	if i := bytes.IndexByte(n, ':'); i != -1 {
+		return n[i+1:]
+	}
+
+	return n
+}
+
+// tag returns the root start tag, including its attributes. The tag is
+// truncated if the input ends before the closing angle bracket.
+func (r xmlRoot) tag(in []byte) []byte {
+	return in[r.tagStart:r.tagEnd]
+}
+
+// attr returns the value of the attribute of the root element having the
+// provided qualified name, or nil if the attribute is not present.
+func (r xmlRoot) attr(in []byte, name string) []byte {
+	tag := r.tag(in)
+	for off := r.nameEnd - r.tagStart; ; {
+		i := bytes.Index(tag[off:], []byte(name))
+		if i == -1 {
+			return nil
+		}
+		off += i
+		rest := tag[off+len(name):]
+		if !isWS(tag[off-1]) || len(rest) < 2 || rest[0] != '=' || rest[1] != '"' && rest[1] != '\'' {
+			off += len(name)
+			continue
+		}
+		if end := bytes.IndexByte(rest[2:], rest[1]); end != -1 {
+			return rest[2 : 2+end]
+		}
+		return nil
+	}
+}
+
+// namespace returns the URI of the namespace of the root element, bound to
+// its prefix, or the default namespace for an unprefixed root. It returns
+// nil when the root element does not declare it, in which case the root is
+// in no namespace, or in one declared by the enclosing document.
+func (r xmlRoot) namespace(in []byte) []byte {
+	var prefix []byte
+	if n := r.name(in); len(n) > len(r.localName(in)) {
+		prefix = n[:len(n)-len(r.localName(in))-1]
+	}
+	tag := r.tag(in)
+	for off := r.nameEnd - r.tagStart; ; {
+		name, value, next, ok := nextAttr(tag, off)
+		if !ok {
+			return nil
+		}
+		if p, ok := declaredPrefix(name); ok && bytes.Equal(p, prefix) {
+			return value
+		}
+		off = next
+	}
+}
+
+// declares reports whether the root element declares a namespace matching
+// uri, whatever its prefix, as told by nsMatch.
+func (r xmlRoot) declares(in, uri []byte) bool {
+	tag := r.tag(in)
+	for off := r.nameEnd - r.tagStart; ; {
+		name, value, next, ok := nextAttr(tag, off)
+		if !ok {
+			return false
+		}
+		if _, ok := declaredPrefix(name); ok && nsMatch(value, uri) {
+			return true
+		}
+		off = next
+	}
+}
+
+// declaredPrefix returns the prefix bound by the attribute having the
+// qualified name, empty for the default namespace, and false if the
+// attribute is not a namespace declaration.
+func declaredPrefix(name []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(name, []byte("xmlns")) {
+		return nil, false
+	}
+	switch rest := name[len("xmlns"):]; {
+	case len(rest) == 0:
+		return nil, true
+	case rest[0] == ':':
+		return rest[1:], true
+	}
+
+	return nil, false
+}
+
+// nsMatch reports whether the namespace URI ns is uri. A uri ending with a
+// slash also matches the versions of the namespace below it, like
+// "http://www.loc.gov/mix/v20" for "http://www.loc.gov/mix/".
+func nsMatch(ns, uri []byte) bool {
+	if uri[len(uri)-1] == '/' {
+		return bytes.HasPrefix(ns, uri)
+	}
+
+	return bytes.Equal(ns, uri)
+}
+
+// nextAttr returns the qualified name and the value of the attribute found
+// at offset off of a start tag, and the offset following it. ok is false when
+// the tag has no more attributes, or ends within the attribute.
+func nextAttr(tag []byte, off int) (name, value []byte, next int, ok bool) {
+	skipWS := func(i int) int {
+		for i < len(tag) && isWS(tag[i]) {
+			i++
+		}
+		return i
+	}
+	start := skipWS(off)
+	end := start
+	for end < len(tag) && !isWS(tag[end]) && tag[end] != '=' && tag[end] != '>' && tag[end] != '/' {
+		end++
+	}
+	if end == start {
+		return nil, nil, 0, false
+	}
+	i := skipWS(end)
+	if i == len(tag) || tag[i] != '=' {
+		return nil, nil, 0, false
+	}
+	i = skipWS(i + 1)
+	if i == len(tag) || tag[i] != '"' && tag[i] != '\'' {
+		return nil, nil, 0, false
+	}
+	closing := bytes.IndexByte(tag[i+1:], tag[i])
+	if closing == -1 {
+		return nil, nil, 0, false
+	}
+
+	return tag[start:end], tag[i+1 : i+1+closing], i + 2 + closing, true
+}
+
+// parseXmlRoot finds the root start tag of in, skipping the prolog: the XML
+// declaration, the processing instructions, the comments and the doctype.
+func parseXmlRoot(in []byte) (xmlRoot, bool) {
+	for off := 0; off < len(in); {
+		i := bytes.IndexByte(in[off:], '<')
+		if i == -1 {
+			return xmlRoot{}, false
+		}
+		off += i
+		rest := in[off:]
+
+		var end int
+		switch {
+		case bytes.HasPrefix(rest, []byte("<?")):
+			end = indexEnd(rest, []byte("?>"))
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			end = indexEnd(rest, []byte("-->"))
+		case bytes.HasPrefix(rest, []byte("<!")):
+			end = doctypeEnd(rest)
+		default:
+			return rootTag(in, off)
+		}
+		if end == -1 {
+			return xmlRoot{}, false
+		}
+		off += end
+	}
+
+	return xmlRoot{}, false
+}
+
+// rootTag returns the location of the start tag beginning at off.
+func rootTag(in []byte, off int) (xmlRoot, bool) {
+	r := xmlRoot{tagStart: off, nameEnd: off + 1}
+	for ; r.nameEnd < len(in); r.nameEnd++ {
+		if b := in[r.nameEnd]; isWS(b) || b == '>' || b == '/' {
+			break
+		}
+	}
+	if r.nameEnd == off+1 || r.nameEnd == len(in) {
+		return xmlRoot{}, false
+	}
+
+	var quote byte
+	for r.tagEnd = r.nameEnd; r.tagEnd < len(in); r.tagEnd++ {
+		switch b := in[r.tagEnd]; {
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == '>':
+			r.tagEnd++
+			r.closed = true
+			return r, true
+		}
+	}
+
+	return r, true
+}
+
+// doctypeEnd returns the offset following the doctype declaration at the
+// beginning of in, which can hold an internal subset between brackets.
+func doctypeEnd(in []byte) int {
+	var quote byte
+	depth := 0
+	for i, b := range in {
+		switch {
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == '[':
+			depth++
+		case b == ']':
+			depth--
+		case b == '>' && depth <= 0:
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// indexEnd returns the offset following the first occurrence of sep in in,
+// or -1 if sep is not present.
+func indexEnd(in, sep []byte) int {
+	if i := bytes.Index(in, sep); i != -1 {
+		return i + len(sep)
+	}
+
+	return -1
+}
+
+// xmlRootMemo holds the root element found in the last parsed document.
+// The matchers of the XML subtypes are all tried on the same input, so they
+// share it instead of each scanning the input again. The memo is keyed by
+// the bytes the root was found in, so it is also valid for callers reusing
+// the same buffer for different inputs.
+var xmlRootMemo struct {
+	sync.Mutex
+	head []byte
+	root xmlRoot
+}
+
+// xmlRootOf returns the location of the root start tag of in.
+func xmlRootOf(in []byte) (xmlRoot, bool) {
+	m := &xmlRootMemo
+	m.Lock()
+	defer m.Unlock()
+
+	if len(m.head) > 0 && bytes.HasPrefix(in, m.head) {
+		return m.root, true
+	}
+	r, ok := parseXmlRoot(in)
+	// Truncated tags are not memoized, as a longer input can complete them.
+	if ok && r.closed {
+		m.head = append(m.head[:0], in[:r.tagEnd]...)
+		m.root = r
+	}
+
+	return r, ok
+}
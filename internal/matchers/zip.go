@@ -0,0 +1,245 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+)
+
+// zipEntry is a file stored in a zip archive, as described by its local file header.
+type zipEntry struct {
+	name []byte
+	// data is the content of the entry, if it is stored without
+	// compression and fits in the input; nil otherwise.
+	data []byte
+}
+
+// appendZipEntries parses, in a single pass, the local file headers found in
+// the input and appends them to entries. The central directory is located at
+// the end of the archive, so it is not available when detecting the head of
+// a file.
+func appendZipEntries(entries []zipEntry, in []byte) []zipEntry {
+	sig := []byte("PK\x03\x04")
+	for {
+		i := bytes.Index(in, sig)
+		if i == -1 || len(in)-i < 30 {
+			return entries
+		}
+		in = in[i:]
+		method := binary.LittleEndian.Uint16(in[8:10])
+		compSize := int(binary.LittleEndian.Uint32(in[18:22]))
+		nameLen := int(binary.LittleEndian.Uint16(in[26:28]))
+		extraLen := int(binary.LittleEndian.Uint16(in[28:30]))
+		if 30+nameLen > len(in) {
+			return entries
+		}
+		e := zipEntry{name: in[30 : 30+nameLen]}
+		dataStart := 30 + nameLen + extraLen
+		if method == 0 && compSize >= 0 && dataStart+compSize <= len(in) {
+			e.data = in[dataStart : dataStart+compSize]
+		}
+		entries = append(entries, e)
+		in = in[30+nameLen:]
+	}
+}
+
+// zipScratch holds the entries of the last input parsed, along with a copy
+// of that input the entries point to. All the children of the zip node are
+// tried on the same input, so they share the entries instead of each
+// parsing the archive again.
+type zipScratch struct {
+	in      []byte
+	entries []zipEntry
+	parsed  bool
+}
+
+// zipScratches keeps the scratches between the calls of the matchers, so the
+// entries are parsed once per input and the slices holding them are reused.
+var zipScratches = sync.Pool{
+	New: func() interface{} { return &zipScratch{} },
+}
+
+// maxZipScratch is the size above which the copy of the input is not kept
+// in the pool, so inputs read with a large limit are not held in memory.
+const maxZipScratch = 1 << 20
+
+// withZipEntries calls f with the entries of the archive in. The entries
+// parsed for the previous input are reused when the inputs are equal, which
+// compares them byte for byte: inputs stored in a reused buffer may have the
+// same address but another content.
+func withZipEntries(in []byte, f func([]zipEntry) bool) bool {
+	// No local file header fits in shorter inputs.
+	if len(in) < 30 {
+		return f(nil)
+	}
+	s := zipScratches.Get().(*zipScratch)
+	if !s.parsed || !bytes.Equal(s.in, in) {
+		s.in = append(s.in[:0], in...)
+		s.entries = appendZipEntries(s.entries[:0], s.in)
+		s.parsed = true
+	}
+	ok := f(s.entries)
+	if cap(s.in) <= maxZipScratch {
+		zipScratches.Put(s)
+	}
+
+	return ok
+}
+
+// zipSig matches zip based formats by the files contained in the archive.
+// All the non-empty fields must match.
+type zipSig struct {
+	// mimetype is the required prefix of the content of the "mimetype" file.
+	// ODF, EPUB and other formats store it uncompressed as the first entry.
+	mimetype string
+	// entries lists file names which must be present in the archive.
+	// A name ending in "/" matches any file in that directory and
+	// a name starting with "*" matches any file with that suffix.
+	entries []string
+	// foldCase makes the entries match names differing in ASCII case.
+	foldCase bool
+}
+
+// zipSigs is a list of alternative zipSig for the same format.
+// The archive is parsed once for all of them.
+type zipSigs []zipSig
+
+// Implement sig interface.
+func (zs zipSigs) detect(in []byte) bool {
+	return withZipEntries(in, zs.match)
+}
+
+func (zs zipSigs) match(entries []zipEntry) bool {
+	for _, z := range zs {
+		if z.match(entries) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (z zipSig) match(entries []zipEntry) bool {
+	if z.mimetype != "" {
+		// The mimetype file must be the first entry of the archive.
+		if len(entries) == 0 || string(entries[0].name) != "mimetype" ||
+			!bytes.HasPrefix(entries[0].data, []byte(z.mimetype)) {
+			return false
+		}
+	}
+	for _, want := range z.entries {
+		if !hasZipEntry(entries, want, z.foldCase) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasZipEntry(entries []zipEntry, pattern string, foldCase bool) bool {
+	p := []byte(pattern)
+	equal := bytes.Equal
+	if foldCase {
+		equal = bytes.EqualFold
+	}
+	for _, e := range entries {
+		switch {
+		case p[0] == '*':
+			if len(e.name) >= len(p)-1 && equal(e.name[len(e.name)-len(p)+1:], p[1:]) {
+				return true
+			}
+		case p[len(p)-1] == '/':
+			if len(e.name) >= len(p) && equal(e.name[:len(p)], p) {
+				return true
+			}
+		default:
+			if equal(e.name, p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var (
+	epubSigs    = zipSigs{{mimetype: "application/epub+zip"}}
+	jarSigs     = zipSigs{{entries: []string{"META-INF/MANIFEST.MF"}}}
+	apkSigs     = zipSigs{{entries: []string{"AndroidManifest.xml"}}}
+	ipaSigs     = zipSigs{{entries: []string{"Payload/"}}}
+	kmzSigs     = zipSigs{{entries: []string{"doc.kml"}}}
+	oraSigs     = zipSigs{{mimetype: "image/openraster"}}
+	takeoutSigs = zipSigs{{entries: []string{"Takeout/archive_browser.html"}}}
+	iCloudSigs  = zipSigs{
+		{entries: []string{"iCloud Photos/"}},
+		{entries: []string{"iCloud Drive/"}},
+		{entries: []string{"iCloud Contacts/"}},
+		{entries: []string{"iCloud Calendars and Reminders/"}},
+		{entries: []string{"iCloud Notes/"}},
+		{entries: []string{"iCloud Mail/"}},
+	}
+	// DAISY 2.02 books contain a navigation control center file named ncc.html,
+	// while DAISY 3 books contain a navigation control file for XML applications.
+	// Both are often named in upper case, by tools writing to FAT file systems.
+	daisySigs = zipSigs{
+		{entries: []string{"ncc.html"}, foldCase: true},
+		{entries: []string{"*/ncc.html"}, foldCase: true},
+		{entries: []string{"*.ncx"}, foldCase: true},
+	}
+	epubOverlaySigs = zipSigs{{entries: []string{"*.smil"}}}
+)
+
+// Epub matches an EPUB file.
+func Epub(in []byte) bool {
+	return epubSigs.detect(in)
+}
+
+// EpubMeta extracts the metadata of an EPUB file.
+// "media-overlays" is set when the book synchronizes text with audio using SMIL files.
+func EpubMeta(in []byte) map[string]string {
+	if epubOverlaySigs.detect(in) || bytes.Contains(in, []byte("media-overlay=")) {
+		return map[string]string{"media-overlays": "true"}
+	}
+
+	return nil
+}
+
+// Jar matches a Java archive file.
+func Jar(in []byte) bool {
+	return jarSigs.detect(in)
+}
+
+// Apk matches an Android application package file.
+func Apk(in []byte) bool {
+	return apkSigs.detect(in)
+}
+
+// Ipa matches an iOS application archive file.
+func Ipa(in []byte) bool {
+	return ipaSigs.detect(in)
+}
+
+// Kmz matches a zipped Keyhole Markup Language file.
+func Kmz(in []byte) bool {
+	return kmzSigs.detect(in)
+}
+
+// Ora matches an OpenRaster image file.
+func Ora(in []byte) bool {
+	return oraSigs.detect(in)
+}
+
+// Takeout matches a Google Takeout export archive.
+func Takeout(in []byte) bool {
+	return takeoutSigs.detect(in)
+}
+
+// ICloud matches an iCloud data export archive, as obtained from privacy.apple.com.
+func ICloud(in []byte) bool {
+	return iCloudSigs.detect(in)
+}
+
+// Daisy matches a DAISY digital talking book packaged as a zip archive.
+func Daisy(in []byte) bool {
+	return daisySigs.detect(in)
+}
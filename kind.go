@@ -0,0 +1,135 @@
+package mimetype
+
+import "strings"
+
+// Kind is the broad category of a file format. It allows routing files
+// without keeping lists of MIME types in the application.
+type Kind int
+
+// The kinds of file formats.
+const (
+	KindUnknown Kind = iota
+	KindText
+	KindImage
+	KindAudio
+	KindVideo
+	KindArchive
+	KindDocument
+	KindFont
+	KindExecutable
+	KindDatabase
+)
+
+var kindNames = [...]string{
+	KindUnknown:    "unknown",
+	KindText:       "text",
+	KindImage:      "image",
+	KindAudio:      "audio",
+	KindVideo:      "video",
+	KindArchive:    "archive",
+	KindDocument:   "document",
+	KindFont:       "font",
+	KindExecutable: "executable",
+	KindDatabase:   "database",
+}
+
+// String returns the lowercase name of the kind.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return kindNames[KindUnknown]
+	}
+
+	return kindNames[k]
+}
+
+// kinds holds the kind of the formats which cannot be categorized
+// by the top-level type of their MIME type alone.
+var kinds = map[string]Kind{
+	"application/x-7z-compressed":           KindArchive,
+	"application/zip":                       KindArchive,
+	"application/x-tar":                     KindArchive,
+	"application/x-xar":                     KindArchive,
+	"application/x-archive":                 KindArchive,
+	"application/x-bzip2":                   KindArchive,
+	"application/gzip":                      KindArchive,
+	"application/x-xz":                      KindArchive,
+	"application/zstd":                      KindArchive,
+	"application/x-rar-compressed":          KindArchive,
+	"application/vnd.debian.binary-package": KindArchive,
+	"application/x-android-backup":          KindArchive,
+
+	"application/pdf":               KindDocument,
+	"application/postscript":        KindDocument,
+	"application/msword":            KindDocument,
+	"application/vnd.ms-excel":      KindDocument,
+	"application/vnd.ms-powerpoint": KindDocument,
+	"application/vnd.ms-publisher":  KindDocument,
+	"application/vnd.ms-outlook":    KindDocument,
+	"application/vnd.visio":         KindDocument,
+	"application/x-ooxml-encrypted": KindDocument,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   KindDocument,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         KindDocument,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": KindDocument,
+	"application/vnd.oasis.opendocument.text":                                   KindDocument,
+	"application/vnd.oasis.opendocument.text-template":                          KindDocument,
+	"application/vnd.oasis.opendocument.spreadsheet":                            KindDocument,
+	"application/vnd.oasis.opendocument.spreadsheet-template":                   KindDocument,
+	"application/vnd.oasis.opendocument.presentation":                           KindDocument,
+	"application/vnd.oasis.opendocument.presentation-template":                  KindDocument,
+	"application/vnd.oasis.opendocument.graphics":                               KindDocument,
+	"application/vnd.oasis.opendocument.graphics-template":                      KindDocument,
+	"application/vnd.oasis.opendocument.formula":                                KindDocument,
+	"application/epub+zip":                                                      KindDocument,
+	"application/x-mobipocket-ebook":                                            KindDocument,
+	"application/x-ms-reader":                                                   KindDocument,
+	"application/x-dtbook+xml":                                                  KindDocument,
+	"application/x-daisy+zip":                                                   KindDocument,
+	"text/rtf":                                                                  KindDocument,
+
+	"application/vnd.microsoft.portable-executable": KindExecutable,
+	"application/x-elf":                             KindExecutable,
+	"application/x-mach-binary":                     KindExecutable,
+	"application/x-java-applet":                     KindExecutable,
+	"application/wasm":                              KindExecutable,
+	"application/x-ms-installer":                    KindExecutable,
+	"application/vnd.android.package-archive":       KindExecutable,
+	"application/x-ios-app":                         KindExecutable,
+
+	"application/x-sqlite3":  KindDatabase,
+	"application/x-msaccess": KindDatabase,
+	"application/x-dbf":      KindDatabase,
+
+	"application/vnd.ms-fontobject": KindFont,
+
+	"application/vnd.rn-realmedia": KindVideo,
+
+	"application/json":       KindText,
+	"application/x-ndjson":   KindText,
+	"application/javascript": KindText,
+	"application/x-python":   KindText,
+}
+
+// kindOf returns the kind of node n. Formats without a kind of their own,
+// like most subtypes of zip or XML, get the kind of their closest ancestor.
+func kindOf(n *node) Kind {
+	for ; n != nil; n = n.parent {
+		mime := mediaType(n.mime)
+		if k, ok := kinds[mime]; ok {
+			return k
+		}
+		switch mime[:strings.IndexByte(mime+"/", '/')] {
+		case "text":
+			return KindText
+		case "image":
+			return KindImage
+		case "audio":
+			return KindAudio
+		case "video":
+			return KindVideo
+		case "font":
+			return KindFont
+		}
+	}
+
+	return KindUnknown
+}
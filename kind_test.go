@@ -0,0 +1,42 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	tcs := []struct {
+		file string
+		kind Kind
+	}{
+		{"png.png", KindImage},
+		{"mp4.mp4", KindVideo},
+		{"ogg.oga", KindAudio},
+		{"zip.zip", KindArchive},
+		{"jar.jar", KindArchive},
+		{"tar.gz.gz", KindArchive},
+		{"docx.docx", KindDocument},
+		{"pdf.pdf", KindDocument},
+		{"rss.rss", KindText},
+		{"json.json", KindText},
+		{"woff.woff", KindFont},
+		{"exe.exe", KindExecutable},
+		{"sqlite3.sqlite3", KindDatabase},
+		{"ogg.ogv", KindVideo},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k := DetectMIME(data).Kind(); k != tc.kind {
+			t.Errorf("%s: expected kind %s, got %s", tc.file, tc.kind, k)
+		}
+	}
+
+	if k := DetectMIME([]byte{0x00, 0x01, 0x02}).Kind(); k != KindUnknown {
+		t.Errorf("expected kind %s for unknown input, got %s", KindUnknown, k)
+	}
+}
@@ -0,0 +1,76 @@
+package mimetype
+
+import (
+	"bytes"
+	"compress/bzip2"
+	stdgzip "compress/gzip"
+	"io"
+)
+
+// unwrappers holds, for each MIME type wrapping other content,
+// the function returning a reader for the wrapped content.
+var unwrappers = map[string]func(io.Reader) (io.Reader, error){
+	Gzip: func(r io.Reader) (io.Reader, error) {
+		return stdgzip.NewReader(r)
+	},
+	Bz2: func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	},
+}
+
+// DetectLayers detects the MIME type of the input and, if the input wraps
+// other content, like a gzip stream does, it unwraps it and detects the
+// wrapped content too, for at most depth levels. The returned slice holds the
+// chain of detected types, starting with the outermost one. A .tar.gz file
+// is reported as application/gzip followed by application/x-tar.
+//
+// Only the head of the wrapped content is unwrapped. Since the input is usually
+// a truncated sniff buffer, running out of input while unwrapping is not an error.
+func DetectLayers(in []byte, depth int, opts ...Option) ([]*MIME, error) {
+	c := newConfig(opts)
+	n, head := c.detectNode(in)
+	layers := []*MIME{c.result(n, head)}
+	for i := 0; i < depth; i++ {
+		unwrap, ok := unwrapperOf(n)
+		if !ok {
+			break
+		}
+		inner, err := unwrapHead(unwrap, bytes.NewReader(in), c.readLimit())
+		if err != nil {
+			return layers, err
+		}
+		in = inner
+		n, head = c.detectNode(in)
+		layers = append(layers, c.result(n, head))
+	}
+
+	return layers, nil
+}
+
+// unwrapperOf returns the unwrapper of n or of its closest ancestor having
+// one, since subtypes of a wrapping format, like Helm charts for gzip, have
+// a MIME type of their own but are unwrapped the same way.
+func unwrapperOf(n *node) (func(io.Reader) (io.Reader, error), bool) {
+	for ; n != nil && n != root; n = n.parent {
+		if unwrap, ok := unwrappers[mediaType(n.mime)]; ok {
+			return unwrap, true
+		}
+	}
+
+	return nil, false
+}
+
+// unwrapHead returns at most limit bytes of the content wrapped by in.
+func unwrapHead(unwrap func(io.Reader) (io.Reader, error), in io.Reader, limit int) ([]byte, error) {
+	r, err := unwrap(in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, limit)
+	n, err := io.ReadFull(r, out)
+	if n > 0 || err == io.EOF {
+		return out[:n], nil
+	}
+
+	return nil, err
+}
@@ -0,0 +1,50 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectLayers(t *testing.T) {
+	tcs := []struct {
+		file  string
+		depth int
+		mimes []string
+	}{
+		{"tar.gz.gz", 1, []string{"application/gzip", "application/x-tar"}},
+		{"tar.gz.gz", 0, []string{"application/gzip"}},
+		{"gz.gz", 5, []string{"application/gzip", "text/plain"}},
+		{"png.png", 5, []string{"image/png"}},
+		// Subtypes of gzip are unwrapped like gzip.
+		{"helm.tgz", 1, []string{"application/vnd.cncf.helm.chart.content.v1.tar+gzip", "application/x-tar"}},
+		{"compose.tgz", 1, []string{"application/x-compose-project", "application/x-compose-project"}},
+		{"kustomize.tgz", 1, []string{"application/x-kustomize", "application/x-kustomize"}},
+		{"etckeeper.tgz", 1, []string{"application/x-etckeeper-archive", "application/x-etckeeper-archive"}},
+		{"vagrant.gz.box", 1, []string{"application/x-vagrant-box", "application/x-vagrant-box"}},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers, err := DetectLayers(data, tc.depth)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		if len(layers) != len(tc.mimes) {
+			t.Fatalf("%s: expected %d layers, got %d", tc.file, len(tc.mimes), len(layers))
+		}
+		for i, l := range layers {
+			if !l.Is(tc.mimes[i]) {
+				t.Errorf("%s: layer %d: expected %s, got %s", tc.file, i, tc.mimes[i], l)
+			}
+		}
+	}
+}
+
+func TestDetectLayersCorrupted(t *testing.T) {
+	if _, err := DetectLayers([]byte{0x1f, 0x8b, 0xff, 0xff}, 1); err == nil {
+		t.Errorf("unwrapping a corrupted gzip header should fail")
+	}
+}
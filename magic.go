@@ -0,0 +1,57 @@
+package mimetype
+
+import (
+	"io"
+	"os"
+
+	"github.com/gabriel-vasile/mimetype/internal/magic"
+)
+
+// LoadMagic parses the rules of a libmagic magic(5) file and attaches them
+// to the matchers tree. Only rules which declare a MIME type, through the
+// "!:mime" directive, are kept. Rules are tried after the built-in matchers.
+//
+// LoadMagic is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func LoadMagic(r io.Reader) error {
+	entries, err := magic.Parse(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		root.appendChild(magicNode(e, root))
+	}
+	invalidateExtTables()
+
+	return nil
+}
+
+// LoadMagicFile is like LoadMagic, but reads the rules from the provided file.
+func LoadMagicFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return LoadMagic(f)
+}
+
+// magicNode converts a magic entry into a node. Entries not declaring
+// a MIME type or an extension inherit them from their parent.
+func magicNode(e *magic.Entry, parent *node) *node {
+	mime, extension := e.Mime, e.Extension
+	if mime == "" {
+		mime = parent.mime
+	}
+	if extension == "" && mime == parent.mime {
+		extension = parent.extension
+	}
+
+	n := newNode(mime, extension, e.Match).withDepth(e.Depth)
+	for _, c := range e.Children {
+		n.appendChild(magicNode(c, n))
+	}
+
+	return n
+}
@@ -0,0 +1,43 @@
+package mimetype
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMagic(t *testing.T) {
+	defer func(children []*node) {
+		root.children = children
+		invalidateExtTables()
+	}(root.children)
+
+	rules := `
+0	string	MYFMT	my format
+!:mime	application/x-myfmt
+!:ext	myf
+>5	byte	2	version 2
+!:mime	application/x-myfmt-v2
+`
+	if err := LoadMagic(strings.NewReader(rules)); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		in, mime, ext string
+	}{
+		{"MYFMT\x01", "application/x-myfmt", "myf"},
+		{"MYFMT\x02", "application/x-myfmt-v2", ""},
+	}
+	for _, tc := range tcs {
+		mime, ext := Detect([]byte(tc.in))
+		if mime != tc.mime || ext != tc.ext {
+			t.Errorf("%q: expected %s %s, got %s %s", tc.in, tc.mime, tc.ext, mime, ext)
+		}
+	}
+}
+
+func TestLoadMagicFileMissing(t *testing.T) {
+	if err := LoadMagicFile("inexistent.magic"); err == nil {
+		t.Errorf("loading inexistent magic file should fail")
+	}
+}
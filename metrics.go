@@ -0,0 +1,157 @@
+package mimetype
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsSink receives the measurements of the detections, to export them
+// to a monitoring system. It is installed with the Metrics field of Hooks.
+// Its methods must be safe for concurrent use.
+type MetricsSink interface {
+	// ObserveDetection is called after each detection with the detected
+	// MIME type, without parameters, whether no format was recognized and
+	// the time spent matching.
+	ObserveDetection(mime string, unknown bool, took time.Duration)
+}
+
+// latencyBuckets are the upper bounds of the buckets of the detection
+// latency histogram of Metrics.
+var latencyBuckets = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Metrics is a MetricsSink keeping, in memory, the number of detections of
+// each MIME type, the number of detections recognizing no format and a
+// histogram of the detection latency. It is safe for concurrent use:
+//
+//	m := mimetype.NewMetrics()
+//	mimetype.SetHooks(mimetype.Hooks{Metrics: m})
+//
+// Metrics can be exported without dependencies: it implements expvar.Var,
+// so expvar.Publish("mimetype", m) adds it to the /debug/vars page, and
+// it is an http.Handler serving the Prometheus text format, to be mounted
+// on a /metrics endpoint. Unknown rates are computed from the counters, by
+// dividing the number of unknown detections by the total.
+type Metrics struct {
+	mu      sync.Mutex
+	byMIME  map[string]uint64
+	total   uint64
+	unknown uint64
+	buckets []uint64 // detections per latency bucket, the last one being +Inf
+	sum     time.Duration
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		byMIME:  map[string]uint64{},
+		buckets: make([]uint64, len(latencyBuckets)+1),
+	}
+}
+
+// ObserveDetection implements MetricsSink.
+func (m *Metrics) ObserveDetection(mime string, unknown bool, took time.Duration) {
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return took <= latencyBuckets[i] })
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byMIME[mime]++
+	m.total++
+	if unknown {
+		m.unknown++
+	}
+	m.buckets[i]++
+	m.sum += took
+}
+
+// metricsSnapshot is the JSON representation of Metrics.
+type metricsSnapshot struct {
+	Detections uint64            `json:"detections"`
+	Unknown    uint64            `json:"unknown"`
+	ByMIME     map[string]uint64 `json:"by_mime"`
+	Latency    struct {
+		SumSeconds float64           `json:"sum_seconds"`
+		Buckets    map[string]uint64 `json:"buckets"` // cumulative, by upper bound in seconds
+	} `json:"latency"`
+}
+
+func (m *Metrics) snapshot() metricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := metricsSnapshot{Detections: m.total, Unknown: m.unknown, ByMIME: make(map[string]uint64, len(m.byMIME))}
+	for k, v := range m.byMIME {
+		s.ByMIME[k] = v
+	}
+	s.Latency.SumSeconds = m.sum.Seconds()
+	s.Latency.Buckets = make(map[string]uint64, len(m.buckets))
+	var cumulative uint64
+	for i, n := range m.buckets {
+		cumulative += n
+		s.Latency.Buckets[bucketBound(i)] = cumulative
+	}
+
+	return s
+}
+
+// bucketBound returns the upper bound of the latency bucket i, in seconds.
+func bucketBound(i int) string {
+	if i == len(latencyBuckets) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(latencyBuckets[i].Seconds(), 'g', -1, 64)
+}
+
+// String returns the metrics as a JSON object. It implements expvar.Var.
+func (m *Metrics) String() string {
+	b, _ := stdjson.Marshal(m.snapshot())
+	return string(b)
+}
+
+// WritePrometheus writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	s := m.snapshot()
+	var b strings.Builder
+	b.WriteString("# HELP mimetype_detections_total Detections by detected MIME type.\n")
+	b.WriteString("# TYPE mimetype_detections_total counter\n")
+	mimes := make([]string, 0, len(s.ByMIME))
+	for k := range s.ByMIME {
+		mimes = append(mimes, k)
+	}
+	sort.Strings(mimes)
+	for _, k := range mimes {
+		fmt.Fprintf(&b, "mimetype_detections_total{mime=%q} %d\n", k, s.ByMIME[k])
+	}
+	b.WriteString("# HELP mimetype_unknown_total Detections recognizing no format.\n")
+	b.WriteString("# TYPE mimetype_unknown_total counter\n")
+	fmt.Fprintf(&b, "mimetype_unknown_total %d\n", s.Unknown)
+	b.WriteString("# HELP mimetype_detection_duration_seconds Time spent matching the input.\n")
+	b.WriteString("# TYPE mimetype_detection_duration_seconds histogram\n")
+	for i := 0; i <= len(latencyBuckets); i++ {
+		le := bucketBound(i)
+		fmt.Fprintf(&b, "mimetype_detection_duration_seconds_bucket{le=%q} %d\n", le, s.Latency.Buckets[le])
+	}
+	fmt.Fprintf(&b, "mimetype_detection_duration_seconds_sum %g\n", s.Latency.SumSeconds)
+	fmt.Fprintf(&b, "mimetype_detection_duration_seconds_count %d\n", s.Detections)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
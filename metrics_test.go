@@ -0,0 +1,48 @@
+package mimetype
+
+import (
+	stdjson "encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	defer SetHooks(Hooks{})
+	m := NewMetrics()
+	SetHooks(Hooks{Metrics: m})
+
+	Detect([]byte("\x89PNG\x0d\x0a\x1a\x0a"))
+	Detect([]byte("\x89PNG\x0d\x0a\x1a\x0a"))
+	Detect([]byte("<html><body></body></html>"))
+	Detect([]byte{0x00, 0x01, 0x02, 0xFF})
+
+	var s metricsSnapshot
+	if err := stdjson.Unmarshal([]byte(m.String()), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Detections != 4 || s.Unknown != 1 {
+		t.Errorf("expected 4 detections, 1 unknown, got %d, %d", s.Detections, s.Unknown)
+	}
+	// The MIME types are counted without their parameters.
+	if s.ByMIME["image/png"] != 2 || s.ByMIME["text/html"] != 1 || s.ByMIME[OctetStream] != 1 {
+		t.Errorf("unexpected counts by MIME type: %v", s.ByMIME)
+	}
+	if s.Latency.Buckets["+Inf"] != 4 {
+		t.Errorf("expected the +Inf bucket to count all the detections, got %d", s.Latency.Buckets["+Inf"])
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		`mimetype_detections_total{mime="image/png"} 2`,
+		`mimetype_unknown_total 1`,
+		`mimetype_detection_duration_seconds_bucket{le="+Inf"} 4`,
+		`mimetype_detection_duration_seconds_count 4`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
@@ -3,8 +3,12 @@
 package mimetype
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype/internal/matchers"
 )
@@ -13,12 +17,119 @@ import (
 //
 // mime is always a valid MIME type, with application/octet-stream as fallback.
 // extension is empty string if detected file format does not have an extension.
-func Detect(in []byte) (mime, extension string) {
-	if len(in) == 0 {
-		return "inode/x-empty", ""
+func Detect(in []byte, opts ...Option) (mime, extension string) {
+	m, _ := DetectBytes(in, opts...)
+	return m.String(), m.Extension()
+}
+
+// DetectMIME is like Detect, but returns the result as a *MIME, which
+// also holds the metadata extracted from the input, if any.
+func DetectMIME(in []byte, opts ...Option) *MIME {
+	m, _ := DetectBytes(in, opts...)
+	return m
+}
+
+// ErrShortInput is returned by DetectBytes when no matcher passed and some
+// of the matchers need more bytes than the input has. A longer input with
+// the same prefix might be detected as a different type.
+var ErrShortInput = errors.New("mimetype: input too short for a conclusive detection")
+
+// DetectBytes returns the detection result of the provided byte slice.
+// The returned *MIME is never nil, not even when an error is returned.
+//
+// If no matcher passes, the result is application/octet-stream, or the parent
+// type when WithSubtree is used. In that case, ErrShortInput is returned if
+// a matcher which may still pass for a longer input starting with the same
+// bytes needs more of them. The matchers needing more than the default read
+// limit, like the ones of disk images, are not taken into account. An empty
+// input is detected as inode/x-empty, without an error.
+func DetectBytes(in []byte, opts ...Option) (*MIME, error) {
+	c := newConfig(opts)
+	n, in := c.detectNode(in)
+	start := c.start(in)
+	m := c.result(n, in)
+	if n == start {
+		if _, need := start.matchPrefix(in, nil, streams{}, matchers.ReadLimit); need != 0 {
+			return m, ErrShortInput
+		}
+	}
+
+	return m, nil
+}
+
+// ErrUnknownParent is returned by DetectUnder when the parent
+// MIME type is not part of the matchers tree.
+var ErrUnknownParent = errors.New("mimetype: parent MIME type is not in the matchers tree")
+
+// DetectUnder runs the detection starting from the node of the parent
+// MIME type, instead of the root of the matchers tree. It is meant for
+// staged processing, where the input is already known to be of the parent
+// type, for example a zip archive, and only its subtype is of interest.
+//
+// The parent matcher itself is not checked, so if none of its children match,
+// the parent MIME type is returned. When several formats share the parent
+// MIME type, like the zip, tar and gzip variants of Kustomize, their matchers
+// are run only to pick the one the detection starts from.
+func DetectUnder(parent string, in []byte, opts ...Option) (*MIME, error) {
+	if findNode(parent) == nil {
+		return nil, ErrUnknownParent
 	}
-	n := root.match(in, root)
-	return n.mime, n.extension
+	c := newConfig(opts)
+	c.subtree = parent
+
+	return c.detect(in), nil
+}
+
+// empty is the node returned when detecting an empty input.
+var empty = newNode("inode/x-empty", "", nil)
+
+// detectFrom returns the deepest node matching the input, starting the search
+// from node p, and reports the detection to the installed hooks. When parallel
+// is set, the children of p are evaluated concurrently.
+func detectFrom(p *node, in []byte, parallel bool) *node {
+	return observed(p, in, func() *node {
+		return matchFrom(p, in, parallel)
+	})
+}
+
+// matchFrom returns the deepest node matching the input, starting the search
+// from node p.
+func matchFrom(p *node, in []byte, parallel bool) *node {
+	switch {
+	case len(in) > 0 && parallel:
+		return p.matchParallel(p.decode(in), p)
+	case len(in) > 0:
+		return p.match(p.decode(in), p)
+	}
+
+	return empty
+}
+
+// observed returns the node found by detect for the input, starting from
+// node p, and reports the detection to the installed hooks.
+func observed(p *node, in []byte, detect func() *node) *node {
+	h := loadHooks()
+	var start time.Time
+	if h.OnDetect != nil || h.Metrics != nil {
+		start = time.Now()
+	}
+
+	n := detect()
+
+	if h.OnDetect != nil || h.Metrics != nil {
+		took := time.Since(start)
+		if h.OnDetect != nil {
+			h.OnDetect(n.mime, took, len(in))
+		}
+		if h.Metrics != nil {
+			h.Metrics.ObserveDetection(mediaType(n.mime), n == root, took)
+		}
+	}
+	if h.Logger != nil {
+		logDetection(h.Logger, p, n, len(in))
+	}
+
+	return n
 }
 
 // DetectReader returns the MIME type and extension
@@ -27,16 +138,79 @@ func Detect(in []byte) (mime, extension string) {
 // mime is always a valid MIME type, with application/octet-stream as fallback.
 // extension is empty string if detection failed with an error or
 // detected file format does not have an extension.
-func DetectReader(r io.Reader) (mime, extension string, err error) {
-	in := make([]byte, matchers.ReadLimit)
-	n, err := r.Read(in)
-	if err != nil && err != io.EOF {
+//
+// The input is read in growing chunks, and reading stops as soon as the bytes
+// read are enough to decide its type, so most binary formats are detected
+// from their first 64 bytes instead of the whole read limit. Without
+// WithLimit, inputs no matcher passes for are read further for the formats
+// needing more bytes than the default read limit, like ISO 9660 images.
+func DetectReader(r io.Reader, opts ...Option) (mime, extension string, err error) {
+	c := newConfig(opts)
+	buf := c.getBuf()
+	defer c.putBuf(buf)
+	in, err := c.readIncrementally(r, *buf, false)
+	if err != nil {
 		return root.mime, root.extension, err
 	}
-	in = in[:n]
 
-	mime, extension = Detect(in)
-	return mime, extension, nil
+	n, in := c.detectNode(in)
+	return c.mimeOf(n, in), n.extension, nil
+}
+
+// DetectReaderMIME is like DetectReader, but returns the result as a *MIME,
+// which also holds the metadata extracted from the input, if any. Inputs of
+// the formats having metadata are read up to the read limit, instead of
+// stopping once their type is decided, as the metadata lies past the
+// signature. The returned *MIME is never nil, not even when an error is returned.
+func DetectReaderMIME(r io.Reader, opts ...Option) (*MIME, error) {
+	c := newConfig(opts)
+	buf := c.getBuf()
+	defer c.putBuf(buf)
+	in, err := c.readIncrementally(r, *buf, true)
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+
+	return c.detect(in), nil
+}
+
+// DetectAndReplay detects the MIME type of the data read from r and returns,
+// along with the result, a reader yielding all the data of r, including the
+// bytes consumed during detection. It allows passing the stream onward
+// untouched, without buffering it beforehand.
+//
+// In case of a read error, the returned reader still replays the bytes read
+// so far and the result falls back to application/octet-stream.
+func DetectAndReplay(r io.Reader, opts ...Option) (*MIME, io.Reader, error) {
+	c := newConfig(opts)
+	in, err := c.readHead(r)
+	replay := io.MultiReader(bytes.NewReader(in), r)
+	if err != nil {
+		return newMIME(root, nil), replay, err
+	}
+
+	return c.detect(in), replay, nil
+}
+
+// DetectBuffered detects the MIME type of the data buffered by br, without
+// consuming it: subsequent reads from br return the whole stream, including
+// the bytes examined during detection. The head of the stream is examined
+// in place, so no sniffing buffer is allocated.
+//
+// At most br.Size() bytes can be examined, so br should be created with
+// bufio.NewReaderSize when the read limit exceeds the default buffer size.
+func DetectBuffered(br *bufio.Reader, opts ...Option) (*MIME, error) {
+	c := newConfig(opts)
+	limit := c.readLimit()
+	if limit > br.Size() {
+		limit = br.Size()
+	}
+	in, err := br.Peek(limit)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return newMIME(root, nil), err
+	}
+
+	return c.detect(in), nil
 }
 
 // DetectFile returns the MIME type and extension of the provided file.
@@ -44,12 +218,12 @@ func DetectReader(r io.Reader) (mime, extension string, err error) {
 // mime is always a valid MIME type, with application/octet-stream as fallback.
 // extension is empty string if detection failed with an error or
 // detected file format does not have an extension.
-func DetectFile(file string) (mime, extension string, err error) {
+func DetectFile(file string, opts ...Option) (mime, extension string, err error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return root.mime, root.extension, err
 	}
 	defer f.Close()
 
-	return DetectReader(f)
+	return DetectReader(f, opts...)
 }
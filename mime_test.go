@@ -1,12 +1,20 @@
 package mimetype
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf16"
 
 	"github.com/gabriel-vasile/mimetype/internal/matchers"
 )
@@ -15,42 +23,54 @@ const testDataDir = "testdata"
 
 var files = map[string]*node{
 	// archives
-	"pdf.pdf":     pdf,
-	"zip.zip":     zip,
-	"tar.tar":     tar,
-	"xls.xls":     xls,
-	"xlsx.xlsx":   xlsx,
-	"doc.doc":     doc,
-	"doc.1.doc":   doc,
-	"docx.docx":   docx,
-	"docx.1.docx": docx,
-	"ppt.ppt":     ppt,
-	"pptx.pptx":   pptx,
-	"pub.pub":     pub,
-	"odt.odt":     odt,
-	"ott.ott":     ott,
-	"ods.ods":     ods,
-	"ots.ots":     ots,
-	"odp.odp":     odp,
-	"otp.otp":     otp,
-	"odg.odg":     odg,
-	"otg.otg":     otg,
-	"odf.odf":     odf,
-	"epub.epub":   epub,
-	"7z.7z":       sevenZ,
-	"jar.jar":     jar,
-	"gz.gz":       gzip,
-	"fits.fits":   fits,
-	"xar.xar":     xar,
-	"bz2.bz2":     bz2,
-	"a.a":         ar,
-	"deb.deb":     deb,
-	"rar.rar":     rar,
-	"djvu.djvu":   djvu,
-	"mobi.mobi":   mobi,
-	"lit.lit":     lit,
-	"warc.warc":   warc,
-	"zst.zst":     zstd,
+	"pdf.pdf":            pdf,
+	"zip.zip":            zip,
+	"tar.tar":            tar,
+	"xls.xls":            xls,
+	"xlsx.xlsx":          xlsx,
+	"doc.doc":            doc,
+	"doc.1.doc":          doc,
+	"docx.docx":          docx,
+	"docx.1.docx":        docx,
+	"ppt.ppt":            ppt,
+	"pptx.pptx":          pptx,
+	"pub.pub":            pub,
+	"msi.msi":            msi,
+	"msg.msg":            msg,
+	"vsd.vsd":            vsd,
+	"odt.odt":            odt,
+	"ott.ott":            ott,
+	"ods.ods":            ods,
+	"ots.ots":            ots,
+	"odp.odp":            odp,
+	"otp.otp":            otp,
+	"odg.odg":            odg,
+	"otg.otg":            otg,
+	"odf.odf":            odf,
+	"epub.epub":          epub,
+	"epub.overlays.epub": epub,
+	"7z.7z":              sevenZ,
+	"jar.jar":            jar,
+	"gz.gz":              gzip,
+	"fits.fits":          fits,
+	"xar.xar":            xar,
+	"bz2.bz2":            bz2,
+	"a.a":                ar,
+	"deb.deb":            deb,
+	"rar.rar":            rar5,
+	"djvu.djvu":          djvu,
+	"mobi.mobi":          mobi,
+	"lit.lit":            lit,
+	"warc.warc":          warc,
+	"zst.zst":            zstd,
+	"takeout.zip":        takeout,
+	"icloud.zip":         iCloud,
+	"daisy.zip":          daisy,
+	"daisy.upper.zip":    daisy,
+	"apk.apk":            apk,
+	"ipa.ipa":            ipa,
+	"kmz.kmz":            kmz,
+	"ora.ora":            ora,
 
 	// images
 	"png.png":          png,
@@ -78,7 +98,7 @@ var files = map[string]*node{
 	"mqv.mqv":   mqv,
 	"mpeg.mpeg": mpeg,
 	"mkv.mkv":   mkv,
-	"asf.asf":   asf,
+	"asf.asf":   wmv,
 
 	// audio
 	"mp3.mp3":            mp3,
@@ -126,6 +146,7 @@ var files = map[string]*node{
 	"vCard.dos.vCard":   vCard,
 	"ics.ics":           iCalendar,
 	"ics.dos.ics":       iCalendar,
+	"brf.brf":           brf,
 
 	// binary
 	"class.class": class,
@@ -151,6 +172,7 @@ var files = map[string]*node{
 	// XML and subtypes of XML
 	"xml.withbr.xml": xml,
 	"kml.kml":        kml,
+	"kml.prolog.kml": kml,
 	"xlf.xlf":        xliff,
 	"dae.dae":        collada,
 	"gml.gml":        gml,
@@ -161,6 +183,7 @@ var files = map[string]*node{
 	"3mf.3mf":        threemf,
 	"rss.rss":        rss,
 	"atom.atom":      atom,
+	"dtbook.xml":     dtbook,
 
 	"shp.shp": shp,
 	"shx.shx": shx,
@@ -172,6 +195,192 @@ var files = map[string]*node{
 	"nes.nes":         nes,
 	"mdb.mdb":         mdb,
 	"accdb.accdb":     accdb,
+
+	// scientific data
+	"grib1.grb":   grib,
+	"grib2.grib2": grib,
+	"bufr.bufr":   bufr,
+	"hdf4.hdf":    hdf4,
+	"hdfeos4.hdf": hdf4Eos,
+	"hdf5.h5":     hdf5,
+	"hdfeos5.he5": hdf5Eos,
+	"asdf.asdf":   asdf,
+	"ecsv.ecsv":   ecsv,
+	"casa.dat":    casaTable,
+
+	// backups
+	"ab.ab":               androidBackup,
+	"manifest.db":         iTunesDb,
+	"manifest.plist":      iTunesBplist,
+	"manifest.xml.plist":  iTunesPlist,
+	"plist.plist":         plist,
+	"bplist.plist":        bplist,
+	"titanium.properties": titanium,
+
+	// container images
+	"docker.tar":           dockerArchive,
+	"oci.tar":              ociLayout,
+	"oci.manifest.json":    ociManifest,
+	"oci.index.json":       ociIndex,
+	"docker.manifest.json": dockerManifest,
+	"docker.list.json":     dockerManifestList,
+
+	// microscopy
+	"zarr.zip":    zarr,
+	"zarray.json": zarrMeta,
+	"zarr.json":   zarrMeta,
+	"nd2.nd2":     nd2,
+	"lif.lif":     lif,
+	"czi.czi":     czi,
+
+	// game saves
+	"ps1.mcr":    ps1MemoryCard,
+	"ps1.gme":    ps1MemoryCard,
+	"ps2.ps2":    ps2MemoryCard,
+	"gsv.gsv":    gbaGameSharkSave,
+	"sps.sps":    gbaSharkPortSave,
+	"switch.sav": switchSave,
+
+	// machine provisioning
+	"vagrant.box":    vagrantBox,
+	"vagrant.gz.box": vagrantBoxGz,
+
+	// legacy audio and video
+	"wma.wma":       wma,
+	"rm.rm":         realMedia,
+	"ra.ra":         realAudio,
+	"au.le.au":      au,
+	"wav.gsm.wav":   wav,
+	"wav.adpcm.wav": wav,
+
+	// telephony audio
+	"wav.telephony.wav": wav,
+	"wav.vox.wav":       wav,
+
+	// software bills of materials and security reports
+	"sarif.sarif":        sarif,
+	"spdx.spdx.json":     spdxJson,
+	"spdx.spdx":          spdxTagValue,
+	"cyclonedx.cdx.json": cycloneDxJson,
+	"cyclonedx.cdx.xml":  cycloneDxXml,
+	"openvex.json":       openVex,
+	"csaf.json":          csaf,
+
+	// archive variants
+	"rar4.rar":    rar4,
+	"7z.aes.7z":   sevenZ,
+	"7z.solid.7z": sevenZ,
+
+	// broadcast audio
+	"bwf.wav":      wav,
+	"adm.bw64.wav": wav,
+
+	// code signing
+	"p7s.p7s":          pkcs7Signature,
+	"authenticode.p7s": authenticode,
+	"catalog.cat":      securityCatalog,
+	"apple.sig":        appleCodeSignature,
+	"jar.sf":           jarSignatureFile,
+	"signed.exe":       exe,
+	"signed.jar":       jar,
+	"signed.apk":       apk,
+
+	// text formats chosen by score
+	"tsv.commas.tsv": tsv,
+
+	// API descriptions
+	"openapi.json":   openApiJson,
+	"swagger.json":   openApiJson,
+	"openapi.yaml":   openApiYaml,
+	"asyncapi.json":  asyncApiJson,
+	"asyncapi.yaml":  asyncApiYaml,
+	"schema.graphql": graphQl,
+
+	// electronic signatures
+	"xmldsig.xml": xmlDsig,
+	"xades.xml":   xades,
+	"asice.asice": asicE,
+	"asics.asics": asicS,
+
+	// mail transport
+	"eml.eml":       eml,
+	"maildir.eml":   maildir,
+	"qmail.queue":   qmailQueue,
+	"smtp.bdat.txt": smtpSession,
+	"smtp.data.txt": smtpSession,
+
+	// page description languages
+	"xfa.pdf":             pdf,
+	"ps.xobject.pdf":      pdf,
+	"pdf.attachments.pdf": pdf,
+	"pdf.portfolio.pdf":   pdf,
+	"pdf.encrypted.pdf":   pdf,
+
+	// MIDI variants
+	"midi.format1.mid": midi,
+	"rmid.rmi":         rmid,
+	"xmf.xmf":          xmf,
+
+	// firmware dumps
+	"ifd.bin": intelFlashImage,
+	"uefi.fv": uefiFirmwareVolume,
+
+	// container orchestration
+	"helm.tgz":      helmChart,
+	"kustomize.tar": kustomizeTar,
+	"kustomize.tgz": kustomizeGz,
+	"kustomize.zip": kustomizeZip,
+	"compose.tar":   composeTar,
+	"compose.tgz":   composeGz,
+	"compose.zip":   composeZip,
+
+	// secrets and configuration
+	"vault.yml":       ansibleVault,
+	"vault.label.yml": ansibleVault,
+	"sops.yaml":       sopsYaml,
+	"sops.json":       sopsJson,
+	"etckeeper.tar":   etckeeperTar,
+	"etckeeper.tgz":   etckeeperGz,
+
+	// digitization and preservation
+	"mets.xml": mets,
+	"alto.xml": alto,
+	"mix.xml":  mix,
+	"jpp.jpp":  jppStream,
+
+	// windows drivers
+	"driver.sys": windowsDriver,
+	"mui.mui":    mui,
+	"driver.inf": windowsInf,
+	// fax and print streams
+	"tiff.fx.tif": tiffFx,
+	"afp.afp":     afp,
+	"pclxl.pxl":   pclXl,
+	// packet captures and live streaming ingest
+	"flv.hevc.flv": flv,
+	"pcap.pcap":    pcap,
+	"srt.pcap":     srtCapture,
+	"srt.pcapng":   srtCaptureNg,
+	// remote sensing
+	"nitf.ntf":           nitf,
+	"envi.hdr":           enviHeader,
+	"cog.tif":            cog,
+	"safe.manifest.safe": safeManifest,
+	"safe.zip":           safeZip,
+	// license files
+	"xrml.xrm-ms": xrml,
+	"flexlm.lic":  flexLm,
+	"jwt.jwt":     jwt,
+}
+
+// largeFiles holds the test files of formats which cannot be detected
+// from the first ReadLimit bytes, only by calling Detect with the whole file
+// or by letting DetectReader read past the default limit.
+var largeFiles = map[string]*node{
+	"iso.iso":    iso9660,
+	"cidata.iso": cloudInitSeed,
+	"ovfenv.iso": ovfEnv,
+	"rtmp.rtmp":  rtmpDump,
 }
 
 func TestMatching(t *testing.T) {
@@ -206,6 +415,18 @@ func TestMatching(t *testing.T) {
 	}
 }
 
+func TestMatchingLargeFiles(t *testing.T) {
+	for fName, node := range largeFiles {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, fName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dMime, _ := Detect(data); dMime != node.mime {
+			t.Errorf("File: %s; Mime: %s != DetectedMime: %s", fName, node.mime, dMime)
+		}
+	}
+}
+
 func TestFaultyInput(t *testing.T) {
 	inexistent := "inexistent.file"
 	if _, _, err := DetectFile(inexistent); err == nil {
@@ -286,3 +507,711 @@ func BenchmarkMatchDetect(b *testing.B) {
 		Detect(data[n%fLen][:])
 	}
 }
+
+func TestDetectAndReplay(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "mp4.1.mp4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, r, err := DetectAndReplay(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(mp4.mime) {
+		t.Errorf("expected %s, got %s", mp4.mime, m)
+	}
+	replayed, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(replayed, data) {
+		t.Errorf("replayed data differs from the original: %d bytes, expected %d", len(replayed), len(data))
+	}
+
+	f, _ := os.Open("inexistent.file")
+	if _, _, err := DetectAndReplay(f); err == nil {
+		t.Errorf("inexistent.file reader should not match successfully")
+	}
+}
+
+func TestDetectBytes(t *testing.T) {
+	png, err := ioutil.ReadFile("testdata/png.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := bytes.Repeat([]byte{0x00, 0xFF}, 2*SniffLength())
+	// Binary garbage long enough for the matchers declaring a depth within
+	// the read limit, and which the matchers looking at the whole input
+	// already reject.
+	garbage := make([]byte, 1500)
+	rand.New(rand.NewSource(1)).Read(garbage)
+
+	tCases := []struct {
+		name     string
+		in       []byte
+		opts     []Option
+		expected string
+		err      error
+	}{
+		{"match", png, nil, "image/png", nil},
+		{"empty", nil, nil, "inode/x-empty", nil},
+		{"short unknown", unknown[:16], nil, "application/octet-stream", ErrShortInput},
+		{"long unknown", unknown, nil, "application/octet-stream", nil},
+		{"limited unknown", unknown, []Option{WithLimit(16)}, "application/octet-stream", ErrShortInput},
+		{"short subtree", png[:16], []Option{WithSubtree("application/zip")}, "application/zip", ErrShortInput},
+		{"random bytes", garbage, nil, "application/octet-stream", nil},
+		// DICOM files start with a preamble of 128 bytes, often zeroed.
+		{"nul bytes", make([]byte, 48), nil, "application/octet-stream", ErrShortInput},
+	}
+	for _, tc := range tCases {
+		m, err := DetectBytes(tc.in, tc.opts...)
+		if m == nil {
+			t.Fatalf("%s: nil result", tc.name)
+		}
+		if m.String() != tc.expected || err != tc.err {
+			t.Errorf("%s: expected %s, %v, got %s, %v", tc.name, tc.expected, tc.err, m, err)
+		}
+	}
+}
+
+func TestDetectBuffered(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/mp4.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(bytes.NewReader(data))
+	m, err := DetectBuffered(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(mp4.mime) {
+		t.Errorf("expected %s, got %s", mp4.mime, m)
+	}
+	read, err := ioutil.ReadAll(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Errorf("buffered data was consumed: %d bytes read, expected %d", len(read), len(data))
+	}
+
+	// The buffer is smaller than the read limit.
+	m, err = DetectBuffered(bufio.NewReaderSize(bytes.NewReader(data), 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(mp4.mime) {
+		t.Errorf("expected %s with a small buffer, got %s", mp4.mime, m)
+	}
+
+	m, err = DetectBuffered(bufio.NewReader(bytes.NewReader(nil)))
+	if err != nil || !m.Is("inode/x-empty") {
+		t.Errorf("expected inode/x-empty for empty input, got %s, %v", m, err)
+	}
+}
+
+// TestMinBytes checks that skipping the matchers of inputs shorter than
+// their minimum length does not change the detection results.
+func TestMinBytes(t *testing.T) {
+	for f, n := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for ; n != root; n = n.parent {
+			for l := 0; l < n.minBytes && l <= len(data); l++ {
+				if n.matchFunc(data[:l]) {
+					t.Errorf("%s: %s matches %d bytes, fewer than its minimum of %d", f, n.mime, l, n.minBytes)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestParallel checks that evaluating the top-level matchers concurrently
+// gives the same results as the sequential detection.
+func TestParallel(t *testing.T) {
+	for f, n := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m, _ := Detect(data, WithParallel()); m != n.mime {
+			t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+		}
+	}
+	if m, _ := Detect(nil, WithParallel()); m != empty.mime {
+		t.Errorf("empty input: expected %s, got %s", empty.mime, m)
+	}
+}
+
+func BenchmarkParallelDetect(b *testing.B) {
+	// Plain text is only matched after all the binary formats are tried.
+	data := bytes.Repeat([]byte("plain text "), matchers.ReadLimit/11)
+
+	b.Run("sequential", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data, WithParallel())
+		}
+	})
+}
+
+func BenchmarkDetectReader(b *testing.B) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := bytes.NewReader(data)
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			r.Reset(data)
+			DetectReader(r)
+		}
+	})
+	// A limit other than ReadLimit bypasses the pool.
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			r.Reset(data)
+			DetectReader(r, WithLimit(matchers.ReadLimit+1))
+		}
+	})
+}
+
+func BenchmarkDetectFile(b *testing.B) {
+	b.ReportAllocs()
+	f := filepath.Join(testDataDir, "png.png")
+	for n := 0; n < b.N; n++ {
+		DetectFile(f)
+	}
+}
+
+// BenchmarkCommonFormats detects a corpus of the formats most found on the
+// web, with the root matchers in the tree order and with the common formats
+// tried last, as they were before being moved to the front of the root.
+func BenchmarkCommonFormats(b *testing.B) {
+	corpus := []string{"jpg.jpg", "png.png", "pdf.pdf", "zip.zip", "docx.docx", "mp4.mp4", "webp.webp"}
+	var data [][]byte
+	for _, f := range corpus {
+		d, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(d) > matchers.ReadLimit {
+			d = d[:matchers.ReadLimit]
+		}
+		data = append(data, d)
+	}
+
+	b.Run("tree order", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data[n%len(data)])
+		}
+	})
+
+	const common = 7
+	saved := append([]*node{}, root.children...)
+	defer func() { root.children = saved }()
+	root.children = append(append([]*node{}, saved[common:]...), saved[:common]...)
+	b.Run("common last", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Detect(data[n%len(data)])
+		}
+	})
+}
+
+// textFiles are the test files of the text based formats, which are only
+// matched after most of the binary matchers had a look at the input.
+var textFiles = []string{
+	"txt.txt", "html.html", "xml.xml", "svg.svg", "php.php", "py.py",
+	"js.js", "pl.pl", "lua.lua", "tcl.tcl", "json.json", "ndjson.ndjson", "csv.csv",
+}
+
+// TestTextMatchersAllocs checks the matchers of the source code and
+// structured text formats, which run on most text inputs, do not allocate.
+func TestTextMatchersAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the matchers reusing pooled buffers allocate under the race detector")
+	}
+	for _, f := range textFiles {
+		d, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []*node{txt, html, php, js, lua, perl, python, tcl, json, ndJson, csv, tsv} {
+			n.passes(d)
+			if allocs := testing.AllocsPerRun(10, func() { n.passes(d) }); allocs != 0 {
+				t.Errorf("%s: the %s matcher allocates %.0f times", f, n.mime, allocs)
+			}
+		}
+	}
+}
+
+// BenchmarkTextDetect detects text based formats.
+func BenchmarkTextDetect(b *testing.B) {
+	benchmarkDetectFiles(b, textFiles)
+}
+
+// zipFiles are the test files of zip based formats, whose matchers all look
+// at the entries of the archive.
+var zipFiles = []string{
+	"zip.zip", "docx.docx", "xlsx.xlsx", "pptx.pptx", "epub.epub", "odt.odt",
+	"jar.jar", "apk.apk", "kmz.kmz", "asice.asice", "compose.zip",
+}
+
+// BenchmarkZipDetect detects zip based formats. The last children of the zip
+// node, like the one of a plain zip archive, are found after all the
+// siblings had a look at the entries.
+func BenchmarkZipDetect(b *testing.B) {
+	benchmarkDetectFiles(b, zipFiles)
+}
+
+// shortInputs are inputs under 16 bytes, common in fuzzing and message
+// queues, which are only matched against the few matchers able to pass.
+var shortInputs = []string{
+	"a", "{}", "ok\n", "\x00\x01\x02\x03", "PK\x03\x04", "GIF89a", "%PDF-1.",
+	"\x89PNG\r\n\x1A\n", "#!/bin/sh\n", `{"id":12}`, "hello world", "\xDE\xAD\xBE\xEF\x01\x02",
+}
+
+// BenchmarkShortDetect detects inputs under 16 bytes.
+func BenchmarkShortDetect(b *testing.B) {
+	for _, in := range shortInputs {
+		in := []byte(in)
+		b.Run(fmt.Sprintf("%q", in), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				Detect(in)
+			}
+		})
+	}
+}
+
+// TestZipEntriesReusedBuffer checks the entries parsed for an input are not
+// reused for another input stored in the same buffer.
+func TestZipEntriesReusedBuffer(t *testing.T) {
+	d, err := ioutil.ReadFile(filepath.Join(testDataDir, "docx.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) > matchers.ReadLimit {
+		d = d[:matchers.ReadLimit]
+	}
+	if m := DetectMIME(d); !m.Is(Docx) {
+		t.Fatalf("docx.docx: expected %s, got %s", Docx, m)
+	}
+	copy(d, bytes.Replace(d, []byte("word/"), []byte("wxrd/"), -1))
+	if m := DetectMIME(d); !m.Is(Zip) {
+		t.Errorf("renamed docx.docx: expected %s, got %s", Zip, m)
+	}
+}
+
+// cfbWithBiff returns a CFB container whose first sector starts like an
+// Excel workbook stream, and whose directory, in the second sector, only
+// holds the root storage. The FAT is stored in sector fatSector, which
+// is part of the container only when it is 2.
+func cfbWithBiff(fatSector uint32) []byte {
+	in := make([]byte, 512*4)
+	copy(in, "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	binary.LittleEndian.PutUint16(in[30:], 9) // 512 bytes sectors
+	binary.LittleEndian.PutUint32(in[48:], 1) // first directory sector
+	binary.LittleEndian.PutUint32(in[76:], fatSector)
+	copy(in[512:], "\x09\x08\x10\x00\x00\x06\x05\x00")
+
+	root := in[1024:]
+	for i, r := range "Root Entry" {
+		binary.LittleEndian.PutUint16(root[2*i:], uint16(r))
+	}
+	binary.LittleEndian.PutUint16(root[64:], 22)
+	root[66] = 5 // root storage
+
+	binary.LittleEndian.PutUint32(in[1536+4:], 0xFFFFFFFE) // end of the directory chain
+
+	return in
+}
+
+func TestCfbIncompleteDirectory(t *testing.T) {
+	// The directory chain continues in sectors past the input, so the
+	// streams not found yet may be there.
+	if m := DetectMIME(cfbWithBiff(100)); !m.Is(Xls) {
+		t.Errorf("incomplete directory: expected %s, got %s", Xls, m)
+	}
+	// The whole directory is read and has no stream of an Office format.
+	if m := DetectMIME(cfbWithBiff(2)); !m.Is(OLE) {
+		t.Errorf("complete directory: expected %s, got %s", OLE, m)
+	}
+}
+
+func benchmarkDetectFiles(b *testing.B, files []string) {
+	for _, f := range files {
+		d, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(d) > matchers.ReadLimit {
+			d = d[:matchers.ReadLimit]
+		}
+		b.Run(f, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				Detect(d)
+			}
+		})
+	}
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// TestDetectReaderMetadata checks the metadata extracted when reading the
+// test files incrementally is the one extracted from their whole head.
+func TestDetectReaderMetadata(t *testing.T) {
+	for f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > matchers.ReadLimit {
+			data = data[:matchers.ReadLimit]
+		}
+		want := DetectMIME(data)
+		if want.Is(root.mime) {
+			// Disk images are read past the read limit.
+			continue
+		}
+		for _, r := range []io.Reader{bytes.NewReader(data), iotest.OneByteReader(bytes.NewReader(data))} {
+			m, err := DetectReaderMIME(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Metadata(), want.Metadata()) {
+				t.Errorf("%s: expected metadata %v, got %v", f, want.Metadata(), m.Metadata())
+			}
+		}
+	}
+}
+
+func TestDetectReaderIncremental(t *testing.T) {
+	tcs := []struct {
+		file string
+		read int
+	}{
+		{"png.png", 64},
+		{"jpg.jpg", 64},
+		// The tar matcher, tried before the one of WAV, needs 263 bytes.
+		{"wav.wav", 263},
+		// Text formats are decided by looking at the whole input.
+		{"eml.eml", 270},
+		// Disk images are read past the default limit.
+		{"iso.iso", 40960},
+		{"cidata.iso", 43008},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &countingReader{r: bytes.NewReader(data)}
+		m, _, err := DetectReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _ := Detect(data); m != want {
+			t.Errorf("%s: expected %s, got %s", tc.file, want, m)
+		}
+		if r.n != tc.read {
+			t.Errorf("%s: expected %d bytes read, got %d", tc.file, tc.read, r.n)
+		}
+	}
+	text := &countingReader{r: bytes.NewReader(bytes.Repeat([]byte("plain text "), matchers.ReadLimit))}
+	if _, _, err := DetectReader(text); err != nil {
+		t.Fatal(err)
+	}
+	if text.n != matchers.ReadLimit {
+		t.Errorf("text: expected %d bytes read, got %d", matchers.ReadLimit, text.n)
+	}
+}
+
+func TestMatchPrefixStreams(t *testing.T) {
+	jsonNode := newNode(JSON, "json", matchers.Json).withStream(matchers.NewJsonStream)
+	csvNode := newNode(CSV, "csv", matchers.Csv).withStream(matchers.NewCsvStream)
+	txtNode := newNode(Text, "txt", matchers.Txt, jsonNode, csvNode).withStream(matchers.NewTxtStream)
+	tree := newNode("application/octet-stream", "", nil, txtNode)
+
+	tcs := []struct {
+		name  string
+		start *node
+		in    string
+		need  int
+	}{
+		{"text", tree, strings.Repeat("plain text ", 6), 2 * firstRead},
+		{"binary", tree, "plain\x00text" + strings.Repeat(" ", 52), 0},
+		{"json", txtNode, `{"a": [1, 2, 3], "b": "` + strings.Repeat("c", 40) + `"`, 2 * firstRead},
+		{"json and csv rejected", txtNode, "[1, 2,, 3]\nx\n" + strings.Repeat("x", 50), 0},
+	}
+	for _, tc := range tcs {
+		in := []byte(tc.in)[:firstRead]
+		n, need := tc.start.matchPrefix(in, nil, streams{}, 0)
+		if need != tc.need {
+			t.Errorf("%s: expected %d bytes needed, got %d", tc.name, tc.need, need)
+		}
+		if need == 0 && n != nil {
+			t.Errorf("%s: expected no match, got %s", tc.name, n.mime)
+		}
+	}
+
+	// The streams kept between the calls are only fed the new bytes.
+	ss := streams{}
+	in := []byte(strings.Repeat("plain text ", 12))
+	tree.matchPrefix(in[:firstRead], nil, ss, 0)
+	in[firstRead+1] = 0
+	if _, need := tree.matchPrefix(in, nil, ss, 0); need != 0 {
+		t.Errorf("expected the text stream to reject the input, got %d bytes needed", need)
+	}
+	if fed := ss[txtNode].fed; fed != len(in) {
+		t.Errorf("expected %d bytes fed, got %d", len(in), fed)
+	}
+}
+
+// encodeWide encodes s in the UTF-16 or UTF-32 charset cs, starting with
+// a byte order mark if bom is set.
+func encodeWide(s, cs string, bom bool) []byte {
+	if bom {
+		s = "\uFEFF" + s
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if strings.HasSuffix(cs, "be") {
+		order = binary.BigEndian
+	}
+	var out []byte
+	if strings.HasPrefix(cs, "utf-32") {
+		for _, r := range s {
+			b := make([]byte, 4)
+			order.PutUint32(b, uint32(r))
+			out = append(out, b...)
+		}
+		return out
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b := make([]byte, 2)
+		order.PutUint16(b, u)
+		out = append(out, b...)
+	}
+
+	return out
+}
+
+func TestDetectWideText(t *testing.T) {
+	texts := []struct {
+		in, mime string
+	}{
+		{"Windows log\r\nline 2 – done\r\n", "text/plain"},
+		{"<!DOCTYPE html><html><body>é</body></html>", "text/html"},
+		{`<?xml version="1.0" encoding="UTF-16"?><a/>`, "text/xml"},
+		{`{"name": "clef", "symbol": "𝄞", "codes": [1, 2, 3]}`, "application/json"},
+		{"Id,Name,Email\r\n1,Ana,ana@example.com\r\n2,Bob,bob@example.com\r\n", "text/csv"},
+	}
+	charsets := []struct {
+		cs  string
+		bom bool
+	}{
+		{"utf-16le", true},
+		{"utf-16be", true},
+		{"utf-16le", false},
+		{"utf-16be", false},
+		{"utf-32le", true},
+		{"utf-32be", true},
+	}
+	for _, tc := range texts {
+		for _, c := range charsets {
+			in := encodeWide(tc.in, c.cs, c.bom)
+			expected := tc.mime + "; charset=" + c.cs
+			if m := DetectMIME(in); m.String() != expected {
+				t.Errorf("%s %s (bom %t): expected %s, got %s", tc.mime, c.cs, c.bom, expected, m)
+			}
+			m, err := DetectReaderMIME(bytes.NewReader(in))
+			if err != nil || m.String() != expected {
+				t.Errorf("%s %s (bom %t): expected %s from DetectReader, got %s, %v", tc.mime, c.cs, c.bom, expected, m, err)
+			}
+		}
+	}
+
+	// Control characters make wide text binary, like narrow text.
+	if m := DetectMIME(encodeWide("a\x01b\x02c\x03", "utf-16le", true)); m.String() != OctetStream {
+		t.Errorf("expected %s for wide binary data, got %s", OctetStream, m)
+	}
+	// A code unit cut short is ignored.
+	in := encodeWide("truncated text", "utf-16le", true)
+	if m := DetectMIME(in[:len(in)-1]); m.String() != "text/plain; charset=utf-16le" {
+		t.Errorf("expected truncated wide text to be detected, got %s", m)
+	}
+}
+
+func TestDetectHtml(t *testing.T) {
+	license := "<!--\n" + strings.Repeat("  Licensed under the Apache License.\n", 20) + "-->\n"
+	tcs := []struct {
+		name, in, mime string
+	}{
+		{"lowercase doctype", "<!doctype html><title>x</title>", HTML},
+		{"doctype spacing", "<!DocType\n\tHTML >\n<p>x", HTML},
+		{"utf-8 bom", "\xEF\xBB\xBF<!DOCTYPE html>", HTML},
+		{"tag on its own line", "<HTML\n lang=\"en\">", HTML},
+		{"empty element", "<br/>text", HTML},
+		{"leading comments", license + "<!-- generated -->\n<html>", HTML},
+		{"conditional comments", "<!--[if lt IE 9]><p>old</p><![endif]-->\n<![if !IE]><div>x</div><![endif]>", HTML},
+		{"comment cut by the read limit", "<!--" + strings.Repeat("x", matchers.ReadLimit), HTML},
+		{"comment before svg", license + "<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>", SVG},
+		{"comment before text", "<!-- x -->\nSome plain text.\n", Text},
+		{"not a doctype", "<!DOCTYPEhtml>", Text},
+	}
+	for _, tc := range tcs {
+		if m := DetectMIME([]byte(tc.in)); m.String() != tc.mime {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.mime, m)
+		}
+	}
+}
+
+func TestDetectStrictJSON(t *testing.T) {
+	tcs := []struct {
+		name, in, lenient, strict string
+	}{
+		{"object", `{"a": [1, 2]}`, JSON, JSON},
+		{"number", "42\n", JSON, Text},
+		{"quoted string", `"hello"`, JSON, Text},
+		{"literal", "true", JSON, Text},
+		{"numbers per line", "1\n2\n3\n", NDJSON, Text},
+		{"objects per line", "{\"a\": 1}\n\n{\"a\": 2}\r\n", NDJSON, NDJSON},
+		{"single object line", "{\"a\": 1}\n", JSON, JSON},
+		{"utf-8 bom", "\xEF\xBB\xBF{\"a\": 1}", Text, JSON},
+		{"utf-8 bom geojson", "\xEF\xBB\xBF{\"type\": \"Point\", \"coordinates\": [1, 2]}", Text, GeoJSON},
+		{"deep leading whitespace", strings.Repeat(" \n", 1000) + `["a"]`, JSON, JSON},
+		{"cut by the read limit", `[` + strings.Repeat(`"abc", `, matchers.ReadLimit/7+1), JSON, JSON},
+		{"object literal", "{a: 1}", Text, Text},
+		{"trailing comma", "[1, 2,]", Text, Text},
+		{"javascript", "function toPoint(x) {\n  return {\"X\": x};\n}\n", Text, Text},
+	}
+	for _, tc := range tcs {
+		if m := DetectMIME([]byte(tc.in)); m.String() != tc.lenient {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.lenient, m)
+		}
+		if m := DetectMIME([]byte(tc.in), WithStrictJSON()); m.String() != tc.strict {
+			t.Errorf("%s, strict: expected %s, got %s", tc.name, tc.strict, m)
+		}
+	}
+}
+
+func TestDetectCsv(t *testing.T) {
+	tcs := []struct {
+		name, in, mime, delimiter string
+	}{
+		{"quoted delimiters", "Name,Comment\n\"Smith, J\",\"Said \"\"hi\"\", then left\"\n\"Doe, A\",Ok\n", CSV, ","},
+		{"quoted line breaks", "Id,Note\n1,\"Line one\nLine two\"\n2,X\n", CSV, ","},
+		{"semicolons", "Name;Price;Qty\nApfel;1,20;3\nBirne;0,99;10\n", CSV, ";"},
+		{"semicolons and decimal commas", "Name;Price\nApfel;1,20\nBirne;0,99\n", CSV, ";"},
+		{"commas in quoted fields", "Name,Note\nApfel,\"a; b\"\nBirne,\"c; d\"\n", CSV, ","},
+		{"quoted tabs", "Id\tName\n1\t\"A\tB\"\n2\tC\n", TSV, ""},
+		{"inconsistent columns", "Name;Price;Qty\nApfel;1,20\nBirne;0,99;10\n", Text, ""},
+		{"prose with semicolons", "I came; I saw.\nYou left; they stayed.\n", Text, ""},
+		{"two semicolon records", "Name;Price\nApfel;1,20\n", Text, ""},
+		{"two semicolon records of three columns", "Name;Price;Qty\nApfel;1,20;3\n", CSV, ";"},
+	}
+	for _, tc := range tcs {
+		m := DetectMIME([]byte(tc.in))
+		if m.String() != tc.mime {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.mime, m)
+		}
+		if d := m.Meta("delimiter"); d != tc.delimiter {
+			t.Errorf("%s: expected delimiter %q, got %q", tc.name, tc.delimiter, d)
+		}
+		if m, err := DetectReaderMIME(strings.NewReader(tc.in)); err != nil || m.String() != tc.mime {
+			t.Errorf("%s: DetectReader: expected %s, got %s, %v", tc.name, tc.mime, m, err)
+		}
+	}
+}
+
+func TestDetectXmlNamespaces(t *testing.T) {
+	const decl = "<?xml version=\"1.0\"?>\n"
+	tcs := []struct {
+		name, in, mime string
+	}{
+		{"prefixed kml", decl + `<kml:kml xmlns:kml="http://www.opengis.net/kml/2.2">`, KML},
+		{"uppercase kml", decl + `<KML xmlns="http://www.opengis.net/kml/2.2">`, KML},
+		{"prefixed atom", decl + `<a:feed xmlns:a='http://www.w3.org/2005/Atom'>`, Atom},
+		{"prefixed gpx", decl + "<g:gpx version=\"1.1\"\n\txmlns:g = \"http://www.topografix.com/GPX/1/1\">", GPX},
+		{"uppercase rss", decl + `<RSS version="2.0">`, RSS},
+		{"x3d", decl + `<x3d xmlns:xsd="http://www.w3.org/2001/XMLSchema-instance">`, X3D},
+		{"versioned namespace", decl + `<m:alto xmlns:m="http://www.loc.gov/standards/alto/ns-v4#">`, ALTO},
+		{"namespace of another prefix", decl + `<x:kml xmlns:x="urn:other" xmlns="http://www.opengis.net/kml/2.2">`, XML},
+		{"namespace in an attribute value", decl + `<gpx creator="http://www.topografix.com/GPX/1/1">`, XML},
+		{"prefixed svg", `<x:svg xmlns:x="http://www.w3.org/2000/svg"/>`, SVG},
+		{"prefixed svg with declaration", decl + `<s:svg xmlns:s="http://www.w3.org/2000/svg" width="10">`, SVG},
+		{"uppercase svg", decl + `<SVG xmlns="http://www.w3.org/2000/svg">`, SVG},
+		{"svg namespace of another prefix", decl + `<x:svg xmlns:x="urn:other" xmlns:s="http://www.w3.org/2000/svg">`, XML},
+		{"namespace of a descendant", decl + `<wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs" xmlns:gml="http://www.opengis.net/gml">`, GML},
+	}
+	for _, tc := range tcs {
+		if m := DetectMIME([]byte(tc.in)); m.String() != tc.mime {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.mime, m)
+		}
+	}
+}
+
+func TestDetectMailNegatives(t *testing.T) {
+	tcs := []struct {
+		name, in, mime string
+	}{
+		{"yaml config", "from: staging\nto: production\ndate: 2024-05-06\nsubject: release\n", Text},
+		{"yaml with addresses", "from: ci@example.org\nto: dev@example.org\nsubject: build\n\nsteps: 3\n", Text},
+		{"no empty line", "From: alice@example.org\nMessage-ID: <1@example.org>\n", Text},
+		{"from without address", "From: Alice\nMIME-Version: 1.0\n\nHi\n", Text},
+		{"eml", "From: Alice <alice@example.org>\nMIME-Version: 1.0\n\nHi\n", EML},
+		{"qmail recipient only", "Tbob@example.com\x00", OctetStream},
+		{"qmail without recipient", "u1001\x00p4242\x00Falice@example.org\x00", OctetStream},
+		{"qmail without ids", "Falice@example.org\x00Tbob@example.com\x00", OctetStream},
+		{"qmail", "u1001\x00p4242\x00Falice@example.org\x00Tbob@example.com\x00\x00", QmailQueue},
+	}
+	for _, tc := range tcs {
+		if m := DetectMIME([]byte(tc.in)); m.String() != tc.mime {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.mime, m)
+		}
+	}
+}
+
+func TestDetectBrfNegatives(t *testing.T) {
+	tcs := []struct {
+		name, in, mime string
+	}{
+		{"csv", "a,b,c\n1,2,3\n4,5,6\n", CSV},
+		{"semicolon csv", "a;b;c\n1;2;3\n4;5;6\n", CSV},
+		{"quoted csv", "name,desc\n\"a\",\"x, y\"\n\"b\",\"z, w\"\n", CSV},
+		{"vcard", "BEGIN:VCARD\nVERSION:3.0\nFN:DOE\nEND:VCARD\n", VCard},
+		{"icalendar", "BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//X//Y//EN\nEND:VCALENDAR\n", ICalendar},
+		{"shell script", "#!/bin/bash\nset -e\necho \"$HOME\"\nexit 0\n", Text},
+		{"go", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1)\n}\n", Text},
+		{"ini", "[section]\nkey=value\nother=1\n", Text},
+		{"yaml", "name: app\nversion: 1\nitems:\n  - a\n  - b\n", Text},
+		{"brf", ",! QUICK BR[N FOX JUMPS OV} ! LAZY DOG4\n,! PAGE ENDS ) A FORM FEED4\n", BRF},
+	}
+	for _, tc := range tcs {
+		if m := DetectMIME([]byte(tc.in)); m.String() != tc.mime {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.mime, m)
+		}
+	}
+}
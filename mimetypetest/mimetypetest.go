@@ -0,0 +1,182 @@
+// Package mimetypetest provides assertions for the tests of programs using
+// mimetype, like upload handlers, so they can check in their own CI how the
+// files they accept are detected.
+//
+// Single files are checked with AssertMIME and AssertBytes:
+//
+//	mimetypetest.AssertMIME(t, "testdata/avatar.png", "image/png")
+//
+// Directories of sample files, corpora, are checked with AssertGolden
+// against a golden file listing the expected type of each sample, one
+// "path: type" line per file, like the output of the mimetype command. The
+// golden file is written by WriteGolden, typically behind a flag of the
+// test, and then reviewed:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	func TestUploads(t *testing.T) {
+//		if *update {
+//			if err := mimetypetest.WriteGolden("testdata/uploads", "testdata/uploads.golden"); err != nil {
+//				t.Fatal(err)
+//			}
+//		}
+//		mimetypetest.AssertGolden(t, "testdata/uploads", "testdata/uploads.golden")
+//	}
+//
+// The types are compared like MIME.Is does: their parameters are ignored.
+package mimetypetest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// AssertMIME reports an error through t unless the file at path is
+// detected as want.
+func AssertMIME(t testing.TB, path, want string, opts ...mimetype.Option) {
+	t.Helper()
+	m, err := mimetype.DetectFileMmap(path, opts...)
+	if err != nil {
+		t.Errorf("mimetypetest: %v", err)
+		return
+	}
+	if !m.Is(want) {
+		t.Errorf("mimetypetest: %s: expected %s, got %s", path, want, m)
+	}
+}
+
+// AssertBytes reports an error through t unless data is detected as want.
+func AssertBytes(t testing.TB, data []byte, want string, opts ...mimetype.Option) {
+	t.Helper()
+	if m := mimetype.DetectMIME(data, opts...); !m.Is(want) {
+		t.Errorf("mimetypetest: expected %s, got %s", want, m)
+	}
+}
+
+// AssertGolden reports an error through t for each file of the directory
+// tree dir not detected as the golden file lists, and for each file present
+// on only one side. Lines of the golden file starting with '#' are comments.
+func AssertGolden(t testing.TB, dir, golden string, opts ...mimetype.Option) {
+	t.Helper()
+	want, err := readGolden(golden)
+	if err != nil {
+		t.Fatalf("mimetypetest: %v", err)
+	}
+	got, err := detectDir(dir, golden, opts)
+	if err != nil {
+		t.Fatalf("mimetypetest: %v", err)
+	}
+
+	for _, path := range got.paths {
+		w, ok := want[path]
+		switch {
+		case !ok:
+			t.Errorf("mimetypetest: %s: not listed in %s, detected as %s", path, golden, got.types[path])
+		case !got.types[path].Is(w):
+			t.Errorf("mimetypetest: %s: expected %s, got %s", path, w, got.types[path])
+		}
+	}
+	var missing []string
+	for path := range want {
+		if _, ok := got.types[path]; !ok {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	for _, path := range missing {
+		t.Errorf("mimetypetest: %s: listed in %s, but not found in %s", path, golden, dir)
+	}
+}
+
+// WriteGolden detects the files of the directory tree dir and writes their
+// types to the golden file, replacing it.
+func WriteGolden(dir, golden string, opts ...mimetype.Option) error {
+	got, err := detectDir(dir, golden, opts)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	for _, path := range got.paths {
+		fmt.Fprintf(&b, "%s: %s\n", path, got.types[path])
+	}
+
+	return ioutil.WriteFile(golden, b.Bytes(), 0644)
+}
+
+// corpus holds the detected types of the files of a directory tree, indexed
+// by their slash separated path relative to the directory.
+type corpus struct {
+	paths []string // sorted
+	types map[string]*mimetype.MIME
+}
+
+// detectDir detects the regular files of the directory tree dir, except the
+// golden file.
+func detectDir(dir, golden string, opts []mimetype.Option) (*corpus, error) {
+	golden, err := filepath.Abs(golden)
+	if err != nil {
+		return nil, err
+	}
+	got := &corpus{types: map[string]*mimetype.MIME{}}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && abs == golden {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		m, err := mimetype.DetectFileMmap(path, opts...)
+		if err != nil {
+			return err
+		}
+		got.paths = append(got.paths, filepath.ToSlash(rel))
+		got.types[filepath.ToSlash(rel)] = m
+		return nil
+	})
+	// Walk visits the files in lexical order, but the slashes may change it.
+	sort.Strings(got.paths)
+
+	return got, err
+}
+
+// readGolden returns the types listed in the golden file, indexed by path.
+func readGolden(golden string) (map[string]string, error) {
+	f, err := os.Open(golden)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	want := map[string]string{}
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		// Paths may hold ": ", types do not.
+		i := strings.LastIndex(l, ": ")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: expected a \"path: type\" line", golden, line)
+		}
+		want[l[:i]] = l[i+2:]
+	}
+
+	return want, s.Err()
+}
@@ -0,0 +1,108 @@
+package mimetypetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder records the failures reported through it, instead of failing
+// the test running it.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+const testDataDir = "../testdata"
+
+func TestAssertMIME(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertMIME(r, filepath.Join(testDataDir, "png.png"), "image/png")
+	AssertBytes(r, []byte("%PDF-1.7"), "application/pdf")
+	// Parameters are ignored.
+	AssertBytes(r, []byte("plain text"), "text/plain; charset=iso-8859-1")
+	if len(r.errors) != 0 {
+		t.Errorf("expected no failures, got %q", r.errors)
+	}
+
+	AssertMIME(r, filepath.Join(testDataDir, "png.png"), "image/jpeg")
+	AssertMIME(r, filepath.Join(testDataDir, "missing"), "image/png")
+	AssertBytes(r, []byte("plain text"), "application/pdf")
+	if len(r.errors) != 3 {
+		t.Errorf("expected 3 failures, got %q", r.errors)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimetypetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	corpus := filepath.Join(dir, "corpus")
+	for name, data := range map[string]string{
+		"a.pdf":        "%PDF-1.7",
+		"sub/b.html":   "<html><body></body></html>",
+		"sub/c: d.txt": "text",
+	} {
+		path := filepath.Join(corpus, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden := filepath.Join(dir, "corpus.golden")
+	if err := WriteGolden(corpus, golden); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a.pdf: application/pdf\nsub/b.html: text/html; charset=utf-8\nsub/c: d.txt: text/plain\n"
+	if string(out) != want {
+		t.Errorf("expected golden file:\n%s\ngot:\n%s", want, out)
+	}
+
+	r := &recorder{TB: t}
+	AssertGolden(r, corpus, golden)
+	if len(r.errors) != 0 {
+		t.Errorf("expected no failures, got %q", r.errors)
+	}
+
+	// A changed type, an unlisted file and a missing file.
+	edited := "# comment\n\na.pdf: image/png\nsub/b.html: text/html\ngone.txt: text/plain\n"
+	if err := ioutil.WriteFile(golden, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(r, corpus, golden)
+	if len(r.errors) != 3 ||
+		!strings.Contains(r.errors[0], "a.pdf: expected image/png") ||
+		!strings.Contains(r.errors[1], "c: d.txt: not listed") ||
+		!strings.Contains(r.errors[2], "gone.txt: listed") {
+		t.Errorf("unexpected failures: %q", r.errors)
+	}
+
+	r = &recorder{TB: t}
+	AssertGolden(r, corpus, filepath.Join(dir, "missing.golden"))
+	if !r.fatal {
+		t.Errorf("expected a fatal failure for a missing golden file")
+	}
+}
@@ -0,0 +1,158 @@
+package mimetype
+
+import (
+	"io"
+	"os"
+)
+
+// DetectFileMmap detects the MIME type of the provided file by mapping it
+// into memory, so the matchers examine its bytes without any read syscall.
+// Besides the head of the file, the end of it is examined too, for formats
+// keeping metadata in a trailer, like the XFA forms of PDF documents.
+//
+// On platforms without mmap support, and for files which cannot be mapped,
+// the head and the end of the file are read instead.
+func DetectFileMmap(path string, opts ...Option) (*MIME, error) {
+	c := newConfig(opts)
+	f, err := os.Open(path)
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	size := info.Size()
+	if size == 0 {
+		return c.detect(nil), nil
+	}
+
+	data, unmap, err := mmapFile(f, size)
+	if err == nil {
+		defer unmap()
+		l := int64(c.readLimit())
+		head, tail := data, []byte(nil)
+		if size > l {
+			head = data[:l]
+			if need := int64(c.deepLimit(head)); need > l {
+				head = data[:min64(size, need)]
+			}
+			if h := int64(len(head)); size > h {
+				tail = data[len(data)-int(min64(size-h, l)):]
+			}
+		}
+		_, m := c.detectWithTail(head, tail)
+		return m, nil
+	}
+
+	head, tail, err := c.readHeadAndTail(f, size)
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	_, m := c.detectWithTail(head, tail)
+
+	return m, nil
+}
+
+// DetectReaderAt detects the MIME type of the size bytes of r. Like with
+// DetectFileMmap, besides the head of the input, its end is examined too.
+// r is read at absolute offsets, so seekable sources, like *os.File or
+// multipart.File, do not need to be rewound after the detection.
+func DetectReaderAt(r io.ReaderAt, size int64, opts ...Option) (*MIME, error) {
+	_, m, err := newConfig(opts).detectReaderAt(r, size)
+
+	return m, err
+}
+
+// detectReaderAt is like DetectReaderAt, but also returns the detected node.
+func (c *config) detectReaderAt(r io.ReaderAt, size int64) (*node, *MIME, error) {
+	if size <= 0 {
+		n, m := c.detectWithTail(nil, nil)
+		return n, m, nil
+	}
+	head, tail, err := c.readHeadAndTail(r, size)
+	if err != nil {
+		return root, newMIME(root, nil), err
+	}
+	n, m := c.detectWithTail(head, tail)
+
+	return n, m, nil
+}
+
+// detectWithTail is like detect, but also extracts the metadata found in
+// tail, the end of the input not covered by head, which may be empty.
+// It returns the detected node along with the result, which does not
+// reference head and tail, which may be unmapped.
+func (c *config) detectWithTail(head, tail []byte) (*node, *MIME) {
+	n, head := c.detectNode(head)
+	m := c.result(n, head)
+	if len(tail) != 0 {
+		m.addTailMeta(n, tail)
+	}
+
+	return n, m
+}
+
+// addTailMeta adds the metadata the node n and its ancestors find in tail.
+func (m *MIME) addTailMeta(n *node, tail []byte) {
+	for ; n != nil; n = n.parent {
+		if n.tailMetaFunc != nil {
+			m.addMeta(n.tailMetaFunc(tail))
+		}
+	}
+}
+
+// hasTailMeta reports whether the node n or one of its ancestors extracts
+// metadata from the end of the input.
+func hasTailMeta(n *node) bool {
+	for ; n != nil; n = n.parent {
+		if n.tailMetaFunc != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readHeadAndTail reads, from the input f of the given size, the bytes
+// examined during detection, along with up to as many bytes as the read
+// limit from its end. The head is read past the read limit for the matchers
+// declaring a larger one, like deepLimit tells.
+func (c *config) readHeadAndTail(f io.ReaderAt, size int64) (head, tail []byte, err error) {
+	l := int64(c.readLimit())
+	head = make([]byte, min64(size, l))
+	if _, err := f.ReadAt(head, 0); err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if size <= l {
+		return head, nil, nil
+	}
+	if need := int64(c.deepLimit(head)); need > l {
+		grown := make([]byte, min64(size, need))
+		copy(grown, head)
+		if _, err := f.ReadAt(grown[l:], l); err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		head = grown
+	}
+	h := int64(len(head))
+	if size <= h {
+		return head, nil, nil
+	}
+	tail = make([]byte, min64(size-h, l))
+	if _, err := f.ReadAt(tail, size-int64(len(tail))); err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	return head, tail, nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package mimetype
+
+import (
+	"errors"
+	"os"
+)
+
+// mmapFile is not supported on this platform, so DetectFileMmap
+// falls back to reading the file.
+func mmapFile(f *os.File, size int64) ([]byte, func(), error) {
+	return nil, nil, errors.New("mimetype: mmap not supported")
+}
@@ -0,0 +1,137 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestDetectFileMmap(t *testing.T) {
+	for f, n := range files {
+		m, err := DetectFileMmap(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.String() != n.mime {
+			t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+		}
+	}
+	if _, err := DetectFileMmap(filepath.Join(testDataDir, "inexistent")); err == nil {
+		t.Errorf("inexistent: expected an error")
+	}
+}
+
+// TestDetectFileMmapTail checks that the metadata kept at the end of large
+// files is found, whether the file is mapped or read.
+func TestDetectFileMmapTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimetype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	emptyPath := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(emptyPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := DetectFileMmap(emptyPath); err != nil || m.String() != empty.mime {
+		t.Errorf("empty file: expected %s, got %s, %v", empty.mime, m, err)
+	}
+
+	data := []byte("%PDF-1.7\n")
+	data = append(data, bytes.Repeat([]byte("% padding\n"), 1000)...)
+	data = append(data, "1 0 obj\n<< /Fields [] /XFA 2 0 R >>\nendobj\n%%EOF\n"...)
+	path := filepath.Join(dir, "xfa.pdf")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, _ := DetectBytes(data, WithLimit(matchers.ReadLimit)); m.Meta("xfa") != "" {
+		t.Errorf("DetectBytes: the XFA key is past the read limit, got xfa=%q", m.Meta("xfa"))
+	}
+	m, err := DetectFileMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Meta("xfa") != "true" {
+		t.Errorf("DetectFileMmap: expected xfa=true, got %q", m.Meta("xfa"))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c := newConfig(nil)
+	head, tail, err := c.readHeadAndTail(f, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, m := c.detectWithTail(head, tail); m.Meta("xfa") != "true" {
+		t.Errorf("read fallback: expected xfa=true, got %q", m.Meta("xfa"))
+	}
+}
+
+// TestLargeFilesEntryPoints checks every function detecting files, readers
+// and ranges reads past the default read limit for the formats needing it.
+func TestLargeFilesEntryPoints(t *testing.T) {
+	for f, n := range largeFiles {
+		path := filepath.Join(testDataDir, f)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check := func(entry string, m *MIME, err error) {
+			t.Helper()
+			if err != nil {
+				t.Errorf("%s: %s: %v", f, entry, err)
+			} else if m.String() != n.mime {
+				t.Errorf("%s: %s: expected %s, got %s", f, entry, n.mime, m)
+			}
+		}
+
+		mime, _, err := DetectFile(path)
+		check("DetectFile", &MIME{mime: mime}, err)
+		m, err := DetectReaderMIME(bytes.NewReader(data))
+		check("DetectReaderMIME", m, err)
+		m, err = DetectFileMmap(path)
+		check("DetectFileMmap", m, err)
+		m, err = DetectReaderAt(bytes.NewReader(data), int64(len(data)))
+		check("DetectReaderAt", m, err)
+		requests := 0
+		m, err = DetectRange(rangesOf(data, &requests), int64(len(data)))
+		check("DetectRange", m, err)
+		m, err = DetectMultipart(multipartFile(t, f, data))
+		check("DetectMultipart", m, err)
+		results, err := DetectFiles([]string{path}, 1)
+		check("DetectFiles", results[path], err)
+		for r := range DetectDir(testDataDir) {
+			if r.Path == path {
+				check("DetectDir", r.MIME, r.Err)
+			}
+		}
+	}
+}
+
+// multipartFile returns the header of data uploaded as a form file.
+func multipartFile(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(data)
+	w.Close()
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return form.File["file"][0]
+}
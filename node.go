@@ -1,5 +1,7 @@
 package mimetype
 
+import "github.com/gabriel-vasile/mimetype/internal/matchers"
+
 type (
 	// node represents a vertex in the matchers tree structure.
 	// It holds the mime type, the extension and the function
@@ -8,31 +10,204 @@ type (
 		mime      string
 		extension string
 		matchFunc func([]byte) bool
-		children  []*node
+		// metaFunc optionally extracts metadata from inputs matching the node.
+		metaFunc func([]byte) map[string]string
+		// tailMetaFunc optionally extracts metadata from the end of the inputs
+		// matching the node, for formats keeping it in a trailer. It is only
+		// used when the end of the input is available, as with DetectFileMmap.
+		tailMetaFunc func([]byte) map[string]string
+		// scoreFunc optionally rates, between 0 and 1, how likely an input
+		// matching the node is to have its type. When several siblings having
+		// a scoreFunc match an input, the one with the highest score wins.
+		scoreFunc func([]byte) float64
+		// streamFunc optionally returns a matcher equivalent to matchFunc,
+		// fed the input in chunks by DetectReader. It lets nodes looking at
+		// the whole input be rejected before all of it is read.
+		streamFunc func() matchers.Stream
+		// decodeFunc optionally converts the inputs matching the node before
+		// they are passed to its children, like text encoded in UTF-16 which
+		// the matchers of the text formats expect in UTF-8.
+		decodeFunc func([]byte) []byte
+		// depth is the number of bytes, counted from the start of the input,
+		// matchFunc needs to decide. Zero means matchFunc may inspect
+		// the input up to the read limit.
+		depth int
+		// minBytes is the length the input must have for matchFunc to pass.
+		// Shorter inputs are not passed to matchFunc at all.
+		minBytes int
+		// readLimit is the number of bytes the node and its children need,
+		// when it is larger than the default read limit. Zero means the
+		// default read limit is enough.
+		readLimit int
+		// prefixes are the signatures the inputs matching the node start with.
+		// They are optional and only used to build the index of the parent.
+		prefixes [][]byte
+		// index narrows the children to try by looking up the first bytes
+		// of the input. It is nil if no child declares prefixes.
+		index *prefixIndex
+		// priority orders the children of a node: children with a higher
+		// priority are tried first. Built-in nodes have priority 0.
+		priority int
+		parent   *node
+		children []*node
 	}
 )
 
 func newNode(mime, extension string, matchFunc func([]byte) bool, children ...*node) *node {
-	return &node{
+	n := &node{
 		mime:      mime,
 		extension: extension,
 		matchFunc: matchFunc,
 		children:  children,
 	}
+	for _, c := range children {
+		c.parent = n
+	}
+	n.buildPrefixIndex()
+
+	return n
+}
+
+// withMeta sets the function used to extract metadata for the node.
+func (n *node) withMeta(metaFunc func([]byte) map[string]string) *node {
+	n.metaFunc = metaFunc
+	return n
+}
+
+// withTailMeta sets the function used to extract metadata from the end of the input.
+func (n *node) withTailMeta(tailMetaFunc func([]byte) map[string]string) *node {
+	n.tailMetaFunc = tailMetaFunc
+	return n
+}
+
+// withScore sets the function used to choose between siblings matching the same input.
+func (n *node) withScore(scoreFunc func([]byte) float64) *node {
+	n.scoreFunc = scoreFunc
+	return n
+}
+
+// withStream sets the function returning the stream matcher of the node.
+func (n *node) withStream(streamFunc func() matchers.Stream) *node {
+	n.streamFunc = streamFunc
+	return n
+}
+
+// withDecoder sets the function converting the input for the children of the node.
+func (n *node) withDecoder(decodeFunc func([]byte) []byte) *node {
+	n.decodeFunc = decodeFunc
+	return n
+}
+
+// decode returns in as seen by the children of n: converted by the
+// decodeFunc of n and of its ancestors, if any.
+func (n *node) decode(in []byte) []byte {
+	var decoders []func([]byte) []byte
+	for ; n != nil; n = n.parent {
+		if n.decodeFunc != nil {
+			decoders = append(decoders, n.decodeFunc)
+		}
+	}
+	for i := len(decoders) - 1; i >= 0; i-- {
+		in = decoders[i](in)
+	}
+
+	return in
+}
+
+// withDepth sets the number of bytes the matcher of the node needs.
+func (n *node) withDepth(depth int) *node {
+	n.depth = depth
+	return n
+}
+
+// withMinBytes sets the length the input must have for the matcher of the node to pass.
+func (n *node) withMinBytes(minBytes int) *node {
+	n.minBytes = minBytes
+	return n
+}
+
+// withReadLimit declares the number of bytes the node and its children need
+// to be matched, for formats whose signatures lie past the default read limit.
+// DetectReader reads that many bytes from inputs no matcher passes for.
+func (n *node) withReadLimit(readLimit int) *node {
+	n.readLimit = readLimit
+	return n
+}
+
+// appendChild adds c as a child of n, after the children having
+// the same or a higher priority.
+func (n *node) appendChild(c *node) {
+	c.parent = n
+	i := len(n.children)
+	for i > 0 && n.children[i-1].priority < c.priority {
+		i--
+	}
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = c
+	n.buildPrefixIndex()
 }
 
 // match does a depth-first search on the matchers tree.
 // it returns the deepest successful matcher for which all the children fail.
+// When the first successful child has a score function, the following
+// siblings having one are tried too, and the best scored one is chosen.
 func (n *node) match(in []byte, deepestMatch *node) *node {
-	for _, c := range n.children {
-		if c.matchFunc(in) {
-			return c.match(in, c)
+	if n.index.usable(n) {
+		var found [maxCandidates]int
+		if nFound := n.index.lookup(in, &found); nFound != -1 {
+			if i := n.index.firstPassing(n, in, found[:nFound]); i != -1 {
+				return n.matchChild(i, in)
+			}
+			return deepestMatch
+		}
+	}
+	for i, c := range n.children {
+		if c.passes(in) {
+			return n.matchChild(i, in)
 		}
 	}
 
 	return deepestMatch
 }
 
+// matchChild continues the search below the child at position i, the first
+// child passing for in. When it has a score function, the following siblings
+// having one are tried too, and the best scored one is chosen.
+func (n *node) matchChild(i int, in []byte) *node {
+	c := n.children[i]
+	if c.scoreFunc != nil {
+		c = bestScored(n.children[i:], in)
+	}
+	if c.decodeFunc != nil {
+		in = c.decodeFunc(in)
+	}
+
+	return c.match(in, c)
+}
+
+// passes reports whether the matcher of n passes for in.
+func (n *node) passes(in []byte) bool {
+	return len(in) >= n.minBytes && n.matchFunc(in)
+}
+
+// bestScored returns the node with the highest score among the candidates
+// having a score function and passing for in. The first candidate must pass.
+// Ties are won by the first candidate, to keep the order of the tree.
+func bestScored(candidates []*node, in []byte) *node {
+	best, bestScore := candidates[0], candidates[0].scoreFunc(in)
+	for _, c := range candidates[1:] {
+		if c.scoreFunc == nil || !c.passes(in) {
+			continue
+		}
+		if score := c.scoreFunc(in); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+
+	return best
+}
+
 func (n *node) flatten() []*node {
 	out := []*node{n}
 	for _, c := range n.children {
@@ -41,3 +216,32 @@ func (n *node) flatten() []*node {
 
 	return out
 }
+
+// findNode returns the first node of the matchers tree having the provided
+// MIME type, or nil if there is none. MIME type parameters are ignored.
+func findNode(mime string) *node {
+	if nodes := findNodes(mime); len(nodes) > 0 {
+		return nodes[0]
+	}
+
+	return nil
+}
+
+// findNodes returns all the nodes of the matchers tree having the provided
+// MIME type, in tree order, since variants of a format, like the zip, tar and
+// gzip ones of Kustomize, share their MIME type. OctetStream resolves to the
+// root only, the other nodes having it being formats without a type of their own.
+func findNodes(mime string) []*node {
+	mime = mediaType(mime)
+	if mime == root.mime {
+		return []*node{root}
+	}
+	var nodes []*node
+	for _, n := range root.flatten() {
+		if mediaType(n.mime) == mime {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
+}
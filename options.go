@@ -0,0 +1,348 @@
+package mimetype
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// Option configures the behavior of the Detect functions.
+// Options not relevant to a function are ignored by it.
+type Option func(*config)
+
+type config struct {
+	limit          int
+	hint           string
+	subtree        string
+	charset        bool
+	strictJSON     bool
+	followSymlinks bool
+	maxSize        int64
+	pollInterval   time.Duration
+	priority       int
+	parallel       bool
+	cache          *Cache
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// WithLimit sets the maximum number of bytes examined during detection.
+// Functions reading their input from a reader or a file read at most limit
+// bytes, instead of the default ReadLimit. Functions receiving the input as
+// a byte slice examine only its first limit bytes, instead of all of it.
+func WithLimit(limit int) Option {
+	return func(c *config) {
+		c.limit = limit
+	}
+}
+
+// WithHint provides the name of the file being detected. The extension of
+// the name is trusted only when it refines the detected type, that is
+// when it belongs to one of its subtypes whose matcher passes, or to the
+// only subtype having that extension. For example, a CSV file which could
+// only be detected as text/plain is reported as text/csv when the hint is
+// "file.csv", but a PNG file is always reported as image/png, and contents
+// of unknown type stay application/octet-stream whatever the hint.
+func WithHint(name string) Option {
+	return func(c *config) {
+		c.hint = name
+	}
+}
+
+// WithSubtree starts the detection from the node of the parent MIME type,
+// like DetectUnder does. The option is ignored if parent is not part of
+// the matchers tree.
+func WithSubtree(parent string) Option {
+	return func(c *config) {
+		c.subtree = parent
+	}
+}
+
+// WithCharset adds a charset parameter to the detected text formats
+// which do not declare one, based on the byte order mark and on the
+// validity of the input as UTF-8. Other inputs are told apart by the
+// statistics of their bytes, among Shift_JIS, GBK, EUC-KR, windows-1251,
+// windows-1252, and iso-8859-1, -2 and -5, the last being the fallback.
+// Text encoded in UTF-16 or UTF-32 gets one even without this option.
+func WithCharset() Option {
+	return func(c *config) {
+		c.charset = true
+	}
+}
+
+// WithStrictJSON validates the inputs detected as text, JSON or NDJSON
+// against the JSON grammar, instead of the lenient heuristic, which
+// accepts any JSON value, like a single number or a quoted string. Inputs
+// are then reported as application/json only when they hold an object or
+// an array, possibly after a UTF-8 byte order mark and any amount of
+// whitespace, and as application/x-ndjson only when they hold at least two
+// of them, one per line. Other inputs, like JavaScript code with object
+// literals, comments or trailing commas, are reported as text/plain.
+// Only the examined bytes are validated: inputs reaching the read limit
+// only need to be a valid beginning of a document.
+func WithStrictJSON() Option {
+	return func(c *config) {
+		c.strictJSON = true
+	}
+}
+
+// WithFollowSymlinks makes DetectDir and Watch follow symbolic links. By default,
+// symbolic links are skipped. Directories reachable through several
+// links are walked only once.
+func WithFollowSymlinks() Option {
+	return func(c *config) {
+		c.followSymlinks = true
+	}
+}
+
+// WithMaxSize makes DetectDir, DetectFiles and Watch skip the files
+// larger than size bytes.
+func WithMaxSize(size int64) Option {
+	return func(c *config) {
+		c.maxSize = size
+	}
+}
+
+// WithPollInterval sets the time Watch waits between two scans of the
+// watched directory, instead of one second.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.pollInterval = d
+	}
+}
+
+// WithPriority sets the priority of the matcher added by Extend. Children
+// with a higher priority are tried first. Built-in matchers have priority 0,
+// so a positive priority makes a custom matcher win over the built-in ones.
+func WithPriority(priority int) Option {
+	return func(c *config) {
+		c.priority = priority
+	}
+}
+
+// WithParallel evaluates the top-level matchers concurrently, on at most
+// GOMAXPROCS goroutines. The result is the same as the one of a sequential
+// detection: the first matcher passing, in the order of the tree, wins.
+// It can speed up the detection of inputs matched late, or not at all,
+// at the cost of starting goroutines for each detection. Matchers added
+// with Extend must then be safe for concurrent use.
+func WithParallel() Option {
+	return func(c *config) {
+		c.parallel = true
+	}
+}
+
+// ReadLimit is the default number of bytes read from readers and files.
+const ReadLimit = matchers.ReadLimit
+
+// readLimit returns the number of bytes to read from readers and files.
+func (c *config) readLimit() int {
+	if c.limit > 0 {
+		return c.limit
+	}
+
+	return ReadLimit
+}
+
+// bufPool holds the buffers of ReadLimit bytes used by the detection
+// functions which do not hand the bytes they read back to the caller.
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, matchers.ReadLimit)
+		return &b
+	},
+}
+
+// getBuf returns a buffer of the read limit length, taken from bufPool
+// when the default read limit is used. It must be released with putBuf.
+func (c *config) getBuf() *[]byte {
+	if c.readLimit() != matchers.ReadLimit {
+		b := make([]byte, c.readLimit())
+		return &b
+	}
+
+	return bufPool.Get().(*[]byte)
+}
+
+// putBuf returns a buffer obtained from getBuf to bufPool.
+func (c *config) putBuf(b *[]byte) {
+	if len(*b) == matchers.ReadLimit {
+		bufPool.Put(b)
+	}
+}
+
+// readHead reads the bytes used for detection from r.
+func (c *config) readHead(r io.Reader) ([]byte, error) {
+	return readInto(r, make([]byte, c.readLimit()))
+}
+
+// readInto reads from r until buf is full or r is exhausted,
+// and returns the part of buf holding the bytes read.
+func readInto(r io.Reader, buf []byte) ([]byte, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	return buf[:n], err
+}
+
+// detectNode returns the node matching in, along with the part of in
+// examined during detection.
+func (c *config) detectNode(in []byte) (*node, []byte) {
+	if c.limit > 0 && len(in) > c.limit {
+		in = in[:c.limit]
+	}
+	var n *node
+	if c.cache != nil {
+		n = c.cache.detect(c.start(in), in, c.parallel)
+	} else {
+		n = detectFrom(c.start(in), in, c.parallel)
+	}
+	if c.strictJSON {
+		n = c.strictJSONNode(n, in)
+	}
+	if c.hint != "" {
+		n = hintedNode(n, c.hint, in)
+	}
+
+	return n, in
+}
+
+// start returns the node the detection of in starts from. When several
+// nodes have the subtree MIME type, the first whose matcher passes for in
+// is chosen, or else the first of them, so the variants of a format are
+// told apart. A single node is chosen without checking its matcher.
+func (c *config) start(in []byte) *node {
+	if c.subtree == "" {
+		return root
+	}
+	nodes := findNodes(c.subtree)
+	if len(nodes) == 0 {
+		return root
+	}
+	if len(nodes) > 1 {
+		for _, n := range nodes {
+			if n.passes(n.parent.decode(in)) {
+				return n
+			}
+		}
+	}
+
+	return nodes[0]
+}
+
+// detect returns the detection result of in, including its metadata.
+func (c *config) detect(in []byte) *MIME {
+	return c.result(c.detectNode(in))
+}
+
+// result returns the detection result for node n matching in.
+func (c *config) result(n *node, in []byte) *MIME {
+	m := newMIME(n, n.decode(in))
+	m.mime = c.mimeOf(n, in)
+
+	return m
+}
+
+// mimeOf returns the MIME type of n, with a charset parameter if requested.
+// Text encoded in UTF-16 or UTF-32 always gets one, replacing the charset
+// the type of n declares, if any, since it cannot be read as UTF-8.
+func (c *config) mimeOf(n *node, in []byte) string {
+	if kindOf(n) == KindText {
+		if cs := matchers.WideCharset(in); cs != "" {
+			mime := n.mime
+			if i := strings.Index(mime, "; charset="); i >= 0 {
+				mime = mime[:i]
+			}
+			return mime + "; charset=" + cs
+		}
+	}
+	if !c.charset || kindOf(n) != KindText || strings.Contains(n.mime, "charset=") {
+		return n.mime
+	}
+
+	return n.mime + "; charset=" + charset(in)
+}
+
+// strictJSONNode returns the node of in validated as JSON or NDJSON when n
+// is plain text, JSON or NDJSON, and n otherwise. The refined node must be
+// part of the subtree the detection starts from.
+func (c *config) strictJSONNode(n *node, in []byte) *node {
+	if n != txt && n != ndJson && !descends(n, json) || !descends(txt, c.start(in)) {
+		return n
+	}
+	in = txt.decode(in)
+	switch {
+	case matchers.StrictJson(in):
+		if descends(n, json) {
+			return n
+		}
+		// The heuristic rejected the input, so the subtypes were not tried.
+		return json.match(bytes.TrimPrefix(in, []byte{0xEF, 0xBB, 0xBF}), json)
+	case matchers.StrictNdJson(in):
+		return ndJson
+	}
+
+	return txt
+}
+
+// descends reports whether n is a or one of its descendants.
+func descends(n, a *node) bool {
+	for ; n != nil; n = n.parent {
+		if n == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hintedNode returns the descendant of n, detected for in, selected by the
+// extension of the file name hint, or n if there is none. The hint is not
+// trusted to give a type to unknown contents, nor to replace a type having
+// its extension already: n must not be the root and must have another
+// extension. Among the descendants having the extension, the first whose
+// matcher passes for in is selected, or the only one when there is a
+// single one, like text/csv for text which could not be told apart.
+func hintedNode(n *node, hint string, in []byte) *node {
+	ext := normalizeExt(filepath.Ext(hint))
+	if ext == "" || n == root || n.extension == ext {
+		return n
+	}
+	found := extensionNodes(n, ext)
+	for _, d := range found {
+		if d.passes(d.parent.decode(in)) {
+			return d
+		}
+	}
+	if len(found) == 1 {
+		return found[0]
+	}
+
+	return n
+}
+
+// extensionNodes returns the descendants of n having the extension ext.
+func extensionNodes(n *node, ext string) []*node {
+	var found []*node
+	for _, d := range n.flatten()[1:] {
+		if d.extension == ext {
+			found = append(found, d)
+		}
+	}
+
+	return found
+}
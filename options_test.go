@@ -0,0 +1,91 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestOptions(t *testing.T) {
+	png, err := ioutil.ReadFile("testdata/png.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docx, err := ioutil.ReadFile("testdata/docx.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, name := range []string{"zip.zip", "tar.tar", "json.json", "xml.xml"} {
+		if files[name], err = ioutil.ReadFile("testdata/" + name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tCases := []struct {
+		name     string
+		in       []byte
+		opts     []Option
+		expected string
+	}{
+		{"no options", png, nil, "image/png"},
+		{"limit too small", docx, []Option{WithLimit(4)}, "application/zip"},
+		{"hint refining", []byte("hello world\n"), []Option{WithHint("x.csv")}, "text/csv"},
+		{"hint not refining", png, []Option{WithHint("x.csv")}, "image/png"},
+		{"hint without extension", png, []Option{WithHint("png")}, "image/png"},
+		{"hint for unknown", []byte{0x8f, 0x03, 0xa1, 0x5e, 0x00, 0xc7}, []Option{WithHint("evil.pdf")}, "application/octet-stream"},
+		{"hint not matching zip", files["zip.zip"], []Option{WithHint("a.zip")}, "application/zip"},
+		{"hint not matching tar", files["tar.tar"], []Option{WithHint("a.tar")}, "application/x-tar"},
+		{"hint not matching json", files["json.json"], []Option{WithHint("a.json")}, "application/json"},
+		{"hint not matching xml", files["xml.xml"], []Option{WithHint("a.xml")}, "text/xml; charset=utf-8"},
+		{"hint ambiguous", []byte("hello world\n"), []Option{WithHint("a.yaml")}, "text/plain"},
+		{"subtree", docx, []Option{WithSubtree("application/zip")}, "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"subtree not matching", png, []Option{WithSubtree("application/zip")}, "application/zip"},
+		{"charset utf-8", []byte("hello world\n"), []Option{WithCharset()}, "text/plain; charset=utf-8"},
+		{"charset truncated utf-8", []byte("hello \xe2\x82"), []Option{WithCharset()}, "text/plain; charset=utf-8"},
+		{"charset latin-1", []byte("caf\xe9 au lait\n"), []Option{WithCharset()}, "text/plain; charset=iso-8859-1"},
+		{"charset binary", png, []Option{WithCharset()}, "image/png"},
+	}
+
+	for _, tc := range tCases {
+		if mime, _ := Detect(tc.in, tc.opts...); mime != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, mime)
+		}
+		if m := DetectMIME(tc.in, tc.opts...); m.String() != tc.expected {
+			t.Errorf("%s: expected %s from DetectMIME, got %s", tc.name, tc.expected, m)
+		}
+	}
+}
+
+func TestOptionsLimitReader(t *testing.T) {
+	// An explicit limit is not raised for the formats needing more bytes.
+	mime, _, err := DetectFile("testdata/iso.iso", WithLimit(matchers.ReadLimit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mime != "application/octet-stream" {
+		t.Errorf("expected application/octet-stream without a larger limit, got %s", mime)
+	}
+
+	mime, _, err = DetectFile("testdata/iso.iso", WithLimit(1<<16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mime != "application/x-iso9660-image" {
+		t.Errorf("expected application/x-iso9660-image, got %s", mime)
+	}
+
+	in, err := ioutil.ReadFile("testdata/iso.iso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mime, _, err = DetectReader(bytes.NewReader(in), WithLimit(1<<16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mime != "application/x-iso9660-image" {
+		t.Errorf("expected application/x-iso9660-image from reader, got %s", mime)
+	}
+}
@@ -0,0 +1,70 @@
+package mimetype
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// matchParallel is like match, but evaluates the matchers of the children of n
+// concurrently, on at most GOMAXPROCS goroutines. The first child passing, in
+// the order of the tree, wins, so the result is the same as the one of match.
+// Only the children of n are evaluated concurrently, the search continues
+// sequentially below the winning child.
+func (n *node) matchParallel(in []byte, deepestMatch *node) *node {
+	i := n.firstPassing(in)
+	if i == -1 {
+		return deepestMatch
+	}
+
+	return n.matchChild(i, in)
+}
+
+// firstPassing returns the index of the first child of n passing for in,
+// or -1 if none does. Workers take the children in order and stop once
+// all the children before the best index found so far have been taken.
+func (n *node) firstPassing(in []byte) int {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(n.children) {
+		workers = len(n.children)
+	}
+	if workers <= 1 {
+		for i, c := range n.children {
+			if c.passes(in) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	next := int32(-1)
+	best := int32(len(n.children))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt32(&next, 1)
+				if i >= atomic.LoadInt32(&best) {
+					return
+				}
+				if !n.children[i].passes(in) {
+					continue
+				}
+				for b := atomic.LoadInt32(&best); i < b; b = atomic.LoadInt32(&best) {
+					if atomic.CompareAndSwapInt32(&best, b, i) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if int(best) == len(n.children) {
+		return -1
+	}
+
+	return int(best)
+}
@@ -0,0 +1,177 @@
+package mimetype
+
+import "bytes"
+
+// withPrefix declares the signatures the inputs matching the node start
+// with. The matcher of the node is then only called for inputs starting
+// with one of them, found by looking up the first bytes of the input in
+// the prefix index of the parent. The matcher must not pass for inputs
+// starting otherwise.
+func (n *node) withPrefix(prefixes ...string) *node {
+	for _, p := range prefixes {
+		n.prefixes = append(n.prefixes, []byte(p))
+	}
+	return n
+}
+
+// prefixIndex narrows the children of a node to the ones which may pass
+// for an input: the children whose declared prefix starts the input,
+// found in a byte trie, and the children not declaring prefixes.
+type prefixIndex struct {
+	// children is the list of children the index was built for. The index
+	// is not used if the children of the node were replaced since.
+	children []*node
+	// first holds the tries of the prefixes, by their first byte.
+	first [256]*prefixTrie
+	// unindexed are the positions of the children not declaring prefixes.
+	unindexed []int
+	// small holds, for each input length under smallInput, the positions of
+	// the unindexed children whose minBytes the length reaches. Short inputs,
+	// common in fuzzing and message queues, skip the other children without
+	// looking at them.
+	small [smallInput][]int
+}
+
+// prefixTrie is a byte trie of the declared prefixes, following their first byte.
+type prefixTrie struct {
+	bytes []byte
+	next  []*prefixTrie
+	// ends are the positions of the children having a prefix ending here.
+	ends []int
+}
+
+// maxCandidates is the maximum number of indexed children passing the trie
+// lookup merged without allocating. Above it, all the children are tried.
+const maxCandidates = 16
+
+// smallInput is the length under which inputs only try the unindexed
+// children listed by the small table of the index.
+const smallInput = 16
+
+// buildPrefixIndex builds the prefix index of the children of n,
+// or removes it if none of them declares prefixes.
+func (n *node) buildPrefixIndex() {
+	n.index = nil
+	idx := &prefixIndex{children: n.children}
+	indexed := false
+	for i, c := range n.children {
+		if len(c.prefixes) == 0 {
+			idx.unindexed = append(idx.unindexed, i)
+			continue
+		}
+		indexed = true
+		for _, p := range c.prefixes {
+			if idx.first[p[0]] == nil {
+				idx.first[p[0]] = &prefixTrie{}
+			}
+			idx.first[p[0]].insert(p[1:], i)
+		}
+	}
+	if !indexed {
+		return
+	}
+	for l := range idx.small {
+		for _, i := range idx.unindexed {
+			if n.children[i].minBytes <= l {
+				idx.small[l] = append(idx.small[l], i)
+			}
+		}
+	}
+	n.index = idx
+}
+
+func (t *prefixTrie) insert(prefix []byte, child int) {
+	for _, b := range prefix {
+		i := bytes.IndexByte(t.bytes, b)
+		if i == -1 {
+			t.bytes = append(t.bytes, b)
+			t.next = append(t.next, &prefixTrie{})
+			i = len(t.next) - 1
+		}
+		t = t.next[i]
+	}
+	// A child may declare prefixes which are prefixes of each other.
+	for _, e := range t.ends {
+		if e == child {
+			return
+		}
+	}
+	t.ends = append(t.ends, child)
+}
+
+// lookup stores in found, in increasing order, the positions of the indexed
+// children having a prefix starting in. It returns their number, or -1 if
+// there are more than found can hold, in which case all the children must
+// be tried.
+func (idx *prefixIndex) lookup(in []byte, found *[maxCandidates]int) int {
+	if len(in) == 0 || idx.first[in[0]] == nil {
+		return 0
+	}
+	nFound := 0
+	t := idx.first[in[0]]
+	for k := 1; ; k++ {
+		for _, e := range t.ends {
+			// Insertion sort: the candidates are few.
+			j := nFound
+			for ; j > 0 && found[j-1] > e; j-- {
+			}
+			if j > 0 && found[j-1] == e {
+				// A child declaring prefixes of each other is found twice.
+				continue
+			}
+			if nFound == maxCandidates {
+				return -1
+			}
+			copy(found[j+1:nFound+1], found[j:nFound])
+			found[j] = e
+			nFound++
+		}
+		if k == len(in) {
+			return nFound
+		}
+		if t = t.child(in[k]); t == nil {
+			return nFound
+		}
+	}
+}
+
+// child returns the trie following byte b, or nil if there is none.
+// Most nodes have a single child, so a loop beats bytes.IndexByte.
+func (t *prefixTrie) child(b byte) *prefixTrie {
+	for i, tb := range t.bytes {
+		if tb == b {
+			return t.next[i]
+		}
+	}
+
+	return nil
+}
+
+// firstPassing returns the position of the first child of n passing for in,
+// trying in the tree order only the candidates found by the index and the
+// unindexed children. It returns -1 if none passes.
+func (idx *prefixIndex) firstPassing(n *node, in []byte, found []int) int {
+	u := idx.unindexed
+	if len(in) < smallInput {
+		u = idx.small[len(in)]
+	}
+	for len(u) > 0 || len(found) > 0 {
+		var i int
+		if len(found) == 0 || len(u) > 0 && u[0] < found[0] {
+			i, u = u[0], u[1:]
+		} else {
+			i, found = found[0], found[1:]
+		}
+		if n.children[i].passes(in) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// usable reports whether the index was built for the current children of n.
+func (idx *prefixIndex) usable(n *node) bool {
+	return idx != nil && len(idx.children) == len(n.children) &&
+		(len(n.children) == 0 || &idx.children[0] == &n.children[0])
+}
@@ -0,0 +1,123 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// TestPrefixes checks that the matchers of the nodes declaring prefixes
+// do not pass for the test files starting with none of them.
+func TestPrefixes(t *testing.T) {
+	nodes := root.flatten()
+	for f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range nodes {
+			if len(n.prefixes) == 0 || !n.matchFunc(data) {
+				continue
+			}
+			if !hasAnyPrefix(data, n.prefixes) {
+				t.Errorf("%s: %s passes but does not start with its prefixes", f, n.mime)
+			}
+		}
+	}
+}
+
+func hasAnyPrefix(in []byte, prefixes [][]byte) bool {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(in, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TestPrefixIndex checks the candidates found by the index: the children
+// whose prefix starts the input, in the tree order.
+func TestPrefixIndex(t *testing.T) {
+	n := newNode("parent", "", matchers.True,
+		newNode("a", "", matchers.True).withPrefix("RIFF"),
+		newNode("b", "", matchers.True),
+		newNode("c", "", matchers.True).withPrefix("RI", "RIFX"),
+		newNode("d", "", matchers.True).withPrefix("PK"),
+		newNode("e", "", matchers.True).withMinBytes(8),
+	)
+	tcs := []struct {
+		in   string
+		want []int
+	}{
+		{"RIFF....", []int{0, 2}},
+		{"RIFX", []int{2}},
+		{"PK\x03\x04", []int{3}},
+		{"", nil},
+	}
+	for _, tc := range tcs {
+		var found [maxCandidates]int
+		got := found[:n.index.lookup([]byte(tc.in), &found)]
+		if !equalInts(got, tc.want) {
+			t.Errorf("%q: expected candidates %v, got %v", tc.in, tc.want, got)
+		}
+	}
+	if !equalInts(n.index.unindexed, []int{1, 4}) {
+		t.Errorf("expected unindexed children [1 4], got %v", n.index.unindexed)
+	}
+	// Short inputs skip the unindexed children needing more bytes.
+	if !equalInts(n.index.small[7], []int{1}) || !equalInts(n.index.small[8], []int{1, 4}) {
+		t.Errorf("expected small unindexed children [1] and [1 4], got %v and %v",
+			n.index.small[7], n.index.small[8])
+	}
+
+	// Replacing the children without rebuilding the index disables it.
+	n.children = append([]*node{}, n.children...)
+	if n.index.usable(n) {
+		t.Errorf("index used for children it was not built for")
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func BenchmarkPrefixIndex(b *testing.B) {
+	var data [][]byte
+	for _, f := range []string{"png.png", "gz.gz", "rar.rar", "xz.xz", "html.html", "a.a"} {
+		d, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			b.Fatal(err)
+		}
+		data = append(data, d)
+	}
+
+	b.Run("indexed", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			Detect(data[n%len(data)])
+		}
+	})
+
+	idx := root.index
+	defer func() { root.index = idx }()
+	root.index = nil
+	b.Run("linear", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			Detect(data[n%len(data)])
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package mimetype
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// nearDuplicates lists, for the test files matched by more than one sibling,
+// the siblings passing besides the detected node. The detected node wins
+// because it comes first in the tree; the others are expected to pass too.
+var nearDuplicates = map[string][]string{
+	// APK files are jar archives with an Android manifest.
+	"apk.apk": {JAR},
+	// Security catalogs hold the Authenticode content type among their attributes.
+	"catalog.cat": {Authenticode},
+	// The EPUB package document has the ncx extension used by DAISY 3 books.
+	"epub.epub": {Daisy},
+	// FITS headers are made of 80 characters long ASCII records.
+	"fits.fits": {Text},
+	// A JSON object written on a single line is also a valid NDJSON document.
+	"geojson.1.geojson": {NDJSON},
+	// PDF files without compressed streams are text.
+	"ps.xobject.pdf": {Text},
+	"xfa.pdf":        {Text},
+	// PostScript programs are text.
+	"ps.ps": {Text},
+	// SMTP sessions are text, unless BDAT transfers binary content.
+	"smtp.bdat.txt": {Text},
+	"smtp.data.txt": {Text},
+	// SVG images are XML documents.
+	"svg.svg": {XML},
+	// XrML licenses are signed with an enveloped XML Signature.
+	"xrml.xrm-ms": {XMLDSig},
+	// Without the whole OLE directory in the input, any OLE file is a Doc
+	// file, and the Ppt and Xls heuristics are used.
+	"xls.xls": {Doc},
+	"ppt.ppt": {Doc},
+	"pub.pub": {Ppt, Doc},
+	"msi.msi": {Doc},
+	"msg.msg": {Doc},
+	"vsd.vsd": {Doc},
+}
+
+// TestUniqueMatch checks that every test file is detected as the node it is
+// registered with, and that at each level of the tree no sibling besides the
+// detected node passes, unless the overlap is listed in nearDuplicates.
+func TestUniqueMatch(t *testing.T) {
+	for f, want := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := (&config{}).detectNode(data); n != want {
+			t.Errorf("%s: detected node %s, expected node %s", f, n.mime, want.mime)
+		}
+		if len(data) > matchers.ReadLimit {
+			data = data[:matchers.ReadLimit]
+		}
+
+		var others []string
+		for p := root; ; {
+			var winner *node
+			for i, c := range p.children {
+				if !c.passes(data) {
+					continue
+				}
+				if winner == nil {
+					winner = c
+					if c.scoreFunc != nil {
+						// Siblings chosen by score are expected to overlap.
+						winner = bestScored(p.children[i:], data)
+						break
+					}
+					continue
+				}
+				others = append(others, c.mime)
+			}
+			if winner == nil {
+				break
+			}
+			p = winner
+		}
+		if want := nearDuplicates[f]; !reflect.DeepEqual(others, want) && (len(others) > 0 || len(want) > 0) {
+			t.Errorf("%s: siblings also passing: %v, expected %v", f, others, want)
+		}
+	}
+}
+
+// TestMutations feeds truncated and corrupted versions of the test files
+// to the detection. The matchers must not panic and the results must be
+// the same across calls and between sequential and parallel detection.
+func TestMutations(t *testing.T) {
+	names := make([]string, 0, len(files))
+	for f := range files {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	r := rand.New(rand.NewSource(1))
+	for _, f := range names {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > matchers.ReadLimit {
+			data = data[:matchers.ReadLimit]
+		}
+
+		var inputs [][]byte
+		for l := 0; l < 16 && l < len(data); l++ {
+			inputs = append(inputs, data[:l])
+		}
+		for i := 0; i < 16; i++ {
+			inputs = append(inputs, data[:r.Intn(len(data)+1)])
+
+			flipped := append([]byte{}, data...)
+			for j := 0; j < 1+r.Intn(4); j++ {
+				flipped[r.Intn(len(flipped))] ^= byte(1 + r.Intn(255))
+			}
+			inputs = append(inputs, flipped)
+		}
+
+		for i, in := range inputs {
+			if err := checkDeterministic(in); err != nil {
+				t.Errorf("%s, mutation %d: %v", f, i, err)
+			}
+		}
+	}
+}
+
+// checkDeterministic detects in several times, sequentially and in parallel,
+// and returns an error if the results differ or if detection panics.
+func checkDeterministic(in []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	first, _ := DetectBytes(in)
+	for _, opts := range [][]Option{nil, {WithParallel()}} {
+		m, _ := DetectBytes(in, opts...)
+		if !reflect.DeepEqual(m, first) {
+			return fmt.Errorf("got %v, then %v", first, m)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package mimetype
+
+// raceEnabled reports whether the tests are built with the race detector,
+// which makes sync.Pool drop the items put back at random.
+const raceEnabled = true
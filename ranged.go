@@ -0,0 +1,72 @@
+package mimetype
+
+// RangeFunc fetches length bytes of a remote object, starting at offset,
+// like a GET request with a Range header does. It may return fewer bytes
+// when the range ends past the end of the object.
+type RangeFunc func(offset, length int64) ([]byte, error)
+
+// DetectRange detects the MIME type of a remote object of the given size,
+// fetching only the ranges the detection needs, so objects stored in
+// buckets can be scanned without downloading them:
+//
+//	m, err := mimetype.DetectRange(func(off, n int64) ([]byte, error) {
+//		out, err := client.GetObject(ctx, &s3.GetObjectInput{
+//			Bucket: bucket,
+//			Key:    key,
+//			Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		defer out.Body.Close()
+//		return ioutil.ReadAll(out.Body)
+//	}, size)
+//
+// The head of the object is fetched first, with a single request. The end
+// of the object, holding the trailer of formats like PDF, is fetched with
+// a second request only when the detected type has metadata there.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectRange(fetch RangeFunc, size int64, opts ...Option) (*MIME, error) {
+	c := newConfig(opts)
+	if size <= 0 {
+		return c.detect(nil), nil
+	}
+	l := int64(c.readLimit())
+	head, err := fetchRange(fetch, 0, min64(size, l))
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	if need := int64(c.deepLimit(head)); need > l && size > l {
+		more, err := fetchRange(fetch, l, min64(size, need)-l)
+		if err != nil {
+			return newMIME(root, nil), err
+		}
+		head = append(head, more...)
+	}
+	n, in := c.detectNode(head)
+	m := c.result(n, in)
+	h := int64(len(head))
+	if size <= h || !hasTailMeta(n) {
+		return m, nil
+	}
+
+	tailLen := min64(size-h, l)
+	tail, err := fetchRange(fetch, size-tailLen, tailLen)
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	m.addTailMeta(n, tail)
+
+	return m, nil
+}
+
+// fetchRange calls fetch, dropping the bytes past the requested length
+// returned by servers ignoring the end of the range.
+func fetchRange(fetch RangeFunc, offset, length int64) ([]byte, error) {
+	b, err := fetch(offset, length)
+	if int64(len(b)) > length {
+		b = b[:length]
+	}
+
+	return b, err
+}
@@ -0,0 +1,76 @@
+package mimetype
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// rangesOf returns a RangeFunc serving data and counting the requests.
+func rangesOf(data []byte, requests *int) RangeFunc {
+	return func(off, n int64) ([]byte, error) {
+		*requests++
+		if off > int64(len(data)) {
+			return nil, nil
+		}
+		// Like servers ignoring the end of the range, return everything
+		// after offset.
+		return data[off:], nil
+	}
+}
+
+func TestDetectRange(t *testing.T) {
+	for f, n := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests := 0
+		m, err := DetectRange(rangesOf(data, &requests), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.String() != n.mime {
+			t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+		}
+		// The end is fetched too for formats with a trailer, like PDF.
+		if requests > 2 {
+			t.Errorf("%s: expected at most 2 requests, got %d", f, requests)
+		}
+	}
+}
+
+// TestDetectRangeTail checks that the end of the object is fetched only
+// for the formats keeping metadata there.
+func TestDetectRangeTail(t *testing.T) {
+	padding := bytes.Repeat([]byte("% padding\n"), 1000)
+	pdf := append([]byte("%PDF-1.7\n"), padding...)
+	pdf = append(pdf, "1 0 obj\n<< /Fields [] /XFA 2 0 R >>\nendobj\n%%EOF\n"...)
+	requests := 0
+	m, err := DetectRange(rangesOf(pdf, &requests), int64(len(pdf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != PDF || m.Meta("xfa") != "true" || requests != 2 {
+		t.Errorf("expected %s with xfa=true in 2 requests, got %s with xfa=%q in %d", PDF, m, m.Meta("xfa"), requests)
+	}
+
+	text := bytes.Repeat([]byte("just text\n"), 1000)
+	requests = 0
+	if m, err := DetectRange(rangesOf(text, &requests), int64(len(text))); err != nil || requests != 1 {
+		t.Errorf("expected a single request for %s, got %d, %v", m, requests, err)
+	}
+}
+
+func TestDetectRangeError(t *testing.T) {
+	errFetch := errors.New("fetch error")
+	fail := func(off, n int64) ([]byte, error) { return nil, errFetch }
+	if m, err := DetectRange(fail, 100); err != errFetch || m.String() != OctetStream {
+		t.Errorf("expected %s and the fetch error, got %s, %v", OctetStream, m, err)
+	}
+	if m, err := DetectRange(fail, 0); err != nil || m.String() != empty.mime {
+		t.Errorf("empty object: expected %s without fetching, got %s, %v", empty.mime, m, err)
+	}
+}
@@ -0,0 +1,127 @@
+package mimetype
+
+import (
+	"mime"
+	"path/filepath"
+)
+
+// MIME is the result of a detection. Besides the MIME type and the extension,
+// it holds the metadata some matchers extract from the input, like
+// versions or flags signaling the presence of optional features.
+type MIME struct {
+	mime      string
+	extension string
+	kind      Kind
+	meta      map[string]string
+}
+
+// newMIME creates the detection result for node n matched against in.
+// Metadata is collected from n and all its ancestors, with the values
+// extracted by deeper nodes taking precedence.
+func newMIME(n *node, in []byte) *MIME {
+	m := &MIME{mime: n.mime, extension: n.extension, kind: kindOf(n)}
+	for ; n != nil; n = n.parent {
+		if n.metaFunc != nil {
+			m.addMeta(n.metaFunc(in))
+		}
+	}
+
+	return m
+}
+
+// addMeta adds to the metadata of m the keys of meta it does not hold yet.
+func (m *MIME) addMeta(meta map[string]string) {
+	for k, v := range meta {
+		if m.meta == nil {
+			m.meta = map[string]string{}
+		}
+		if _, ok := m.meta[k]; !ok {
+			m.meta[k] = v
+		}
+	}
+}
+
+// String returns the MIME type, including its parameters, if any.
+func (m *MIME) String() string {
+	return m.mime
+}
+
+// StringWithCodecs returns the MIME type with a codecs parameter, as defined
+// by RFC 6381, listing the codecs found in the tracks of audio and video
+// containers, like `video/webm; codecs="vp9,opus"`. The MIME type is returned
+// unchanged when no codec could be identified, for example because the
+// track headers are not part of the input.
+func (m *MIME) StringWithCodecs() string {
+	codecs := m.meta["codecs"]
+	if codecs == "" {
+		return m.mime
+	}
+	if s := mime.FormatMediaType(m.mime, map[string]string{"codecs": codecs}); s != "" {
+		return s
+	}
+
+	return m.mime
+}
+
+// Extension returns the file extension associated with the MIME type.
+// It is empty string if the detected format does not have an extension.
+func (m *MIME) Extension() string {
+	return m.extension
+}
+
+// Kind returns the broad category of the detected file format.
+func (m *MIME) Kind() Kind {
+	return m.kind
+}
+
+// Is checks whether the detected MIME type is equal to expected.
+// MIME type parameters are ignored and the comparison is case insensitive.
+func (m *MIME) Is(expected string) bool {
+	return mediaType(m.mime) == mediaType(expected)
+}
+
+// FitsExtension reports whether the extension of the file name fits the
+// detected type. Unknown extensions fit any type, and so do the extensions
+// of the subtypes of m: a docx file whose entries are past the read limit is
+// detected as a zip archive, which is not a mismatch. Unknown contents,
+// detected as application/octet-stream, fit no known extension.
+func (m *MIME) FitsExtension(name string) bool {
+	ext := normalizeExt(filepath.Ext(name))
+	if ext == "" || TypeByExtension(ext) == "" || m.mime == empty.mime {
+		return true
+	}
+	if m.mime == root.mime {
+		return false
+	}
+	if normalizeExt(m.extension) == ext {
+		return true
+	}
+	for _, e := range ExtensionsByType(m.mime) {
+		if normalizeExt(e) == ext {
+			return true
+		}
+	}
+	for _, n := range findNodes(m.mime) {
+		if len(extensionNodes(n, ext)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Meta returns the metadata value stored under key, or
+// an empty string if the matcher did not extract it.
+func (m *MIME) Meta(key string) string {
+	return m.meta[key]
+}
+
+// Metadata returns a copy of all the metadata extracted from the input.
+func (m *MIME) Metadata() map[string]string {
+	out := make(map[string]string, len(m.meta))
+	for k, v := range m.meta {
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,288 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectMIME(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "html.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := DetectMIME(data)
+	if m.String() != html.mime || m.Extension() != html.extension {
+		t.Errorf("expected %s %s, got %s %s", html.mime, html.extension, m, m.Extension())
+	}
+	if !m.Is("TEXT/HTML") {
+		t.Errorf("%s should be equal to TEXT/HTML when ignoring parameters", m)
+	}
+	if m.Is("text/plain") {
+		t.Errorf("%s should not be equal to text/plain", m)
+	}
+
+	if m := DetectMIME(nil); m.String() != "inode/x-empty" {
+		t.Errorf("expected inode/x-empty for empty input, got %s", m)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	tcs := []struct {
+		file, key, value string
+	}{
+		{"epub.overlays.epub", "media-overlays", "true"},
+		{"epub.epub", "media-overlays", ""},
+		{"grib1.grb", "edition", "1"},
+		{"grib2.grib2", "edition", "2"},
+		{"bufr.bufr", "edition", "4"},
+		{"asdf.asdf", "version", "1.0.0"},
+		{"ab.ab", "version", "5"},
+		{"ab.ab", "compressed", "true"},
+		{"ab.ab", "encryption", "none"},
+		{"ooxml.agile.docx", "encryption", "agile"},
+		{"ooxml.agile.docx", "cipher", "AES-256"},
+		{"ooxml.agile.docx", "hash", "SHA512"},
+		{"ooxml.standard.xlsx", "encryption", "standard"},
+		{"ooxml.standard.xlsx", "cipher", "AES-128"},
+		{"ooxml.standard.xlsx", "hash", "SHA1"},
+		{"zarray.json", "zarr-format", "2"},
+		{"zarray.json", "node-type", "array"},
+		{"zarr.json", "zarr-format", "3"},
+		{"zarr.json", "node-type", "group"},
+		{"lif.lif", "scenes", "2"},
+		{"czi.czi", "scenes", "3"},
+		{"mp4.mp4", "codecs", "avc1.42C01E,mp4a.40.2"},
+		{"mov.mov", "codecs", "avc1.64001E,mp4a.40.2"},
+		{"3gp.3gp", "codecs", "s263,samr"},
+		{"mkv.mkv", "codecs", "mp4v.20,mp4a.40.2"},
+		{"webm.webm", "codecs", "vp8,vorbis"},
+		{"ogg.ogv", "codecs", "theora,vorbis"},
+		{"ogg.spx.oga", "codecs", "speex"},
+		{"ps1.mcr", "confidence", "high"},
+		{"ps1.gme", "confidence", "high"},
+		{"switch.sav", "confidence", "high"},
+		{"wav.wav", "codec", "pcm"},
+		{"wav.gsm.wav", "codec", "gsm610"},
+		{"wav.adpcm.wav", "codec", "ima-adpcm"},
+		{"vault.yml", "version", "1.1"},
+		{"vault.yml", "cipher", "AES256"},
+		{"vault.yml", "label", ""},
+		{"vault.label.yml", "version", "1.2"},
+		{"vault.label.yml", "label", "prod"},
+		{"sops.yaml", "version", "3.8.1"},
+		{"sops.yaml", "key-types", "age"},
+		{"sops.json", "version", "3.7.3"},
+		{"sops.json", "key-types", "kms"},
+		{"mets.xml", "profile", "http://www.loc.gov/standards/mets/profiles/00000016.xml"},
+		{"alto.xml", "version", "4"},
+		{"mix.xml", "version", "2.0"},
+		{"driver.sys", "architecture", "amd64"},
+		{"driver.sys", "signed", "false"},
+		{"mui.mui", "signed", "false"},
+		{"driver.inf", "signature", "$WINDOWS NT$"},
+		{"driver.inf", "class", "Net"},
+		{"exe.exe", "architecture", ""},
+		{"tiff.fx.tif", "profile", "F"},
+		{"tiff.fx.tif", "version", "1998"},
+		{"tif.tif", "profile", ""},
+		{"pclxl.pxl", "version", "2.0"},
+		{"nitf.ntf", "version", "2.10"},
+		{"nitf.ntf", "complexity", "03"},
+		{"nitf.ntf", "title", "Lake Constance, true color"},
+		{"nitf.ntf", "profile", ""},
+		{"envi.hdr", "bands", "4"},
+		{"envi.hdr", "interleave", "bsq"},
+		{"cog.tif", "block-order", "ROW_MAJOR"},
+		{"flexlm.lic", "vendor", "acmed"},
+		{"jwt.jwt", "alg", "RS256"},
+		{"jwt.jwt", "typ", "JWT"},
+		{"jwt.jwt", "enc", ""},
+		{"safe.zip", "product", "S2A_MSIL1C_20230615T102031_N0509_R065_T32TQM_20230615T140459"},
+		{"flv.flv", "codecs", "flv1,mp4a.6b"},
+		{"flv.hevc.flv", "codecs", "hvc1,mp4a.40.2"},
+		{"srt.pcap", "encryption", "aes-128"},
+		{"srt.pcapng", "encryption", "aes-256"},
+		{"pcap.pcap", "encryption", ""},
+		{"wav.telephony.wav", "codec", "mulaw"},
+		{"wav.telephony.wav", "channels", "2"},
+		{"wav.telephony.wav", "sample-rate", "8000"},
+		{"wav.telephony.wav", "telephony", "true"},
+		{"wav.vox.wav", "codec", "dialogic-oki-adpcm"},
+		{"wav.vox.wav", "sample-rate", "6000"},
+		{"wav.vox.wav", "telephony", ""},
+		{"wav.wav", "telephony", ""},
+		{"au.au", "codec", "pcm16"},
+		{"au.le.au", "codec", "alaw"},
+		{"spdx.spdx.json", "version", "SPDX-2.3"},
+		{"spdx.spdx", "version", "SPDX-2.2"},
+		{"cyclonedx.cdx.json", "version", "1.5"},
+		{"cyclonedx.cdx.xml", "version", "1.4"},
+		{"csaf.json", "category", "csaf_vex"},
+		{"rar.rar", "version", "5"},
+		{"rar.rar", "solid", "false"},
+		{"rar4.rar", "version", "4"},
+		{"rar4.rar", "solid", "true"},
+		{"rar4.rar", "encrypted-headers", "false"},
+		{"7z.7z", "version", "0.3"},
+		{"7z.7z", "solid", "false"},
+		{"7z.solid.7z", "solid", "true"},
+		{"7z.aes.7z", "encrypted-headers", "true"},
+		{"7z.aes.7z", "solid", ""},
+		{"bwf.wav", "broadcast-wave", "true"},
+		{"bwf.wav", "bext-version", "2"},
+		{"bwf.wav", "loudness", "-23.00"},
+		{"bwf.wav", "ixml", "true"},
+		{"bwf.wav", "codec", "pcm"},
+		{"adm.bw64.wav", "adm", "true"},
+		{"adm.bw64.wav", "broadcast-wave", ""},
+		{"wav.wav", "broadcast-wave", ""},
+		{"exe.exe", "signed", "false"},
+		{"signed.exe", "signed", "true"},
+		{"signed.jar", "signed", "true"},
+		{"jar.jar", "signed", ""},
+		{"signed.apk", "signed", "true"},
+		{"signed.apk", "signature-schemes", "v1,v2,v3"},
+		{"apk.apk", "signed", ""},
+		{"openapi.json", "version", "3.0.3"},
+		{"swagger.json", "version", "2.0"},
+		{"openapi.yaml", "version", "3.1.0"},
+		{"asyncapi.yaml", "version", "2.6.0"},
+		{"asice.asice", "signature-formats", "xades"},
+		{"asics.asics", "signature-formats", "cades,timestamp"},
+		{"smtp.bdat.txt", "chunking", "true"},
+		{"smtp.data.txt", "chunking", "false"},
+		{"ps.ps", "level", "2"},
+		{"xfa.pdf", "xfa", "true"},
+		{"xfa.pdf", "postscript", ""},
+		{"ps.xobject.pdf", "postscript", "true"},
+		{"pdf.pdf", "xfa", ""},
+		{"pdf.attachments.pdf", "attachments", "true"},
+		{"pdf.attachments.pdf", "javascript", "true"},
+		{"pdf.attachments.pdf", "encrypted", ""},
+		{"pdf.portfolio.pdf", "portfolio", "true"},
+		{"pdf.portfolio.pdf", "attachments", "true"},
+		{"pdf.encrypted.pdf", "encrypted", "true"},
+		{"pdf.pdf", "javascript", ""},
+		{"pdf.pdf", "attachments", ""},
+		{"midi.midi", "format", "0"},
+		{"midi.midi", "tracks", "1"},
+		{"midi.format1.mid", "format", "1"},
+		{"midi.format1.mid", "tracks", "2"},
+		{"rmid.rmi", "format", "1"},
+		{"rmid.rmi", "tracks", "2"},
+		{"xmf.xmf", "version", "2.00"},
+		{"ifd.bin", "components", "2"},
+		{"ifd.bin", "regions", "descriptor,bios,me,gbe"},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := DetectMIME(data)
+		if v := m.Meta(tc.key); v != tc.value {
+			t.Errorf("%s: expected %s=%q, got %q", tc.file, tc.key, tc.value, v)
+		}
+		if v, ok := m.Metadata()[tc.key]; v != tc.value || ok != (tc.value != "") {
+			t.Errorf("%s: unexpected Metadata() value %q for %s", tc.file, v, tc.key)
+		}
+	}
+}
+
+func TestDetectUnder(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "docx.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := DetectUnder("application/zip", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(docx.mime) {
+		t.Errorf("expected %s, got %s", docx.mime, m)
+	}
+
+	// None of the zip children match, so zip is returned without checking its matcher.
+	if m, _ := DetectUnder("application/zip", []byte("not a zip")); !m.Is(zip.mime) {
+		t.Errorf("expected %s, got %s", zip.mime, m)
+	}
+
+	if _, err := DetectUnder("application/x-inexistent", data); err != ErrUnknownParent {
+		t.Errorf("expected ErrUnknownParent, got %v", err)
+	}
+}
+
+// TestDetectUnderVariants checks the detection under a MIME type shared by
+// several formats starts from the variant matching the input.
+func TestDetectUnderVariants(t *testing.T) {
+	tcs := []struct {
+		file   string
+		parent string
+		ext    string
+	}{
+		{"kustomize.zip", Kustomize, "zip"},
+		{"kustomize.tar", Kustomize, "tar"},
+		{"kustomize.tgz", Kustomize, "tgz"},
+		{"etckeeper.tgz", Etckeeper, "tgz"},
+		{"vagrant.gz.box", VagrantBox, "box"},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := files[tc.file]
+		m, err := DetectUnder(tc.parent, data)
+		if err != nil || !m.Is(tc.parent) || m.Extension() != want.extension {
+			t.Errorf("%s: expected %s with extension %s, got %v with extension %s, %v", tc.file, tc.parent, want.extension, m, m.Extension(), err)
+		}
+		if n, _ := newConfig([]Option{WithSubtree(tc.parent)}).detectNode(data); n != want {
+			t.Errorf("%s: expected the %s variant of %s with WithSubtree", tc.file, tc.ext, tc.parent)
+		}
+	}
+}
+
+func TestFitsExtension(t *testing.T) {
+	tcs := []struct {
+		in   []byte
+		name string
+		fits bool
+	}{
+		{[]byte("\x89PNG\r\n\x1a\n"), "a.png", true},
+		{[]byte("\x89PNG\r\n\x1a\n"), "a.PNG", true},
+		{[]byte("\x89PNG\r\n\x1a\n"), "a.jpg", false},
+		{[]byte("\x89PNG\r\n\x1a\n"), "a.unknown-ext", true},
+		{[]byte("\x89PNG\r\n\x1a\n"), "noext", true},
+		// A subtype of zip whose entries are past the read limit.
+		{[]byte("PK\x03\x04"), "a.docx", true},
+		{[]byte("hello world\n"), "a.csv", true},
+		{[]byte{0x8f, 0x03, 0xa1, 0x5e, 0x00, 0xc7}, "a.pdf", false},
+		{nil, "a.pdf", true},
+	}
+	for _, tc := range tcs {
+		m := DetectMIME(tc.in)
+		if fits := m.FitsExtension(tc.name); fits != tc.fits {
+			t.Errorf("%s detected as %s: expected fitting %t, got %t", tc.name, m, tc.fits, fits)
+		}
+	}
+}
+
+func TestStringWithCodecs(t *testing.T) {
+	tcs := []struct {
+		file, expected string
+	}{
+		{"webm.webm", `video/webm; codecs="vp8,vorbis"`},
+		{"ogg.oga", "audio/ogg; codecs=vorbis"},
+		{"png.png", "image/png"},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := DetectMIME(data).StringWithCodecs(); s != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.file, tc.expected, s)
+		}
+	}
+}
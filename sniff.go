@@ -0,0 +1,184 @@
+package mimetype
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+// SniffLength returns the number of bytes, counted from the start of the
+// input, needed to detect the provided MIME types. A type is detected when
+// its matcher and the matchers of all its ancestors pass, while the matchers
+// tried before them and the matchers of its subtypes fail, so all of them
+// are taken into account.
+// Without arguments, the length needed by the whole matchers tree is returned.
+//
+// SniffLength is meant for detection on remote storage, where fetching the
+// minimal byte range matters. The length never exceeds the read limit.
+// Types unknown to the matchers tree need the whole read limit.
+func SniffLength(mimes ...string) int {
+	l := 0
+	if len(mimes) == 0 {
+		return subtreeDepth(root)
+	}
+	for _, mime := range mimes {
+		nodes := findNodes(mime)
+		if len(nodes) == 0 {
+			return matchers.ReadLimit
+		}
+		for _, n := range nodes {
+			l = maxInt(l, detectionDepth(n))
+		}
+	}
+
+	return l
+}
+
+// detectionDepth returns the number of bytes needed to detect node n.
+func detectionDepth(n *node) int {
+	l := 0
+	for _, c := range n.children {
+		l = maxInt(l, nodeDepth(c))
+	}
+	for ; n.parent != nil; n = n.parent {
+		for _, s := range n.parent.children {
+			l = maxInt(l, nodeDepth(s))
+			if s == n {
+				break
+			}
+		}
+	}
+
+	return l
+}
+
+// subtreeDepth returns the number of bytes needed by the matchers of all
+// the descendants of n. It stops as soon as a matcher needs the whole
+// read limit.
+func subtreeDepth(n *node) int {
+	l := 0
+	for _, c := range n.children {
+		if l = maxInt(l, nodeDepth(c)); l == matchers.ReadLimit {
+			return l
+		}
+		if l = maxInt(l, subtreeDepth(c)); l == matchers.ReadLimit {
+			return l
+		}
+	}
+
+	return l
+}
+
+// nodeDepth returns the number of bytes the matcher of n needs.
+func nodeDepth(n *node) int {
+	if n.depth <= 0 || n.depth > matchers.ReadLimit {
+		return matchers.ReadLimit
+	}
+
+	return n.depth
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// DetectURL detects the MIME type of the resource found at url, by using
+// DetectRange with HTTP Range requests: the head of the resource, up to the
+// read limit, is requested first, and the other ranges only when the
+// detection needs them. Servers ignoring the Range header are supported;
+// the rest of the body is not read. The requests are sent with client, or
+// with http.DefaultClient if client is nil, and are canceled with ctx.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectURL(ctx context.Context, client *http.Client, url string, opts ...Option) (*MIME, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r := httpRanges{ctx: ctx, client: client, url: url}
+	head, size, err := r.get(0, int64(newConfig(opts).readLimit()))
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	if size < 0 {
+		// Without the size of the resource, only its head is examined.
+		size = int64(len(head))
+	}
+
+	return DetectRange(func(offset, length int64) ([]byte, error) {
+		if offset == 0 && length <= int64(len(head)) {
+			return head[:length], nil
+		}
+		b, _, err := r.get(offset, length)
+		return b, err
+	}, size, opts...)
+}
+
+// httpRanges fetches the ranges of the resource found at url.
+type httpRanges struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+}
+
+// get requests length bytes of the resource, starting at offset. size is
+// the length of the whole resource, or -1 when the response does not tell.
+func (r httpRanges) get(offset, length int64) (b []byte, size int64, err error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	req = req.WithContext(r.ctx)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, -1, err
+	}
+	defer resp.Body.Close()
+
+	size = -1
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		size = contentRangeSize(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		// The Range header is ignored and the whole resource is sent.
+		size = resp.ContentLength
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, offset); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return nil, size, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The resource ends before offset.
+		return nil, offset, nil
+	default:
+		return nil, -1, fmt.Errorf("mimetype: unexpected status %q for %s", resp.Status, r.url)
+	}
+	b, err = ioutil.ReadAll(io.LimitReader(resp.Body, length))
+
+	return b, size, err
+}
+
+// contentRangeSize returns the complete length given by the Content-Range
+// header of a partial response, or -1 if it is unknown.
+func contentRangeSize(contentRange string) int64 {
+	i := strings.LastIndexByte(contentRange, '/')
+	if i == -1 {
+		return -1
+	}
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return size
+}
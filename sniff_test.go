@@ -0,0 +1,147 @@
+package mimetype
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestSniffLength(t *testing.T) {
+	tcs := []struct {
+		mimes []string
+		l     int
+	}{
+		// png is tried right after jpeg, which needs 3 bytes.
+		{[]string{"image/png"}, 8},
+		{[]string{"application/pdf"}, 8},
+		{[]string{"image/png", "image/gif"}, 13},
+		{[]string{"application/x-elf"}, 24},
+		{[]string{"video/mp4", "image/jpeg"}, 13},
+		{[]string{"application/zip"}, matchers.ReadLimit},
+		{[]string{"application/x-inexistent"}, matchers.ReadLimit},
+		{nil, matchers.ReadLimit},
+	}
+	for _, tc := range tcs {
+		if l := SniffLength(tc.mimes...); l != tc.l {
+			t.Errorf("%v: expected %d, got %d", tc.mimes, tc.l, l)
+		}
+	}
+}
+
+// TestSniffLengthDetection checks the depth annotations of the matchers
+// by detecting every test file from the number of bytes its type needs.
+func TestSniffLengthDetection(t *testing.T) {
+	for fName, n := range files {
+		l := SniffLength(n.mime)
+		if l == matchers.ReadLimit {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, fName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > l {
+			data = data[:l]
+		}
+		if m, _ := Detect(data); m != n.mime {
+			t.Errorf("%s: expected %s from %d bytes, got %s", fName, n.mime, l, m)
+		}
+	}
+}
+
+func TestDetectURL(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rng string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng = r.Header.Get("Range")
+		http.ServeContent(w, r, "png.png", time.Time{}, bytes.NewReader(data))
+	}))
+	defer s.Close()
+
+	m, err := DetectURL(context.Background(), s.Client(), s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(png.mime) {
+		t.Errorf("expected %s, got %s", png.mime, m)
+	}
+	if expected := fmt.Sprintf("bytes=0-%d", matchers.ReadLimit-1); rng != expected {
+		t.Errorf("expected Range header %q, got %q", expected, rng)
+	}
+
+	s404 := httptest.NewServer(http.NotFoundHandler())
+	defer s404.Close()
+	m, err = DetectURL(context.Background(), nil, s404.URL)
+	if err == nil {
+		t.Errorf("expected error for 404 response")
+	}
+	if m == nil || !m.Is(OctetStream) {
+		t.Errorf("expected %s along with the error, got %v", OctetStream, m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DetectURL(ctx, nil, s.URL); err == nil {
+		t.Errorf("expected error for a canceled context")
+	}
+}
+
+// TestDetectURLRanges checks DetectURL fetches the ranges DetectRange needs,
+// from servers honoring the Range header or not.
+func TestDetectURLRanges(t *testing.T) {
+	pdf, err := ioutil.ReadFile(filepath.Join(testDataDir, "pdf.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iso, err := ioutil.ReadFile(filepath.Join(testDataDir, "iso.iso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ranges := range []bool{true, false} {
+		for _, tc := range []struct {
+			data []byte
+			mime string
+		}{
+			{pdf, "application/pdf"},
+			{iso, "application/x-iso9660-image"},
+			{pdf[:100], "application/pdf"},
+			{nil, "inode/x-empty"},
+		} {
+			data := tc.data
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ranges {
+					http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+					return
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+				w.Write(data)
+			}))
+			m, err := DetectURL(context.Background(), s.Client(), s.URL)
+			s.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !m.Is(tc.mime) {
+				t.Errorf("ranges %t, %d bytes: expected %s, got %s", ranges, len(data), tc.mime, m)
+			}
+			var requests int
+			want, _ := DetectRange(rangesOf(data, &requests), int64(len(data)))
+			if !reflect.DeepEqual(m.Metadata(), want.Metadata()) {
+				t.Errorf("ranges %t, %d bytes: expected metadata %v, got %v", ranges, len(data), want.Metadata(), m.Metadata())
+			}
+		}
+	}
+}
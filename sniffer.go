@@ -0,0 +1,69 @@
+package mimetype
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Sniffer detects the MIME type of a stream while letting it be read whole,
+// for pipelines reading sources which cannot be read twice, like network
+// object stores or tapes. The head of the stream is read once, either by
+// the first call to MIME or by the first read from Reader, and then
+// replayed by Reader.
+type Sniffer struct {
+	c    *config
+	src  io.Reader
+	once sync.Once
+	m    *MIME
+	r    io.Reader // replays the head, then reads the rest of src
+}
+
+// NewSniffer returns a Sniffer for the stream r. The options are used for
+// the detection.
+func NewSniffer(r io.Reader, opts ...Option) *Sniffer {
+	return &Sniffer{c: newConfig(opts), src: r}
+}
+
+// MIME returns the detection result, reading the head of the stream if it
+// was not read yet. It can be called concurrently with the reads from
+// Reader, and blocks until the type is determined. If reading the head of
+// the stream fails, the result is application/octet-stream and the error
+// is returned by Reader, after the bytes read before it.
+func (s *Sniffer) MIME() *MIME {
+	s.once.Do(s.sniff)
+	return s.m
+}
+
+// Reader returns a reader yielding the whole stream, including the bytes
+// read during detection.
+func (s *Sniffer) Reader() io.Reader {
+	return sniffedReader{s}
+}
+
+// sniff reads the head of the stream, stopping as soon as its type and
+// metadata can be decided, like DetectReaderMIME does, and detects it.
+func (s *Sniffer) sniff() {
+	in, err := s.c.readIncrementally(s.src, make([]byte, s.c.readLimit()), true)
+	if err != nil {
+		s.m = newMIME(root, nil)
+		s.r = io.MultiReader(bytes.NewReader(in), failingReader{err})
+		return
+	}
+	s.m = s.c.detect(in)
+	s.r = io.MultiReader(bytes.NewReader(in), s.src)
+}
+
+type sniffedReader struct{ s *Sniffer }
+
+func (r sniffedReader) Read(p []byte) (int, error) {
+	r.s.once.Do(r.s.sniff)
+	return r.s.r.Read(p)
+}
+
+// failingReader returns err from all its reads.
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
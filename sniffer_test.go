@@ -0,0 +1,52 @@
+package mimetype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSniffer(t *testing.T) {
+	for f, n := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The stream is read whole, whether the detection is done before
+		// reading it or while reading it, from another goroutine.
+		s := NewSniffer(iotest.OneByteReader(bytes.NewReader(data)))
+		if m := s.MIME(); m.String() != n.mime {
+			t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+		}
+		if got, err := ioutil.ReadAll(s.Reader()); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: the stream was not read whole after the detection: %v", f, err)
+		}
+
+		s = NewSniffer(bytes.NewReader(data))
+		done := make(chan *MIME)
+		go func() { done <- s.MIME() }()
+		if got, err := ioutil.ReadAll(s.Reader()); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: the stream was not read whole during the detection: %v", f, err)
+		}
+		if m := <-done; m.String() != n.mime {
+			t.Errorf("%s: expected %s, got %s", f, n.mime, m)
+		}
+	}
+}
+
+func TestSnifferReadError(t *testing.T) {
+	errRead := errors.New("read error")
+	r := io.MultiReader(bytes.NewReader([]byte("abc")), failingReader{errRead})
+	s := NewSniffer(r)
+	if m := s.MIME(); m.String() != OctetStream {
+		t.Errorf("expected %s, got %s", OctetStream, m)
+	}
+	got, err := ioutil.ReadAll(s.Reader())
+	if string(got) != "abc" || err != errRead {
+		t.Errorf("expected the bytes read and the read error, got %q, %v", got, err)
+	}
+}
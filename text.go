@@ -0,0 +1,42 @@
+package mimetype
+
+import "github.com/gabriel-vasile/mimetype/internal/matchers"
+
+// TextPolicy tells text from binary data, for IsText. Its zero value is the
+// policy of the detection: any control character, other than the ones used
+// for layout like the tab and the line feed, or the escape character of the
+// terminal colors, makes the data binary. Looser policies fit inputs like
+// logs, where a few stray control characters do not make a line binary.
+type TextPolicy struct {
+	// MaxControlRatio is the fraction of the characters, between 0 and 1,
+	// which may be control characters.
+	MaxControlRatio float64
+	// AllowNUL counts the NUL bytes as any other control character,
+	// instead of making the data binary whatever MaxControlRatio is.
+	AllowNUL bool
+}
+
+// IsText reports whether in is text according to the policy. Text encoded
+// in UTF-16 or UTF-32 is decoded first, so the NUL bytes of its code units
+// do not count as characters. Empty inputs are text.
+func (p TextPolicy) IsText(in []byte) bool {
+	in = matchers.DecodeWide(in)
+	controls := 0
+	for _, b := range in {
+		if b == 0 && !p.AllowNUL {
+			return false
+		}
+		if matchers.IsBinaryChar(b) {
+			controls++
+		}
+	}
+
+	return float64(controls) <= p.MaxControlRatio*float64(len(in))
+}
+
+// IsText reports whether in is text, as the detection decides it before
+// trying the text formats: in holds no control characters other than the
+// ones used for layout. Use a TextPolicy for a looser decision.
+func IsText(in []byte) bool {
+	return TextPolicy{}.IsText(in)
+}
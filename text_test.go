@@ -0,0 +1,54 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype/internal/matchers"
+)
+
+func TestIsText(t *testing.T) {
+	loose := TextPolicy{MaxControlRatio: 0.05}
+	nul := TextPolicy{MaxControlRatio: 0.05, AllowNUL: true}
+	log := []byte("2024-01-02 started\n\x1b[31merror\x1b[0m: disk\x07 full\n")
+	tcs := []struct {
+		name              string
+		in                []byte
+		text, loose, nuls bool
+	}{
+		{"empty", nil, true, true, true},
+		{"plain", []byte("hello world\r\n\tindented\f"), true, true, true},
+		{"latin-1", []byte("caf\xe9"), true, true, true},
+		{"utf-16", []byte("\xff\xfeh\x00i\x00"), true, true, true},
+		{"bell in a log", log, false, true, true},
+		{"nul in a log", append(log, 0), false, false, true},
+		{"binary", bytes.Repeat([]byte{0x01, 'a'}, 10), false, false, false},
+	}
+	for _, tc := range tcs {
+		if got := IsText(tc.in); got != tc.text {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.text, got)
+		}
+		if got := loose.IsText(tc.in); got != tc.loose {
+			t.Errorf("%s: expected %t with %+v, got %t", tc.name, tc.loose, loose, got)
+		}
+		if got := nul.IsText(tc.in); got != tc.nuls {
+			t.Errorf("%s: expected %t with %+v, got %t", tc.name, tc.nuls, nul, got)
+		}
+	}
+
+	// The inputs detected as text formats are text for the default policy.
+	for f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > matchers.ReadLimit {
+			data = data[:matchers.ReadLimit]
+		}
+		if m := DetectMIME(data); isA(m.String(), Text) && !IsText(data) {
+			t.Errorf("%s: detected as %s, but not text", f, m)
+		}
+	}
+}
@@ -5,150 +5,316 @@ import "github.com/gabriel-vasile/mimetype/internal/matchers"
 // root is a matcher which passes for any slice of bytes.
 // When a matcher passes the check, the children matchers
 // are tried in order to find a more accurate mime type.
-var root = newNode("application/octet-stream", "", matchers.True,
-	sevenZ, zip, pdf, ole, ps, psd, ogg, png, jpg, jp2, jpx, jpm, gif, webp, exe, elf,
+var root = newNode(OctetStream, "", matchers.True,
+	// The most common formats on the web come first, to keep their detection fast.
+	jpg, png, pdf, zip, mp4, gif, webp,
+	sevenZ, ole, ps, psd, ogg, jp2, jpx, jpm, exe, elf,
 	ar, tar, xar, bz2, fits, tiff, bmp, ico, mp3, flac, midi, ape, musePack, amr,
-	wav, aiff, au, mpeg, quickTime, mqv, mp4, webM, threeGP, threeG2, avi, flv,
-	mkv, asf, aac, voc, aMp4, m4a, txt, gzip, class, swf, crx, woff, woff2, otf,
-	eot, wasm, shx, dbf, dcm, rar, djvu, mobi, lit, bpg, sqlite3, dwg, nes, macho,
-	qcp, icns, heic, heicSeq, heif, heifSeq, mrc, mdb, accdb, zstd,
+	wav, aiff, au, mpeg, quickTime, mqv, webM, threeGP, threeG2, avi, flv,
+	mkv, asf, aac, voc, aMp4, m4a, asdf, smtpSession, txt, gzip, class, swf, crx, woff, woff2, otf,
+	eot, wasm, shx, dbf, dcm, rar4, rar5, djvu, mobi, lit, bpg, sqlite3, dwg, nes, macho,
+	qcp, icns, heic, heicSeq, heif, heifSeq, mrc, mdb, accdb, zstd, grib, bufr,
+	hdf4, hdf5, casaTable, androidBackup, bplist, xz, nd2, lif, czi,
+	ps1MemoryCard, ps2MemoryCard, gbaGameSharkSave, gbaSharkPortSave, switchSave, iso9660,
+	realMedia, realAudio, pkcs7Signature, appleCodeSignature, qmailQueue, rmid, xmf,
+	intelFlashImage, uefiFirmwareVolume, jppStream, afp, pclXl, pcap, pcapng, rtmpDump, nitf,
 )
 
 // The list of nodes appended to the root node
 var (
-	gzip      = newNode("application/gzip", "gz", matchers.Gzip)
-	sevenZ    = newNode("application/x-7z-compressed", "7z", matchers.SevenZ)
-	zip       = newNode("application/zip", "zip", matchers.Zip, xlsx, docx, pptx, epub, jar, odt, ods, odp, odg, odf)
-	tar       = newNode("application/x-tar", "tar", matchers.Tar)
-	xar       = newNode("application/x-xar", "xar", matchers.Xar)
-	bz2       = newNode("application/x-bzip2", "bz2", matchers.Bz2)
-	pdf       = newNode("application/pdf", "pdf", matchers.Pdf)
-	xlsx      = newNode("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", matchers.Xlsx)
-	docx      = newNode("application/vnd.openxmlformats-officedocument.wordprocessingml.document", "docx", matchers.Docx)
-	pptx      = newNode("application/vnd.openxmlformats-officedocument.presentationml.presentation", "pptx", matchers.Pptx)
-	epub      = newNode("application/epub+zip", "epub", matchers.Epub)
-	jar       = newNode("application/jar", "jar", matchers.Jar)
-	ole       = newNode("application/x-ole-storage", "", matchers.Ole, xls, pub, ppt, doc)
-	doc       = newNode("application/msword", "doc", matchers.Doc)
-	ppt       = newNode("application/vnd.ms-powerpoint", "ppt", matchers.Ppt)
-	pub       = newNode("application/vnd.ms-publisher", "pub", matchers.Pub)
-	xls       = newNode("application/vnd.ms-excel", "xls", matchers.Xls)
-	ps        = newNode("application/postscript", "ps", matchers.Ps)
-	fits      = newNode("application/fits", "fits", matchers.Fits)
-	ogg       = newNode("application/ogg", "ogg", matchers.Ogg, oggAudio, oggVideo)
-	oggAudio  = newNode("audio/ogg", "oga", matchers.OggAudio)
-	oggVideo  = newNode("video/ogg", "ogv", matchers.OggVideo)
-	txt       = newNode("text/plain", "txt", matchers.Txt, html, svg, xml, php, js, lua, perl, python, json, ndJson, rtf, tcl, csv, tsv, vCard, iCalendar, warc)
-	xml       = newNode("text/xml; charset=utf-8", "xml", matchers.Xml, rss, atom, x3d, kml, xliff, collada, gml, gpx, tcx, amf, threemf)
-	json      = newNode("application/json", "json", matchers.Json, geoJson)
-	csv       = newNode("text/csv", "csv", matchers.Csv)
-	tsv       = newNode("text/tab-separated-values", "tsv", matchers.Tsv)
-	geoJson   = newNode("application/geo+json", "geojson", matchers.GeoJson)
-	ndJson    = newNode("application/x-ndjson", "ndjson", matchers.NdJson)
-	html      = newNode("text/html; charset=utf-8", "html", matchers.Html)
-	php       = newNode("text/x-php; charset=utf-8", "php", matchers.Php)
-	rtf       = newNode("text/rtf", "rtf", matchers.Rtf)
-	js        = newNode("application/javascript", "js", matchers.Js)
-	lua       = newNode("text/x-lua", "lua", matchers.Lua)
-	perl      = newNode("text/x-perl", "pl", matchers.Perl)
-	python    = newNode("application/x-python", "py", matchers.Python)
-	tcl       = newNode("text/x-tcl", "tcl", matchers.Tcl)
-	vCard     = newNode("text/vcard", "vcf", matchers.VCard)
-	iCalendar = newNode("text/calendar", "ics", matchers.ICalendar)
-	svg       = newNode("image/svg+xml", "svg", matchers.Svg)
-	rss       = newNode("application/rss+xml", "rss", matchers.Rss)
-	atom      = newNode("application/atom+xml", "atom", matchers.Atom)
-	x3d       = newNode("model/x3d+xml", "x3d", matchers.X3d)
-	kml       = newNode("application/vnd.google-earth.kml+xml", "kml", matchers.Kml)
-	xliff     = newNode("application/x-xliff+xml", "xlf", matchers.Xliff)
-	collada   = newNode("model/vnd.collada+xml", "dae", matchers.Collada)
-	gml       = newNode("application/gml+xml", "gml", matchers.Gml)
-	gpx       = newNode("application/gpx+xml", "gpx", matchers.Gpx)
-	tcx       = newNode("application/vnd.garmin.tcx+xml", "tcx", matchers.Tcx)
-	amf       = newNode("application/x-amf", "amf", matchers.Amf)
-	threemf   = newNode("application/vnd.ms-package.3dmanufacturing-3dmodel+xml", "3mf", matchers.Threemf)
-	png       = newNode("image/png", "png", matchers.Png)
-	jpg       = newNode("image/jpeg", "jpg", matchers.Jpg)
-	jp2       = newNode("image/jp2", "jp2", matchers.Jp2)
-	jpx       = newNode("image/jpx", "jpf", matchers.Jpx)
-	jpm       = newNode("image/jpm", "jpm", matchers.Jpm)
-	bpg       = newNode("image/bpg", "bpg", matchers.Bpg)
-	gif       = newNode("image/gif", "gif", matchers.Gif)
-	webp      = newNode("image/webp", "webp", matchers.Webp)
-	tiff      = newNode("image/tiff", "tiff", matchers.Tiff)
-	bmp       = newNode("image/bmp", "bmp", matchers.Bmp)
-	ico       = newNode("image/x-icon", "ico", matchers.Ico)
-	icns      = newNode("image/x-icns", "icns", matchers.Icns)
-	psd       = newNode("image/vnd.adobe.photoshop", "psd", matchers.Psd)
-	heic      = newNode("image/heic", "heic", matchers.Heic)
-	heicSeq   = newNode("image/heic-sequence", "heic", matchers.HeicSequence)
-	heif      = newNode("image/heif", "heif", matchers.Heif)
-	heifSeq   = newNode("image/heif-sequence", "heif", matchers.HeifSequence)
-	mp3       = newNode("audio/mpeg", "mp3", matchers.Mp3)
-	flac      = newNode("audio/flac", "flac", matchers.Flac)
-	midi      = newNode("audio/midi", "midi", matchers.Midi)
-	ape       = newNode("audio/ape", "ape", matchers.Ape)
-	musePack  = newNode("audio/musepack", "mpc", matchers.MusePack)
-	wav       = newNode("audio/wav", "wav", matchers.Wav)
-	aiff      = newNode("audio/aiff", "aiff", matchers.Aiff)
-	au        = newNode("audio/basic", "au", matchers.Au)
-	amr       = newNode("audio/amr", "amr", matchers.Amr)
-	aac       = newNode("audio/aac", "aac", matchers.Aac)
-	voc       = newNode("audio/x-unknown", "voc", matchers.Voc)
-	aMp4      = newNode("audio/mp4", "mp4", matchers.AMp4)
-	m4a       = newNode("audio/x-m4a", "m4a", matchers.M4a)
-	mp4       = newNode("video/mp4", "mp4", matchers.Mp4)
-	webM      = newNode("video/webm", "webm", matchers.WebM)
-	mpeg      = newNode("video/mpeg", "mpeg", matchers.Mpeg)
-	quickTime = newNode("video/quicktime", "mov", matchers.QuickTime)
-	mqv       = newNode("video/quicktime", "mqv", matchers.Mqv)
-	threeGP   = newNode("video/3gpp", "3gp", matchers.ThreeGP)
-	threeG2   = newNode("video/3gpp2", "3g2", matchers.ThreeG2)
-	avi       = newNode("video/x-msvideo", "avi", matchers.Avi)
-	flv       = newNode("video/x-flv", "flv", matchers.Flv)
-	mkv       = newNode("video/x-matroska", "mkv", matchers.Mkv)
-	asf       = newNode("video/x-ms-asf", "asf", matchers.Asf)
-	class     = newNode("application/x-java-applet; charset=binary", "class", matchers.Class)
-	swf       = newNode("application/x-shockwave-flash", "swf", matchers.Swf)
-	crx       = newNode("application/x-chrome-extension", "crx", matchers.Crx)
-	woff      = newNode("font/woff", "woff", matchers.Woff)
-	woff2     = newNode("font/woff2", "woff2", matchers.Woff2)
-	otf       = newNode("font/otf", "otf", matchers.Otf)
-	eot       = newNode("application/vnd.ms-fontobject", "eot", matchers.Eot)
-	wasm      = newNode("application/wasm", "wasm", matchers.Wasm)
-	shp       = newNode("application/octet-stream", "shp", matchers.Shp)
-	shx       = newNode("application/octet-stream", "shx", matchers.Shx, shp)
-	dbf       = newNode("application/x-dbf", "dbf", matchers.Dbf)
-	exe       = newNode("application/vnd.microsoft.portable-executable", "exe", matchers.Exe)
-	elf       = newNode("application/x-elf", "", matchers.Elf, elfObj, elfExe, elfLib, elfDump)
-	elfObj    = newNode("application/x-object", "", matchers.ElfObj)
-	elfExe    = newNode("application/x-executable", "", matchers.ElfExe)
-	elfLib    = newNode("application/x-sharedlib", "so", matchers.ElfLib)
-	elfDump   = newNode("application/x-coredump", "", matchers.ElfDump)
-	ar        = newNode("application/x-archive", "a", matchers.Ar, deb)
-	deb       = newNode("application/vnd.debian.binary-package", "deb", matchers.Deb)
-	dcm       = newNode("application/dicom", "dcm", matchers.Dcm)
-	odt       = newNode("application/vnd.oasis.opendocument.text", "odt", matchers.Odt, ott)
-	ott       = newNode("application/vnd.oasis.opendocument.text-template", "ott", matchers.Ott)
-	ods       = newNode("application/vnd.oasis.opendocument.spreadsheet", "ods", matchers.Ods, ots)
-	ots       = newNode("application/vnd.oasis.opendocument.spreadsheet-template", "ots", matchers.Ots)
-	odp       = newNode("application/vnd.oasis.opendocument.presentation", "odp", matchers.Odp, otp)
-	otp       = newNode("application/vnd.oasis.opendocument.presentation-template", "otp", matchers.Otp)
-	odg       = newNode("application/vnd.oasis.opendocument.graphics", "odg", matchers.Odg, otg)
-	otg       = newNode("application/vnd.oasis.opendocument.graphics-template", "otg", matchers.Otg)
-	odf       = newNode("application/vnd.oasis.opendocument.formula", "odf", matchers.Odf)
-	rar       = newNode("application/x-rar-compressed", "rar", matchers.Rar)
-	djvu      = newNode("image/vnd.djvu", "djvu", matchers.DjVu)
-	mobi      = newNode("application/x-mobipocket-ebook", "mobi", matchers.Mobi)
-	lit       = newNode("application/x-ms-reader", "lit", matchers.Lit)
-	sqlite3   = newNode("application/x-sqlite3", "sqlite", matchers.Sqlite)
-	dwg       = newNode("image/vnd.dwg", "dwg", matchers.Dwg)
-	warc      = newNode("application/warc", "warc", matchers.Warc)
-	nes       = newNode("application/vnd.nintendo.snes.rom", "nes", matchers.Nes)
-	macho     = newNode("application/x-mach-binary", "macho", matchers.MachO)
-	qcp       = newNode("audio/qcelp", "qcp", matchers.Qcp)
-	mrc       = newNode("application/marc", "mrc", matchers.Marc)
-	mdb       = newNode("application/x-msaccess", "mdb", matchers.MsAccessMdb)
-	accdb     = newNode("application/x-msaccess", "accdb", matchers.MsAccessAce)
-	zstd      = newNode("application/zstd", "zst", matchers.Zstd)
+	gzip           = newNode(Gzip, "gz", matchers.Gzip, vagrantBoxGz, helmChart, kustomizeGz, composeGz, etckeeperGz).withDepth(2).withMinBytes(2).withPrefix("\x1F\x8B")
+	sevenZ         = newNode(SevenZ, "7z", matchers.SevenZ).withMeta(matchers.SevenZMeta).withDepth(6).withMinBytes(6).withPrefix("7z\xBC\xAF\x27\x1C")
+	zip            = newNode(Zip, "zip", matchers.Zip, xlsx, docx, pptx, epub, apk, jar, odt, ods, odp, odg, odf, takeout, iCloud, daisy, ipa, kmz, ora, zarr, asicE, asicS, kustomizeZip, composeZip, safeZip).withDepth(4).withMinBytes(4).withPrefix("PK")
+	tar            = newNode(Tar, "tar", matchers.Tar, ociLayout, dockerArchive, vagrantBox, kustomizeTar, composeTar, etckeeperTar).withDepth(263).withMinBytes(263)
+	xar            = newNode(XAR, "xar", matchers.Xar).withDepth(4).withMinBytes(4).withPrefix("xar!")
+	bz2            = newNode(Bz2, "bz2", matchers.Bz2).withDepth(3).withMinBytes(3).withPrefix("BZh")
+	pdf            = newNode(PDF, "pdf", matchers.Pdf).withMeta(matchers.PdfMeta).withTailMeta(matchers.PdfMeta).withDepth(4).withMinBytes(4).withPrefix("%PDF")
+	xlsx           = newNode(Xlsx, "xlsx", matchers.Xlsx)
+	docx           = newNode(Docx, "docx", matchers.Docx)
+	pptx           = newNode(Pptx, "pptx", matchers.Pptx)
+	epub           = newNode(EPUB, "epub", matchers.Epub).withMeta(matchers.EpubMeta)
+	jar            = newNode(JAR, "jar", matchers.Jar).withMeta(matchers.JarMeta)
+	apk            = newNode(APK, "apk", matchers.Apk).withMeta(matchers.ApkMeta)
+	ipa            = newNode(IPA, "ipa", matchers.Ipa)
+	kmz            = newNode(KMZ, "kmz", matchers.Kmz)
+	ora            = newNode(ORA, "ora", matchers.Ora)
+	takeout        = newNode(Takeout, "zip", matchers.Takeout)
+	iCloud         = newNode(ICloud, "zip", matchers.ICloud)
+	daisy          = newNode(Daisy, "zip", matchers.Daisy)
+	ole            = newNode(OLE, "", matchers.Ole, ooxmlEncrypted, xls, pub, ppt, msi, msg, vsd, doc).withDepth(8).withMinBytes(8).withPrefix("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	ooxmlEncrypted = newNode(OOXMLEncrypted, "", matchers.OoxmlEncrypted).withMeta(matchers.OoxmlEncryptedMeta)
+	doc            = newNode(Doc, "doc", matchers.Doc)
+	ppt            = newNode(Ppt, "ppt", matchers.Ppt)
+	pub            = newNode(Pub, "pub", matchers.Pub)
+	xls            = newNode(Xls, "xls", matchers.Xls)
+	ps             = newNode(PostScript, "ps", matchers.Ps).withMeta(matchers.PsMeta).withDepth(11).withMinBytes(11).withPrefix("%!PS-Adobe-")
+	fits           = newNode(FITS, "fits", matchers.Fits).withDepth(30).withMinBytes(30).withPrefix("SIMPLE  = ")
+	ogg            = newNode(Ogg, "ogg", matchers.Ogg, oggAudio, oggVideo).withMeta(matchers.OggCodecs).withDepth(5).withMinBytes(5).withPrefix("OggS\x00")
+	oggAudio       = newNode(OggAudio, "oga", matchers.OggAudio).withDepth(37).withMinBytes(37)
+	oggVideo       = newNode(OggVideo, "ogv", matchers.OggVideo).withDepth(37).withMinBytes(37)
+	txt            = newNode(Text, "txt", matchers.Txt, ansibleVault, sopsYaml, ecsv, spdxTagValue, jarSignatureFile, openApiYaml, asyncApiYaml, graphQl, eml, titanium, html, svg, xml, php, js, lua, perl, python, json, ndJson, rtf, tcl, csv, tsv, vCard, iCalendar, brf, warc, windowsInf, enviHeader, flexLm, jwt).withStream(matchers.NewTxtStream).withDecoder(matchers.DecodeWide)
+	xml            = newNode(XML, "xml", matchers.Xml, rss, atom, x3d, kml, xliff, collada, gml, gpx, tcx, amf, threemf, dtbook, plist, cycloneDxXml, xrml, xmlDsig, mets, alto, mix, safeManifest)
+	json           = newNode(JSON, "json", matchers.Json, sopsJson, geoJson, ociManifest, ociIndex, dockerManifest, dockerManifestList, zarrMeta, sarif, spdxJson, cycloneDxJson, openVex, csaf, openApiJson, asyncApiJson).withStream(matchers.NewJsonStream)
+	csv            = newNode(CSV, "csv", matchers.Csv).withScore(matchers.CsvScore).withStream(matchers.NewCsvStream).withMeta(matchers.CsvMeta)
+	tsv            = newNode(TSV, "tsv", matchers.Tsv).withScore(matchers.TsvScore).withStream(matchers.NewTsvStream)
+	geoJson        = newNode(GeoJSON, "geojson", matchers.GeoJson)
+	ndJson         = newNode(NDJSON, "ndjson", matchers.NdJson).withScore(matchers.NdJsonScore).withStream(matchers.NewNdJsonStream)
+	html           = newNode(HTML, "html", matchers.Html).withScore(matchers.HtmlScore)
+	php            = newNode(PHP, "php", matchers.Php).withScore(matchers.PhpScore).withPrefix("<", "#!")
+	rtf            = newNode(RTF, "rtf", matchers.Rtf).withPrefix("{\\rtf1")
+	js             = newNode(JS, "js", matchers.Js).withScore(matchers.JsScore).withPrefix("#!")
+	lua            = newNode(Lua, "lua", matchers.Lua).withPrefix("#!")
+	perl           = newNode(Perl, "pl", matchers.Perl).withPrefix("#!")
+	python         = newNode(Python, "py", matchers.Python).withPrefix("#!")
+	tcl            = newNode(Tcl, "tcl", matchers.Tcl).withPrefix("#!")
+	brf            = newNode(BRF, "brf", matchers.Brf).withScore(matchers.BrfScore)
+	vCard          = newNode(VCard, "vcf", matchers.VCard).withMinBytes(12)
+	iCalendar      = newNode(ICalendar, "ics", matchers.ICalendar).withMinBytes(16)
+	svg            = newNode(SVG, "svg", matchers.Svg).withMinBytes(4)
+	rss            = newNode(RSS, "rss", matchers.Rss)
+	atom           = newNode(Atom, "atom", matchers.Atom)
+	x3d            = newNode(X3D, "x3d", matchers.X3d)
+	kml            = newNode(KML, "kml", matchers.Kml)
+	xliff          = newNode(XLIFF, "xlf", matchers.Xliff)
+	collada        = newNode(Collada, "dae", matchers.Collada)
+	gml            = newNode(GML, "gml", matchers.Gml)
+	gpx            = newNode(GPX, "gpx", matchers.Gpx)
+	tcx            = newNode(TCX, "tcx", matchers.Tcx)
+	amf            = newNode(AMF, "amf", matchers.Amf)
+	dtbook         = newNode(DTBook, "xml", matchers.Dtbook)
+	threemf        = newNode(ThreeMF, "3mf", matchers.Threemf)
+	png            = newNode(PNG, "png", matchers.Png).withDepth(8).withMinBytes(8).withPrefix("\x89PNG\r\n\x1A\n")
+	jpg            = newNode(JPEG, "jpg", matchers.Jpg).withDepth(3).withMinBytes(3).withPrefix("\xFF\xD8\xFF")
+	jp2            = newNode(JP2, "jp2", matchers.Jp2).withDepth(24).withMinBytes(24)
+	jpx            = newNode(JPX, "jpf", matchers.Jpx).withDepth(24).withMinBytes(24)
+	jpm            = newNode(JPM, "jpm", matchers.Jpm).withDepth(24).withMinBytes(24)
+	bpg            = newNode(BPG, "bpg", matchers.Bpg).withDepth(4).withMinBytes(4).withPrefix("BPG\xFB")
+	gif            = newNode(GIF, "gif", matchers.Gif).withDepth(6).withMinBytes(6).withPrefix("GIF87a", "GIF89a")
+	webp           = newNode(WebP, "webp", matchers.Webp).withDepth(13).withMinBytes(13).withPrefix("RIFF")
+	tiff           = newNode(TIFF, "tiff", matchers.Tiff, tiffFx, cog).withDepth(4).withMinBytes(4).withPrefix("II*\x00", "MM\x00*")
+	bmp            = newNode(BMP, "bmp", matchers.Bmp).withDepth(2).withMinBytes(2).withPrefix("BM")
+	ico            = newNode(ICO, "ico", matchers.Ico).withDepth(4).withMinBytes(4).withPrefix("\x00\x00\x01\x00")
+	icns           = newNode(ICNS, "icns", matchers.Icns).withDepth(4).withMinBytes(4).withPrefix("icns")
+	psd            = newNode(PSD, "psd", matchers.Psd).withDepth(4).withMinBytes(4).withPrefix("8BPS")
+	heic           = newNode(HEIC, "heic", matchers.Heic).withDepth(13).withMinBytes(13)
+	heicSeq        = newNode(HEICSequence, "heic", matchers.HeicSequence).withDepth(13).withMinBytes(13)
+	heif           = newNode(HEIF, "heif", matchers.Heif).withDepth(13).withMinBytes(13)
+	heifSeq        = newNode(HEIFSequence, "heif", matchers.HeifSequence).withDepth(13).withMinBytes(13)
+	mp3            = newNode(MP3, "mp3", matchers.Mp3).withDepth(3).withMinBytes(3)
+	flac           = newNode(FLAC, "flac", matchers.Flac).withDepth(8).withMinBytes(8).withPrefix("fLaC\x00\x00\x00\x22")
+	midi           = newNode(MIDI, "midi", matchers.Midi).withMeta(matchers.MidiMeta).withDepth(4).withMinBytes(4).withPrefix("MThd")
+	ape            = newNode(APE, "ape", matchers.Ape).withDepth(18).withMinBytes(18).withPrefix("MAC \x96\x0F")
+	musePack       = newNode(MusePack, "mpc", matchers.MusePack).withDepth(4).withMinBytes(4).withPrefix("MPCK")
+	wav            = newNode(WAV, "wav", matchers.Wav).withMeta(matchers.WavMeta).withDepth(13).withMinBytes(13).withPrefix("RIFF", "RF64", "BW64")
+	aiff           = newNode(AIFF, "aiff", matchers.Aiff).withDepth(13).withMinBytes(13).withPrefix("FORM")
+	au             = newNode(AU, "au", matchers.Au).withMeta(matchers.AuMeta).withDepth(4).withMinBytes(4).withPrefix(".snd", "dns.")
+	amr            = newNode(AMR, "amr", matchers.Amr).withDepth(5).withMinBytes(5).withPrefix("#!AMR")
+	aac            = newNode(AAC, "aac", matchers.Aac).withDepth(2).withMinBytes(2).withPrefix("\xFF\xF1", "\xFF\xF9")
+	voc            = newNode(VOC, "voc", matchers.Voc).withDepth(19).withMinBytes(19).withPrefix("Creative Voice File")
+	aMp4           = newNode(AudioMP4, "mp4", matchers.AMp4).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	m4a            = newNode(M4A, "m4a", matchers.M4a).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	mp4            = newNode(MP4, "mp4", matchers.Mp4).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	webM           = newNode(WebM, "webm", matchers.WebM).withMeta(matchers.MatroskaCodecs).withPrefix("\x1A\x45\xDF\xA3")
+	mpeg           = newNode(MPEG, "mpeg", matchers.Mpeg).withDepth(4).withMinBytes(4).withPrefix("\x00\x00\x01")
+	quickTime      = newNode(QuickTime, "mov", matchers.QuickTime).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	mqv            = newNode(QuickTime, "mqv", matchers.Mqv).withDepth(13).withMinBytes(13)
+	threeGP        = newNode(ThreeGP, "3gp", matchers.ThreeGP).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	threeG2        = newNode(ThreeG2, "3g2", matchers.ThreeG2).withMeta(matchers.Mp4Codecs).withDepth(13).withMinBytes(13)
+	avi            = newNode(AVI, "avi", matchers.Avi).withDepth(17).withMinBytes(17).withPrefix("RIFF")
+	flv            = newNode(FLV, "flv", matchers.Flv).withMeta(matchers.FlvCodecs).withDepth(4).withMinBytes(4).withPrefix("FLV\x01")
+	mkv            = newNode(MKV, "mkv", matchers.Mkv).withMeta(matchers.MatroskaCodecs).withPrefix("\x1A\x45\xDF\xA3")
+	asf            = newNode(ASF, "asf", matchers.Asf, wmv, wma).withDepth(16).withMinBytes(16).withPrefix("\x30\x26\xB2\x75")
+	class          = newNode(Class, "class", matchers.Class).withDepth(8).withMinBytes(8)
+	swf            = newNode(SWF, "swf", matchers.Swf).withDepth(3).withMinBytes(3).withPrefix("CWS", "FWS", "ZWS")
+	crx            = newNode(CRX, "crx", matchers.Crx).withDepth(4).withMinBytes(4).withPrefix("Cr24")
+	woff           = newNode(WOFF, "woff", matchers.Woff).withDepth(4).withMinBytes(4).withPrefix("wOFF")
+	woff2          = newNode(WOFF2, "woff2", matchers.Woff2).withDepth(4).withMinBytes(4).withPrefix("wOF2")
+	otf            = newNode(OTF, "otf", matchers.Otf).withDepth(5).withMinBytes(5).withPrefix("OTTO\x00")
+	eot            = newNode(EOT, "eot", matchers.Eot).withDepth(36).withMinBytes(36)
+	wasm           = newNode(Wasm, "wasm", matchers.Wasm).withDepth(4).withMinBytes(4).withPrefix("\x00asm")
+	shp            = newNode(OctetStream, "shp", matchers.Shp).withDepth(112).withMinBytes(112)
+	shx            = newNode(OctetStream, "shx", matchers.Shx, shp).withDepth(4).withMinBytes(4).withPrefix("\x00\x00\x27\x0A")
+	dbf            = newNode(DBF, "dbf", matchers.Dbf).withDepth(4).withMinBytes(4)
+	exe            = newNode(EXE, "exe", matchers.Exe, windowsDriver, mui).withMeta(matchers.ExeMeta).withDepth(2).withMinBytes(2).withPrefix("MZ")
+	elf            = newNode(ELF, "", matchers.Elf, elfObj, elfExe, elfLib, elfDump).withDepth(4).withMinBytes(4).withPrefix("\x7FELF")
+	elfObj         = newNode(ELFObject, "", matchers.ElfObj).withDepth(18).withMinBytes(18)
+	elfExe         = newNode(ELFExecutable, "", matchers.ElfExe).withDepth(18).withMinBytes(18)
+	elfLib         = newNode(ELFLibrary, "so", matchers.ElfLib).withDepth(18).withMinBytes(18)
+	elfDump        = newNode(ELFDump, "", matchers.ElfDump).withDepth(18).withMinBytes(18)
+	ar             = newNode(Ar, "a", matchers.Ar, deb).withDepth(7).withMinBytes(7).withPrefix("!<arch>")
+	deb            = newNode(Deb, "deb", matchers.Deb).withDepth(21).withMinBytes(21)
+	dcm            = newNode(DICOM, "dcm", matchers.Dcm).withDepth(132).withMinBytes(132)
+	odt            = newNode(ODT, "odt", matchers.Odt, ott)
+	ott            = newNode(OTT, "ott", matchers.Ott)
+	ods            = newNode(ODS, "ods", matchers.Ods, ots)
+	ots            = newNode(OTS, "ots", matchers.Ots)
+	odp            = newNode(ODP, "odp", matchers.Odp, otp)
+	otp            = newNode(OTP, "otp", matchers.Otp)
+	odg            = newNode(ODG, "odg", matchers.Odg, otg)
+	otg            = newNode(OTG, "otg", matchers.Otg)
+	odf            = newNode(ODF, "odf", matchers.Odf)
+	rar4           = newNode(RAR, "rar", matchers.Rar4).withMeta(matchers.Rar4Meta).withDepth(12).withMinBytes(9).withPrefix("Rar!\x1A\x07\x00")
+	rar5           = newNode(RAR, "rar", matchers.Rar5).withMeta(matchers.Rar5Meta).withDepth(32).withMinBytes(9).withPrefix("Rar!\x1A\x07\x01\x00")
+	djvu           = newNode(DjVu, "djvu", matchers.DjVu).withDepth(16).withMinBytes(16).withPrefix("AT&TFORM")
+	mobi           = newNode(Mobi, "mobi", matchers.Mobi).withDepth(68).withMinBytes(68)
+	lit            = newNode(Lit, "lit", matchers.Lit).withDepth(8).withMinBytes(8).withPrefix("ITOLITLS")
+	sqlite3        = newNode(SQLite3, "sqlite", matchers.Sqlite, iTunesDb).withDepth(16).withMinBytes(16).withPrefix("SQLite format 3\x00")
+	dwg            = newNode(DWG, "dwg", matchers.Dwg).withDepth(6).withMinBytes(6).withPrefix("AC")
+	warc           = newNode(WARC, "warc", matchers.Warc).withDepth(5).withMinBytes(5)
+	nes            = newNode(NES, "nes", matchers.Nes).withDepth(4).withMinBytes(4).withPrefix("NES\x1A")
+	macho          = newNode(MachO, "macho", matchers.MachO).withDepth(8).withMinBytes(4)
+	qcp            = newNode(QCP, "qcp", matchers.Qcp).withDepth(13).withMinBytes(13).withPrefix("RIFF")
+	mrc            = newNode(MRC, "mrc", matchers.Marc).withPrefix("0", "1", "2", "3", "4", "5", "6", "7", "8", "9")
+	mdb            = newNode(MSAccess, "mdb", matchers.MsAccessMdb).withDepth(20).withMinBytes(20)
+	accdb          = newNode(MSAccess, "accdb", matchers.MsAccessAce).withDepth(20).withMinBytes(20)
+	zstd           = newNode(Zstd, "zst", matchers.Zstd).withDepth(4).withMinBytes(4)
+	xz             = newNode(XZ, "xz", matchers.Xz).withDepth(6).withMinBytes(6).withPrefix("\xFD7zXZ\x00")
+	grib           = newNode(GRIB, "grb", matchers.Grib).withMeta(matchers.GribMeta).withDepth(8).withMinBytes(8).withPrefix("GRIB")
+	bufr           = newNode(BUFR, "bufr", matchers.Bufr).withMeta(matchers.BufrMeta).withDepth(8).withMinBytes(8).withPrefix("BUFR")
+	hdf4           = newNode(HDF4, "hdf", matchers.Hdf4, hdf4Eos).withDepth(4).withMinBytes(4).withPrefix("\x0E\x03\x13\x01")
+	hdf4Eos        = newNode(HDF4EOS, "hdf", matchers.HdfEos)
+	hdf5           = newNode(HDF5, "h5", matchers.Hdf5, hdf5Eos).withDepth(8).withMinBytes(8).withPrefix("\x89HDF\r\n\x1A\n")
+	hdf5Eos        = newNode(HDF5EOS, "he5", matchers.HdfEos)
+	asdf           = newNode(ASDF, "asdf", matchers.Asdf).withMeta(matchers.AsdfMeta).withDepth(6).withMinBytes(6).withPrefix("#ASDF ")
+	ecsv           = newNode(ECSV, "ecsv", matchers.Ecsv).withPrefix("# %ECSV ")
+	casaTable      = newNode(CASATable, "dat", matchers.CasaTable).withDepth(17).withMinBytes(17).withPrefix("\xBE\xBE\xBE\xBE")
+	androidBackup  = newNode(AndroidBackup, "ab", matchers.AndroidBackup).withMeta(matchers.AndroidBackupMeta).withDepth(15).withMinBytes(15).withPrefix("ANDROID BACKUP\n")
+	iTunesDb       = newNode(ITunesDB, "db", matchers.ITunesBackupManifestDb)
+	bplist         = newNode(BPlist, "plist", matchers.Bplist, iTunesBplist).withDepth(8).withMinBytes(8).withPrefix("bplist00")
+	iTunesBplist   = newNode(ITunesBPlist, "plist", matchers.ITunesBackupManifestPlist)
+	plist          = newNode(Plist, "plist", matchers.Plist, iTunesPlist)
+	iTunesPlist    = newNode(ITunesPlist, "plist", matchers.ITunesBackupManifestPlist)
+	titanium       = newNode(Titanium, "properties", matchers.TitaniumBackup).withPrefix("#Titanium Backup")
+
+	msi = newNode(MSI, "msi", matchers.Msi)
+	msg = newNode(MSG, "msg", matchers.Msg)
+	vsd = newNode(VSD, "vsd", matchers.Vsd)
+	// container images
+	ociLayout          = newNode(OCILayout, "tar", matchers.OciLayout)
+	dockerArchive      = newNode(DockerArchive, "tar", matchers.DockerArchive)
+	ociManifest        = newNode(OCIManifest, "json", matchers.OciManifest)
+	ociIndex           = newNode(OCIIndex, "json", matchers.OciIndex)
+	dockerManifest     = newNode(DockerManifest, "json", matchers.DockerManifest)
+	dockerManifestList = newNode(DockerManifestList, "json", matchers.DockerManifestList)
+
+	// microscopy
+	zarr     = newNode(Zarr, "zip", matchers.Zarr)
+	zarrMeta = newNode(ZarrMeta, "json", matchers.ZarrMetadata).withMeta(matchers.ZarrMetadataMeta)
+	nd2      = newNode(ND2, "nd2", matchers.Nd2).withDepth(48).withMinBytes(48).withPrefix("\xDA\xCE\xBE\x0A")
+	lif      = newNode(LIF, "lif", matchers.Lif).withMeta(matchers.LifMeta).withDepth(59).withMinBytes(59).withPrefix("\x70\x00\x00\x00")
+	czi      = newNode(CZI, "czi", matchers.Czi).withMeta(matchers.CziMeta).withDepth(16).withMinBytes(16).withPrefix("ZISRAWFILE")
+
+	// game saves
+	ps1MemoryCard    = newNode(PS1MemoryCard, "mcr", matchers.Ps1MemoryCard).withMeta(matchers.Ps1MemoryCardMeta).withDepth(12).withMinBytes(4).withPrefix("MC\x00\x00", "123-456-STD\x00")
+	ps2MemoryCard    = newNode(PS2MemoryCard, "ps2", matchers.Ps2MemoryCard).withDepth(28).withMinBytes(28).withPrefix("Sony PS2 Memory Card Format ")
+	gbaGameSharkSave = newNode(GBAGameSharkSave, "gsv", matchers.GbaGameSharkSave).withDepth(21).withMinBytes(21)
+	gbaSharkPortSave = newNode(GBASharkPortSave, "sps", matchers.GbaSharkPortSave).withDepth(17).withMinBytes(17).withPrefix("\x0D\x00\x00\x00SharkPortSave")
+	switchSave       = newNode(SwitchSave, "", matchers.SwitchSave).withMeta(matchers.SwitchSaveMeta).withDepth(264).withMinBytes(264)
+
+	// machine provisioning
+	vagrantBox    = newNode(VagrantBox, "box", matchers.VagrantBox)
+	vagrantBoxGz  = newNode(VagrantBox, "box", matchers.VagrantBoxGzip)
+	iso9660       = newNode(ISO9660, "iso", matchers.Iso9660, cloudInitSeed, ovfEnv).withDepth(0x8006).withMinBytes(0x8006).withReadLimit(64 << 10)
+	cloudInitSeed = newNode(CloudInitSeed, "iso", matchers.CloudInitSeed)
+	ovfEnv        = newNode(OVFEnvironment, "iso", matchers.OvfEnvironment)
+
+	// legacy audio and video
+	wmv       = newNode(WMV, "wmv", matchers.Wmv)
+	wma       = newNode(WMA, "wma", matchers.Wma)
+	realMedia = newNode(RealMedia, "rm", matchers.RealMedia).withDepth(4).withMinBytes(4).withPrefix(".RMF")
+	realAudio = newNode(RealAudio, "ra", matchers.RealAudio).withDepth(4).withMinBytes(4).withPrefix(".ra\xFD")
+
+	// software bills of materials and security reports
+	sarif         = newNode(SARIF, "sarif", matchers.Sarif)
+	spdxJson      = newNode(SPDXJSON, "json", matchers.SpdxJson).withMeta(matchers.SbomMeta)
+	spdxTagValue  = newNode(SPDXTagValue, "spdx", matchers.SpdxTagValue).withMeta(matchers.SbomMeta).withMinBytes(12)
+	cycloneDxJson = newNode(CycloneDXJSON, "json", matchers.CycloneDxJson).withMeta(matchers.SbomMeta)
+	cycloneDxXml  = newNode(CycloneDXXML, "xml", matchers.CycloneDxXml).withMeta(matchers.SbomMeta)
+	openVex       = newNode(OpenVEX, "json", matchers.OpenVex)
+	csaf          = newNode(CSAF, "json", matchers.Csaf).withMeta(matchers.CsafMeta)
+
+	// code signing
+	pkcs7Signature     = newNode(PKCS7Signature, "p7s", matchers.Pkcs7Signature, securityCatalog, authenticode).withDepth(17).withMinBytes(13).withPrefix("\x30")
+	securityCatalog    = newNode(SecurityCatalog, "cat", matchers.SecurityCatalog)
+	authenticode       = newNode(Authenticode, "p7s", matchers.Authenticode)
+	appleCodeSignature = newNode(AppleCodeSignature, "sig", matchers.AppleCodeSignature).withDepth(4).withMinBytes(4).withPrefix("\xFA\xDE\x0C\xC1", "\xFA\xDE\x0C\xC0")
+	jarSignatureFile   = newNode(JarSignatureFile, "sf", matchers.JarSignatureFile).withDepth(19).withMinBytes(19)
+
+	// API descriptions
+	openApiJson  = newNode(OpenAPIJSON, "json", matchers.OpenApiJson).withMeta(matchers.ApiMeta)
+	openApiYaml  = newNode(OpenAPIYAML, "yaml", matchers.OpenApiYaml).withMeta(matchers.ApiMeta).withMinBytes(10)
+	asyncApiJson = newNode(AsyncAPIJSON, "json", matchers.AsyncApiJson).withMeta(matchers.ApiMeta)
+	asyncApiYaml = newNode(AsyncAPIYAML, "yaml", matchers.AsyncApiYaml).withMeta(matchers.ApiMeta).withMinBytes(10)
+	graphQl      = newNode(GraphQL, "graphql", matchers.GraphQl).withMinBytes(5)
+
+	// electronic signatures
+	xmlDsig = newNode(XMLDSig, "xml", matchers.XmlDsig, xades)
+	xades   = newNode(XAdES, "xml", matchers.Xades)
+	asicE   = newNode(ASiCE, "asice", matchers.AsicE).withMeta(matchers.AsicMeta)
+	asicS   = newNode(ASiCS, "asics", matchers.AsicS).withMeta(matchers.AsicMeta)
+
+	// mail transport
+	eml         = newNode(EML, "eml", matchers.Eml, maildir)
+	maildir     = newNode(Maildir, "eml", matchers.Maildir)
+	smtpSession = newNode(SMTPSession, "", matchers.SmtpSession).withMeta(matchers.SmtpSessionMeta).withPrefix("220", "E", "e", "H", "h", "L", "l")
+	qmailQueue  = newNode(QmailQueue, "", matchers.QmailQueue).withPrefix("u", "p")
+
+	// MIDI containers
+	rmid = newNode(RMID, "rmi", matchers.Rmid).withMeta(matchers.RmidMeta).withDepth(12).withMinBytes(12).withPrefix("RIFF")
+	xmf  = newNode(XMF, "xmf", matchers.Xmf).withMeta(matchers.XmfMeta).withDepth(8).withMinBytes(8).withPrefix("XMF_")
+
+	// firmware dumps
+	intelFlashImage    = newNode(IntelFlashImage, "bin", matchers.IntelFlashImage).withMeta(matchers.IntelFlashImageMeta).withDepth(20).withMinBytes(4)
+	uefiFirmwareVolume = newNode(UEFIFirmwareVolume, "fv", matchers.UefiFirmwareVolume).withDepth(44).withMinBytes(44)
+
+	// container orchestration
+	helmChart    = newNode(HelmChart, "tgz", matchers.HelmChart)
+	kustomizeTar = newNode(Kustomize, "tar", matchers.KustomizeTar)
+	kustomizeGz  = newNode(Kustomize, "tgz", matchers.KustomizeGzip)
+	kustomizeZip = newNode(Kustomize, "zip", matchers.KustomizeZip)
+	composeTar   = newNode(ComposeProject, "tar", matchers.ComposeTar)
+	composeGz    = newNode(ComposeProject, "tgz", matchers.ComposeGzip)
+	composeZip   = newNode(ComposeProject, "zip", matchers.ComposeZip)
+
+	// secrets and configuration
+	ansibleVault = newNode(AnsibleVault, "yml", matchers.AnsibleVault).withMeta(matchers.AnsibleVaultMeta).withPrefix("$ANSIBLE_VAULT;")
+	sopsYaml     = newNode(SOPSYAML, "yaml", matchers.SopsYaml).withMeta(matchers.SopsMeta).withTailMeta(matchers.SopsMeta).withMinBytes(22)
+	sopsJson     = newNode(SOPSJSON, "json", matchers.SopsJson).withMeta(matchers.SopsMeta).withTailMeta(matchers.SopsMeta)
+	etckeeperTar = newNode(Etckeeper, "tar", matchers.EtckeeperTar)
+	etckeeperGz  = newNode(Etckeeper, "tgz", matchers.EtckeeperGzip)
+
+	// digitization and preservation
+	mets      = newNode(METS, "xml", matchers.Mets).withMeta(matchers.MetsMeta)
+	alto      = newNode(ALTO, "xml", matchers.Alto).withMeta(matchers.AltoMeta)
+	mix       = newNode(MIX, "xml", matchers.Mix).withMeta(matchers.MixMeta)
+	jppStream = newNode(JPPStream, "jpp", matchers.JpipStream).withDepth(41).withMinBytes(8).withPrefix("\x40\x06", "\x50\x06", "\x60\x06", "\x70\x06")
+
+	// windows drivers
+	windowsDriver = newNode(WindowsDriver, "sys", matchers.WindowsDriver).withMeta(matchers.WindowsDriverMeta)
+	mui           = newNode(MUI, "mui", matchers.Mui)
+	windowsInf    = newNode(WindowsINF, "inf", matchers.WindowsInf).withMeta(matchers.WindowsInfMeta).withMinBytes(29)
+
+	// fax and print streams
+	tiffFx = newNode(TIFFFX, "tfx", matchers.TiffFx).withMeta(matchers.TiffFxMeta)
+	afp    = newNode(AFP, "afp", matchers.Afp).withMinBytes(9).withPrefix("\x5A")
+	pclXl  = newNode(PCLXL, "pxl", matchers.PclXl).withMeta(matchers.PclXlMeta).withPrefix(") HP-PCL XL;", "( HP-PCL XL;", "\x1B%-12345X")
+
+	// packet captures and live streaming ingest
+	pcap         = newNode(PCAP, "pcap", matchers.Pcap, srtCapture).withDepth(24).withMinBytes(24).withPrefix("\xD4\xC3\xB2\xA1", "\xA1\xB2\xC3\xD4", "\x4D\x3C\xB2\xA1", "\xA1\xB2\x3C\x4D")
+	pcapng       = newNode(PCAPNG, "pcapng", matchers.Pcapng, srtCaptureNg).withDepth(28).withMinBytes(28).withPrefix("\x0A\x0D\x0D\x0A")
+	srtCapture   = newNode(SRTCapture, "pcap", matchers.SrtCapture).withMeta(matchers.SrtCaptureMeta)
+	srtCaptureNg = newNode(SRTCapture, "pcapng", matchers.SrtCapture).withMeta(matchers.SrtCaptureMeta)
+	rtmpDump     = newNode(RTMPDump, "rtmp", matchers.RtmpDump).withDepth(3085).withMinBytes(3085).withReadLimit(3085).withPrefix("\x03")
+
+	// remote sensing
+	nitf         = newNode(NITF, "ntf", matchers.Nitf).withMeta(matchers.NitfMeta).withDepth(15).withMinBytes(15).withPrefix("NITF0", "NSIF0")
+	enviHeader   = newNode(ENVIHeader, "hdr", matchers.EnviHeader).withMeta(matchers.EnviHeaderMeta).withMinBytes(7).withPrefix("ENVI")
+	cog          = newNode(COG, "tif", matchers.Cog).withMeta(matchers.CogMeta)
+	safeManifest = newNode(SAFEManifest, "safe", matchers.SafeManifest)
+	safeZip      = newNode(SAFEZip, "zip", matchers.SafeZip).withMeta(matchers.SafeZipMeta)
+
+	// license files
+	xrml   = newNode(XrML, "xrm-ms", matchers.Xrml)
+	flexLm = newNode(FlexLM, "lic", matchers.FlexLm).withMeta(matchers.FlexLmMeta)
+	jwt    = newNode(JWT, "jwt", matchers.Jwt).withMeta(matchers.JwtMeta).withMinBytes(17).withPrefix("eyJ")
 )
@@ -0,0 +1,251 @@
+package mimetype
+
+// The MIME types detected by the matchers tree. They can be used instead of
+// string literals when comparing detection results or when referencing a
+// node of the tree, as DetectUnder, WithSubtree and SniffLength do.
+const (
+	Gzip               = "application/gzip"
+	SevenZ             = "application/x-7z-compressed"
+	Zip                = "application/zip"
+	Tar                = "application/x-tar"
+	XAR                = "application/x-xar"
+	Bz2                = "application/x-bzip2"
+	PDF                = "application/pdf"
+	Xlsx               = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	Docx               = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	Pptx               = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	EPUB               = "application/epub+zip"
+	JAR                = "application/jar"
+	APK                = "application/vnd.android.package-archive"
+	IPA                = "application/x-ios-app"
+	KMZ                = "application/vnd.google-earth.kmz"
+	ORA                = "image/openraster"
+	Takeout            = "application/x-google-takeout+zip"
+	ICloud             = "application/x-icloud-export+zip"
+	Daisy              = "application/x-daisy+zip"
+	OLE                = "application/x-ole-storage"
+	OOXMLEncrypted     = "application/x-ooxml-encrypted"
+	Doc                = "application/msword"
+	Ppt                = "application/vnd.ms-powerpoint"
+	Pub                = "application/vnd.ms-publisher"
+	Xls                = "application/vnd.ms-excel"
+	PostScript         = "application/postscript"
+	FITS               = "application/fits"
+	Ogg                = "application/ogg"
+	OggAudio           = "audio/ogg"
+	OggVideo           = "video/ogg"
+	Text               = "text/plain"
+	XML                = "text/xml; charset=utf-8"
+	JSON               = "application/json"
+	CSV                = "text/csv"
+	TSV                = "text/tab-separated-values"
+	GeoJSON            = "application/geo+json"
+	NDJSON             = "application/x-ndjson"
+	HTML               = "text/html; charset=utf-8"
+	PHP                = "text/x-php; charset=utf-8"
+	RTF                = "text/rtf"
+	JS                 = "application/javascript"
+	Lua                = "text/x-lua"
+	Perl               = "text/x-perl"
+	Python             = "application/x-python"
+	Tcl                = "text/x-tcl"
+	BRF                = "text/x-brf"
+	VCard              = "text/vcard"
+	ICalendar          = "text/calendar"
+	SVG                = "image/svg+xml"
+	RSS                = "application/rss+xml"
+	Atom               = "application/atom+xml"
+	X3D                = "model/x3d+xml"
+	KML                = "application/vnd.google-earth.kml+xml"
+	XLIFF              = "application/x-xliff+xml"
+	Collada            = "model/vnd.collada+xml"
+	GML                = "application/gml+xml"
+	GPX                = "application/gpx+xml"
+	TCX                = "application/vnd.garmin.tcx+xml"
+	AMF                = "application/x-amf"
+	DTBook             = "application/x-dtbook+xml"
+	ThreeMF            = "application/vnd.ms-package.3dmanufacturing-3dmodel+xml"
+	PNG                = "image/png"
+	JPEG               = "image/jpeg"
+	JP2                = "image/jp2"
+	JPX                = "image/jpx"
+	JPM                = "image/jpm"
+	BPG                = "image/bpg"
+	GIF                = "image/gif"
+	WebP               = "image/webp"
+	TIFF               = "image/tiff"
+	BMP                = "image/bmp"
+	ICO                = "image/x-icon"
+	ICNS               = "image/x-icns"
+	PSD                = "image/vnd.adobe.photoshop"
+	HEIC               = "image/heic"
+	HEICSequence       = "image/heic-sequence"
+	HEIF               = "image/heif"
+	HEIFSequence       = "image/heif-sequence"
+	MP3                = "audio/mpeg"
+	FLAC               = "audio/flac"
+	MIDI               = "audio/midi"
+	APE                = "audio/ape"
+	MusePack           = "audio/musepack"
+	WAV                = "audio/wav"
+	AIFF               = "audio/aiff"
+	AU                 = "audio/basic"
+	AMR                = "audio/amr"
+	AAC                = "audio/aac"
+	VOC                = "audio/x-unknown"
+	AudioMP4           = "audio/mp4"
+	M4A                = "audio/x-m4a"
+	MP4                = "video/mp4"
+	WebM               = "video/webm"
+	MPEG               = "video/mpeg"
+	QuickTime          = "video/quicktime"
+	ThreeGP            = "video/3gpp"
+	ThreeG2            = "video/3gpp2"
+	AVI                = "video/x-msvideo"
+	FLV                = "video/x-flv"
+	MKV                = "video/x-matroska"
+	ASF                = "video/x-ms-asf"
+	Class              = "application/x-java-applet; charset=binary"
+	SWF                = "application/x-shockwave-flash"
+	CRX                = "application/x-chrome-extension"
+	WOFF               = "font/woff"
+	WOFF2              = "font/woff2"
+	OTF                = "font/otf"
+	EOT                = "application/vnd.ms-fontobject"
+	Wasm               = "application/wasm"
+	OctetStream        = "application/octet-stream"
+	DBF                = "application/x-dbf"
+	EXE                = "application/vnd.microsoft.portable-executable"
+	ELF                = "application/x-elf"
+	ELFObject          = "application/x-object"
+	ELFExecutable      = "application/x-executable"
+	ELFLibrary         = "application/x-sharedlib"
+	ELFDump            = "application/x-coredump"
+	Ar                 = "application/x-archive"
+	Deb                = "application/vnd.debian.binary-package"
+	DICOM              = "application/dicom"
+	ODT                = "application/vnd.oasis.opendocument.text"
+	OTT                = "application/vnd.oasis.opendocument.text-template"
+	ODS                = "application/vnd.oasis.opendocument.spreadsheet"
+	OTS                = "application/vnd.oasis.opendocument.spreadsheet-template"
+	ODP                = "application/vnd.oasis.opendocument.presentation"
+	OTP                = "application/vnd.oasis.opendocument.presentation-template"
+	ODG                = "application/vnd.oasis.opendocument.graphics"
+	OTG                = "application/vnd.oasis.opendocument.graphics-template"
+	ODF                = "application/vnd.oasis.opendocument.formula"
+	RAR                = "application/x-rar-compressed"
+	DjVu               = "image/vnd.djvu"
+	Mobi               = "application/x-mobipocket-ebook"
+	Lit                = "application/x-ms-reader"
+	SQLite3            = "application/x-sqlite3"
+	DWG                = "image/vnd.dwg"
+	WARC               = "application/warc"
+	NES                = "application/vnd.nintendo.snes.rom"
+	MachO              = "application/x-mach-binary"
+	QCP                = "audio/qcelp"
+	MRC                = "application/marc"
+	MSAccess           = "application/x-msaccess"
+	Zstd               = "application/zstd"
+	XZ                 = "application/x-xz"
+	GRIB               = "application/x-grib"
+	BUFR               = "application/x-bufr"
+	HDF4               = "application/x-hdf"
+	HDF4EOS            = "application/x-hdfeos"
+	HDF5               = "application/x-hdf5"
+	HDF5EOS            = "application/x-hdfeos5"
+	ASDF               = "application/x-asdf"
+	ECSV               = "text/x-ecsv"
+	CASATable          = "application/x-casa-table"
+	AndroidBackup      = "application/x-android-backup"
+	ITunesDB           = "application/x-itunes-backup-manifest+sqlite3"
+	BPlist             = "application/x-bplist"
+	ITunesBPlist       = "application/x-itunes-backup-manifest+bplist"
+	Plist              = "application/x-plist"
+	ITunesPlist        = "application/x-itunes-backup-manifest+plist"
+	Titanium           = "text/x-titanium-backup-properties"
+	MSI                = "application/x-ms-installer"
+	MSG                = "application/vnd.ms-outlook"
+	VSD                = "application/vnd.visio"
+	OCILayout          = "application/x-oci-image-layout+tar"
+	DockerArchive      = "application/x-docker-image-archive+tar"
+	OCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	OCIIndex           = "application/vnd.oci.image.index.v1+json"
+	DockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	DockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	Zarr               = "application/x-zarr+zip"
+	ZarrMeta           = "application/x-zarr-metadata+json"
+	ND2                = "image/x-nd2"
+	LIF                = "image/x-lif"
+	CZI                = "image/x-czi"
+	PS1MemoryCard      = "application/x-ps1-memory-card"
+	PS2MemoryCard      = "application/x-ps2-memory-card"
+	GBAGameSharkSave   = "application/x-gba-gameshark-save"
+	GBASharkPortSave   = "application/x-gba-sharkport-save"
+	SwitchSave         = "application/x-nintendo-switch-save"
+	VagrantBox         = "application/x-vagrant-box"
+	ISO9660            = "application/x-iso9660-image"
+	CloudInitSeed      = "application/x-cloud-init-seed"
+	OVFEnvironment     = "application/x-ovf-environment"
+	WMV                = "video/x-ms-wmv"
+	WMA                = "audio/x-ms-wma"
+	RealMedia          = "application/vnd.rn-realmedia"
+	RealAudio          = "audio/x-pn-realaudio"
+	SARIF              = "application/sarif+json"
+	SPDXJSON           = "application/spdx+json"
+	SPDXTagValue       = "text/spdx"
+	CycloneDXJSON      = "application/vnd.cyclonedx+json"
+	CycloneDXXML       = "application/vnd.cyclonedx+xml"
+	OpenVEX            = "application/vnd.openvex+json"
+	CSAF               = "application/csaf+json"
+	PKCS7Signature     = "application/pkcs7-signature"
+	SecurityCatalog    = "application/vnd.ms-pki.seccat"
+	Authenticode       = "application/x-authenticode-signature"
+	AppleCodeSignature = "application/x-apple-code-signature"
+	JarSignatureFile   = "text/x-java-signature"
+	OpenAPIJSON        = "application/vnd.oai.openapi+json"
+	OpenAPIYAML        = "application/vnd.oai.openapi"
+	AsyncAPIJSON       = "application/vnd.aai.asyncapi+json"
+	AsyncAPIYAML       = "application/vnd.aai.asyncapi+yaml"
+	GraphQL            = "application/graphql"
+	XMLDSig            = "application/xmldsig+xml"
+	XAdES              = "application/vnd.etsi.xades+xml"
+	ASiCE              = "application/vnd.etsi.asic-e+zip"
+	ASiCS              = "application/vnd.etsi.asic-s+zip"
+	EML                = "message/rfc822"
+	Maildir            = "message/x-maildir"
+	SMTPSession        = "application/x-smtp-session"
+	QmailQueue         = "application/x-qmail-queue"
+	RMID               = "audio/x-rmid"
+	XMF                = "audio/x-xmf"
+	IntelFlashImage    = "application/x-intel-flash-image"
+	UEFIFirmwareVolume = "application/x-uefi-firmware-volume"
+	HelmChart          = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	Kustomize          = "application/x-kustomize"
+	ComposeProject     = "application/x-compose-project"
+	AnsibleVault       = "application/x-ansible-vault"
+	SOPSYAML           = "application/x-sops+yaml"
+	SOPSJSON           = "application/x-sops+json"
+	Etckeeper          = "application/x-etckeeper-archive"
+	METS               = "application/mets+xml"
+	ALTO               = "application/x-alto+xml"
+	MIX                = "application/x-mix+xml"
+	JPPStream          = "image/jpp-stream"
+	WindowsDriver      = "application/x-ms-driver"
+	MUI                = "application/x-ms-mui"
+	WindowsINF         = "text/x-ms-inf"
+	TIFFFX             = "image/tiff-fx"
+	AFP                = "application/vnd.ibm.modcap"
+	PCLXL              = "application/vnd.hp-pclxl"
+	PCAP               = "application/vnd.tcpdump.pcap"
+	PCAPNG             = "application/x-pcapng"
+	SRTCapture         = "application/x-srt-capture"
+	RTMPDump           = "application/x-rtmp"
+	NITF               = "application/vnd.nitf"
+	ENVIHeader         = "text/x-envi-header"
+	COG                = "image/tiff; application=geotiff; profile=cloud-optimized"
+	SAFEManifest       = "application/x-safe-manifest+xml"
+	SAFEZip            = "application/x-safe+zip"
+	XrML               = "application/x-xrml+xml"
+	FlexLM             = "text/x-flexlm-license"
+	JWT                = "application/jwt"
+)
@@ -0,0 +1,29 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestTypeConstants(t *testing.T) {
+	for _, mime := range []string{OctetStream, Zip, Docx, PNG, XML, HTML, QuickTime, MSAccess, CSAF} {
+		if findNode(mime) == nil {
+			t.Errorf("%s is not part of the matchers tree", mime)
+		}
+	}
+
+	docx, err := ioutil.ReadFile("testdata/docx.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := DetectUnder(Zip, docx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is(Docx) {
+		t.Errorf("expected %s, got %s", Docx, m)
+	}
+	if mime, _ := Detect([]byte("<html><body></body></html>")); mime != HTML {
+		t.Errorf("expected %s, got %s", HTML, mime)
+	}
+}
@@ -0,0 +1,298 @@
+package mimetype
+
+import (
+	"fmt"
+	"io"
+	stdmime "mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxMemory is the number of bytes of a multipart form kept in
+// memory, the rest being stored in temporary files. It is the value used
+// by the net/http package for the FormFile method.
+const defaultMaxMemory = 32 << 20
+
+// UploadPolicy describes the content accepted by ValidateUploads, and by
+// the upload services checking content themselves with Check.
+//
+// The MIME types of Allow, Deny and MaxSize also cover their subtypes in
+// the matchers tree: allowing application/zip allows docx files, and
+// denying text/plain denies HTML and JSON. A type ending in "/*", like
+// "image/*", covers all the types having that top-level type. MIME type
+// parameters are ignored.
+type UploadPolicy struct {
+	// Allow lists the accepted MIME types. All the types are accepted
+	// when it is empty.
+	Allow []string
+	// Deny lists the rejected MIME types. It takes precedence over Allow.
+	Deny []string
+	// RejectMismatch rejects the uploads whose declared Content-Type is
+	// neither the detected type nor one of its ancestors in the matchers
+	// tree. Undeclared types and application/octet-stream are not checked.
+	RejectMismatch bool
+	// RejectExtensionMismatch rejects the uploads whose file name has an
+	// extension known to belong to another type than the detected one, like
+	// a PNG image named photo.jpg. Names without an extension, or with an
+	// unknown one, are not checked.
+	RejectExtensionMismatch bool
+	// MaxSize maps MIME types to the maximum size in bytes of the uploads
+	// of that type, like {"image/*": 10 << 20}. When several types cover
+	// the detected one, the smallest size applies.
+	MaxSize map[string]int64
+	// MaxMemory is the number of bytes of multipart forms kept in memory,
+	// as passed to http.Request.ParseMultipartForm. Zero means 32 MB.
+	MaxMemory int64
+}
+
+// Allows reports whether content of the detected type m is accepted.
+func (p UploadPolicy) Allows(m *MIME) bool {
+	for _, d := range p.Deny {
+		if isA(m.mime, d) {
+			return false
+		}
+	}
+	for _, a := range p.Allow {
+		if isA(m.mime, a) {
+			return true
+		}
+	}
+
+	return len(p.Allow) == 0
+}
+
+// Upload describes uploaded content, as checked by UploadPolicy.Check.
+type Upload struct {
+	// MIME is the detected type of the content.
+	MIME *MIME
+	// Declared is the Content-Type the content was uploaded with, if any.
+	Declared string
+	// Name is the file name the content was uploaded with, if any.
+	Name string
+	// Size is the size of the content in bytes, or -1 if it is unknown.
+	Size int64
+}
+
+// Violation is a rule of an UploadPolicy an upload breaks.
+type Violation struct {
+	// Rule is one of the Rule constants.
+	Rule string
+	// MIME is the detected type of the upload, without its parameters.
+	MIME    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	return v.Message
+}
+
+// Rules of the violations.
+const (
+	// The detected type is not covered by UploadPolicy.Allow.
+	RuleAllow = "allow"
+	// The detected type is covered by UploadPolicy.Deny.
+	RuleDeny = "deny"
+	// The upload is larger than the UploadPolicy.MaxSize of its type.
+	RuleMaxSize = "max-size"
+	// The declared type does not fit the detected one.
+	RuleTypeMismatch = "type-mismatch"
+	// The extension of the file name does not fit the detected type.
+	RuleExtensionMismatch = "extension-mismatch"
+)
+
+// Check returns the rules of the policy the upload u breaks, in the order
+// of the Rule constants, or nil if it is accepted.
+func (p UploadPolicy) Check(u Upload) []Violation {
+	var vs []Violation
+	mime := mediaType(u.MIME.mime)
+	add := func(rule, format string, args ...interface{}) {
+		vs = append(vs, Violation{Rule: rule, MIME: mime, Message: fmt.Sprintf(format, args...)})
+	}
+
+	denied := false
+	for _, d := range p.Deny {
+		if isA(mime, d) {
+			denied = true
+			add(RuleDeny, "content of type %s is not allowed", mime)
+			break
+		}
+	}
+	if !denied && !p.Allows(u.MIME) {
+		add(RuleAllow, "content of type %s is not allowed", mime)
+	}
+	if max := p.maxSize(u.MIME); max > 0 && u.Size > max {
+		add(RuleMaxSize, "content of type %s larger than %d bytes", mime, max)
+	}
+	declared := mediaType(u.Declared)
+	if p.RejectMismatch && declared != "" && declared != OctetStream && !isA(mime, declared) {
+		add(RuleTypeMismatch, "content of type %s declared as %s", mime, declared)
+	}
+	if p.RejectExtensionMismatch && !u.MIME.FitsExtension(u.Name) {
+		add(RuleExtensionMismatch, "content of type %s named %s", mime, u.Name)
+	}
+
+	return vs
+}
+
+// maxSize returns the maximum size of the uploads of the detected type m,
+// or 0 if there is none.
+func (p UploadPolicy) maxSize(m *MIME) int64 {
+	var max int64
+	for t, size := range p.MaxSize {
+		if isA(m.mime, t) && (max == 0 || size < max) {
+			max = size
+		}
+	}
+
+	return max
+}
+
+// check returns the status of the response to send, and the error
+// describing why, when the upload u is rejected, or nil if it is accepted.
+func (p UploadPolicy) check(u Upload) (int, error) {
+	vs := p.Check(u)
+	if len(vs) == 0 {
+		return 0, nil
+	}
+	if vs[0].Rule == RuleMaxSize {
+		return http.StatusRequestEntityTooLarge, vs[0]
+	}
+
+	return http.StatusUnsupportedMediaType, vs[0]
+}
+
+// isA reports whether the MIME type mime is the type t, or one of its
+// subtypes in the matchers tree, or has the top-level type of t when t is
+// a "type/*" wildcard.
+func isA(mime, t string) bool {
+	mime, t = mediaType(mime), mediaType(t)
+	if strings.HasSuffix(t, "/*") {
+		return strings.HasPrefix(mime, t[:len(t)-1])
+	}
+	if mime == t {
+		return true
+	}
+	// Several nodes of the tree may have the same MIME type.
+	for _, n := range root.flatten() {
+		if mediaType(n.mime) != mime {
+			continue
+		}
+		for a := n.parent; a != nil; a = a.parent {
+			if mediaType(a.mime) == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ValidateUploads returns a handler detecting the type of the content
+// uploaded to h, and rejecting the requests whose content is not accepted
+// by the policy p with a 415 Unsupported Media Type response, or a 413
+// Request Entity Too Large one when it is larger than the MaxSize of its
+// type. The requests whose body cannot be read or parsed get a 400 Bad
+// Request response. The options are used for the detection.
+//
+// For multipart/form-data requests, the form is parsed before calling h
+// and the type of each of its files is checked. The Content-Type header of
+// the files is set to their detected type. Because the form is already
+// parsed, h must use the MultipartForm field, or the FormFile and FormValue
+// methods, instead of the MultipartReader method.
+//
+// For the other requests, the type of the body is checked and set as the
+// Content-Type header of the request. The body is passed to h unchanged.
+// Requests without a body are passed to h unchecked.
+func ValidateUploads(h http.Handler, p UploadPolicy, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validate := validateBody
+		if mediaType(r.Header.Get("Content-Type")) == "multipart/form-data" {
+			validate = validateForm
+		}
+		if status, err := validate(w, r, p, opts); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// validateBody checks the type of the body of r and replaces the body with
+// one replaying the bytes read for the detection. It returns the status of
+// the response to send when the body is rejected. When the size of the body
+// is unknown, reading it fails past the MaxSize of its type.
+func validateBody(w http.ResponseWriter, r *http.Request, p UploadPolicy, opts []Option) (int, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return 0, nil
+	}
+	m, replay, err := DetectAndReplay(r.Body, opts...)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	body := struct {
+		io.Reader
+		io.Closer
+	}{replay, r.Body}
+	r.Body = body
+	if m.mime == empty.mime {
+		return 0, nil
+	}
+	u := Upload{MIME: m, Declared: r.Header.Get("Content-Type"), Size: r.ContentLength}
+	if _, params, err := stdmime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+		u.Name = params["filename"]
+	}
+	if status, err := p.check(u); err != nil {
+		return status, err
+	}
+	if max := p.maxSize(m); max > 0 && u.Size < 0 {
+		r.Body = http.MaxBytesReader(w, body, max)
+	}
+	r.Header.Set("Content-Type", m.mime)
+
+	return 0, nil
+}
+
+// validateForm parses the multipart form of r and checks the type of each
+// of its files. It returns the status of the response to send when the
+// form is rejected.
+func validateForm(w http.ResponseWriter, r *http.Request, p UploadPolicy, opts []Option) (int, error) {
+	maxMemory := p.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxMemory
+	}
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return http.StatusBadRequest, err
+	}
+	for _, files := range r.MultipartForm.File {
+		for _, fh := range files {
+			m, err := DetectMultipart(fh, opts...)
+			if err != nil {
+				return http.StatusBadRequest, err
+			}
+			u := Upload{MIME: m, Declared: fh.Header.Get("Content-Type"), Name: fh.Filename, Size: fh.Size}
+			if status, err := p.check(u); err != nil {
+				return status, fmt.Errorf("%s: %v", fh.Filename, err)
+			}
+			fh.Header.Set("Content-Type", m.mime)
+		}
+	}
+
+	return 0, nil
+}
+
+// DetectMultipart detects the MIME type of the file uploaded in a multipart
+// form described by fh, as found in http.Request.MultipartForm. The file is
+// opened and closed by DetectMultipart and read at absolute offsets, so the
+// files later returned by fh.Open start at the beginning, whether the
+// upload is kept in memory or stored in a temporary file. Like with
+// DetectFileMmap, the end of the file is examined too.
+func DetectMultipart(fh *multipart.FileHeader, opts ...Option) (*MIME, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return newMIME(root, nil), err
+	}
+	defer f.Close()
+
+	return DetectReaderAt(f, fh.Size, opts...)
+}
@@ -0,0 +1,276 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadPolicyAllows(t *testing.T) {
+	tcs := []struct {
+		file   string
+		policy UploadPolicy
+		allows bool
+	}{
+		{"png.png", UploadPolicy{}, true},
+		{"png.png", UploadPolicy{Allow: []string{"image/png"}}, true},
+		{"png.png", UploadPolicy{Allow: []string{"image/*"}}, true},
+		{"png.png", UploadPolicy{Allow: []string{"image/*"}, Deny: []string{"image/png"}}, false},
+		{"png.png", UploadPolicy{Allow: []string{"application/pdf"}}, false},
+		// Subtypes are covered by the types of the lists.
+		{"docx.docx", UploadPolicy{Allow: []string{"application/zip"}}, true},
+		{"html.html", UploadPolicy{Deny: []string{"text/plain; charset=utf-8"}}, false},
+		{"exe.exe", UploadPolicy{Deny: []string{"text/*"}}, true},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allows := tc.policy.Allows(DetectMIME(data)); allows != tc.allows {
+			t.Errorf("%s: %+v: expected %t, got %t", tc.file, tc.policy, tc.allows, allows)
+		}
+	}
+}
+
+func TestUploadPolicyCheck(t *testing.T) {
+	png, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docx, err := ioutil.ReadFile(filepath.Join(testDataDir, "docx.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := UploadPolicy{
+		Allow:                   []string{"image/*", "application/zip"},
+		Deny:                    []string{"image/gif"},
+		RejectMismatch:          true,
+		RejectExtensionMismatch: true,
+		MaxSize:                 map[string]int64{"image/*": 1 << 20, "image/png": 100},
+	}
+	tcs := []struct {
+		name  string
+		u     Upload
+		rules []string
+	}{
+		{"accepted", Upload{MIME: DetectMIME(png[:50]), Name: "a.png", Size: 50}, nil},
+		{"unknown size", Upload{MIME: DetectMIME(png), Size: -1}, nil},
+		{"smallest size", Upload{MIME: DetectMIME(png), Size: 101}, []string{RuleMaxSize}},
+		{"not allowed", Upload{MIME: DetectMIME([]byte("%PDF-1.7")), Size: 8}, []string{RuleAllow}},
+		{"denied", Upload{MIME: DetectMIME([]byte("GIF89a")), Size: 6}, []string{RuleDeny}},
+		{"declared", Upload{MIME: DetectMIME(png[:50]), Declared: "image/jpeg", Size: 50}, []string{RuleTypeMismatch}},
+		{"named", Upload{MIME: DetectMIME(png[:50]), Name: "a.jpg", Size: 50}, []string{RuleExtensionMismatch}},
+		{"unknown extension", Upload{MIME: DetectMIME(png[:50]), Name: "a.unknown-ext", Size: 50}, nil},
+		// The extension of a subtype fits: docx files may be detected as zip.
+		{"subtype extension", Upload{MIME: DetectMIME(docx[:30]), Name: "a.docx", Size: 30}, nil},
+		{"all", Upload{MIME: DetectMIME(png), Declared: "text/plain", Name: "a.txt", Size: 2 << 20},
+			[]string{RuleMaxSize, RuleTypeMismatch, RuleExtensionMismatch}},
+	}
+	for _, tc := range tcs {
+		detected := mediaType(tc.u.MIME.String())
+		var rules []string
+		for _, v := range p.Check(tc.u) {
+			rules = append(rules, v.Rule)
+			if v.MIME != detected || v.Error() == "" {
+				t.Errorf("%s: unexpected violation %+v", tc.name, v)
+			}
+		}
+		if strings.Join(rules, ",") != strings.Join(tc.rules, ",") {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.rules, rules)
+		}
+	}
+}
+
+// echoContentType responds with the Content-Type of the request, or of its
+// uploaded file, followed by the uploaded content.
+var echoContentType = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.MultipartForm != nil {
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		data, _ := ioutil.ReadAll(f)
+		w.Write([]byte(fh.Header.Get("Content-Type") + "\n"))
+		w.Write(data)
+		return
+	}
+	data, _ := ioutil.ReadAll(r.Body)
+	w.Write([]byte(r.Header.Get("Content-Type") + "\n"))
+	w.Write(data)
+})
+
+func TestValidateUploads(t *testing.T) {
+	h := ValidateUploads(echoContentType, UploadPolicy{
+		Allow:          []string{"image/*", "application/zip"},
+		RejectMismatch: true,
+	})
+	tcs := []struct {
+		file, declared string
+		multipart      bool
+		status         int
+		contentType    string
+	}{
+		{"png.png", "", false, http.StatusOK, "image/png"},
+		{"png.png", "image/png", false, http.StatusOK, "image/png"},
+		{"docx.docx", "application/zip", false, http.StatusOK, "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"zip.zip", "application/octet-stream", false, http.StatusOK, "application/zip"},
+		{"zip.zip", "image/png", false, http.StatusUnsupportedMediaType, ""},
+		{"exe.exe", "", false, http.StatusUnsupportedMediaType, ""},
+		{"png.png", "image/png", true, http.StatusOK, "image/png"},
+		{"jpg.jpg", "", true, http.StatusOK, "image/jpeg"},
+		{"jpg.jpg", "image/png", true, http.StatusUnsupportedMediaType, ""},
+		{"pdf.pdf", "application/pdf", true, http.StatusUnsupportedMediaType, ""},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var req *http.Request
+		if tc.multipart {
+			req = newUploadRequest(t, tc.file, tc.declared, data)
+		} else {
+			req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+			if tc.declared != "" {
+				req.Header.Set("Content-Type", tc.declared)
+			}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.file, tc.status, rec.Code, rec.Body)
+			continue
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		want := append([]byte(tc.contentType+"\n"), data...)
+		if !bytes.Equal(rec.Body.Bytes(), want) {
+			t.Errorf("%s: expected %q and the uploaded content, got %q",
+				tc.file, tc.contentType, firstLineOf(rec.Body.Bytes()))
+		}
+	}
+}
+
+func TestValidateUploadsNoBody(t *testing.T) {
+	h := ValidateUploads(echoContentType, UploadPolicy{Allow: []string{"image/png"}})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected requests without a body to pass, got status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("--x\r\n")))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=y")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected malformed forms to get status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestValidateUploadsMaxSize(t *testing.T) {
+	var readErr error
+	h := ValidateUploads(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = ioutil.ReadAll(r.Body)
+	}), UploadPolicy{MaxSize: map[string]int64{"application/pdf": 100}})
+	data := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte("% padding\n"), 100)...)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+
+	// Without a Content-Length, the body fails to be read past the size.
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.ContentLength = -1
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr == nil {
+		t.Errorf("expected reading a body larger than the max size to fail")
+	}
+
+	rec = httptest.NewRecorder()
+	req = newUploadRequest(t, "large.pdf", "", data)
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("multipart: expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
+
+// TestDetectMultipart checks the detection of uploads kept in memory and of
+// uploads stored in temporary files, and that they can be read afterwards.
+func TestDetectMultipart(t *testing.T) {
+	data := []byte("%PDF-1.7\n")
+	data = append(data, bytes.Repeat([]byte("% padding\n"), 1000)...)
+	data = append(data, "1 0 obj\n<< /Fields [] /XFA 2 0 R >>\nendobj\n%%EOF\n"...)
+
+	for _, maxMemory := range []int64{1 << 20, 1} {
+		req := newUploadRequest(t, "xfa.pdf", "", data)
+		if err := req.ParseMultipartForm(maxMemory); err != nil {
+			t.Fatal(err)
+		}
+		defer req.MultipartForm.RemoveAll()
+		fh := req.MultipartForm.File["file"][0]
+
+		m, err := DetectMultipart(fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.Is(PDF) || m.Meta("xfa") != "true" {
+			t.Errorf("max memory %d: expected %s with xfa=true, got %s with xfa=%q",
+				maxMemory, PDF, m, m.Meta("xfa"))
+		}
+		f, err := fh.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("max memory %d: the upload is not read whole after detection", maxMemory)
+		}
+	}
+}
+
+// newUploadRequest returns a request uploading data in the "file" field of
+// a multipart form, with the declared Content-Type.
+func newUploadRequest(t *testing.T, name, declared string, data []byte) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	hdr := textproto.MIMEHeader{}
+	hdr.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	if declared != "" {
+		hdr.Set("Content-Type", declared)
+	}
+	part, err := mw.CreatePart(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
+
+func firstLineOf(b []byte) []byte {
+	if i := bytes.IndexByte(b, '\n'); i != -1 {
+		return b[:i]
+	}
+
+	return b
+}
@@ -0,0 +1,118 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command mimetypejs exposes the detection to JavaScript, so browsers can
+// check the files users select before uploading them. Build it from the
+// v2 directory with:
+//
+//	GOOS=js GOARCH=wasm go build -o mimetype.wasm ./cmd/mimetypejs
+//
+// and load mimetype.wasm with the wasm_exec.js file of the Go distribution.
+// Once running, it defines a global mimetype object with two functions:
+//
+//	mimetype.detect(bytes)     detects a Uint8Array and returns the result
+//	mimetype.detectBlob(blob)  returns a Promise of the result of a Blob,
+//	                           like the File objects of <input type="file">
+//
+// The results are objects holding the mime, extension and kind of the
+// detected type, and its metadata. Arguments of another type make detect
+// throw a TypeError, and detectBlob return a Promise rejected with one.
+// Only the head of blobs is read, so selecting large files costs no more
+// than selecting small ones:
+//
+//	input.onchange = async () => {
+//		const m = await mimetype.detectBlob(input.files[0]);
+//		if (!m.mime.startsWith("image/")) { ... }
+//	};
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/gabriel-vasile/mimetype/v2"
+)
+
+func main() {
+	js.Global().Set("mimetype", js.ValueOf(map[string]interface{}{
+		"detect":     throwing.Invoke(js.FuncOf(detect)),
+		"detectBlob": js.FuncOf(detectBlob),
+	}))
+	// The functions are called by JavaScript after main returns, so the
+	// program must keep running.
+	select {}
+}
+
+// throwing wraps a function so the Error values it returns are thrown to
+// its caller instead. Go functions called from JavaScript cannot throw,
+// and a panic would stop the program for good.
+var throwing = js.Global().Get("Function").New("f", `return function() {
+	const r = f.apply(this, arguments);
+	if (r instanceof Error) throw r;
+	return r;
+}`)
+
+// typeError returns a TypeError with the message msg.
+func typeError(msg string) js.Value {
+	return js.Global().Get("TypeError").New(msg)
+}
+
+func detect(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return typeError("mimetype.detect: expected a Uint8Array")
+	}
+	in, ok := bytesOf(args[0])
+	if !ok {
+		return typeError("mimetype.detect: expected a Uint8Array")
+	}
+	return result(mimetype.Detect(in))
+}
+
+// detectBlob reads the head of the blob and resolves the returned Promise
+// with its detection result, or rejects it with the read error.
+func detectBlob(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 || !args[0].InstanceOf(js.Global().Get("Blob")) {
+		return js.Global().Get("Promise").Call("reject", typeError("mimetype.detectBlob: expected a Blob"))
+	}
+	head := args[0].Call("slice", 0, mimetype.ReadLimit)
+
+	var onData, onError js.Func
+	release := func() {
+		onData.Release()
+		onError.Release()
+	}
+	onData = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		in, _ := bytesOf(js.Global().Get("Uint8Array").New(args[0]))
+		return result(mimetype.Detect(in))
+	})
+	onError = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		return js.Global().Get("Promise").Call("reject", args[0])
+	})
+
+	return head.Call("arrayBuffer").Call("then", onData, onError)
+}
+
+// bytesOf copies the content of the Uint8Array v. ok is false when v is
+// not a Uint8Array, which js.CopyBytesToGo panics on.
+func bytesOf(v js.Value) (b []byte, ok bool) {
+	if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+		return nil, false
+	}
+	b = make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b, true
+}
+
+func result(m *mimetype.MIME) interface{} {
+	meta := map[string]interface{}{}
+	for k, v := range m.Metadata() {
+		meta[k] = v
+	}
+	return js.ValueOf(map[string]interface{}{
+		"mime":      m.String(),
+		"extension": m.Extension(),
+		"kind":      m.Kind().String(),
+		"metadata":  meta,
+	})
+}
@@ -0,0 +1,12 @@
+module github.com/gabriel-vasile/mimetype/v2
+
+go 1.12
+
+// The v2 packages wrap the v1 package of the same revision, so each v2
+// release requires the v1 release tagged along with it, from the same commit.
+// v1.5.0 is the first v1 release having the APIs wrapped by v2.
+require github.com/gabriel-vasile/mimetype v1.5.0
+
+// Builds in this repository use the v1 package of the working tree. The
+// replace directive is ignored by the modules requiring v2, which get v1.5.0.
+replace github.com/gabriel-vasile/mimetype => ../
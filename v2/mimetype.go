@@ -0,0 +1,217 @@
+// Package mimetype detects the MIME type of files from their content, using
+// the matchers tree of the github.com/gabriel-vasile/mimetype package.
+//
+// This package is the version 2 API. All the detection functions return a
+// *MIME, which holds the MIME type, the extension, the kind and the
+// metadata found in the input, and all of them accept the same options:
+//
+//	m := mimetype.Detect(data, mimetype.WithHint("report.csv"))
+//	if m.Is("text/csv") {
+//		...
+//	}
+//
+// The inputs can be byte slices, readers, seekable sources and files.
+// Custom matchers are added to the tree with Extend, and the order in which
+// the children of a type are tried is changed with Reorder.
+//
+// The functions of the version 1 package returning the MIME type and the
+// extension as strings, like Detect, DetectReader and DetectFile, are kept
+// as a compatibility layer: they return the String and the Extension of the
+// result of the function of the same name of this package.
+//
+// Both packages share the matchers tree, so matchers added with the Extend
+// function of either package are used by the detection functions of both.
+package mimetype
+
+import (
+	"io"
+
+	v1 "github.com/gabriel-vasile/mimetype"
+)
+
+// ReadLimit is the default number of bytes read from readers and files.
+const ReadLimit = v1.ReadLimit
+
+// MIME is the result of a detection.
+type MIME = v1.MIME
+
+// Kind is the broad category of a file format.
+type Kind = v1.Kind
+
+// The kinds of file formats.
+const (
+	KindUnknown    = v1.KindUnknown
+	KindText       = v1.KindText
+	KindImage      = v1.KindImage
+	KindAudio      = v1.KindAudio
+	KindVideo      = v1.KindVideo
+	KindArchive    = v1.KindArchive
+	KindDocument   = v1.KindDocument
+	KindFont       = v1.KindFont
+	KindExecutable = v1.KindExecutable
+	KindDatabase   = v1.KindDatabase
+)
+
+var (
+	// ErrUnknownParent is returned by Extend and Reorder when the parent
+	// MIME type is not part of the matchers tree.
+	ErrUnknownParent = v1.ErrUnknownParent
+	// ErrNotChild is returned by Reorder when one of the provided MIME types
+	// is not a child of the parent type.
+	ErrNotChild = v1.ErrNotChild
+)
+
+// Detect returns the detection result of the provided byte slice. If no
+// matcher passes, the result is application/octet-stream, and an empty
+// input is detected as inode/x-empty.
+func Detect(in []byte, opts ...Option) *MIME {
+	return v1.DetectMIME(in, opts...)
+}
+
+// DetectReader returns the detection result of the data read from r. At
+// most ReadLimit bytes are read, or the limit set with WithLimit, and
+// reading stops as soon as the bytes read are enough to decide the type.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectReader(r io.Reader, opts ...Option) (*MIME, error) {
+	return v1.DetectReaderMIME(r, opts...)
+}
+
+// DetectReaderAt returns the detection result of the size bytes of r.
+// Besides the head of the input, its end is examined too, for the formats
+// keeping metadata in a trailer. r is read at absolute offsets, so seekable
+// sources, like *os.File, do not need to be rewound after the detection.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectReaderAt(r io.ReaderAt, size int64, opts ...Option) (*MIME, error) {
+	return v1.DetectReaderAt(r, size, opts...)
+}
+
+// RangeFunc fetches length bytes of a remote object, starting at offset.
+type RangeFunc = v1.RangeFunc
+
+// DetectRange returns the detection result of a remote object of the given
+// size, fetching its head, and its end only for the formats keeping
+// metadata in a trailer.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectRange(fetch RangeFunc, size int64, opts ...Option) (*MIME, error) {
+	return v1.DetectRange(fetch, size, opts...)
+}
+
+// DetectFile returns the detection result of the file at path. Like with
+// DetectReaderAt, the end of the file is examined too. The file is mapped
+// into memory when the platform supports it.
+// The returned *MIME is never nil, not even when an error is returned.
+func DetectFile(path string, opts ...Option) (*MIME, error) {
+	return v1.DetectFileMmap(path, opts...)
+}
+
+// ArchiveEntry is a file stored in an archive, as listed by DetectArchive.
+type ArchiveEntry = v1.ArchiveEntry
+
+// ErrNotArchive is returned by DetectArchive when the input is not an
+// archive whose entries can be listed.
+var ErrNotArchive = v1.ErrNotArchive
+
+// DetectArchive returns the detection result of the size bytes of r and,
+// for zip and tar archives, compressed or not, the one of each file they
+// store. Only the head of each entry is decompressed.
+func DetectArchive(r io.ReaderAt, size int64, opts ...Option) (*MIME, []ArchiveEntry, error) {
+	return v1.DetectArchive(r, size, opts...)
+}
+
+// Explanation describes how the detection of an input went through the
+// matchers tree, as returned by Explain.
+type Explanation = v1.Explanation
+
+// ExplainStep describes the matching of the children of a node of the tree.
+type ExplainStep = v1.ExplainStep
+
+// ByteRange is a range of bytes of the input.
+type ByteRange = v1.ByteRange
+
+// Rejection is a child of a node which did not pass, and why.
+type Rejection = v1.Rejection
+
+// Reasons of the rejections.
+const (
+	ReasonTooShort = v1.ReasonTooShort
+	ReasonPrefix   = v1.ReasonPrefix
+	ReasonMatcher  = v1.ReasonMatcher
+	ReasonScore    = v1.ReasonScore
+)
+
+// Explain returns the detection result of in along with the description of
+// how the detection went: the matcher which passed at each level of the
+// tree, the bytes it depends on and the siblings rejected before it.
+func Explain(in []byte, opts ...Option) *Explanation {
+	return v1.Explain(in, opts...)
+}
+
+// Extend adds a matcher for the mime type to the matchers tree, as a child
+// of the parent type. Use "application/octet-stream" as parent to add a top
+// level matcher. The match function is called only when the parent matcher
+// passes. WithPriority changes the position of the new matcher among the
+// children of parent.
+//
+// Extend is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func Extend(parent, mime, extension string, match func([]byte) bool, opts ...Option) error {
+	return v1.Extend(parent, mime, extension, match, opts...)
+}
+
+// Reorder changes the order in which the children of the parent type are
+// tried. The provided children are moved first, in the given order, while
+// the rest keep their relative order.
+//
+// Reorder is not safe to call concurrently with the Detect functions.
+// It should be used during initialization, before any detection happens.
+func Reorder(parent string, children ...string) error {
+	return v1.Reorder(parent, children...)
+}
+
+// TypeByExtension returns the MIME type associated with the file extension
+// ext, or an empty string. The extension may start with a dot.
+func TypeByExtension(ext string) string {
+	return v1.TypeByExtension(ext)
+}
+
+// ExtensionsByType returns the extensions associated with the MIME type.
+func ExtensionsByType(mime string) []string {
+	return v1.ExtensionsByType(mime)
+}
+
+// SyncStdlib registers the extensions known to this package, and unknown
+// to the mime package of the standard library, with the mime package.
+func SyncStdlib() error {
+	return v1.SyncStdlib()
+}
+
+// TextPolicy tells text from binary data, tolerating some control
+// characters when loosened, for inputs like logs.
+type TextPolicy = v1.TextPolicy
+
+// IsText reports whether in is text, as the detection decides it before
+// trying the text formats.
+func IsText(in []byte) bool {
+	return v1.IsText(in)
+}
+
+// SniffWriter is an io.Writer detecting the MIME type of the data written
+// to it, and forwarding the data to an underlying writer once detected.
+type SniffWriter = v1.SniffWriter
+
+// NewSniffWriter returns a SniffWriter forwarding the data written to it
+// to w, and calling f, which may be nil, with the detection result before
+// forwarding the first byte. Close must be called after the last write.
+func NewSniffWriter(w io.Writer, f func(*MIME), opts ...Option) *SniffWriter {
+	return v1.NewSniffWriter(w, f, opts...)
+}
+
+// Sniffer detects the MIME type of a stream while letting it be read whole,
+// replaying the bytes read during detection.
+type Sniffer = v1.Sniffer
+
+// NewSniffer returns a Sniffer for the stream r. MIME blocks until the type
+// is determined, and Reader yields the whole stream.
+func NewSniffer(r io.Reader, opts ...Option) *Sniffer {
+	return v1.NewSniffer(r, opts...)
+}
@@ -0,0 +1,86 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/gabriel-vasile/mimetype"
+)
+
+const testDataDir = "../testdata"
+
+// TestCompat checks that the string returning functions of the version 1
+// package agree with the functions of this package.
+func TestCompat(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(testDataDir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue // directories
+		}
+		m := Detect(data)
+		if mime, ext := v1.Detect(data); mime != m.String() || ext != m.Extension() {
+			t.Errorf("%s: Detect: v1 %s %s, v2 %s %s", path, mime, ext, m, m.Extension())
+		}
+
+		m, err = DetectReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mime, ext, _ := v1.DetectReader(bytes.NewReader(data)); mime != m.String() || ext != m.Extension() {
+			t.Errorf("%s: DetectReader: v1 %s %s, v2 %s %s", path, mime, ext, m, m.Extension())
+		}
+
+		m, err = DetectFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mime, ext, _ := v1.DetectFile(path); mime != m.String() || ext != m.Extension() {
+			t.Errorf("%s: DetectFile: v1 %s %s, v2 %s %s", path, mime, ext, m, m.Extension())
+		}
+	}
+}
+
+// TestDetectReaderAt checks that the metadata kept at the end of files is
+// found by the functions of seekable sources, which need no rewinding.
+func TestDetectReaderAt(t *testing.T) {
+	data := []byte("%PDF-1.7\n")
+	data = append(data, bytes.Repeat([]byte("% padding\n"), 1000)...)
+	data = append(data, "1 0 obj\n<< /Fields [] /XFA 2 0 R >>\nendobj\n%%EOF\n"...)
+
+	if m := Detect(data, WithLimit(ReadLimit)); m.Meta("xfa") != "" {
+		t.Errorf("Detect: the XFA key is past the read limit, got xfa=%q", m.Meta("xfa"))
+	}
+	r := bytes.NewReader(data)
+	m, err := DetectReaderAt(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Is("application/pdf") || m.Meta("xfa") != "true" {
+		t.Errorf("DetectReaderAt: expected application/pdf with xfa=true, got %s with xfa=%q", m, m.Meta("xfa"))
+	}
+	if r.Len() != len(data) {
+		t.Errorf("DetectReaderAt: the offset of the reader moved to %d", len(data)-r.Len())
+	}
+
+	f, err := ioutil.TempFile("", "mimetype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Write(data)
+	f.Close()
+	if m, err := DetectFile(f.Name()); err != nil || m.Meta("xfa") != "true" {
+		t.Errorf("DetectFile: expected xfa=true, got %q, %v", m.Meta("xfa"), err)
+	}
+
+	if m, err := DetectReaderAt(r, 0); err != nil || m.String() != "inode/x-empty" {
+		t.Errorf("DetectReaderAt: expected inode/x-empty for an empty input, got %s, %v", m, err)
+	}
+}
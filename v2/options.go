@@ -0,0 +1,66 @@
+package mimetype
+
+import v1 "github.com/gabriel-vasile/mimetype"
+
+// Option configures the behavior of the Detect functions.
+// Options not relevant to a function are ignored by it.
+type Option = v1.Option
+
+// Cache is a bounded cache of detection results, safe for concurrent use.
+type Cache = v1.Cache
+
+// NewCache returns a cache holding at most size detection results.
+func NewCache(size int) *Cache {
+	return v1.NewCache(size)
+}
+
+// WithCache makes the detection look up its result in cache before running
+// the matchers, and store it there afterwards.
+func WithCache(cache *Cache) Option {
+	return v1.WithCache(cache)
+}
+
+// WithLimit sets the maximum number of bytes examined during detection,
+// instead of ReadLimit for readers and files, and instead of the whole
+// input for byte slices.
+func WithLimit(limit int) Option {
+	return v1.WithLimit(limit)
+}
+
+// WithHint provides the name of the file being detected. Its extension is
+// trusted only when it belongs to one of the subtypes of the detected type.
+func WithHint(name string) Option {
+	return v1.WithHint(name)
+}
+
+// WithSubtree starts the detection from the node of the parent MIME type,
+// for inputs already known to be of that type.
+func WithSubtree(parent string) Option {
+	return v1.WithSubtree(parent)
+}
+
+// WithCharset adds a charset parameter to the detected text formats
+// which do not declare one.
+func WithCharset() Option {
+	return v1.WithCharset()
+}
+
+// WithStrictJSON validates the inputs detected as text, JSON or NDJSON
+// against the JSON grammar, reporting JSON only for documents holding an
+// object or an array, and NDJSON only for several of them, one per line.
+func WithStrictJSON() Option {
+	return v1.WithStrictJSON()
+}
+
+// WithPriority sets the priority of the matcher added by Extend. Children
+// with a higher priority are tried first. Built-in matchers have priority 0.
+func WithPriority(priority int) Option {
+	return v1.WithPriority(priority)
+}
+
+// WithParallel evaluates the top-level matchers concurrently, without
+// changing the result. Matchers added with Extend must then be safe for
+// concurrent use.
+func WithParallel() Option {
+	return v1.WithParallel()
+}
@@ -0,0 +1,130 @@
+package mimetype
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// defaultPollInterval is the time Watch waits between two scans of the
+// watched directory, unless WithPollInterval is used.
+const defaultPollInterval = time.Second
+
+// fileState is what Watch remembers of a file between two scans.
+type fileState struct {
+	size     int64
+	modTime  time.Time
+	reported bool // the current version of the file was reported
+}
+
+// Watch scans the directory tree rooted at dir periodically, and calls f
+// with the detection result of the files created or modified since Watch
+// started. Files are reported once they have kept the same size and
+// modification time for a whole scan interval, so the files being written
+// are reported once complete, not after each write. The files present when
+// Watch starts are not reported, until they are modified.
+//
+// Paths which cannot be read are reported with an error, once until they
+// can be read again. Watch polls the file system, so it works on all the
+// platforms and file systems, network ones included, without dependencies;
+// the scan interval is set with WithPollInterval. Symbolic links are followed
+// when WithFollowSymlinks is used and the files larger than the size set by
+// WithMaxSize are skipped.
+//
+// Watch returns when ctx is done, with the error of ctx, or immediately if
+// dir cannot be read. f is called from the goroutine running Watch.
+func Watch(ctx context.Context, dir string, f func(DirResult), opts ...Option) error {
+	c := newConfig(opts)
+	interval := c.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	w := &watcher{config: c, dir: dir, buf: make([]byte, c.readLimit())}
+	if err := w.scan(nil); err != nil {
+		return err
+	}
+	// Files existing before Watch are not reported.
+	for _, s := range w.files {
+		s.reported = true
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			w.scan(f)
+		}
+	}
+}
+
+type watcher struct {
+	*config
+	dir    string
+	buf    []byte
+	files  map[string]*fileState
+	failed map[string]bool // paths whose error was reported
+}
+
+// scan walks the directory tree, reporting to report the files which did
+// not change since the previous scan and were not reported yet. It returns
+// the error of reading dir itself.
+func (w *watcher) scan(report func(DirResult)) error {
+	info, err := os.Stat(w.dir)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]*fileState, len(w.files))
+	failed := map[string]bool{}
+	walker := &dirWalker{
+		config: w.config,
+		file: func(path string, info os.FileInfo) {
+			s, ok := w.files[path]
+			if !ok || s.size != info.Size() || !s.modTime.Equal(info.ModTime()) {
+				// New or changed: wait for the next scan to tell whether
+				// it is still being written.
+				files[path] = &fileState{size: info.Size(), modTime: info.ModTime()}
+				return
+			}
+			files[path] = s
+			if s.reported || report == nil {
+				return
+			}
+			m, err := detectFileMIME(path, w.buf, w.config)
+			if os.IsNotExist(err) {
+				// Removed since it was walked.
+				delete(files, path)
+				return
+			}
+			if err != nil {
+				w.fail(failed, report, path, err)
+				return
+			}
+			s.reported = true
+			if m != nil {
+				report(DirResult{Path: path, MIME: m})
+			}
+		},
+		fail: func(path string, err error) {
+			if report != nil {
+				w.fail(failed, report, path, err)
+			}
+		},
+		visited: map[string]bool{},
+	}
+	walker.walk(w.dir, info)
+	w.files, w.failed = files, failed
+
+	return nil
+}
+
+// fail records the error of path in failed, the errors of the current
+// scan, and reports it unless the previous scan reported it already.
+func (w *watcher) fail(failed map[string]bool, report func(DirResult), path string, err error) {
+	failed[path] = true
+	if !w.failed[path] {
+		report(DirResult{Path: path, Err: err})
+	}
+}
@@ -0,0 +1,76 @@
+package mimetype
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mimetype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	png, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, data []byte) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("old.png", png)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan DirResult, 10)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, dir, func(r DirResult) { results <- r }, WithPollInterval(10*time.Millisecond))
+	}()
+
+	expect := func(name, mime string) {
+		t.Helper()
+		select {
+		case r := <-results:
+			if r.Err != nil || r.Path != filepath.Join(dir, name) || r.MIME.String() != mime {
+				t.Errorf("expected %s of type %s, got %s of type %s, %v", name, mime, r.Path, r.MIME, r.Err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s was not reported", name)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	write("sub/new.pdf", []byte("%PDF-1.7\n"))
+	expect("sub/new.pdf", PDF)
+	// A file modified is reported again, with its new type.
+	write("sub/new.pdf", png)
+	expect("sub/new.pdf", "image/png")
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	// The file existing before Watch started, and the ones reported once,
+	// are not reported.
+	select {
+	case r := <-results:
+		t.Errorf("unexpected result for %s", r.Path)
+	default:
+	}
+}
+
+func TestWatchMissingDir(t *testing.T) {
+	err := Watch(context.Background(), filepath.Join(testDataDir, "inexistent"), func(DirResult) {})
+	if !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
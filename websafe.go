@@ -0,0 +1,65 @@
+package mimetype
+
+import "strings"
+
+// scriptable lists the formats a browser may execute scripts from, when
+// served inline from the origin of a website. Formats using the +xml
+// suffix are scriptable too, through XSLT.
+var scriptable = map[string]bool{
+	"text/html":              true,
+	"application/xhtml+xml":  true,
+	"image/svg+xml":          true,
+	"text/xml":               true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"text/javascript":        true,
+}
+
+// WebSafeType returns the Content-Type to use when serving content of the
+// detected type uploaded by untrusted users, and whether it should be served
+// as an attachment, with a "Content-Disposition: attachment" header, instead
+// of being displayed by the browser.
+//
+// The mapping follows the practice of file hosting services:
+//   - formats browsers can run scripts from, like HTML, SVG, XML and
+//     JavaScript, are served as text/plain, so their source is displayed;
+//   - text formats get a charset parameter, utf-8 unless the result has
+//     one, so WithCharset should be used for the detection;
+//   - images, audio, video and PDF documents are displayed;
+//   - archives, documents, fonts and databases keep their type but are
+//     downloaded;
+//   - everything else, like executables, is downloaded as
+//     application/octet-stream.
+func WebSafeType(m *MIME) (contentType string, attachment bool) {
+	mime := mediaType(m.mime)
+	switch {
+	case scriptable[mime] || strings.HasSuffix(mime, "+xml"):
+		return "text/plain; charset=" + charsetParam(m.mime), false
+	case m.kind == KindText:
+		if strings.Contains(m.mime, "charset=") {
+			return m.mime, false
+		}
+		return m.mime + "; charset=utf-8", false
+	case m.kind == KindImage, m.kind == KindAudio, m.kind == KindVideo, mime == PDF:
+		return m.mime, false
+	case m.kind == KindArchive, m.kind == KindDocument, m.kind == KindFont, m.kind == KindDatabase:
+		return m.mime, true
+	}
+
+	return OctetStream, true
+}
+
+// charsetParam returns the value of the charset parameter
+// of the MIME type, or utf-8 if there is none.
+func charsetParam(mime string) string {
+	i := strings.Index(mime, "charset=")
+	if i == -1 {
+		return "utf-8"
+	}
+	cs := mime[i+len("charset="):]
+	if end := strings.IndexByte(cs, ';'); end != -1 {
+		cs = cs[:end]
+	}
+
+	return strings.TrimSpace(cs)
+}
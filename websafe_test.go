@@ -0,0 +1,48 @@
+package mimetype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebSafeType(t *testing.T) {
+	tcs := []struct {
+		file        string
+		contentType string
+		attachment  bool
+	}{
+		{"html.html", "text/plain; charset=utf-8", false},
+		{"svg.svg", "text/plain; charset=utf-8", false},
+		{"xml.xml", "text/plain; charset=utf-8", false},
+		{"rss.rss", "text/plain; charset=utf-8", false},
+		{"js.js", "text/plain; charset=utf-8", false},
+		{"csv.csv", "text/csv; charset=utf-8", false},
+		{"json.json", "application/json; charset=utf-8", false},
+		{"png.png", "image/png", false},
+		{"mp4.mp4", "video/mp4", false},
+		{"pdf.pdf", "application/pdf", false},
+		{"zip.zip", "application/zip", true},
+		{"docx.docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", true},
+		{"exe.exe", "application/octet-stream", true},
+		{"swf.swf", "application/octet-stream", true},
+	}
+	for _, tc := range tcs {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, tc.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, _ := DetectBytes(data)
+		ct, attachment := WebSafeType(m)
+		if ct != tc.contentType || attachment != tc.attachment {
+			t.Errorf("%s: expected %q, attachment %t, got %q, attachment %t",
+				tc.file, tc.contentType, tc.attachment, ct, attachment)
+		}
+	}
+
+	// The charset found during detection is kept.
+	m, _ := DetectBytes([]byte("caf\xE9 cr\xE8me"), WithCharset())
+	if ct, _ := WebSafeType(m); ct != "text/plain; charset=iso-8859-1" {
+		t.Errorf("expected the charset of the input, got %q", ct)
+	}
+}
@@ -0,0 +1,110 @@
+package mimetype
+
+import "io"
+
+// SniffWriter is an io.Writer detecting the MIME type of the data written
+// to it, and forwarding the data to an underlying writer. The data is held
+// back until the detection is done, so the callback receiving the result
+// runs before the first byte reaches the underlying writer: it can, for
+// example, set the Content-Type header of an http.ResponseWriter.
+//
+// The detection is done as soon as the bytes written are enough to decide
+// the type, like DetectReader does, or once the read limit is reached.
+// Close must be called after the last write, to detect and forward the data
+// of the streams shorter than that.
+type SniffWriter struct {
+	w    io.Writer
+	c    *config
+	f    func(*MIME)
+	buf  []byte
+	want int // length of buf at which to check whether it is enough
+	ss   streams
+	m    *MIME
+}
+
+// NewSniffWriter returns a SniffWriter forwarding the data written to it
+// to w, and calling f, which may be nil, with the detection result.
+// The options are used for the detection.
+func NewSniffWriter(w io.Writer, f func(*MIME), opts ...Option) *SniffWriter {
+	return &SniffWriter{w: w, c: newConfig(opts), f: f, want: firstRead, ss: streams{}}
+}
+
+// Write buffers p until the detection is done, and then writes it to the
+// underlying writer.
+func (s *SniffWriter) Write(p []byte) (int, error) {
+	if s.m != nil {
+		return s.w.Write(p)
+	}
+	limit := s.c.readLimit()
+	n := len(p)
+	if room := limit - len(s.buf); n > room {
+		n = room
+	}
+	s.buf = append(s.buf, p[:n]...)
+	if !s.enough(limit) {
+		return len(p), nil
+	}
+	if err := s.flush(); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		written, err := s.w.Write(p[n:])
+		return n + written, err
+	}
+
+	return len(p), nil
+}
+
+// Close detects the type of the data written so far, if it is not detected
+// yet, and forwards the data held back. It does not close the underlying
+// writer.
+func (s *SniffWriter) Close() error {
+	if s.m != nil {
+		return nil
+	}
+
+	return s.flush()
+}
+
+// MIME returns the detection result, or nil if the detection is not done.
+func (s *SniffWriter) MIME() *MIME {
+	return s.m
+}
+
+// enough reports whether the data held back is enough to decide its type.
+func (s *SniffWriter) enough(limit int) bool {
+	if len(s.buf) >= limit {
+		return true
+	}
+	if len(s.buf) < s.want {
+		return false
+	}
+	n, need := s.c.start(s.buf).matchPrefix(s.buf, nil, s.ss, 0)
+	switch {
+	case need == 0 && hasMeta(n):
+		// The metadata functions look past the bytes deciding the type.
+		s.want = limit
+	case need == 0:
+		return true
+	case need < 0:
+		s.want = limit
+	default:
+		s.want = need
+	}
+
+	return false
+}
+
+// flush detects the type of the data held back, reports it to the
+// callback and writes the data to the underlying writer.
+func (s *SniffWriter) flush() error {
+	s.m = s.c.detect(s.buf)
+	s.ss = nil
+	if s.f != nil {
+		s.f(s.m)
+	}
+	_, err := s.w.Write(s.buf)
+	s.buf = nil
+
+	return err
+}
@@ -0,0 +1,69 @@
+package mimetype
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestSniffWriter checks that the data written in chunks of various sizes
+// is forwarded whole, and detected like DetectReader does, before any of it
+// reaches the underlying writer.
+func TestSniffWriter(t *testing.T) {
+	for f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(testDataDir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _, _ := DetectReader(bytes.NewReader(data))
+		for _, chunk := range []int{1, 100, 1 << 20} {
+			out := &bytes.Buffer{}
+			calls := 0
+			sw := NewSniffWriter(out, func(m *MIME) {
+				calls++
+				if out.Len() != 0 {
+					t.Errorf("%s: %d bytes were forwarded before the detection", f, out.Len())
+				}
+			})
+			for rest := data; len(rest) > 0; {
+				l := chunk
+				if l > len(rest) {
+					l = len(rest)
+				}
+				if written, err := sw.Write(rest[:l]); err != nil || written != l {
+					t.Fatalf("%s: wrote %d of %d bytes: %v", f, written, l, err)
+				}
+				rest = rest[l:]
+			}
+			if err := sw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if calls != 1 {
+				t.Errorf("%s: the callback was called %d times", f, calls)
+			}
+			if !bytes.Equal(out.Bytes(), data) {
+				t.Errorf("%s: the data was not forwarded whole", f)
+			}
+			if got := sw.MIME().String(); got != want {
+				t.Errorf("%s, chunks of %d bytes: expected %s, got %s", f, chunk, want, got)
+			}
+		}
+	}
+}
+
+// TestSniffWriterEarly checks that the detection does not wait for the read
+// limit when the data written is enough to decide the type.
+func TestSniffWriterEarly(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join(testDataDir, "png.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := NewSniffWriter(ioutil.Discard, nil)
+	if _, err := sw.Write(data[:firstRead]); err != nil {
+		t.Fatal(err)
+	}
+	if m := sw.MIME(); m == nil || !m.Is(PNG) {
+		t.Errorf("expected %s to be detected from the first %d bytes, got %v", PNG, firstRead, m)
+	}
+}